@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// promptReadyTimeout is how long `gtw add --prompt` waits for the agent (or
+// ready_pattern) before typing the prompt anyway.
+const promptReadyTimeout = 60 * time.Second
+
+// Agent launches an interactive tool in a worker pane and tells from the
+// pane when it is ready for a prompt.
+type Agent interface {
+	Name() string
+	// Command starts the agent; "" runs the worker's init command instead.
+	Command() string
+	// Ready reports whether the agent accepts input, given the pane's
+	// foreground command ("" if unknown), its recent output and how long the
+	// output has not changed.
+	Ready(command, output string, unchangedFor time.Duration) bool
+}
+
+// patternAgent is ready once its output matches a pattern, e.g. the input
+// box of Claude Code or the prompt of aider.
+type patternAgent struct {
+	name    string
+	command string
+	pattern *regexp.Regexp
+}
+
+func (a patternAgent) Name() string    { return a.name }
+func (a patternAgent) Command() string { return a.command }
+
+func (a patternAgent) Ready(command, output string, unchangedFor time.Duration) bool {
+	if command != "" && isShellCommand(command) {
+		// Not started yet, or it already exited
+		return false
+	}
+	return a.pattern.MatchString(output)
+}
+
+// replAgent is any REPL-style tool: it is ready when it runs in the
+// foreground and its output has settled.
+type replAgent struct{}
+
+func (replAgent) Name() string    { return "repl" }
+func (replAgent) Command() string { return "" }
+
+func (replAgent) Ready(command, output string, unchangedFor time.Duration) bool {
+	if command != "" && isShellCommand(command) {
+		return false
+	}
+	return strings.TrimSpace(output) != "" && unchangedFor >= readyQuiet
+}
+
+// agentPresets are the presets that have an agent adapter.
+var agentPresets = []string{"claude", "aider", "codex"}
+
+// agentNames lists the available agents for flags and error messages.
+func agentNames() []string {
+	names := append([]string{}, agentPresets...)
+	names = append(names, replAgent{}.Name())
+	sort.Strings(names)
+	return names
+}
+
+// findAgent returns the adapter called name. Its command comes from the
+// agents config (e.g. {"claude": "claude --model opus"}) or the preset.
+func findAgent(config *Config, name string) (Agent, error) {
+	if name == (replAgent{}).Name() {
+		return replAgent{}, nil
+	}
+	for _, agent := range agentPresets {
+		if agent != name {
+			continue
+		}
+		preset, _ := findPreset(name)
+		command := preset.InitCommand
+		if override := config.Agents[name]; override != "" {
+			command = override
+		}
+		return patternAgent{name: name, command: command, pattern: regexp.MustCompile(preset.ReadyPattern)}, nil
+	}
+	return nil, fmt.Errorf("unknown agent '%s' (available: %s)", name, strings.Join(agentNames(), ", "))
+}
+
+// workerAgent returns what decides when a worker accepts its prompt: its
+// agent, else a ready_pattern of its profile or the config. nil means the
+// prompt is sent right away.
+func workerAgent(config *Config, worker Worker) (Agent, error) {
+	if worker.Agent != "" {
+		return findAgent(config, worker.Agent)
+	}
+	readyPattern := config.readyPatternFor(worker)
+	if readyPattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(readyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ready_pattern: %v", err)
+	}
+	return patternAgent{name: "ready_pattern", pattern: pattern}, nil
+}
+
+// waitForAgent polls the pane until the agent is ready or the timeout
+// expires.
+func waitForAgent(paneID string, agent Agent, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	previous := ""
+	changedAt := time.Now()
+	for {
+		command, err := paneCurrentCommand(paneID)
+		if err != nil {
+			return err
+		}
+		output, err := capturePane(paneID, 50)
+		if err != nil {
+			return err
+		}
+		if output != previous {
+			previous = output
+			changedAt = time.Now()
+		}
+		if agent.Ready(command, output, time.Since(changedAt)) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s in pane %s was not ready after %s", agent.Name(), paneID, timeout)
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// sendPrompt pastes the prompt into a new worker's pane, first waiting for
+// the agent started by the init command to be ready.
+func sendPrompt(config *Config, worker Worker, prompt string) {
+	agent, err := workerAgent(config, worker)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if agent != nil {
+		fmt.Printf("Waiting for %s in worker '%s' to be ready...\n", agent.Name(), worker.ID)
+		if err := waitForAgent(worker.PaneID, agent, promptReadyTimeout); err != nil {
+			fmt.Printf("Warning: %v; sending the prompt anyway\n", err)
+		}
+	}
+	if err := pastePrompt(worker.PaneID, prompt, true); err != nil {
+		fmt.Printf("Warning: Could not send the prompt: %v\n", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgentReady(t *testing.T) {
+	config := &Config{}
+	claude, err := findAgent(config, "claude")
+	if err != nil {
+		t.Fatalf("findAgent(claude) returned error: %v", err)
+	}
+	aider, _ := findAgent(config, "aider")
+	repl, _ := findAgent(config, "repl")
+
+	tests := []struct {
+		name         string
+		agent        Agent
+		command      string
+		output       string
+		unchangedFor time.Duration
+		want         bool
+	}{
+		{"claude input box", claude, "node", "│ >  │\n  ? for shortcuts", 0, true},
+		{"claude starting", claude, "node", "Welcome to Claude Code", time.Minute, false},
+		{"claude exited", claude, "bash", "  ? for shortcuts\n$ ", 0, false},
+		{"aider prompt", aider, "python3", "Repo-map: using 1024 tokens\n> ", 0, true},
+		{"aider unknown command", aider, "", "> ", 0, true},
+		{"aider loading", aider, "python3", "Aider v0.80.0", 0, false},
+		{"repl settled", repl, "python3", ">>> ", readyQuiet, true},
+		{"repl printing", repl, "python3", ">>> ", 0, false},
+		{"repl not started", repl, "zsh", "$ ", time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := tt.agent.Ready(tt.command, tt.output, tt.unchangedFor); got != tt.want {
+			t.Errorf("%s: Ready() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindAgent(t *testing.T) {
+	config := &Config{Agents: map[string]string{"claude": "claude --model opus"}}
+	agent, err := findAgent(config, "claude")
+	if err != nil {
+		t.Fatalf("findAgent returned error: %v", err)
+	}
+	if agent.Command() != "claude --model opus" {
+		t.Errorf("Expected the configured command, got %q", agent.Command())
+	}
+	if agent, _ := findAgent(config, "aider"); agent.Command() != "aider --no-auto-commits --no-pretty" {
+		t.Errorf("Expected the preset command, got %q", agent.Command())
+	}
+	if _, err := findAgent(config, "npm-dev"); err == nil {
+		t.Error("Expected an error for a preset without an agent")
+	}
+}
+
+func TestInitCommandForAgent(t *testing.T) {
+	config := &Config{
+		InitCommand: "default",
+		Profiles:    map[string]Profile{"py": {InitCommand: "python3"}},
+	}
+	if got := config.initCommandFor(Worker{Agent: "codex", Profile: "py"}); got != "codex" {
+		t.Errorf("initCommandFor(codex) = %q", got)
+	}
+	// The generic REPL runs the profile's command
+	if got := config.initCommandFor(Worker{Agent: "repl", Profile: "py"}); got != "python3" {
+		t.Errorf("initCommandFor(repl) = %q", got)
+	}
+}
+
+func TestWorkerAgent(t *testing.T) {
+	config := &Config{ReadyPattern: `ready>`}
+	agent, err := workerAgent(config, Worker{})
+	if err != nil || agent == nil {
+		t.Fatalf("Expected a ready_pattern agent, got %v, %v", agent, err)
+	}
+	if !agent.Ready("", "ready>", 0) {
+		t.Error("Expected the ready_pattern to make the worker ready")
+	}
+	if agent, _ := workerAgent(&Config{}, Worker{}); agent != nil {
+		t.Errorf("Expected no agent without ready_pattern, got %v", agent)
+	}
+	if _, err := workerAgent(&Config{ReadyPattern: "("}, Worker{}); err == nil {
+		t.Error("Expected an error for an invalid ready_pattern")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var archiveForce bool
+	archiveCmd := &cobra.Command{
+		Use:   "archive <worker-id>",
+		Short: "Park a worker: close its pane and worktree but keep its branch",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { archiveWorker(args[0], archiveForce) },
+	}
+	archiveCmd.Flags().BoolVarP(&archiveForce, "force", "f", false, "Archive even if the worktree has uncommitted changes (they are lost)")
+	rootCmd.AddCommand(archiveCmd)
+
+	var unarchiveNoInit bool
+	unarchiveCmd := &cobra.Command{
+		Use:   "unarchive <worker-id>",
+		Short: "Recreate an archived worker's worktree and pane",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { unarchiveWorker(args[0], !unarchiveNoInit) },
+	}
+	unarchiveCmd.Flags().BoolVar(&unarchiveNoInit, "no-init", false, "Do not run the initialization command in the new pane")
+	rootCmd.AddCommand(unarchiveCmd)
+}
+
+func findArchivedIndex(config *Config, id string) int {
+	for i, w := range config.Archived {
+		if w.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func archiveWorker(id string, force bool) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := config.Workers[index]
+	if refuseLocked(id, worker.WorktreePath) {
+		return
+	}
+
+	worktreeExists := false
+	if _, err := os.Stat(worker.WorktreePath); err == nil {
+		worktreeExists = true
+	}
+
+	if worktreeExists && !force {
+		dirty, err := worktreeDirty(worker.WorktreePath)
+		if err != nil {
+			fmt.Printf("Error checking worktree for changes: %v\n", err)
+			return
+		}
+		if dirty {
+			fmt.Printf("Error: Worktree '%s' has uncommitted changes\n", worker.WorktreePath)
+			fmt.Printf("Commit them to the branch first, or pass --force to discard them\n")
+			return
+		}
+	}
+
+	worker.Branch = getWorktreeBranch(worker.WorktreePath, worker.branchName())
+	fmt.Printf("Archiving worker '%s' (branch: %s)...\n", id, worker.Branch)
+
+	if mux.PaneExists(worker.PaneID) {
+		if err := mux.KillPane(worker.PaneID); err != nil {
+			fmt.Printf("Warning: Could not kill pane: %v\n", err)
+		}
+	}
+
+	if worktreeExists {
+		args := []string{"worktree", "remove", worker.WorktreePath}
+		if force {
+			args = []string{"worktree", "remove", "--force", worker.WorktreePath}
+		}
+		if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+			fmt.Printf("❌ Error removing git worktree: %v\n", err)
+			fmt.Printf("Git output: %s\n", string(output))
+			return
+		}
+	} else {
+		gitCommand("worktree", "prune").Run()
+	}
+
+	now := time.Now()
+	worker.setState(StateArchived, "gtw archive")
+	worker.PaneID = ""
+	worker.ArchivedAt = &now
+	config.Workers = append(config.Workers[:index], config.Workers[index+1:]...)
+	config.Archived = append(config.Archived, worker)
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	recordEvent(EventWorkerArchived, id, "branch "+worker.Branch)
+	fmt.Printf("✅ Archived worker '%s'. Branch '%s' was kept; run 'gtw unarchive %s' to restore it.\n", id, worker.Branch, id)
+}
+
+func unarchiveWorker(id string, runInit bool) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findArchivedIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Archived worker '%s' not found\n", id)
+		return
+	}
+	if findWorkerIndex(config, id) != -1 {
+		fmt.Printf("Worker '%s' already exists\n", id)
+		return
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return
+	}
+	if !mux.HasSession(sessionName) {
+		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+		return
+	}
+
+	// Move the worker back first so restoreWorkers keeps its metadata
+	worker := config.Archived[index]
+	worker.ArchivedAt = nil
+	config.Archived = append(config.Archived[:index], config.Archived[index+1:]...)
+	config.Workers = append(config.Workers, worker)
+
+	restored := restoreWorkers(config, sessionName, []SnapshotWorker{{
+		ID:           worker.ID,
+		Branch:       worker.Branch,
+		WorktreePath: worker.WorktreePath,
+	}}, runInit)
+	if restored == 0 {
+		fmt.Printf("❌ Could not unarchive worker '%s'; it stays archived\n", id)
+		return
+	}
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	recordEvent(EventWorkerUnarchived, id, "")
+	fmt.Printf("✅ Unarchived worker '%s'\n", id)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveAndUnarchive(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := filepath.Join(t.TempDir(), "demo")
+	os.Mkdir(dir, 0755)
+	t.Chdir(dir)
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("init", "-q", "-b", "main")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	run("worktree", "add", "-q", "-b", "feature/one", "worktree/issue-1")
+
+	worktree := filepath.Join("worktree", "issue-1")
+	config := &Config{ProjectPath: dir, Workers: []Worker{
+		{ID: "issue-1", PaneID: "%1", WorktreePath: worktree, Note: "parked", Tags: []string{"backend"}},
+		{ID: "issue-2", PaneID: "%2", WorktreePath: filepath.Join("worktree", "issue-2")},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	fake := newSessionMultiplexer("%1", "%2")
+	fake.sessions["demo"] = true
+	useMultiplexer(t, fake)
+
+	// Uncommitted changes would be lost
+	os.WriteFile(filepath.Join(worktree, "draft.txt"), []byte("x"), 0644)
+	archiveWorker("issue-1", false)
+	if saved, _ := loadConfig(); len(saved.Archived) != 0 {
+		t.Fatal("Expected a dirty worktree not to be archived")
+	}
+	os.Remove(filepath.Join(worktree, "draft.txt"))
+
+	archiveWorker("issue-1", false)
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Workers) != 1 || saved.Workers[0].ID != "issue-2" {
+		t.Errorf("Expected only issue-2 to stay a worker, got %+v", saved.Workers)
+	}
+	if len(saved.Archived) != 1 {
+		t.Fatalf("Expected 1 archived worker, got %+v", saved.Archived)
+	}
+	archived := saved.Archived[0]
+	if archived.Branch != "feature/one" || archived.PaneID != "" || archived.ArchivedAt == nil || archived.Status != StateArchived {
+		t.Errorf("Unexpected archived worker: %+v", archived)
+	}
+	if len(fake.killed) != 1 || fake.killed[0] != "%1" {
+		t.Errorf("Expected pane %%1 to be killed, got %v", fake.killed)
+	}
+	if _, err := os.Stat(worktree); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree to be removed, got %v", err)
+	}
+	run("rev-parse", "--verify", "-q", "feature/one") // The branch is kept
+
+	// The archived worker only shows up in the archived list
+	list := captureStdout(func() { listWorkers(ListOptions{}) })
+	if strings.Contains(list, "issue-1") || !strings.Contains(list, "issue-2") {
+		t.Errorf("Expected only issue-2 in the worker list, got:\n%s", list)
+	}
+	list = captureStdout(func() { listWorkers(ListOptions{Archived: true}) })
+	if !strings.Contains(list, "issue-1") || !strings.Contains(list, "feature/one") || strings.Contains(list, "issue-2") {
+		t.Errorf("Expected only issue-1 in the archived list, got:\n%s", list)
+	}
+	list = captureStdout(func() { listWorkers(ListOptions{Archived: true, Tags: []string{"frontend"}}) })
+	if !strings.Contains(list, "No archived workers found") {
+		t.Errorf("Expected the tag to filter the archived list, got:\n%s", list)
+	}
+
+	// Adding a worker with the same ID points at unarchive instead
+	if output := captureStdout(func() { addWorker("issue-1", AddOptions{}) }); !strings.Contains(output, "gtw unarchive issue-1") {
+		t.Errorf("Expected add to refuse an archived ID, got:\n%s", output)
+	}
+
+	unarchiveWorker("issue-1", false)
+	saved, err = loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Archived) != 0 || len(saved.Workers) != 2 {
+		t.Fatalf("Expected issue-1 to be a worker again, got workers %+v and archived %+v", saved.Workers, saved.Archived)
+	}
+	worker := saved.Workers[1]
+	if worker.ID != "issue-1" || worker.PaneID != "%100" || worker.ArchivedAt != nil || worker.Note != "parked" || worker.Branch != "feature/one" {
+		t.Errorf("Unexpected unarchived worker: %+v", worker)
+	}
+	if branch := run("-C", worktree, "branch", "--show-current"); branch != "feature/one" {
+		t.Errorf("Expected the worktree to be back on feature/one, got %s", branch)
+	}
+}
+
+func TestUnarchiveWithoutSession(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "demo")
+	os.Mkdir(dir, 0755)
+	t.Chdir(dir)
+	if err := saveConfig(&Config{Archived: []Worker{{ID: "issue-1", Branch: "feature/one"}}}); err != nil {
+		t.Fatal(err)
+	}
+	useMultiplexer(t, newSessionMultiplexer())
+
+	unarchiveWorker("issue-1", false)
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Archived) != 1 || len(saved.Workers) != 0 {
+		t.Errorf("Expected the worker to stay archived, got workers %+v and archived %+v", saved.Workers, saved.Archived)
+	}
+}
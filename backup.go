@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Values of the backup_on_remove config.
+const (
+	BackupAsk    = "ask"
+	BackupAlways = "always"
+	BackupNever  = "never"
+)
+
+// resolveBackupMode picks the backup behavior for `gtw remove` from the
+// flags or the backup_on_remove config value.
+func resolveBackupMode(config *Config, opts RemoveOptions) string {
+	switch {
+	case opts.Backup:
+		return BackupAlways
+	case opts.NoBackup:
+		return BackupNever
+	}
+
+	switch config.BackupOnRemove {
+	case BackupAlways, BackupNever:
+		return config.BackupOnRemove
+	case "", BackupAsk:
+		return BackupAsk
+	}
+	fmt.Printf("Warning: Invalid backup_on_remove %q in config, using %s\n", config.BackupOnRemove, BackupAsk)
+	return BackupAsk
+}
+
+// backupBranchName returns the branch that holds a removed worker's work.
+func backupBranchName(id string, now time.Time) string {
+	return fmt.Sprintf("gtw/backup/%s/%s", id, now.Format("20060102-150405"))
+}
+
+// backupWorktree creates a branch with everything in the worktree: the
+// current HEAD plus, when dirty, a commit of all uncommitted and untracked
+// (but not ignored) files. The worktree and its index are left untouched.
+func backupWorktree(worktreePath, branch string) error {
+	commit := "HEAD"
+
+	dirty, err := worktreeDirty(worktreePath)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		// Stage into a throwaway index so the user's index is not modified
+		indexFile, err := os.CreateTemp("", "gtw-backup-index-")
+		if err != nil {
+			return err
+		}
+		indexFile.Close()
+		os.Remove(indexFile.Name())
+		defer os.Remove(indexFile.Name())
+
+		env := append(os.Environ(), "GIT_INDEX_FILE="+indexFile.Name())
+		git := func(args ...string) (string, error) {
+			cmd := gitCommand(append([]string{"-C", worktreePath}, args...)...)
+			cmd.Env = env
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+			}
+			return strings.TrimSpace(string(output)), nil
+		}
+
+		if _, err := git("read-tree", "HEAD"); err != nil {
+			return err
+		}
+		if _, err := git("add", "-A"); err != nil {
+			return err
+		}
+		tree, err := git("write-tree")
+		if err != nil {
+			return err
+		}
+		commit, err = git("-c", "user.name=gtw", "-c", "user.email=gtw@localhost", "commit-tree", tree, "-p", "HEAD", "-m", "gtw backup of uncommitted changes in "+filepath.Base(worktreePath))
+		if err != nil {
+			return err
+		}
+	}
+
+	output, err := gitCommand("-C", worktreePath, "branch", branch, commit).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git branch: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveBackupMode(t *testing.T) {
+	tests := []struct {
+		configured string
+		opts       RemoveOptions
+		want       string
+	}{
+		{"", RemoveOptions{}, BackupAsk},
+		{BackupAlways, RemoveOptions{}, BackupAlways},
+		{BackupNever, RemoveOptions{Backup: true}, BackupAlways},
+		{BackupAlways, RemoveOptions{NoBackup: true}, BackupNever},
+	}
+
+	for _, tt := range tests {
+		if got := resolveBackupMode(&Config{BackupOnRemove: tt.configured}, tt.opts); got != tt.want {
+			t.Errorf("resolveBackupMode(%q, %+v) = %q, want %q", tt.configured, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestBackupBranchName(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	if got := backupBranchName("issue-1", now); got != "gtw/backup/issue-1/20250304-050607" {
+		t.Errorf("backupBranchName() = %q", got)
+	}
+}
+
+func TestBackupWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(dir, "tracked"), []byte("v1"), 0644)
+	git("add", "tracked")
+	git("commit", "-q", "-m", "init")
+
+	os.WriteFile(filepath.Join(dir, "tracked"), []byte("v2"), 0644)
+	os.WriteFile(filepath.Join(dir, "untracked"), []byte("new"), 0644)
+
+	if err := backupWorktree(dir, "gtw/backup/test/1"); err != nil {
+		t.Fatalf("backupWorktree() failed: %v", err)
+	}
+
+	if got := git("show", "gtw/backup/test/1:tracked"); got != "v2" {
+		t.Errorf("Backup has tracked=%q, want v2", got)
+	}
+	if got := git("show", "gtw/backup/test/1:untracked"); got != "new" {
+		t.Errorf("Backup has untracked=%q, want new", got)
+	}
+
+	// The user's index and files must be untouched
+	if status := git("status", "--porcelain"); status != "M tracked\n?? untracked" {
+		t.Errorf("Worktree status changed to %q", status)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// bareDir is where `gtw init --bare-clone` puts the repository; a .git file
+// next to it points git there, so git commands work from the project root.
+const bareDir = ".bare"
+
+// checkGitRepository reports why worktrees cannot be created from the
+// current directory, with a hint on how to fix it.
+func checkGitRepository() error {
+	if err := gitCommand("rev-parse", "--git-dir").Run(); err != nil {
+		return errors.New("not a git repository; run 'git init' and commit first, or clone into an empty directory with 'gtw init --bare-clone <url>'")
+	}
+	if err := gitCommand("rev-parse", "--verify", "--quiet", "HEAD^{commit}").Run(); err != nil {
+		return errors.New("the repository has no commits yet; worktrees need one (e.g. git commit --allow-empty -m init)")
+	}
+	return nil
+}
+
+// bareClone clones url as a bare repository into the current directory and
+// sets it up for worktrees: a .git file pointing at the repository, and
+// remote-tracking branches, which `git clone --bare` does not configure.
+func bareClone(url string) error {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" || entry.Name() == bareDir {
+			return fmt.Errorf("the current directory already has %s; --bare-clone needs a directory without a repository", entry.Name())
+		}
+	}
+
+	fmt.Printf("Cloning %s into %s...\n", url, bareDir)
+	clone := gitCommand("clone", "--bare", url, bareDir)
+	clone.Timeout = 0 // Large repositories take a while
+	if output, err := clone.CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone failed: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	if err := os.WriteFile(".git", []byte("gitdir: ./"+bareDir+"\n"), 0644); err != nil {
+		return err
+	}
+	if output, err := gitCommand("config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*").CombinedOutput(); err != nil {
+		return fmt.Errorf("configuring origin failed: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	fetch := gitCommand("fetch", "--quiet", "origin")
+	fetch.Timeout = 0
+	if output, err := fetch.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch failed: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCheckGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+
+	if err := checkGitRepository(); err == nil || !strings.Contains(err.Error(), "git init") {
+		t.Errorf("Expected a hint to run git init, got %v", err)
+	}
+	exec.Command("git", "init", "-q").Run()
+	if err := checkGitRepository(); err == nil || !strings.Contains(err.Error(), "no commits") {
+		t.Errorf("Expected an error for a repository without commits, got %v", err)
+	}
+	exec.Command("git", "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init").Run()
+	if err := checkGitRepository(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestBareClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	origin := t.TempDir()
+	if output, err := exec.Command("sh", "-c", "cd "+origin+" && git init -q -b main && git -c user.name=t -c user.email=t@t commit -q --allow-empty -m init").CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+	t.Chdir(t.TempDir())
+
+	if err := bareClone(origin); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkGitRepository(); err != nil {
+		t.Errorf("Expected the clone to be usable, got %v", err)
+	}
+	if output, err := exec.Command("git", "rev-parse", "--verify", "origin/main").CombinedOutput(); err != nil {
+		t.Errorf("Expected remote-tracking branches: %v\n%s", err, output)
+	}
+	if output, err := exec.Command("git", "worktree", "add", "-q", "-b", "api", "worktree/api").CombinedOutput(); err != nil {
+		t.Errorf("Expected worktrees to work: %v\n%s", err, output)
+	}
+	if _, err := os.Stat("worktree/api"); err != nil {
+		t.Error(err)
+	}
+
+	if err := bareClone(origin); err == nil {
+		t.Error("Expected an error when the directory already has a repository")
+	}
+}
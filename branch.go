@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+)
+
+// BranchData is the data given to the branch_template config value.
+type BranchData struct {
+	ID        string // Worker ID
+	User      string // Login name of the current user
+	Project   string // Project directory name
+	Workspace string // --workspace, empty for the default workspace
+}
+
+// currentUser returns the login name of the user running gtw.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		// Windows reports DOMAIN\user
+		return u.Username[strings.LastIndex(u.Username, `\`)+1:]
+	}
+	return os.Getenv("USER")
+}
+
+// renderBranchName expands a branch template such as "feature/{{.ID}}".
+// An empty template names the branch after the worker ID.
+func renderBranchName(branchTemplate string, data BranchData) (string, error) {
+	if branchTemplate == "" {
+		return data.ID, nil
+	}
+	tmpl, err := template.New("branch").Funcs(formatFuncs).Parse(branchTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	branch := strings.TrimSpace(b.String())
+	if branch == "" {
+		return "", fmt.Errorf("template %q produced an empty branch name", branchTemplate)
+	}
+	return branch, nil
+}
+
+// workerBranchName returns the branch for a new worker, checking that git
+// accepts the name.
+func workerBranchName(config *Config, id string) (string, error) {
+	branch, err := renderBranchName(config.BranchTemplate, BranchData{
+		ID:        id,
+		User:      currentUser(),
+		Project:   getCurrentProjectName(),
+		Workspace: workspace,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := gitCommand("check-ref-format", "--branch", branch).Run(); err != nil {
+		return "", fmt.Errorf("'%s' is not a valid branch name", branch)
+	}
+	return branch, nil
+}
+
+// branchName returns the worker's recorded branch, or its ID for workers
+// created before branches were recorded.
+func (w Worker) branchName() string {
+	if w.Branch != "" {
+		return w.Branch
+	}
+	return w.ID
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestRenderBranchName(t *testing.T) {
+	data := BranchData{ID: "issue-123", User: "alice", Project: "app", Workspace: "review"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "issue-123"},
+		{"feature/{{.ID}}", "feature/issue-123"},
+		{"agent/{{.User}}/{{.ID}}", "agent/alice/issue-123"},
+		{"{{.Project}}/{{.Workspace}}/{{upper .ID}}", "app/review/ISSUE-123"},
+	}
+	for _, tt := range tests {
+		got, err := renderBranchName(tt.template, data)
+		if err != nil || got != tt.want {
+			t.Errorf("renderBranchName(%q) = %q, %v; want %q", tt.template, got, err, tt.want)
+		}
+	}
+
+	for _, template := range []string{"{{.Missing}}", "{{.ID", "{{if false}}x{{end}}"} {
+		if _, err := renderBranchName(template, data); err == nil {
+			t.Errorf("Expected an error for template %q", template)
+		}
+	}
+}
+
+func TestWorkerBranchName(t *testing.T) {
+	if got := (Worker{ID: "w1"}).branchName(); got != "w1" {
+		t.Errorf("Expected the ID for workers without a branch, got %q", got)
+	}
+	if got := (Worker{ID: "w1", Branch: "feature/w1"}).branchName(); got != "feature/w1" {
+		t.Errorf("Expected the recorded branch, got %q", got)
+	}
+}
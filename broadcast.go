@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// BroadcastOptions holds the settings given to `gtw broadcast`.
+type BroadcastOptions struct {
+	Tags          []string
+	Workers       []string
+	IncludePaused bool
+	Interactive   string
+}
+
+func init() {
+	var opts BroadcastOptions
+	broadcastCmd := &cobra.Command{
+		Use:   "broadcast [flags] -- <command>",
+		Short: "Send the same command to every worker pane",
+		Long: `Type the same command into every worker pane (or those selected with --tag
+and --worker) and press Enter. Paused workers are skipped unless
+--include-paused is given.
+
+With --interactive on, tmux's synchronize-panes is enabled on the worker
+windows so that everything typed in one pane goes to all of them; turn it off
+again with --interactive off. Note that this includes every pane of those
+windows, such as the project pane.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.Interactive != "" {
+				setSynchronizePanes(opts)
+				return
+			}
+			if len(args) == 0 {
+				fmt.Println("Error: Specify the command to send after --, e.g. gtw broadcast -- git pull")
+				return
+			}
+			broadcastCommand(strings.Join(args, " "), opts)
+		},
+	}
+	broadcastCmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Only send to workers with this tag (repeatable, all must match)")
+	broadcastCmd.Flags().StringSliceVar(&opts.Workers, "worker", nil, "Only send to these workers (comma-separated or repeatable)")
+	broadcastCmd.Flags().BoolVar(&opts.IncludePaused, "include-paused", false, "Also send to paused workers")
+	broadcastCmd.Flags().StringVar(&opts.Interactive, "interactive", "", "Turn synchronized typing across worker panes on or off (tmux)")
+	rootCmd.AddCommand(broadcastCmd)
+}
+
+// broadcastTargets returns the workers selected by the broadcast filters.
+func broadcastTargets(workers []Worker, opts BroadcastOptions) []Worker {
+	wanted := make(map[string]bool)
+	for _, id := range opts.Workers {
+		wanted[id] = true
+	}
+
+	var targets []Worker
+	for _, worker := range workers {
+		if len(wanted) > 0 && !wanted[worker.ID] {
+			continue
+		}
+		if !hasAllTags(worker, opts.Tags) {
+			continue
+		}
+		if worker.Status == WorkerPaused && !opts.IncludePaused {
+			continue
+		}
+		targets = append(targets, worker)
+	}
+	return targets
+}
+
+func broadcastCommand(command string, opts BroadcastOptions) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	for _, id := range opts.Workers {
+		if findWorkerIndex(config, id) == -1 {
+			fmt.Printf("Worker '%s' not found\n", id)
+			return
+		}
+	}
+
+	targets := broadcastTargets(config.Workers, opts)
+	if len(targets) == 0 {
+		fmt.Println("No matching workers found")
+		return
+	}
+
+	sent := 0
+	for _, worker := range targets {
+		if !mux.PaneExists(worker.PaneID) {
+			fmt.Printf("⚠️  Skipping worker '%s': pane %s not found\n", worker.ID, worker.PaneID)
+			continue
+		}
+		if err := sendToPane(worker.PaneID, command); err != nil {
+			fmt.Printf("❌ Error sending to worker '%s': %v\n", worker.ID, err)
+			continue
+		}
+		recordEvent(EventBroadcast, worker.ID, command)
+		sent++
+	}
+
+	fmt.Printf("✅ Sent to %d of %d worker(s): %s\n", sent, len(targets), command)
+}
+
+// setSynchronizePanes toggles synchronize-panes on every window that holds
+// a selected worker pane.
+func setSynchronizePanes(opts BroadcastOptions) {
+	if !requireTmux("broadcast --interactive") {
+		return
+	}
+
+	value := strings.ToLower(opts.Interactive)
+	if value != "on" && value != "off" {
+		fmt.Printf("Error: --interactive must be 'on' or 'off', got '%s'\n", opts.Interactive)
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	// Targeting a pane sets the option on the window that contains it
+	windows := make(map[string]bool)
+	for _, worker := range broadcastTargets(config.Workers, opts) {
+		// display-message falls back to the current window for a missing pane
+		if !mux.PaneExists(worker.PaneID) {
+			continue
+		}
+		output, err := tmuxCommand("display-message", "-t", worker.PaneID, "-p", "#{window_id}").Output()
+		if err != nil {
+			continue
+		}
+		windows[strings.TrimSpace(string(output))] = true
+	}
+	if len(windows) == 0 {
+		fmt.Println("No matching worker panes found")
+		return
+	}
+
+	ids := make([]string, 0, len(windows))
+	for id := range windows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := tmuxCommand("set-option", "-w", "-t", id, "synchronize-panes", value).Run(); err != nil {
+			fmt.Printf("❌ Error setting synchronize-panes on window %s: %v\n", id, err)
+			return
+		}
+	}
+
+	if value == "on" {
+		fmt.Printf("✅ Synchronized typing is on for %d window(s). Run 'gtw broadcast --interactive off' to stop.\n", len(ids))
+	} else {
+		fmt.Printf("✅ Synchronized typing is off for %d window(s)\n", len(ids))
+	}
+}
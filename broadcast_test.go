@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestBroadcastTargets(t *testing.T) {
+	workers := []Worker{
+		{ID: "a", Tags: []string{"agent"}, Status: "active"},
+		{ID: "b", Tags: []string{"agent"}, Status: WorkerPaused},
+		{ID: "c", Status: "active"},
+	}
+
+	ids := func(workers []Worker) []string {
+		var result []string
+		for _, w := range workers {
+			result = append(result, w.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		opts BroadcastOptions
+		want []string
+	}{
+		{"all", BroadcastOptions{}, []string{"a", "c"}},
+		{"include paused", BroadcastOptions{IncludePaused: true}, []string{"a", "b", "c"}},
+		{"tag", BroadcastOptions{Tags: []string{"agent"}, IncludePaused: true}, []string{"a", "b"}},
+		{"worker", BroadcastOptions{Workers: []string{"c", "b"}}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(broadcastTargets(workers, tt.opts))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
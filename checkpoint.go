@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCheckpointInterval = 15 * time.Minute
+	defaultCheckpointMessage  = "WIP: checkpoint {{.ID}} ({{.Files}} files)"
+)
+
+// CheckpointConfig controls automatic checkpoints of workers with
+// auto_checkpoint set, done by `gtw daemon`.
+type CheckpointConfig struct {
+	Interval string `json:"interval,omitempty"` // Commit at most this often (default: "15m")
+	OnIdle   bool   `json:"on_idle,omitempty"`  // Also commit as soon as the pane goes idle
+	Message  string `json:"message,omitempty"`  // Commit message template
+}
+
+// CheckpointData is the data given to the checkpoint message template.
+type CheckpointData struct {
+	ID     string // Worker ID
+	Branch string
+	Files  int    // Number of changed files
+	Time   string // Local time of the checkpoint (RFC 3339)
+}
+
+// CheckpointOptions holds the settings given to `gtw checkpoint`.
+type CheckpointOptions struct {
+	All     bool
+	Message string
+	Auto    bool // Value of --auto, applied only when the flag is given
+}
+
+func init() {
+	var opts CheckpointOptions
+	checkpointCmd := &cobra.Command{
+		Use:   "checkpoint [worker-id...]",
+		Short: "Commit a worker's uncommitted work to its branch",
+		Long: `Commit everything in the worker's worktree (including untracked files) to its
+branch as a WIP commit, so that a crash does not lose hours of work. Clean
+worktrees and worktrees in the middle of a merge or rebase are skipped.
+
+With --auto, 'gtw daemon' checkpoints the worker on the interval set by
+checkpoint.interval in the config, and when its pane goes idle if
+checkpoint.on_idle is true.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.Flags().Changed("auto") {
+				setAutoCheckpoint(args, opts.All, opts.Auto)
+				return
+			}
+			checkpointWorkers(args, opts)
+		},
+	}
+	checkpointCmd.Flags().BoolVar(&opts.All, "all", false, "Checkpoint every worker")
+	checkpointCmd.Flags().StringVarP(&opts.Message, "message", "m", "", "Commit message template (default: checkpoint.message from the config)")
+	checkpointCmd.Flags().BoolVar(&opts.Auto, "auto", false, "Turn automatic checkpoints by 'gtw daemon' on (--auto) or off (--auto=false)")
+	rootCmd.AddCommand(checkpointCmd)
+}
+
+// selectCheckpointWorkers returns the indexes of the workers named by ids,
+// or of all workers with all.
+func selectCheckpointWorkers(config *Config, ids []string, all bool) ([]int, error) {
+	if all == (len(ids) > 0) {
+		return nil, fmt.Errorf("give worker IDs or --all")
+	}
+	var indexes []int
+	if all {
+		for i := range config.Workers {
+			indexes = append(indexes, i)
+		}
+		return indexes, nil
+	}
+	for _, id := range ids {
+		index := findWorkerIndex(config, id)
+		if index == -1 {
+			return nil, fmt.Errorf("worker '%s' not found", id)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func setAutoCheckpoint(ids []string, all, enabled bool) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	indexes, err := selectCheckpointWorkers(config, ids, all)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, index := range indexes {
+		config.Workers[index].AutoCheckpoint = enabled
+	}
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	fmt.Printf("✅ Automatic checkpoints %s for %d worker(s)\n", state, len(indexes))
+}
+
+func checkpointWorkers(ids []string, opts CheckpointOptions) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	indexes, err := selectCheckpointWorkers(config, ids, opts.All)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	message := opts.Message
+	if message == "" {
+		message = checkpointMessage(config)
+	}
+
+	for _, index := range indexes {
+		worker := config.Workers[index]
+		commit, err := checkpointWorker(worker, message)
+		switch {
+		case err != nil:
+			fmt.Printf("❌ %s: %v\n", worker.ID, err)
+		case commit == "":
+			fmt.Printf("%s: nothing to checkpoint\n", worker.ID)
+		default:
+			fmt.Printf("✅ %s: committed %s\n", worker.ID, commit)
+		}
+	}
+}
+
+// checkpointMessage returns the configured commit message template.
+func checkpointMessage(config *Config) string {
+	if config.Checkpoint != nil && config.Checkpoint.Message != "" {
+		return config.Checkpoint.Message
+	}
+	return defaultCheckpointMessage
+}
+
+// resolveCheckpointInterval returns checkpoint.interval, falling back to the
+// default when it is unset or invalid.
+func resolveCheckpointInterval(config *Config) time.Duration {
+	if config.Checkpoint == nil || config.Checkpoint.Interval == "" {
+		return defaultCheckpointInterval
+	}
+	interval, err := time.ParseDuration(config.Checkpoint.Interval)
+	if err != nil || interval <= 0 {
+		fmt.Printf("Warning: Invalid checkpoint.interval %q in config, using %s\n", config.Checkpoint.Interval, defaultCheckpointInterval)
+		return defaultCheckpointInterval
+	}
+	return interval
+}
+
+// renderCheckpointMessage expands the commit message template.
+func renderCheckpointMessage(messageTemplate string, data CheckpointData) (string, error) {
+	tmpl, err := template.New("checkpoint").Funcs(formatFuncs).Parse(messageTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	message := strings.TrimSpace(b.String())
+	if message == "" {
+		return "", fmt.Errorf("template %q produced an empty commit message", messageTemplate)
+	}
+	return message, nil
+}
+
+// operationInProgress reports the merge, rebase or cherry-pick the worktree
+// is in the middle of, if any. Committing then would end it prematurely.
+func operationInProgress(worktreePath string) string {
+	for _, name := range []string{"MERGE_HEAD", "rebase-merge", "rebase-apply", "CHERRY_PICK_HEAD", "REVERT_HEAD"} {
+		output, err := gitCommand("-C", worktreePath, "rev-parse", "--path-format=absolute", "--git-path", name).Output()
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(strings.TrimSpace(string(output))); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkpointWorker commits all changes in the worker's worktree and returns
+// the new commit's short hash, or "" when there was nothing to commit.
+func checkpointWorker(worker Worker, messageTemplate string) (string, error) {
+	if _, err := os.Stat(worker.WorktreePath); err != nil {
+		return "", fmt.Errorf("worktree %s is missing", worker.WorktreePath)
+	}
+	if op := operationInProgress(worker.WorktreePath); op != "" {
+		return "", fmt.Errorf("skipped: %s in progress", op)
+	}
+
+	output, err := gitCommand("-C", worker.WorktreePath, "status", "--porcelain").Output()
+	if err != nil {
+		return "", fmt.Errorf("git status failed: %v", err)
+	}
+	status := strings.TrimSpace(string(output))
+	if status == "" {
+		return "", nil
+	}
+
+	message, err := renderCheckpointMessage(messageTemplate, CheckpointData{
+		ID:     worker.ID,
+		Branch: getWorktreeBranch(worker.WorktreePath, worker.branchName()),
+		Files:  len(strings.Split(status, "\n")),
+		Time:   time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid checkpoint message: %v", err)
+	}
+
+	if output, err := gitCommand("-C", worker.WorktreePath, "add", "-A").CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git add failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	// Hooks are skipped: a checkpoint must not fail on a linter
+	if output, err := gitCommand("-C", worker.WorktreePath, "commit", "--no-verify", "-q", "-m", message).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git commit failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	output, _ = gitCommand("-C", worker.WorktreePath, "rev-parse", "--short", "HEAD").Output()
+	commit := strings.TrimSpace(string(output))
+	recordEvent(EventCheckpoint, worker.ID, commit)
+	return commit, nil
+}
+
+// Checkpointer runs automatic checkpoints from the daemon.
+type Checkpointer struct {
+	tracker *IdleTracker
+	last    map[string]time.Time
+	idle    map[string]bool
+}
+
+func NewCheckpointer(quiet time.Duration) *Checkpointer {
+	return &Checkpointer{tracker: NewIdleTracker(quiet), last: make(map[string]time.Time), idle: make(map[string]bool)}
+}
+
+// checkpointDue reports whether a worker last checkpointed at last is due
+// at now: the interval has passed, or the pane just went idle with onIdle.
+func checkpointDue(last, now time.Time, interval time.Duration, onIdle, becameIdle bool) bool {
+	if onIdle && becameIdle {
+		return true
+	}
+	return !last.IsZero() && now.Sub(last) >= interval
+}
+
+// Run checkpoints the workers with auto_checkpoint that are due.
+func (c *Checkpointer) Run(config *Config) {
+	interval := resolveCheckpointInterval(config)
+	onIdle := config.Checkpoint != nil && config.Checkpoint.OnIdle
+	now := time.Now()
+
+	for _, worker := range config.Workers {
+		if !worker.AutoCheckpoint || worker.Status == WorkerPaused || worker.Status == WorkerDetached {
+			continue
+		}
+		// The interval starts when the daemon first sees the worker
+		if _, seen := c.last[worker.ID]; !seen {
+			c.last[worker.ID] = now
+		}
+
+		becameIdle := false
+		if onIdle {
+			idle, err := c.tracker.Observe(worker.PaneID)
+			if err == nil {
+				becameIdle = idle && !c.idle[worker.ID]
+				c.idle[worker.ID] = idle
+			}
+		}
+		if !checkpointDue(c.last[worker.ID], now, interval, onIdle, becameIdle) {
+			continue
+		}
+
+		c.last[worker.ID] = now
+		commit, err := checkpointWorker(worker, checkpointMessage(config))
+		if err != nil {
+			fmt.Printf("Warning: Could not checkpoint worker '%s': %v\n", worker.ID, err)
+		} else if commit != "" {
+			fmt.Printf("Checkpointed worker '%s' (%s)\n", worker.ID, commit)
+		}
+	}
+}
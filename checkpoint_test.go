@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCheckpointMessage(t *testing.T) {
+	data := CheckpointData{ID: "api", Branch: "feature/api", Files: 3}
+	if got, err := renderCheckpointMessage(defaultCheckpointMessage, data); err != nil || got != "WIP: checkpoint api (3 files)" {
+		t.Errorf("renderCheckpointMessage() = %q, %v", got, err)
+	}
+	if got, _ := renderCheckpointMessage("wip({{.Branch | upper}})", data); got != "wip(FEATURE/API)" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	if _, err := renderCheckpointMessage("{{if false}}x{{end}}", data); err == nil {
+		t.Error("Expected an error for an empty message")
+	}
+}
+
+func TestCheckpointDue(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if checkpointDue(now.Add(-time.Minute), now, 15*time.Minute, false, false) {
+		t.Error("Expected no checkpoint before the interval")
+	}
+	if !checkpointDue(now.Add(-20*time.Minute), now, 15*time.Minute, false, false) {
+		t.Error("Expected a checkpoint after the interval")
+	}
+	if !checkpointDue(now.Add(-time.Minute), now, 15*time.Minute, true, true) {
+		t.Error("Expected a checkpoint when the pane went idle")
+	}
+	if checkpointDue(now.Add(-time.Minute), now, 15*time.Minute, false, true) {
+		t.Error("Expected idle to be ignored without on_idle")
+	}
+}
+
+func TestCheckpointWorker(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		if output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	worker := Worker{ID: "api", WorktreePath: dir}
+
+	if commit, err := checkpointWorker(worker, defaultCheckpointMessage); err != nil || commit != "" {
+		t.Errorf("Expected nothing to commit, got %q, %v", commit, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644)
+	commit, err := checkpointWorker(worker, defaultCheckpointMessage)
+	if err != nil || commit == "" {
+		t.Fatalf("checkpointWorker() = %q, %v", commit, err)
+	}
+	output, _ := exec.Command("git", "-C", dir, "log", "-1", "--format=%s").Output()
+	if strings.TrimSpace(string(output)) != "WIP: checkpoint api (1 files)" {
+		t.Errorf("Unexpected commit message %q", output)
+	}
+
+	// A merge in progress must not be committed
+	os.WriteFile(filepath.Join(dir, ".git", "MERGE_HEAD"), []byte("0000000000000000000000000000000000000000\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0644)
+	if _, err := checkpointWorker(worker, defaultCheckpointMessage); err == nil || !strings.Contains(err.Error(), "MERGE_HEAD") {
+		t.Errorf("Expected the merge to block the checkpoint, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultCopyLines = 50
+
+// CopyOptions holds the settings given to `gtw copy`.
+type CopyOptions struct {
+	Last int // Lines copied from the end of the pane's output
+}
+
+// PasteOptions holds the settings given to `gtw paste`.
+type PasteOptions struct {
+	Enter bool // Press Enter after pasting
+}
+
+// Clipboard holds the commands that write and read the system clipboard.
+type Clipboard struct {
+	Copy  []string
+	Paste []string
+}
+
+// systemClipboard finds the clipboard commands of this machine. Tests
+// replace it.
+var systemClipboard = func() (*Clipboard, error) {
+	return detectClipboard(runtime.GOOS, os.Getenv, exec.LookPath)
+}
+
+func init() {
+	var copyOpts CopyOptions
+	copyCmd := &cobra.Command{
+		Use:   "copy <worker-id>",
+		Short: "Copy a worker's recent output to the system clipboard",
+		Long: `Copy the last lines of a worker's pane to the system clipboard, using
+pbcopy on macOS, wl-copy on Wayland, xclip or xsel on X11 and clip.exe on WSL.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !copyWorkerOutput(args[0], copyOpts) {
+				os.Exit(1)
+			}
+		},
+	}
+	copyCmd.Flags().IntVarP(&copyOpts.Last, "last", "n", defaultCopyLines, "Number of lines to copy from the end of the output")
+	rootCmd.AddCommand(copyCmd)
+
+	var pasteOpts PasteOptions
+	pasteCmd := &cobra.Command{
+		Use:   "paste <worker-id>",
+		Short: "Paste the system clipboard into a worker's pane",
+		Long: `Paste the system clipboard into a worker's pane through a tmux buffer, as
+one block, so that its newlines do not submit each line on its own. The text
+is not submitted unless --enter is given.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !pasteToWorker(args[0], pasteOpts) {
+				os.Exit(1)
+			}
+		},
+	}
+	pasteCmd.Flags().BoolVar(&pasteOpts.Enter, "enter", false, "Press Enter after pasting")
+	rootCmd.AddCommand(pasteCmd)
+}
+
+// detectClipboard picks the clipboard commands for the platform, preferring
+// the display server gtw runs under.
+func detectClipboard(goos string, getenv func(string) string, lookPath func(string) (string, error)) (*Clipboard, error) {
+	has := func(name string) bool {
+		_, err := lookPath(name)
+		return err == nil
+	}
+
+	switch {
+	case goos == "darwin" && has("pbcopy"):
+		return &Clipboard{Copy: []string{"pbcopy"}, Paste: []string{"pbpaste"}}, nil
+	case getenv("WAYLAND_DISPLAY") != "" && has("wl-copy"):
+		return &Clipboard{Copy: []string{"wl-copy"}, Paste: []string{"wl-paste", "--no-newline"}}, nil
+	case getenv("DISPLAY") != "" && has("xclip"):
+		return &Clipboard{Copy: []string{"xclip", "-selection", "clipboard", "-in"}, Paste: []string{"xclip", "-selection", "clipboard", "-out"}}, nil
+	case getenv("DISPLAY") != "" && has("xsel"):
+		return &Clipboard{Copy: []string{"xsel", "--clipboard", "--input"}, Paste: []string{"xsel", "--clipboard", "--output"}}, nil
+	case has("clip.exe"):
+		return &Clipboard{Copy: []string{"clip.exe"}, Paste: []string{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}, nil
+	}
+	return nil, errors.New("no clipboard command found (install pbcopy, wl-clipboard, xclip or xsel)")
+}
+
+func (c *Clipboard) write(text string) error {
+	cmd := newCommand(c.Copy[0], c.Copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", c.Copy[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *Clipboard) read() (string, error) {
+	output, err := newCommand(c.Paste[0], c.Paste[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", c.Paste[0], err)
+	}
+	text := string(output)
+	if c.Paste[0] == "powershell.exe" {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return text, nil
+}
+
+// copyText trims a capture to its last n lines, without the padding tmux
+// leaves at the end of lines and below the output.
+func copyText(output string, n int) string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func copyWorkerOutput(id string, opts CopyOptions) bool {
+	if opts.Last <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --last must be positive")
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+	worker := config.Workers[index]
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: The pane of worker '%s' is gone (see 'gtw repair')\n", id)
+		return false
+	}
+
+	clipboard, err := systemClipboard()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	output, err := capturePane(worker.PaneID, opts.Last)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	text := copyText(output, opts.Last)
+	if text == "" {
+		fmt.Fprintf(os.Stderr, "Worker '%s' has no output to copy\n", id)
+		return false
+	}
+	if err := clipboard.write(text); err != nil {
+		fmt.Fprintf(os.Stderr, "Error copying to the clipboard: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Copied %d lines of worker '%s' to the clipboard\n", strings.Count(text, "\n"), id)
+	return true
+}
+
+func pasteToWorker(id string, opts PasteOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+	worker := config.Workers[index]
+	if _, ok := mux.(Paster); !ok {
+		fmt.Fprintf(os.Stderr, "Error: 'paste' requires the tmux multiplexer (current: %s)\n", mux.Name())
+		return false
+	}
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: The pane of worker '%s' is gone (see 'gtw repair')\n", id)
+		return false
+	}
+
+	clipboard, err := systemClipboard()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	text, err := clipboard.read()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading the clipboard: %v\n", err)
+		return false
+	}
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "The clipboard is empty")
+		return false
+	}
+	if err := pastePrompt(worker.PaneID, text, opts.Enter); err != nil {
+		fmt.Fprintf(os.Stderr, "Error pasting into worker '%s': %v\n", id, err)
+		return false
+	}
+	fmt.Printf("✅ Pasted %d bytes into worker '%s'\n", len(text), id)
+	return true
+}
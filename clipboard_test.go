@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectClipboard(t *testing.T) {
+	tests := []struct {
+		name  string
+		goos  string
+		env   map[string]string
+		tools []string
+		want  string
+	}{
+		{"macOS", "darwin", nil, []string{"pbcopy"}, "pbcopy"},
+		{"Wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xclip"}, "wl-copy"},
+		{"XWayland without wl-clipboard", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"xclip"}, "xclip"},
+		{"X11 with xsel", "linux", map[string]string{"DISPLAY": ":0"}, []string{"xsel"}, "xsel"},
+		{"no display", "linux", nil, []string{"xclip"}, ""},
+		{"WSL", "linux", nil, []string{"clip.exe"}, "clip.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			lookPath := func(name string) (string, error) {
+				for _, tool := range tt.tools {
+					if tool == name {
+						return "/usr/bin/" + name, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+			clipboard, err := detectClipboard(tt.goos, getenv, lookPath)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("Expected no clipboard, got %+v", clipboard)
+				}
+				return
+			}
+			if err != nil || clipboard.Copy[0] != tt.want {
+				t.Errorf("Expected %s, got %+v (%v)", tt.want, clipboard, err)
+			}
+		})
+	}
+}
+
+func TestCopyText(t *testing.T) {
+	output := "one\ntwo   \nthree\t\n\n\n"
+	if got := copyText(output, 50); got != "one\ntwo\nthree\n" {
+		t.Errorf("Expected padding to be trimmed, got %q", got)
+	}
+	if got := copyText(output, 2); got != "two\nthree\n" {
+		t.Errorf("Expected the last 2 lines, got %q", got)
+	}
+	if got := copyText("\n  \n", 10); got != "" {
+		t.Errorf("Expected nothing from a blank pane, got %q", got)
+	}
+}
+
+// pasteMultiplexer captures fixed output and records pasted text.
+type pasteMultiplexer struct {
+	captureMultiplexer
+	pasted []string
+}
+
+func (p *pasteMultiplexer) PaneExists(paneID string) bool { return p.output[paneID] != "" }
+
+func (p *pasteMultiplexer) Paste(paneID, text string) error {
+	p.pasted = append(p.pasted, paneID+" "+text)
+	return nil
+}
+
+func setupClipboardTest(t *testing.T, clipboard *Clipboard) *pasteMultiplexer {
+	t.Chdir(t.TempDir())
+	if err := saveConfig(&Config{Workers: []Worker{{ID: "api", PaneID: "%1"}, {ID: "gone", PaneID: "%9"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &pasteMultiplexer{captureMultiplexer: captureMultiplexer{output: map[string]string{"%1": "$ go test\nok\n$ \n\n"}}}
+	previousMux, previousClipboard := mux, systemClipboard
+	mux = fake
+	systemClipboard = func() (*Clipboard, error) { return clipboard, nil }
+	t.Cleanup(func() { mux, systemClipboard = previousMux, previousClipboard })
+	return fake
+}
+
+func TestCopyWorkerOutput(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "clipboard")
+	setupClipboardTest(t, &Clipboard{Copy: []string{"sh", "-c", "cat > " + shellQuote(file)}})
+
+	if !copyWorkerOutput("api", CopyOptions{Last: 2}) {
+		t.Fatal("Expected the copy to succeed")
+	}
+	if data, _ := os.ReadFile(file); string(data) != "ok\n$\n" {
+		t.Errorf("Expected the last 2 lines in the clipboard, got %q", data)
+	}
+	if copyWorkerOutput("gone", CopyOptions{Last: 2}) {
+		t.Error("Expected a worker without a pane to fail")
+	}
+	if copyWorkerOutput("api", CopyOptions{Last: 0}) {
+		t.Error("Expected --last 0 to fail")
+	}
+}
+
+func TestPasteToWorker(t *testing.T) {
+	fake := setupClipboardTest(t, &Clipboard{Paste: []string{"printf", "fix the tests\nthen commit"}})
+
+	if !pasteToWorker("api", PasteOptions{}) {
+		t.Fatal("Expected the paste to succeed")
+	}
+	if want := []string{"%1 fix the tests\nthen commit"}; !reflect.DeepEqual(fake.pasted, want) {
+		t.Errorf("Expected %q pasted as one block, got %q", want, fake.pasted)
+	}
+	if pasteToWorker("missing", PasteOptions{}) {
+		t.Error("Expected an unknown worker to fail")
+	}
+}
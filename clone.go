@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CloneOptions holds the settings given to `gtw clone`.
+type CloneOptions struct {
+	Init InitOptions
+	Bare bool // Clone as a bare repository with a worktree of the default branch
+}
+
+func init() {
+	var opts CloneOptions
+	cloneCmd := &cobra.Command{
+		Use:   "clone <repo-url> [dir]",
+		Short: "Clone a repository and initialize a gtw session in it",
+		Long: `Clone the repository into dir (by default named after the repository) and
+run 'gtw init' there with the given settings.
+
+With --bare the repository is cloned bare into dir/.bare (like 'gtw init
+--bare-clone') and the default branch is checked out as a worktree in
+dir/<branch>; workers are created next to it.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := ""
+			if len(args) == 2 {
+				dir = args[1]
+			}
+			if !cloneProject(args[0], dir, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	cloneCmd.Flags().BoolVar(&opts.Bare, "bare", false, "Clone as a bare repository with a worktree of the default branch")
+	cloneCmd.Flags().StringVar(&opts.Init.Command, "command", "", "Default initialization command")
+	cloneCmd.Flags().StringVar(&opts.Init.WorktreePrefix, "worktree-prefix", "", "Prefix for worktree directories (default: 'worktree')")
+	cloneCmd.Flags().StringVar(&opts.Init.Multiplexer, "multiplexer", "", "Terminal multiplexer backend: tmux (default), zellij or screen")
+	cloneCmd.Flags().BoolVar(&opts.Init.Attach, "attach", false, "Attach to the session right after creating it")
+	rootCmd.AddCommand(cloneCmd)
+}
+
+// repoDirName returns the directory git would clone url into, e.g. "app"
+// for git@github.com:owner/app.git.
+func repoDirName(url string) string {
+	name := strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(name, "/:"); i != -1 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
+
+func cloneProject(url, dir string, opts CloneOptions) bool {
+	if dir == "" {
+		dir = repoDirName(url)
+		if dir == "" {
+			fmt.Fprintf(os.Stderr, "Error: Cannot tell a directory name from '%s'; give one\n", url)
+			return false
+		}
+	}
+	// Relative to where gtw was started, not the project it may have entered
+	dir = userPath(dir)
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists and is not empty\n", dir)
+		return false
+	}
+
+	if opts.Bare {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if err := bareClone(url); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if err := addDefaultBranchWorktree(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	} else {
+		fmt.Printf("Cloning %s into %s...\n", url, dir)
+		clone := gitCommand("clone", url, dir)
+		clone.Stdout, clone.Stderr = os.Stdout, os.Stderr
+		clone.Timeout = 0 // Large repositories take a while
+		if err := clone.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: git clone failed: %v\n", err)
+			return false
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	}
+
+	// The multiplexer was chosen from the config of the directory gtw was
+	// started in; the new project has none yet
+	mux = resolveMultiplexer("")
+	initSession(opts.Init)
+	return true
+}
+
+// addDefaultBranchWorktree checks out the bare repository's default branch
+// in a worktree named after it.
+func addDefaultBranchWorktree() error {
+	output, err := gitCommand("symbolic-ref", "--short", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("cannot find the default branch: %v", err)
+	}
+	branch := strings.TrimSpace(string(output))
+	fmt.Printf("Checking out %s in %s...\n", branch, filepath.FromSlash(branch))
+	if output, err := gitCommand("worktree", "add", branch, branch).CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add failed: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
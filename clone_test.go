@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestRepoDirName(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:owner/app.git":      "app",
+		"https://github.com/owner/app":      "app",
+		"https://github.com/owner/app.git/": "app",
+		"/srv/git/tool.git":                 "tool",
+		"app":                               "app",
+	}
+	for url, want := range tests {
+		if got := repoDirName(url); got != want {
+			t.Errorf("repoDirName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
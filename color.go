@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// colorEnabled is set from --no-color, NO_COLOR and whether stdout is a
+// terminal before each command runs.
+var colorEnabled bool
+
+// colorTheme maps what is being shown (mostly worker statuses) to a color.
+var colorTheme = defaultTheme()
+
+// ANSI codes of the color names accepted in the theme config
+var colorCodes = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"bold":    "1",
+	"none":    "",
+}
+
+// defaultTheme colors live workers green, dead ones red and the ones that
+// need attention yellow.
+func defaultTheme() map[string]string {
+	return map[string]string{
+		"active":          "green",
+		"inactive":        "red",
+		"creating":        "blue",
+		"initializing":    "blue",
+		"ready":           "green",
+		"busy":            "cyan",
+		"needs-attention": "yellow",
+		"failed":          "red",
+		"removing":        "gray",
+		"detached":        "yellow",
+		"paused":          "yellow",
+		"archived":        "gray",
+		"pass":            "green",
+		"fail":            "red",
+		"error":           "yellow",
+		"header":          "bold",
+		"separator":       "gray",
+	}
+}
+
+// validateTheme reports unknown color names in a theme from the config.
+func validateTheme(theme map[string]string) error {
+	for _, key := range sortedKeys(theme) {
+		if _, ok := colorCodes[theme[key]]; !ok {
+			return fmt.Errorf("unknown color '%s' for '%s' in theme (use %s)", theme[key], key, strings.Join(sortedKeys(colorCodes), ", "))
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveTheme returns the default theme with the colors set in the config.
+func resolveTheme(config *Config) map[string]string {
+	theme := defaultTheme()
+	for key, name := range config.Theme {
+		if _, ok := colorCodes[name]; ok {
+			theme[key] = name
+		}
+	}
+	return theme
+}
+
+// resolveColor reports whether output is colored: never with --no-color or
+// NO_COLOR set (https://no-color.org), and otherwise only on a terminal.
+func resolveColor(noColor bool) bool {
+	if noColor || nonInteractive || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// colorize wraps text in the theme's color for key. Pad text before
+// coloring it so that table columns stay aligned.
+func colorize(text, key string) string {
+	if !colorEnabled {
+		return text
+	}
+	code := colorCodes[colorTheme[key]]
+	if code == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// statusColorKey returns the theme key for a worker status, e.g. "fail (2)"
+// is colored as "fail".
+func statusColorKey(status string) string {
+	key, _, _ := strings.Cut(status, " ")
+	return key
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestColorize(t *testing.T) {
+	defer func(enabled bool, theme map[string]string) { colorEnabled, colorTheme = enabled, theme }(colorEnabled, colorTheme)
+	colorTheme = defaultTheme()
+
+	colorEnabled = false
+	if got := colorize("active", "active"); got != "active" {
+		t.Errorf("Expected plain text with color disabled, got %q", got)
+	}
+
+	colorEnabled = true
+	tests := map[string]string{
+		"active":   "\x1b[32mactive\x1b[0m",
+		"inactive": "\x1b[31minactive\x1b[0m",
+		"paused":   "\x1b[33mpaused\x1b[0m",
+		"unknown":  "unknown",
+	}
+	for key, want := range tests {
+		if got := colorize(key, key); got != want {
+			t.Errorf("colorize(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	theme := resolveTheme(&Config{Theme: map[string]string{"inactive": "magenta", "active": "sparkly"}})
+	if theme["inactive"] != "magenta" {
+		t.Errorf("Expected the config to override inactive, got %q", theme["inactive"])
+	}
+	if theme["active"] != "green" {
+		t.Errorf("Expected an unknown color to keep the default, got %q", theme["active"])
+	}
+	if err := validateTheme(map[string]string{"active": "sparkly"}); err == nil {
+		t.Error("Expected an error for an unknown color")
+	}
+	if err := validateTheme(map[string]string{"active": "none"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if resolveColor(false) {
+		t.Error("Expected NO_COLOR to disable color")
+	}
+	t.Setenv("NO_COLOR", "")
+	if resolveColor(true) {
+		t.Error("Expected --no-color to disable color")
+	}
+}
+
+func TestStatusColorKey(t *testing.T) {
+	if got := statusColorKey("fail (2)"); got != "fail" {
+		t.Errorf("Expected 'fail', got %q", got)
+	}
+	if got := statusColorKey("active"); got != "active" {
+		t.Errorf("Expected 'active', got %q", got)
+	}
+}
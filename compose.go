@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// composeFile declares the workers of a project for `gtw up` and
+// `gtw down`. It is a manifest (see Manifest), so `gtw manifest export
+// .gtw.yaml` writes one from the current workers.
+const composeFile = ".gtw.yaml"
+
+// defaultComposeReadyTimeout bounds how long `gtw up` waits for a worker
+// to pass its readiness checks.
+const defaultComposeReadyTimeout = 5 * time.Minute
+
+// ComposeOptions holds the settings given to `gtw up` and `gtw down`.
+type ComposeOptions struct {
+	File  string
+	Only  []string // Only these workers of the file
+	Yes   bool
+	Force bool // down: remove workers even if they are running a program
+
+	Wait         bool          // up: wait until every worker is ready
+	ReadyTimeout time.Duration // up: how long to wait for a worker to be ready
+}
+
+func init() {
+	var opts ComposeOptions
+	upCmd := &cobra.Command{
+		Use:   "up",
+		Short: "Create the session and the workers declared in " + composeFile,
+		Long: `Bring the project up to the workers declared in ` + composeFile + `: create the
+session when it is not running (recreating recorded workers), create the
+declared workers that do not exist, update the note, tags and profile of
+the ones that do, and recreate their missing panes and worktrees. Workers
+that are not declared are left alone.
+
+Workers are created after the workers they depend on (depends_on), once
+those pass the readiness checks of their profile. --only includes the
+dependencies of the named workers.
+
+` + composeFile + ` uses the manifest format:
+
+  version: 1
+  workers:
+    - id: api
+      base: main
+      profile: claude
+      note: REST API
+      tags: [backend]
+    - id: web
+      depends_on: [api]`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !composeUp(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	downCmd := &cobra.Command{
+		Use:   "down",
+		Short: "Remove the workers declared in " + composeFile,
+		Long: `Tear down the workers declared in ` + composeFile + ` as 'gtw remove' does,
+saving unsaved work to a backup branch first. When no workers are left the
+session is destroyed too. Workers that are not declared are left alone.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !composeDown(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	for _, c := range []*cobra.Command{upCmd, downCmd} {
+		c.Flags().StringVarP(&opts.File, "file", "f", composeFile, "File declaring the workers")
+		c.Flags().StringSliceVar(&opts.Only, "only", nil, "Only these workers of the file (comma-separated)")
+	}
+	upCmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait until every worker passes its readiness checks")
+	upCmd.Flags().DurationVar(&opts.ReadyTimeout, "ready-timeout", defaultComposeReadyTimeout, "How long to wait for a worker to be ready (0 means no limit)")
+	downCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Remove the workers without asking")
+	downCmd.Flags().BoolVar(&opts.Force, "force", false, "Remove workers even if they are running a program")
+	rootCmd.AddCommand(upCmd, downCmd)
+}
+
+// loadComposeFile reads the declared workers, narrowed down to only.
+func loadComposeFile(path string, only []string) (*Manifest, error) {
+	manifest, err := loadManifest(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found; write one with 'gtw manifest export %s'", path, composeFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return selectComposeWorkers(manifest, only)
+}
+
+// selectComposeWorkers keeps the workers named by only and the workers
+// they depend on, in dependency order. Every name has to be declared.
+func selectComposeWorkers(manifest *Manifest, only []string) (*Manifest, error) {
+	ordered, err := dependencyOrder(manifest.Workers)
+	if err != nil {
+		return nil, err
+	}
+	if len(only) == 0 {
+		return &Manifest{Version: manifest.Version, Workers: ordered}, nil
+	}
+	wanted := make(map[string]bool)
+	for _, id := range only {
+		if !slices.ContainsFunc(manifest.Workers, func(w ManifestWorker) bool { return w.ID == id }) {
+			return nil, fmt.Errorf("worker '%s' is not declared", id)
+		}
+		wanted[id] = true
+	}
+	// Dependencies come first, so walking backwards reaches all of them
+	for i := len(ordered) - 1; i >= 0; i-- {
+		if wanted[ordered[i].ID] {
+			for _, id := range ordered[i].DependsOn {
+				wanted[id] = true
+			}
+		}
+	}
+	selected := &Manifest{Version: manifest.Version}
+	for _, worker := range ordered {
+		if wanted[worker.ID] {
+			selected.Workers = append(selected.Workers, worker)
+		}
+	}
+	return selected, nil
+}
+
+// waitForComposeWorkers waits until the workers ids pass their readiness
+// checks.
+func waitForComposeWorkers(ids []string, timeout time.Duration) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		index := findWorkerIndex(config, id)
+		if index == -1 {
+			return fmt.Errorf("worker '%s' does not exist", id)
+		}
+		worker := config.Workers[index]
+		if len(config.readinessFor(worker)) == 0 {
+			continue
+		}
+		fmt.Printf("Waiting for worker '%s' to be ready...\n", id)
+		if err := waitForReadiness(config, worker, timeout, time.Second); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func composeUp(opts ComposeOptions) bool {
+	manifest, err := loadComposeFile(userPath(opts.File), opts.Only)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if err := checkManifestProfiles(config, manifest); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	sessionName := getSessionName()
+	if !mux.HasSession(sessionName) {
+		if len(config.Workers) > 0 {
+			if !recreateSession(sessionName, true) {
+				return false
+			}
+		} else {
+			initSession(InitOptions{})
+		}
+		if !mux.HasSession(sessionName) {
+			return false
+		}
+		if config, err = loadConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return false
+		}
+	}
+
+	plan := planSync(config.Workers, manifest)
+	adds := plan.Add
+	plan.Add, plan.Remove = nil, nil
+	applySyncPlan(config, plan, false)
+
+	// In dependency order: a worker starts once what it depends on is ready
+	failed := 0
+	for _, desired := range adds {
+		if err := waitForComposeWorkers(desired.DependsOn, opts.ReadyTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: not creating '%s': %v\n", desired.ID, err)
+			failed++
+			continue
+		}
+		if !addManifestWorker(desired) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("❌ %d worker(s) could not be created\n", failed)
+	}
+
+	// Declared workers that exist but lost their pane or worktree
+	if config, err = loadConfig(); err == nil {
+		alive := livePaneCheck()
+		for _, desired := range manifest.Workers {
+			index := findWorkerIndex(config, desired.ID)
+			if index == -1 || slices.ContainsFunc(adds, func(w ManifestWorker) bool { return w.ID == desired.ID }) {
+				continue
+			}
+			worker := config.Workers[index]
+			if _, err := os.Stat(worker.WorktreePath); err == nil && alive(worker.PaneID) {
+				continue
+			}
+			repairInconsistencies(RepairOptions{Only: []string{RepairMissingWorktrees, RepairMissingPanes}, Worker: worker.ID})
+		}
+	}
+
+	if failed > 0 {
+		return false
+	}
+	if opts.Wait {
+		ids := make([]string, len(manifest.Workers))
+		for i, desired := range manifest.Workers {
+			ids[i] = desired.ID
+		}
+		if err := waitForComposeWorkers(ids, opts.ReadyTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	}
+	fmt.Printf("✅ %d worker(s) up in session '%s'\n", len(manifest.Workers), sessionName)
+	return true
+}
+
+func composeDown(opts ComposeOptions) bool {
+	manifest, err := loadComposeFile(userPath(opts.File), opts.Only)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if nonInteractive && !opts.Yes {
+		fmt.Fprintln(os.Stderr, "Error: gtw down asks before removing workers; pass --yes")
+		return false
+	}
+
+	var ids []string
+	for _, desired := range manifest.Workers {
+		if findWorkerIndex(config, desired.ID) != -1 {
+			ids = append(ids, desired.ID)
+		}
+	}
+	if len(ids) > 0 {
+		if !opts.Yes && !confirm(fmt.Sprintf("Remove %d worker(s) (%s)?", len(ids), strings.Join(ids, ", "))) {
+			fmt.Println("Aborted")
+			return false
+		}
+		for _, id := range ids {
+			removeWorker(id, RemoveOptions{Yes: true, Force: opts.Force})
+		}
+	}
+
+	if config, err = loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	var left []string
+	for _, id := range ids {
+		if findWorkerIndex(config, id) != -1 {
+			left = append(left, id)
+		}
+	}
+	if len(left) > 0 {
+		fmt.Fprintf(os.Stderr, "❌ Could not remove %s\n", strings.Join(left, ", "))
+		return false
+	}
+
+	// The session goes down with the last worker
+	sessionName := getSessionName()
+	if len(opts.Only) == 0 && len(config.Workers) == 0 && mux.HasSession(sessionName) {
+		destroySession(RemoveOptions{Yes: true, Force: opts.Force})
+	} else if len(opts.Only) == 0 && len(config.Workers) > 0 {
+		fmt.Printf("%d worker(s) that are not declared in %s keep the session running\n", len(config.Workers), opts.File)
+	}
+	fmt.Printf("✅ %d worker(s) down\n", len(ids))
+	return true
+}
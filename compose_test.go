@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectComposeWorkers(t *testing.T) {
+	manifest := &Manifest{Version: 1, Workers: []ManifestWorker{{ID: "api"}, {ID: "web"}, {ID: "docs"}}}
+
+	if all, err := selectComposeWorkers(manifest, nil); err != nil || len(all.Workers) != 3 {
+		t.Errorf("Expected every worker without --only, got %+v (%v)", all, err)
+	}
+	selected, err := selectComposeWorkers(manifest, []string{"docs", "api"})
+	if err != nil || len(selected.Workers) != 2 || selected.Workers[0].ID != "api" || selected.Workers[1].ID != "docs" {
+		t.Errorf("Expected api and docs in file order, got %+v (%v)", selected, err)
+	}
+	if _, err := selectComposeWorkers(manifest, []string{"cli"}); err == nil {
+		t.Error("Expected an error for a worker that is not declared")
+	}
+}
+
+func TestSelectComposeWorkersDependencies(t *testing.T) {
+	manifest := &Manifest{Version: 1, Workers: []ManifestWorker{
+		{ID: "web", DependsOn: []string{"api"}},
+		{ID: "api", DependsOn: []string{"db"}},
+		{ID: "db"},
+		{ID: "docs"},
+	}}
+
+	selected, err := selectComposeWorkers(manifest, []string{"web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, worker := range selected.Workers {
+		ids = append(ids, worker.ID)
+	}
+	if got := strings.Join(ids, ","); got != "db,api,web" {
+		t.Errorf("Expected --only web to bring up its dependencies first, got %s", got)
+	}
+}
+
+func TestLoadComposeFileMissing(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if _, err := loadComposeFile(composeFile, nil); err == nil {
+		t.Error("Expected an error without " + composeFile)
+	}
+}
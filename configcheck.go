@@ -0,0 +1,533 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaID identifies the config schema; editors fetch it from "$schema".
+const schemaID = "https://github.com/nakamasato/git-tmux-workspace/schema/tmux-workers.schema.json"
+
+// schemaEnums lists the allowed values of config fields that take one of a
+// few words, keyed by JSON field name.
+var schemaEnums = map[string][]string{
+	"multiplexer":         {"tmux", "zellij", "screen"},
+	"split_direction":     {SplitAuto, SplitVertical, SplitHorizontal, "v", "h"},
+	"backup_on_remove":    {BackupAsk, BackupAlways, BackupNever},
+	"focus_on_add":        {FocusAlways, FocusNever, FocusInteractive},
+	"on_error":            {StepAbort, StepContinue},
+	"mode":                {DepSymlink, DepCopy},
+	"action":              {PolicyNotify, PolicyApprove, PolicyPause},
+	"kind":                {WebhookSlack, WebhookDiscord},
+	"session_idle_action": {SessionIdleDestroy, SessionIdleArchive},
+	"store":               {StoreJSON, StoreSQLite},
+}
+
+// durationFields are config fields holding a Go duration such as "30s".
+var durationFields = []string{"command_timeout", "retry_backoff", "worker_ttl", "shutdown_grace", "git_cache_ttl", "session_idle_ttl"}
+
+// ConfigIssue is a problem found by `gtw config validate`.
+type ConfigIssue struct {
+	Path    string `json:"path"` // JSON path of the value, e.g. workers[1].pane_id
+	Message string `json:"message"`
+	Error   bool   `json:"error"` // false for warnings
+}
+
+func (i ConfigIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// configCheckCommands returns `gtw config validate` and `gtw config schema`.
+func configCheckCommands() []*cobra.Command {
+	var validateJSON bool
+	configValidateCmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check the config for unknown fields, wrong types and stale entries",
+		Long: `Check .tmux-workers.json (or file) against the config schema: unknown fields,
+values of the wrong type or outside the allowed words, durations that do not
+parse, duplicate worker IDs and worktree or project paths that do not exist.
+
+Exits with status 1 if an error is found; warnings alone exit with 0.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := configFile
+			if len(args) == 1 {
+				file = userPath(args[0])
+			}
+			if !validateConfigFile(file, validateJSON) {
+				os.Exit(1)
+			}
+		},
+	}
+	configValidateCmd.Flags().BoolVar(&validateJSON, "json", false, "Print the issues as JSON")
+
+	configSchemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the config file",
+		Long: `Print the JSON Schema of .tmux-workers.json, for editor completion and
+checks. Save it and point "$schema" in the config (or the editor's JSON
+schema settings) at it:
+
+  gtw config schema > .gtw/tmux-workers.schema.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := json.MarshalIndent(configSchema(), "", "  ")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		},
+	}
+	return []*cobra.Command{configValidateCmd, configSchemaCmd}
+}
+
+// configSchema builds the JSON Schema of the config from the Config type.
+func configSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = schemaID
+	schema["title"] = "gtw config (" + configFile + ")"
+	// Editors add "$schema" to point at the schema itself
+	schema["properties"].(map[string]interface{})["$schema"] = map[string]interface{}{"type": "string"}
+	return schema
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	initStepType = reflect.TypeOf(InitStep{})
+)
+
+// typeSchema returns the schema of a Go type as encoding/json reads it;
+// field is the JSON name it appears under, for enums.
+func typeSchema(t reflect.Type, field string) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case initStepType:
+		// A bare command is accepted too (see InitStep.UnmarshalJSON)
+		return map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			structSchema(t),
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema := map[string]interface{}{"type": "string"}
+		if values, ok := schemaEnums[field]; ok {
+			schema["enum"] = values
+		}
+		return schema
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), "")}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), "")}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema lists the exported fields of a struct by their JSON names.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			for key, value := range structSchema(f.Type)["properties"].(map[string]interface{}) {
+				properties[key] = value
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = typeSchema(f.Type, name)
+	}
+	return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+}
+
+// checkSchema reports where value (decoded from JSON) does not match schema.
+func checkSchema(schema map[string]interface{}, value interface{}, path string) []ConfigIssue {
+	if options, ok := schema["anyOf"].([]interface{}); ok {
+		var first []ConfigIssue
+		for i, option := range options {
+			issues := checkSchema(option.(map[string]interface{}), value, path)
+			if len(issues) == 0 {
+				return nil
+			}
+			if i == 0 || matchesType(option.(map[string]interface{}), value) {
+				first = issues
+			}
+		}
+		return first
+	}
+
+	if !matchesType(schema, value) {
+		return []ConfigIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", schema["type"], jsonTypeName(value)), Error: true}}
+	}
+
+	var issues []ConfigIssue
+	switch v := value.(type) {
+	case string:
+		// "" means the default everywhere
+		if values, ok := schema["enum"].([]string); ok && v != "" && !containsString(values, v) {
+			issues = append(issues, ConfigIssue{Path: path, Message: fmt.Sprintf("invalid value %q (expected %s)", v, strings.Join(values, ", ")), Error: true})
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				issues = append(issues, ConfigIssue{Path: path, Message: fmt.Sprintf("invalid time %q", v), Error: true})
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range v {
+			issues = append(issues, checkSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinJSONPath(path, key)
+			if property, ok := properties[key]; ok {
+				issues = append(issues, checkSchema(property.(map[string]interface{}), v[key], child)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				issues = append(issues, checkSchema(additional, v[key], child)...)
+			case bool:
+				if !additional {
+					issues = append(issues, ConfigIssue{Path: child, Message: "unknown field (ignored)"})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// matchesType reports whether value has the schema's JSON type.
+func matchesType(schema map[string]interface{}, value interface{}) bool {
+	want, ok := schema["type"].(string)
+	if !ok || value == nil {
+		// null is what encoding/json writes for nil pointers, maps and slices
+		return true
+	}
+	switch value.(type) {
+	case string:
+		return want == "string"
+	case bool:
+		return want == "boolean"
+	case float64:
+		n := value.(float64)
+		return want == "number" || (want == "integer" && n == float64(int64(n)))
+	case []interface{}:
+		return want == "array"
+	case map[string]interface{}:
+		return want == "object"
+	}
+	return false
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
+
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigValues checks what the schema cannot: durations, colors,
+// duplicate worker IDs and paths on disk. Relative paths are resolved
+// against dir, the directory of the config file.
+func checkConfigValues(config *Config, dir string) []ConfigIssue {
+	var issues []ConfigIssue
+	durations := map[string]string{
+		"command_timeout":  config.CommandTimeout,
+		"retry_backoff":    config.RetryBackoff,
+		"worker_ttl":       config.WorkerTTL,
+		"shutdown_grace":   config.ShutdownGrace,
+		"git_cache_ttl":    config.GitCacheTTL,
+		"session_idle_ttl": config.SessionIdleTTL,
+	}
+	if config.Checkpoint != nil {
+		durations["checkpoint.interval"] = config.Checkpoint.Interval
+	}
+	for _, field := range append(durationFields, "checkpoint.interval") {
+		if value := durations[field]; value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				issues = append(issues, ConfigIssue{Path: field, Message: fmt.Sprintf("invalid duration %q (e.g. 30s, 10m, 72h)", value), Error: true})
+			}
+		}
+	}
+	if config.RetryAttempts < 0 {
+		issues = append(issues, ConfigIssue{Path: "retry_attempts", Message: fmt.Sprintf("invalid number of attempts %d (1 disables retries)", config.RetryAttempts), Error: true})
+	}
+	if config.PaneSize != "" && !paneSizePattern.MatchString(config.PaneSize) {
+		issues = append(issues, ConfigIssue{Path: "pane_size", Message: fmt.Sprintf("invalid size %q (e.g. 25%% or 80)", config.PaneSize), Error: true})
+	}
+	if _, err := renderPaneTitle(config.PaneTitle, PaneTitleData{ID: "w1", Branch: "w1"}); err != nil {
+		issues = append(issues, ConfigIssue{Path: "pane_title", Message: err.Error(), Error: true})
+	}
+	if _, err := expandWorktreePrefix(config.WorktreePrefix, WorktreePrefixData{Project: "project"}); err != nil {
+		issues = append(issues, ConfigIssue{Path: "worktree_prefix", Message: err.Error(), Error: true})
+	}
+	if err := validateTheme(config.Theme); err != nil {
+		issues = append(issues, ConfigIssue{Path: "theme", Message: err.Error(), Error: true})
+	}
+	if _, err := regexp.Compile(config.ReadyPattern); err != nil {
+		issues = append(issues, ConfigIssue{Path: "ready_pattern", Message: err.Error(), Error: true})
+	}
+	for _, name := range config.profileNames() {
+		if config.Profiles[name].InitCommand == "" {
+			issues = append(issues, ConfigIssue{Path: "profiles." + name, Message: "profile has no init_command"})
+		}
+		if _, err := regexp.Compile(config.Profiles[name].ReadyPattern); err != nil {
+			issues = append(issues, ConfigIssue{Path: "profiles." + name + ".ready_pattern", Message: err.Error(), Error: true})
+		}
+		for i, check := range config.Profiles[name].Readiness {
+			if err := check.validate(); err != nil {
+				issues = append(issues, ConfigIssue{Path: fmt.Sprintf("profiles.%s.readiness[%d]", name, i), Message: err.Error(), Error: true})
+			}
+		}
+	}
+
+	agents := make([]string, 0, len(config.Agents))
+	for name := range config.Agents {
+		agents = append(agents, name)
+	}
+	sort.Strings(agents)
+	for _, name := range agents {
+		if _, err := findAgent(config, name); err != nil || name == (replAgent{}).Name() {
+			issues = append(issues, ConfigIssue{Path: "agents." + name, Message: fmt.Sprintf("no agent '%s' to set the command of (available: %s)", name, strings.Join(agentPresets, ", "))})
+		}
+	}
+
+	if config.Notifications != nil {
+		events := make([]string, 0, len(config.Notifications.Events))
+		for event := range config.Notifications.Events {
+			events = append(events, event)
+		}
+		sort.Strings(events)
+		for _, event := range events {
+			known := false
+			for _, e := range notifyEvents {
+				known = known || e == event
+			}
+			if !known {
+				issues = append(issues, ConfigIssue{Path: "notifications.events." + event, Message: fmt.Sprintf("unknown event (expected %s)", strings.Join(notifyEvents, ", "))})
+			}
+		}
+	}
+	if config.Notifications != nil {
+		for i, webhook := range config.Notifications.Webhooks {
+			at := fmt.Sprintf("notifications.webhooks[%d]", i)
+			if webhook.URL == "" {
+				issues = append(issues, ConfigIssue{Path: at + ".url", Message: "webhook has no url", Error: true})
+			}
+			if _, err := webhook.payload(""); err != nil {
+				issues = append(issues, ConfigIssue{Path: at + ".kind", Message: err.Error(), Error: true})
+			} else if _, err := webhook.message(Notification{}); err != nil {
+				issues = append(issues, ConfigIssue{Path: at + ".template", Message: err.Error(), Error: true})
+			}
+		}
+	}
+	policies := make([]string, 0, len(config.Policies))
+	for name := range config.Policies {
+		policies = append(policies, name)
+	}
+	sort.Strings(policies)
+	for _, name := range policies {
+		for i, pattern := range config.Policies[name].Approve {
+			if _, err := regexp.Compile(pattern); err != nil {
+				issues = append(issues, ConfigIssue{Path: fmt.Sprintf("policies.%s.approve[%d]", name, i), Message: err.Error(), Error: true})
+			}
+		}
+	}
+
+	if config.ProjectPath != "" {
+		if info, err := os.Stat(config.ProjectPath); err != nil || !info.IsDir() {
+			issues = append(issues, ConfigIssue{Path: "project_path", Message: fmt.Sprintf("directory %s does not exist", config.ProjectPath)})
+		}
+	}
+
+	// Worker IDs are unique across workspaces and the archive
+	seen := map[string]string{}
+	checkWorkers := func(path string, workers []Worker, archived bool) {
+		for i, worker := range workers {
+			at := fmt.Sprintf("%s[%d]", path, i)
+			if worker.ID == "" {
+				issues = append(issues, ConfigIssue{Path: at, Message: "worker has no id", Error: true})
+				continue
+			}
+			if first, ok := seen[worker.ID]; ok {
+				issues = append(issues, ConfigIssue{Path: at + ".id", Message: fmt.Sprintf("duplicate worker ID '%s' (also %s)", worker.ID, first), Error: true})
+			} else {
+				seen[worker.ID] = at
+			}
+			if archived || worker.WorktreePath == "" {
+				continue
+			}
+			worktree := worker.WorktreePath
+			if !filepath.IsAbs(worktree) {
+				worktree = filepath.Join(dir, worktree)
+			}
+			if _, err := os.Stat(worktree); err != nil {
+				issues = append(issues, ConfigIssue{Path: at + ".worktree_path", Message: fmt.Sprintf("worktree %s does not exist (see 'gtw repair')", worker.WorktreePath)})
+			}
+			if worker.Profile != "" {
+				if _, ok := config.Profiles[worker.Profile]; !ok {
+					issues = append(issues, ConfigIssue{Path: at + ".profile", Message: fmt.Sprintf("unknown profile '%s'", worker.Profile)})
+				}
+			}
+			if worker.Policy != "" {
+				if _, ok := config.Policies[worker.Policy]; !ok {
+					issues = append(issues, ConfigIssue{Path: at + ".policy", Message: fmt.Sprintf("unknown policy '%s'", worker.Policy)})
+				}
+			}
+			if worker.Agent != "" {
+				if _, err := findAgent(config, worker.Agent); err != nil {
+					issues = append(issues, ConfigIssue{Path: at + ".agent", Message: err.Error()})
+				}
+			}
+		}
+	}
+	checkWorkers("workers", config.Workers, false)
+	checkWorkers("archived", config.Archived, true)
+	names := make([]string, 0, len(config.Workspaces))
+	for name := range config.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ws := config.Workspaces[name]; ws != nil {
+			checkWorkers("workspaces."+name+".workers", ws.Workers, false)
+		}
+	}
+	return issues
+}
+
+// validateConfig checks config file data against the schema and, when it
+// decodes, its values.
+func validateConfig(data []byte, dir string) []ConfigIssue {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []ConfigIssue{{Message: fmt.Sprintf("not valid JSON: %v", err), Error: true}}
+	}
+	issues := checkSchema(configSchema(), raw, "")
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		for _, issue := range issues {
+			if issue.Error {
+				// Already explained by the schema check
+				return append(issues, ConfigIssue{Message: "fix the errors above to check the values further"})
+			}
+		}
+		return append(issues, ConfigIssue{Message: err.Error(), Error: true})
+	}
+	return append(issues, checkConfigValues(config, dir)...)
+}
+
+func validateConfigFile(file string, asJSON bool) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	dir, _ := filepath.Abs(filepath.Dir(file))
+	issues := validateConfig(data, dir)
+
+	ok := true
+	for _, issue := range issues {
+		if issue.Error {
+			ok = false
+		}
+	}
+
+	if asJSON {
+		if issues == nil {
+			issues = []ConfigIssue{}
+		}
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		fmt.Println(string(out))
+		return ok
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("✅ %s is valid\n", file)
+		return true
+	}
+	errors := 0
+	for _, issue := range issues {
+		if issue.Error {
+			errors++
+			fmt.Printf("❌ %s\n", issue)
+		} else {
+			fmt.Printf("⚠️  %s\n", issue)
+		}
+	}
+	fmt.Printf("\n%d error(s), %d warning(s) in %s\n", errors, len(issues)-errors, file)
+	return ok
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	schema := configSchema()
+	properties := schema["properties"].(map[string]interface{})
+	for _, field := range []string{"workers", "init_command", "profiles", "theme", "$schema"} {
+		if _, ok := properties[field]; !ok {
+			t.Errorf("Expected %s in the schema", field)
+		}
+	}
+	worker := properties["workers"].(map[string]interface{})["items"].(map[string]interface{})
+	if _, ok := worker["properties"].(map[string]interface{})["pane_id"]; !ok {
+		t.Error("Expected worker fields in the schema")
+	}
+	if enum := properties["split_direction"].(map[string]interface{})["enum"]; enum == nil {
+		t.Error("Expected split_direction to list its values")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "worktree", "w1"), 0755)
+
+	valid := `{"workers": [{"id": "w1", "worktree_path": "worktree/w1", "created_at": "2026-01-02T03:04:05Z"}],
+		"init_steps": ["make", {"run": "npm ci", "on_error": "continue"}], "worker_ttl": "72h"}`
+	if issues := validateConfig([]byte(valid), dir); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+
+	tests := []struct {
+		config string
+		path   string
+		error  bool
+	}{
+		{`{"colour": "red"}`, "colour", false},
+		{`{"workers": [{"id": "w1", "pane_index": "1"}]}`, "workers[0].pane_index", true},
+		{`{"split_direction": "diagonal"}`, "split_direction", true},
+		{`{"init_steps": [{"run": "x", "on_error": "skip"}]}`, "init_steps[0].on_error", true},
+		{`{"shutdown_grace": "ten seconds"}`, "shutdown_grace", true},
+		{`{"checkpoint": {"interval": "often"}}`, "checkpoint.interval", true},
+		{`{"workers": [{"id": "w1", "worktree_path": "worktree/w1"}], "archived": [{"id": "w1"}]}`, "archived[0].id", true},
+		{`{"workers": [{"id": "w2", "worktree_path": "worktree/w2"}]}`, "workers[0].worktree_path", false},
+		{`{"workers": [}`, "", true},
+	}
+	for _, tt := range tests {
+		issues := validateConfig([]byte(tt.config), dir)
+		found := false
+		for _, issue := range issues {
+			if issue.Path == tt.path && issue.Error == tt.error {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("validateConfig(%s): expected an issue at %q (error=%v), got %v", tt.config, tt.path, tt.error, issues)
+		}
+	}
+
+	issues := validateConfig([]byte(`{"worktree_prefix": 5}`), dir)
+	if len(issues) == 0 || !strings.Contains(issues[0].Message, "expected string, got number") {
+		t.Errorf("Expected a type error, got %v", issues)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stateKeys are maintained by gtw commands and cannot be set by hand.
+var stateKeys = map[string]bool{
+	"workers":    true,
+	"archived":   true,
+	"workspaces": true,
+	"counters":   true,
+}
+
+// schemaAt returns the schema of the value at a dotted key such as
+// "checkpoint.interval" or "profiles.fast.init_command".
+func schemaAt(key string) (map[string]interface{}, error) {
+	schema := configSchema()
+	for _, part := range strings.Split(key, ".") {
+		if part == "" {
+			return nil, fmt.Errorf("invalid key '%s'", key)
+		}
+		if options, ok := schema["anyOf"].([]interface{}); ok {
+			// Keys lead into the object form
+			schema = options[len(options)-1].(map[string]interface{})
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			if property, ok := properties[part]; ok {
+				schema = property.(map[string]interface{})
+				continue
+			}
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			schema = additional
+			continue
+		}
+		return nil, fmt.Errorf("unknown config key '%s' (see 'gtw config schema')", key)
+	}
+	return schema, nil
+}
+
+// parseConfigValue converts the command line value for a key: strings are
+// taken as they are, anything else is read as JSON (true, 5, ["a","b"]).
+func parseConfigValue(schema map[string]interface{}, value string) (interface{}, error) {
+	if schema["type"] == "string" {
+		return value, nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		kind, _ := schema["type"].(string)
+		if kind == "" {
+			kind = "JSON"
+		}
+		return nil, fmt.Errorf("expected a JSON value of type %s, got %q", kind, value)
+	}
+	return parsed, nil
+}
+
+// setConfigKey sets (or, with a nil value, removes) a dotted key in a
+// decoded config, creating the objects on the way.
+func setConfigKey(raw map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	node := raw
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node[part].(map[string]interface{})
+		if !ok {
+			if value == nil {
+				return
+			}
+			child = map[string]interface{}{}
+			node[part] = child
+		}
+		node = child
+	}
+	last := parts[len(parts)-1]
+	if value == nil {
+		delete(node, last)
+	} else {
+		node[last] = value
+	}
+}
+
+// getConfigKey returns the value at a dotted key in a decoded config.
+func getConfigKey(raw map[string]interface{}, key string) (interface{}, bool) {
+	var node interface{} = raw
+	for _, part := range strings.Split(key, ".") {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// readRawConfig decodes the config file as generic JSON, keeping fields gtw
+// does not know; a missing file is an empty config.
+func readRawConfig() (map[string]interface{}, error) {
+	raw := map[string]interface{}{}
+	data, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return raw, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %v", configFile, err)
+	}
+	return raw, nil
+}
+
+// newConfigErrors returns the errors in after that were not in before, so
+// that a change is not blamed for problems the config already had.
+func newConfigErrors(before, after []byte) []ConfigIssue {
+	dir, _ := os.Getwd()
+	existing := map[string]bool{}
+	for _, issue := range validateConfig(before, dir) {
+		existing[issue.String()] = true
+	}
+	var errs []ConfigIssue
+	for _, issue := range validateConfig(after, dir) {
+		if issue.Error && !existing[issue.String()] {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// updateConfigKey sets or (with unset) removes a key in the config file
+// after checking that the result is valid.
+func updateConfigKey(key, value string, unset bool) error {
+	top, _, _ := strings.Cut(key, ".")
+	if stateKeys[top] {
+		return fmt.Errorf("'%s' is managed by gtw commands and cannot be set", top)
+	}
+	schema, err := schemaAt(key)
+	if err != nil {
+		return err
+	}
+	// Read and written under the lock so that no worker saved meanwhile is lost
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	raw, err := readRawConfig()
+	if err != nil {
+		return err
+	}
+	before, _ := json.Marshal(raw)
+
+	var parsed interface{}
+	if !unset {
+		if parsed, err = parseConfigValue(schema, value); err != nil {
+			return err
+		}
+	}
+	setConfigKey(raw, key, parsed)
+	after, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if errs := newConfigErrors(before, after); len(errs) > 0 {
+		return errors.New(errs[0].String())
+	}
+
+	// Written in the usual field order; keys gtw does not know are dropped
+	config := &Config{Workers: []Worker{}}
+	if err := json.Unmarshal(after, config); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(configFile, data)
+}
+
+func setConfigKeyCommand(key, value string) bool {
+	if err := updateConfigKey(key, value, false); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Set %s to %s\n", key, value)
+	return true
+}
+
+func unsetConfigKeyCommand(key string) bool {
+	if err := updateConfigKey(key, "", true); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Unset %s\n", key)
+	return true
+}
+
+// getConfigKeyCommand prints a key's value, including defaults such as
+// worktree_prefix: strings as they are, anything else as JSON.
+func getConfigKeyCommand(key string) bool {
+	if _, err := schemaAt(key); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	data, err := json.Marshal(config.persisted(workspace))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	raw := map[string]interface{}{}
+	json.Unmarshal(data, &raw)
+
+	value, ok := getConfigKey(raw, key)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s is not set\n", key)
+		return false
+	}
+	if s, ok := value.(string); ok {
+		fmt.Println(s)
+		return true
+	}
+	out, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(out))
+	return true
+}
+
+// configEditor returns the editor for `gtw config edit`. It has to wait
+// until the file is closed, so the "editor" setting for `gtw open` (often a
+// GUI editor) is not used.
+func configEditor() string {
+	for _, candidate := range []string{os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(candidate) != "" {
+			return strings.TrimSpace(candidate)
+		}
+	}
+	return "vi"
+}
+
+// saveEditedConfig writes the config edited from original. When another
+// gtw command saved the config meanwhile, only the settings are taken from
+// the edit and the state (workers and the like) is kept as saved; merged
+// reports that.
+func saveEditedConfig(original, edited []byte) (merged bool, err error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		current = []byte("{}\n")
+	} else if err != nil {
+		return false, err
+	}
+	if bytes.Equal(current, original) {
+		return false, writeFileAtomic(configFile, edited)
+	}
+
+	settings := map[string]json.RawMessage{}
+	if err := json.Unmarshal(edited, &settings); err != nil {
+		return false, err
+	}
+	state := map[string]json.RawMessage{}
+	if err := json.Unmarshal(current, &state); err != nil {
+		return false, fmt.Errorf("%s is not valid JSON: %v", configFile, err)
+	}
+	for key := range stateKeys {
+		delete(settings, key)
+		if value, ok := state[key]; ok {
+			settings[key] = value
+		}
+	}
+
+	// Written in the usual field order, as by 'gtw config set'
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return false, err
+	}
+	config := &Config{Workers: []Worker{}}
+	if err := json.Unmarshal(data, config); err != nil {
+		return false, err
+	}
+	if data, err = json.MarshalIndent(config, "", "  "); err != nil {
+		return false, err
+	}
+	return true, writeFileAtomic(configFile, data)
+}
+
+// editConfig opens a copy of the config in the editor and saves it only
+// once it is valid; on errors the user can edit it again or discard it.
+func editConfig() bool {
+	if nonInteractive {
+		fmt.Fprintf(os.Stderr, "Error: config edit needs a terminal; use 'gtw config set' instead\n")
+		return false
+	}
+	original, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		original = []byte("{}\n")
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	tmp, err := os.CreateTemp("", "tmux-workers-*.json")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(original)
+	tmp.Close()
+
+	dir, _ := filepath.Abs(".")
+	fields := strings.Fields(configEditor())
+	for {
+		cmd := newCommand(fields[0], append(fields[1:], tmp.Name())...)
+		cmd.Timeout = 0
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", fields[0], err)
+			return false
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if string(edited) == string(original) {
+			fmt.Println("No changes")
+			return true
+		}
+
+		errs := newConfigErrors(original, edited)
+		for _, issue := range errs {
+			fmt.Printf("❌ %s\n", issue)
+		}
+		for _, issue := range validateConfig(edited, dir) {
+			if !issue.Error {
+				fmt.Printf("⚠️  %s\n", issue)
+			}
+		}
+		if len(errs) == 0 {
+			merged, err := saveEditedConfig(original, edited)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+				return false
+			}
+			if merged {
+				fmt.Println("⚠️  Workers changed by another gtw command while you were editing were kept")
+			}
+			fmt.Printf("✅ Saved %s\n", configFile)
+			return true
+		}
+		if !confirm("Edit again? (no discards the changes)") {
+			fmt.Println("Changes discarded")
+			return false
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestSchemaAt(t *testing.T) {
+	tests := []struct {
+		key      string
+		wantType string
+		wantErr  bool
+	}{
+		{"worktree_prefix", "string", false},
+		{"checkpoint.on_idle", "boolean", false},
+		{"profiles.fast.init_command", "string", false},
+		{"worker_env.GOMODCACHE", "string", false},
+		{"artifact_dirs", "array", false},
+		{"nosuch", "", true},
+		{"checkpoint.nosuch", "", true},
+		{"checkpoint..interval", "", true},
+	}
+	for _, tt := range tests {
+		schema, err := schemaAt(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("schemaAt(%q) error = %v", tt.key, err)
+			continue
+		}
+		if err == nil && schema["type"] != tt.wantType {
+			t.Errorf("schemaAt(%q) type = %v, want %s", tt.key, schema["type"], tt.wantType)
+		}
+	}
+}
+
+func TestUpdateConfigKey(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile(configFile, []byte(`{"workers": [], "init_command": "claude", "checkpoint": {"interval": "5m"}}`), 0644)
+
+	if err := updateConfigKey("checkpoint.on_idle", "true", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateConfigKey("profiles.fast.init_command", "claude --fast", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateConfigKey("artifact_dirs", `["node_modules", "dist"]`, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateConfigKey("checkpoint.interval", "", true); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := []struct{ key, value string }{
+		{"worker_ttl", "3 days"},
+		{"split_direction", "diagonal"},
+		{"checkpoint.on_idle", "maybe"},
+		{"workers", "[]"},
+		{"nosuch", "1"},
+	}
+	for _, r := range rejected {
+		if err := updateConfigKey(r.key, r.value, false); err == nil {
+			t.Errorf("Expected setting %s to %q to be rejected", r.key, r.value)
+		}
+	}
+
+	data, _ := os.ReadFile(configFile)
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		t.Fatal(err)
+	}
+	if config.InitCommand != "claude" || !config.Checkpoint.OnIdle || config.Checkpoint.Interval != "" {
+		t.Errorf("Unexpected config: %+v", config.Checkpoint)
+	}
+	if config.Profiles["fast"].InitCommand != "claude --fast" || len(config.ArtifactDirs) != 2 {
+		t.Errorf("Unexpected config: %s", data)
+	}
+	if config.WorkerTTL != "" || config.SplitDirection != "" {
+		t.Errorf("Expected rejected values not to be saved: %s", data)
+	}
+}
+
+func TestSaveEditedConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	original := []byte(`{"workers": [{"id": "a"}], "init_command": "claude"}`)
+	os.WriteFile(configFile, original, 0644)
+
+	// Nothing else saved: the edit is written as it is
+	edited := []byte(`{"workers": [{"id": "a", "note": "by hand"}], "init_command": "codex"}`)
+	if merged, err := saveEditedConfig(original, edited); err != nil || merged {
+		t.Fatalf("saveEditedConfig() = %v, %v", merged, err)
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != string(edited) {
+		t.Errorf("Expected the edit to be saved as it is, got %s", data)
+	}
+
+	// 'gtw add' saved a worker while the editor was open
+	original = edited
+	if err := saveConfig(&Config{InitCommand: "codex", Workers: []Worker{{ID: "a"}, {ID: "b"}}}); err != nil {
+		t.Fatal(err)
+	}
+	edited = []byte(`{"workers": [{"id": "a"}], "init_command": "aider", "worker_ttl": "72h"}`)
+	if merged, err := saveEditedConfig(original, edited); err != nil || !merged {
+		t.Fatalf("saveEditedConfig() = %v, %v", merged, err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.InitCommand != "aider" || config.WorkerTTL != "72h" {
+		t.Errorf("Expected the edited settings, got %+v", config)
+	}
+	if len(config.Workers) != 2 || config.Workers[1].ID != "b" {
+		t.Errorf("Expected the worker added meanwhile to be kept, got %+v", config.Workers)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	configLockFile  = ".gtw/config.lock"
+	configLockWait  = 10 * time.Second // Give up waiting for another gtw after this long
+	configLockStale = 30 * time.Second // A lock this old was left by a gtw that died
+	configLockPoll  = 20 * time.Millisecond
+)
+
+// lockConfig takes the lock that keeps gtw processes (commands and the
+// daemon) from writing the config at the same time. The lock is a file
+// created exclusively, which works on every platform; one left behind by
+// a crashed process is taken over once it is stale. It returns the
+// function that releases the lock.
+func lockConfig() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(configLockFile), 0755); err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	for {
+		file, err := os.OpenFile(configLockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(configLockFile) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(configLockFile); err == nil && time.Since(info.ModTime()) > configLockStale {
+			os.Remove(configLockFile)
+			continue
+		}
+		if time.Since(started) > configLockWait {
+			return nil, fmt.Errorf("the config is locked by another gtw (remove %s if none is running)", configLockFile)
+		}
+		time.Sleep(configLockPoll)
+	}
+}
+
+// workerSnapshot records the workers as loaded, to tell later which ones
+// were changed.
+type workerSnapshot map[string]string
+
+func snapshotWorkerState(workers []Worker) workerSnapshot {
+	snapshot := make(workerSnapshot, len(workers))
+	for _, worker := range workers {
+		data, _ := json.Marshal(worker)
+		snapshot[worker.ID] = string(data)
+	}
+	return snapshot
+}
+
+// changed reports whether the worker differs from when it was loaded.
+func (s workerSnapshot) changed(worker Worker) bool {
+	data, _ := json.Marshal(worker)
+	return s[worker.ID] != string(data)
+}
+
+// mergeWorkerChanges copies the workers of changed that differ from the
+// snapshot into current. Workers that were added or removed in current
+// meanwhile stay added or removed.
+func mergeWorkerChanges(current *Config, changed []Worker, loaded workerSnapshot) {
+	for _, worker := range changed {
+		if _, ok := loaded[worker.ID]; !ok || !loaded.changed(worker) {
+			continue
+		}
+		if index := findWorkerIndex(current, worker.ID); index != -1 {
+			current.Workers[index] = worker
+		}
+	}
+}
+
+// saveWorkerChanges saves what a long-running loop changed in the workers
+// of config since loaded was taken, re-reading the config under the lock
+// so that what other gtw commands saved meanwhile is kept.
+func saveWorkerChanges(config *Config, loaded workerSnapshot) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	mergeWorkerChanges(current, config.Workers, loaded)
+	return writeConfig(current)
+}
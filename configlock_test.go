@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	unlock, err := lockConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(configLockFile); err != nil {
+		t.Fatalf("Expected the lock file to exist: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(configLockFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock file to be removed, got %v", err)
+	}
+
+	// A lock left behind by a gtw that died is taken over
+	if err := os.WriteFile(configLockFile, []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * configLockStale)
+	if err := os.Chtimes(configLockFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = lockConfig()
+	if err != nil {
+		t.Fatalf("Expected a stale lock to be taken over: %v", err)
+	}
+	unlock()
+}
+
+func TestSaveWorkerChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := saveConfig(&Config{Workers: []Worker{{ID: "a"}, {ID: "b"}, {ID: "c"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := snapshotWorkerState(config.Workers)
+	config.Workers[0].Status = WorkerPaused
+	config.Workers[1].Status = WorkerPaused
+
+	// Meanwhile another command removes b, renames c's branch and adds d
+	current, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.Workers = []Worker{current.Workers[0], current.Workers[2], {ID: "d"}}
+	current.Workers[1].Branch = "feature"
+	if err := saveConfig(current); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveWorkerChanges(config, loaded); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, worker := range saved.Workers {
+		ids = append(ids, worker.ID)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[1] != "c" || ids[2] != "d" {
+		t.Fatalf("Expected workers a, c and d, got %v", ids)
+	}
+	if saved.Workers[0].Status != WorkerPaused {
+		t.Errorf("Expected the change to a to be saved, got %q", saved.Workers[0].Status)
+	}
+	if saved.Workers[1].Branch != "feature" {
+		t.Errorf("Expected the unchanged c to keep the other command's change, got %q", saved.Workers[1].Branch)
+	}
+	if _, err := os.Stat(configLockFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock to be released, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// ConflictsOptions holds the settings given to `gtw conflicts`.
+type ConflictsOptions struct {
+	Tags   []string
+	Base   string // Compare with this ref instead of each worker's base
+	NoBase bool   // Only compare workers with each other
+	JSON   bool
+}
+
+// ConflictResult is the outcome of a trial merge of two branches.
+type ConflictResult struct {
+	Left  string   `json:"left"`  // Worker ID, or the base ref
+	Right string   `json:"right"` // Worker ID
+	Files []string `json:"files,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+func init() {
+	var opts ConflictsOptions
+	conflictsCmd := &cobra.Command{
+		Use:   "conflicts [worker-id...]",
+		Short: "Report which workers' branches would conflict with their base or each other",
+		Long: `Trial-merge each worker's branch with its base and with every other worker's
+branch (with 'git merge-tree', without touching any worktree) and report the
+pairs that would conflict and on which files. Only committed changes are
+considered. gtw exits with status 1 when a conflict is found.
+
+Requires git 2.38 or later.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !reportConflicts(args, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	conflictsCmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Only check workers with this tag (repeatable, all must match)")
+	conflictsCmd.Flags().StringVar(&opts.Base, "base", "", "Check against this ref (e.g. origin/main) instead of each worker's base")
+	conflictsCmd.Flags().BoolVar(&opts.NoBase, "no-base", false, "Only check workers against each other")
+	conflictsCmd.Flags().BoolVar(&opts.JSON, "json", false, "Print the results as JSON")
+	conflictsCmd.MarkFlagsMutuallyExclusive("base", "no-base")
+	rootCmd.AddCommand(conflictsCmd)
+}
+
+// trialMerge merges two commits in memory and returns the conflicted files.
+func trialMerge(left, right string) ([]string, error) {
+	output, err := gitCommand("merge-tree", "--write-tree", "--name-only", "--no-messages", left, right).Output()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// The first line is the tree written with conflict markers
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		return lines[1:], nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 129:
+		return nil, errors.New("git merge-tree --write-tree needs git 2.38 or later")
+	}
+	return nil, fmt.Errorf("git merge-tree failed: %v", err)
+}
+
+// conflictPair is a trial merge to run: refs to merge and the names shown.
+type conflictPair struct {
+	left, right       string
+	leftRef, rightRef string
+}
+
+// conflictPairs returns each worker against its base (or base) and every
+// pair of workers.
+func conflictPairs(workers []Worker, branches map[string]string, opts ConflictsOptions) []conflictPair {
+	var pairs []conflictPair
+	if !opts.NoBase {
+		projectBase := ""
+		for _, worker := range workers {
+			base := opts.Base
+			if base == "" {
+				base = worker.Base
+			}
+			if base == "" {
+				if projectBase == "" {
+					projectBase = workerBase(Worker{})
+				}
+				base = projectBase
+			}
+			pairs = append(pairs, conflictPair{left: base, right: worker.ID, leftRef: base, rightRef: branches[worker.ID]})
+		}
+	}
+	for i := range workers {
+		for j := i + 1; j < len(workers); j++ {
+			left, right := workers[i].ID, workers[j].ID
+			pairs = append(pairs, conflictPair{left: left, right: right, leftRef: branches[left], rightRef: branches[right]})
+		}
+	}
+	return pairs
+}
+
+// runTrialMerges runs the merges a few at a time, keeping their order.
+func runTrialMerges(pairs []conflictPair) []ConflictResult {
+	results := make([]ConflictResult, len(pairs))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.NumCPU())
+	for i, pair := range pairs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			result := ConflictResult{Left: pair.left, Right: pair.right}
+			files, err := trialMerge(pair.leftRef, pair.rightRef)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.Files = files
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func reportConflicts(ids []string, opts ConflictsOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	var workers []Worker
+	if len(ids) > 0 {
+		for _, id := range ids {
+			index := findWorkerIndex(config, id)
+			if index == -1 {
+				fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+				return false
+			}
+			workers = append(workers, config.Workers[index])
+		}
+	} else {
+		for _, worker := range config.Workers {
+			if hasAllTags(worker, opts.Tags) {
+				workers = append(workers, worker)
+			}
+		}
+	}
+	if len(workers) == 0 {
+		fmt.Fprintln(os.Stderr, "No matching workers found")
+		return false
+	}
+
+	branches := map[string]string{}
+	for _, worker := range workers {
+		branches[worker.ID] = getWorktreeBranch(worker.WorktreePath, worker.branchName())
+	}
+	results := runTrialMerges(conflictPairs(workers, branches, opts))
+
+	clean := true
+	for _, result := range results {
+		if len(result.Files) > 0 || result.Error != "" {
+			clean = false
+		}
+	}
+
+	if opts.JSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		fmt.Println(string(data))
+		return clean
+	}
+
+	conflicts := 0
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			fmt.Printf("⚠️  %s ↔ %s: %s\n", result.Left, result.Right, result.Error)
+		case len(result.Files) > 0:
+			conflicts++
+			fmt.Printf("❌ %s ↔ %s: %s\n", result.Left, result.Right, strings.Join(result.Files, ", "))
+		}
+	}
+	if conflicts == 0 && clean {
+		fmt.Printf("✅ No conflicts among %d worker(s) (%d pair(s) checked)\n", len(workers), len(results))
+	} else if conflicts > 0 {
+		fmt.Printf("\n%d of %d pair(s) would conflict\n", conflicts, len(results))
+	}
+	return clean
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestTrialMergeAndPairs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	t.Chdir(t.TempDir())
+	git := func(args ...string) {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(branch, file, content string) {
+		git("checkout", "-q", "-B", branch, "main")
+		os.WriteFile(file, []byte(content), 0644)
+		git("add", file)
+		git("commit", "-q", "-m", branch)
+	}
+	git("init", "-q", "-b", "main")
+	os.WriteFile("shared.txt", []byte("base\n"), 0644)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	commit("a", "shared.txt", "a\n")
+	commit("b", "shared.txt", "b\n")
+	commit("c", "other.txt", "c\n")
+	git("checkout", "-q", "main")
+
+	if _, err := exec.Command("git", "merge-tree", "--write-tree", "HEAD", "HEAD").Output(); err != nil {
+		t.Skip("git merge-tree --write-tree is not supported")
+	}
+
+	files, err := trialMerge("a", "b")
+	if err != nil || len(files) != 1 || files[0] != "shared.txt" {
+		t.Errorf("trialMerge(a, b) = %v, %v", files, err)
+	}
+	if files, err := trialMerge("a", "c"); err != nil || len(files) != 0 {
+		t.Errorf("trialMerge(a, c) = %v, %v", files, err)
+	}
+
+	workers := []Worker{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	branches := map[string]string{"a": "a", "b": "b", "c": "c"}
+	if pairs := conflictPairs(workers, branches, ConflictsOptions{}); len(pairs) != 6 || pairs[0].leftRef != "main" {
+		t.Errorf("Expected 3 base and 3 worker pairs, got %+v", pairs)
+	}
+	pairs := conflictPairs(workers, branches, ConflictsOptions{NoBase: true})
+	results := runTrialMerges(pairs)
+	if len(results) != 3 || len(results[0].Files) != 1 || len(results[1].Files) != 0 {
+		t.Errorf("Unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// DiffOptions holds the settings given to `gtw diff`.
+type DiffOptions struct {
+	Stat     bool
+	NameOnly bool
+}
+
+// CherryPickOptions holds the settings given to `gtw cherry-pick`.
+type CherryPickOptions struct {
+	DryRun bool
+	Yes    bool
+}
+
+func init() {
+	var diffOpts DiffOptions
+	diffCmd := &cobra.Command{
+		Use:   "diff <worker>[..<worker>] [<worker>]",
+		Short: "Compare the branches of two workers, or a worker with its base",
+		Long: `Show the difference between two workers' branches, given as 'w1..w2' or
+'w1 w2' ('w1...w2' shows only w2's changes since the branches diverged).
+With a single worker its branch is compared with its base.
+
+Only committed changes are compared; see 'gtw checkpoint' to commit work in
+progress first.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !diffWorkers(args, diffOpts) {
+				os.Exit(1)
+			}
+		},
+	}
+	diffCmd.Flags().BoolVar(&diffOpts.Stat, "stat", false, "Show a diffstat instead of the patch")
+	diffCmd.Flags().BoolVar(&diffOpts.NameOnly, "name-only", false, "Show only the names of changed files")
+	rootCmd.AddCommand(diffCmd)
+
+	var pickOpts CherryPickOptions
+	cherryPickCmd := &cobra.Command{
+		Use:   "cherry-pick <from-worker> <to-worker> [commit...]",
+		Short: "Copy commits from one worker's branch onto another's",
+		Long: `Cherry-pick commits into the worktree of <to-worker>. Without commits, the
+commits of <from-worker> since its base that <to-worker> does not have yet
+(by patch, like 'git cherry') are picked, oldest first.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !cherryPickWorkers(args[0], args[1], args[2:], pickOpts) {
+				os.Exit(1)
+			}
+		},
+	}
+	cherryPickCmd.Flags().BoolVarP(&pickOpts.DryRun, "dry-run", "n", false, "Only list the commits that would be picked")
+	cherryPickCmd.Flags().BoolVarP(&pickOpts.Yes, "yes", "y", false, "Do not ask for confirmation")
+	rootCmd.AddCommand(cherryPickCmd)
+}
+
+// workerBranch returns the branch checked out in the worker's worktree.
+func workerBranch(config *Config, id string) (Worker, string, error) {
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		return Worker{}, "", fmt.Errorf("worker '%s' not found", id)
+	}
+	worker := config.Workers[index]
+	return worker, getWorktreeBranch(worker.WorktreePath, worker.branchName()), nil
+}
+
+// parseDiffArgs splits the arguments of `gtw diff` into the two workers and
+// the git range operator; to is "" when a worker is compared with its base.
+func parseDiffArgs(args []string) (from, to, op string, err error) {
+	if len(args) == 2 {
+		return args[0], args[1], "..", nil
+	}
+	spec := args[0]
+	for _, op := range []string{"...", ".."} {
+		if before, after, found := strings.Cut(spec, op); found {
+			if before == "" || after == "" {
+				return "", "", "", fmt.Errorf("invalid range '%s' (use w1..w2)", spec)
+			}
+			return before, after, op, nil
+		}
+	}
+	return spec, "", "...", nil
+}
+
+func diffWorkers(args []string, opts DiffOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	fromID, toID, op, err := parseDiffArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	var from, to string
+	if toID == "" {
+		worker, branch, err := workerBranch(config, fromID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		from, to = workerBase(worker), branch
+	} else {
+		if _, from, err = workerBranch(config, fromID); err == nil {
+			_, to, err = workerBranch(config, toID)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	}
+
+	gitArgs := []string{"diff"}
+	if !colorEnabled {
+		gitArgs = append(gitArgs, "--no-color")
+	}
+	if opts.Stat {
+		gitArgs = append(gitArgs, "--stat")
+	}
+	if opts.NameOnly {
+		gitArgs = append(gitArgs, "--name-only")
+	}
+	gitArgs = append(gitArgs, from+op+to, "--")
+
+	cmd := gitCommand(gitArgs...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Timeout = 0 // git may page the output
+	return cmd.Run() == nil
+}
+
+// commitsToPick returns the commits of from since base that are missing on
+// to, oldest first. Commits whose patch is already on to are left out.
+func commitsToPick(from, to, base string) ([]string, error) {
+	args := []string{"rev-list", "--reverse", "--no-merges", "--cherry-pick", "--right-only", to + "..." + from}
+	if base != "" {
+		args = append(args, "^"+base)
+	}
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing commits of %s failed: %v", from, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+func cherryPickWorkers(fromID, toID string, commits []string, opts CherryPickOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if fromID == toID {
+		fmt.Fprintln(os.Stderr, "Error: Give two different workers")
+		return false
+	}
+	fromWorker, from, err := workerBranch(config, fromID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	toWorker, to, err := workerBranch(config, toID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if _, err := os.Stat(toWorker.WorktreePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Worktree of '%s' is missing\n", toID)
+		return false
+	}
+
+	if len(commits) == 0 {
+		if commits, err = commitsToPick(from, to, workerBase(fromWorker)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if len(commits) == 0 {
+			fmt.Printf("'%s' already has every commit of '%s'\n", toID, fromID)
+			return true
+		}
+	}
+
+	// Commits are resolved in the source worktree, so HEAD~1 means the
+	// source worker's HEAD~1
+	fmt.Printf("Commits to pick from '%s' (%s) onto '%s' (%s):\n", fromID, from, toID, to)
+	for i, commit := range commits {
+		output, err := gitCommand("-C", fromWorker.WorktreePath, "log", "-1", "--format=%H %h %s", commit).Output()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Unknown commit '%s'\n", commit)
+			return false
+		}
+		hash, summary, _ := strings.Cut(strings.TrimSpace(string(output)), " ")
+		commits[i] = hash
+		fmt.Printf("  %s\n", summary)
+	}
+	if opts.DryRun {
+		return true
+	}
+	if !opts.Yes && !confirm(fmt.Sprintf("Cherry-pick %d commit(s) onto '%s'?", len(commits), toID)) {
+		fmt.Println("Cancelled")
+		return false
+	}
+
+	output, err := gitCommand(append([]string{"-C", toWorker.WorktreePath, "cherry-pick"}, commits...)...).CombinedOutput()
+	if err != nil {
+		fmt.Print(string(output))
+		if operationInProgress(toWorker.WorktreePath) != "" {
+			fmt.Printf("❌ Cherry-pick stopped on a conflict. Resolve it in %s and run 'git cherry-pick --continue' (or --abort)\n", toWorker.WorktreePath)
+		} else {
+			fmt.Printf("❌ Cherry-pick failed: %v\n", err)
+		}
+		return false
+	}
+	fmt.Printf("✅ Picked %d commit(s) onto '%s'\n", len(commits), toID)
+	return true
+}
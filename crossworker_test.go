@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestParseDiffArgs(t *testing.T) {
+	tests := []struct {
+		args         []string
+		from, to, op string
+		wantErr      bool
+	}{
+		{[]string{"a..b"}, "a", "b", "..", false},
+		{[]string{"a...b"}, "a", "b", "...", false},
+		{[]string{"a", "b"}, "a", "b", "..", false},
+		{[]string{"a"}, "a", "", "...", false},
+		{[]string{"a.."}, "", "", "", true},
+	}
+	for _, tt := range tests {
+		from, to, op, err := parseDiffArgs(tt.args)
+		if (err != nil) != tt.wantErr || from != tt.from || to != tt.to || op != tt.op {
+			t.Errorf("parseDiffArgs(%v) = %q, %q, %q, %v", tt.args, from, to, op, err)
+		}
+	}
+}
+
+func TestCommitsToPick(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	t.Chdir(t.TempDir())
+	git := func(args ...string) {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(file string) {
+		os.WriteFile(file, []byte(file), 0644)
+		git("add", file)
+		git("commit", "-q", "-m", file)
+	}
+	git("init", "-q", "-b", "main")
+	commit("base")
+	git("checkout", "-q", "-b", "to")
+	commit("to-only")
+	git("checkout", "-q", "main")
+	commit("main-later") // Not part of either worker's own work
+	git("checkout", "-q", "-b", "from")
+	commit("one")
+	commit("two")
+	git("checkout", "-q", "to")
+	git("cherry-pick", "from~1") // "one" is already on to
+
+	commits, err := commitsToPick("from", "to", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("Expected only 'two' to be picked, got %v", commits)
+	}
+	output, _ := exec.Command("git", "log", "-1", "--format=%s", commits[0]).Output()
+	if string(output) != "two\n" {
+		t.Errorf("Expected 'two', got %q", output)
+	}
+}
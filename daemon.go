@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DaemonOptions holds the settings given to `gtw daemon`.
+type DaemonOptions struct {
+	Interval    time.Duration
+	Quiet       time.Duration
+	MetricsAddr string
+	GCInterval  time.Duration
+	GCRemove    bool
+
+	RestoreSession bool // Recreate the session at start when workers are recorded
+}
+
+func init() {
+	var opts DaemonOptions
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Watch workers in the foreground and dispatch queued tasks",
+		Run:   func(cmd *cobra.Command, args []string) { runDaemon(opts) },
+	}
+	daemonCmd.Flags().DurationVar(&opts.Interval, "interval", 5*time.Second, "How often workers are polled")
+	daemonCmd.Flags().DurationVar(&opts.Quiet, "quiet", defaultIdleQuiet, "How long pane output must stay unchanged to count as idle")
+	daemonCmd.Flags().StringVar(&opts.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address (e.g. 127.0.0.1:9464)")
+	daemonCmd.Flags().DurationVar(&opts.GCInterval, "gc-interval", time.Hour, "How often workers past worker_ttl are checked (0 disables)")
+	daemonCmd.Flags().BoolVar(&opts.GCRemove, "gc-remove", false, "Remove expired workers without unsaved work instead of only reporting them")
+	daemonCmd.Flags().BoolVar(&opts.RestoreSession, "restore-session", false, "Recreate the session and worker panes at start when they are gone (e.g. after a reboot)")
+	daemonCmd.AddCommand(daemonInstallCommand(), daemonUninstallCommand())
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(opts DaemonOptions) {
+	fmt.Printf("gtw daemon started (interval: %s, idle after: %s). Press Ctrl-C to stop.\n", opts.Interval, opts.Quiet)
+
+	// Only at start, so that sessions closed for being idle stay closed
+	if opts.RestoreSession {
+		if sessionName := getSessionName(); !mux.HasSession(sessionName) {
+			if config, err := loadConfig(); err == nil && len(config.Workers) > 0 {
+				recreateSession(sessionName, true)
+			}
+		}
+	}
+
+	if opts.MetricsAddr != "" {
+		go serveMetrics(opts.MetricsAddr)
+	}
+
+	tracker := NewIdleTracker(opts.Quiet)
+	checkpointer := NewCheckpointer(opts.Quiet)
+	watcher := NewPermissionWatcher()
+	activity := NewActivityWatcher(opts.Quiet)
+	sessions := NewSessionWatcher()
+	states := NewStateWatcher(opts.Quiet)
+	health := NewHealthWatcher()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var lastGC time.Time
+	for {
+		now := time.Now()
+		if slept := sessions.Tick(now, opts.Interval); slept > 0 {
+			fmt.Printf("Woke up after %s asleep; re-verifying panes...\n", slept.Round(time.Second))
+			repairAfterWake()
+		}
+
+		if config, err := loadConfig(); err == nil {
+			loaded := snapshotWorkerState(config.Workers)
+			changed := revalidatePanes(config)
+			if watcher.Run(config) {
+				changed = true
+			}
+			if states.Run(config) {
+				changed = true
+			}
+			if health.Run(config) {
+				changed = true
+			}
+			// The checks take a while; keep what other commands saved
+			if changed {
+				if err := saveWorkerChanges(config, loaded); err != nil {
+					fmt.Printf("Warning: Could not save worker changes: %v\n", err)
+				}
+			}
+			checkpointer.Run(config)
+			activity.Run(config)
+			refreshStaleGitCache(config)
+			sessions.Run(config, now)
+		}
+
+		dispatchTasks(tracker, true)
+
+		if opts.GCInterval > 0 && time.Since(lastGC) >= opts.GCInterval {
+			reportExpiredWorkers(opts.GCRemove)
+			lastGC = time.Now()
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Modes of a dependency cache rule
+const (
+	DepSymlink = "symlink"
+	DepCopy    = "copy"
+)
+
+// DependencyCache wires a dependency directory of new worktrees (e.g.
+// node_modules or .venv) to a shared one instead of installing it again.
+type DependencyCache struct {
+	Path   string `json:"path"`             // Relative to the worktree
+	Source string `json:"source,omitempty"` // Shared directory (default: the same path in the project directory)
+	Mode   string `json:"mode,omitempty"`   // symlink (default) or copy
+}
+
+// dependencyCachesFor returns the rules for a worker: its profile's, when
+// the profile has any, or else dependency_caches.
+func (c *Config) dependencyCachesFor(profile string) []DependencyCache {
+	if p, ok := c.Profiles[profile]; ok && profile != "" && len(p.DependencyCaches) > 0 {
+		return p.DependencyCaches
+	}
+	return c.DependencyCaches
+}
+
+// workerEnvFor returns worker_env merged with the profile's env.
+func (c *Config) workerEnvFor(profile string) map[string]string {
+	env := map[string]string{}
+	for key, value := range c.WorkerEnv {
+		env[key] = value
+	}
+	if p, ok := c.Profiles[profile]; ok && profile != "" {
+		for key, value := range p.Env {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// dependencySource resolves a rule's shared directory.
+func dependencySource(config *Config, rule DependencyCache) string {
+	source := rule.Source
+	if source == "" {
+		source = rule.Path
+	}
+	if strings.HasPrefix(source, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			source = filepath.Join(home, source[2:])
+		}
+	}
+	if !filepath.IsAbs(source) {
+		projectPath := config.ProjectPath
+		if projectPath == "" {
+			projectPath, _ = os.Getwd()
+		}
+		source = filepath.Join(projectPath, source)
+	}
+	return source
+}
+
+// applyDependencyCaches links or copies the shared dependency directories
+// into a new worktree. Paths that already exist in the worktree and missing
+// sources are skipped with a warning.
+func applyDependencyCaches(config *Config, profile, worktreePath string) {
+	for _, rule := range config.dependencyCachesFor(profile) {
+		if rule.Path == "" || filepath.IsAbs(rule.Path) || strings.HasPrefix(filepath.Clean(rule.Path), "..") {
+			fmt.Printf("Warning: Skipping dependency cache with invalid path '%s' (use a path inside the worktree)\n", rule.Path)
+			continue
+		}
+		source := dependencySource(config, rule)
+		target := filepath.Join(worktreePath, rule.Path)
+		if _, err := os.Stat(source); err != nil {
+			fmt.Printf("Warning: Skipping dependency cache '%s': %s does not exist\n", rule.Path, source)
+			continue
+		}
+		if _, err := os.Lstat(target); err == nil {
+			fmt.Printf("Warning: Skipping dependency cache '%s': it already exists in the worktree\n", rule.Path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			fmt.Printf("Warning: Dependency cache '%s': %v\n", rule.Path, err)
+			continue
+		}
+
+		switch rule.Mode {
+		case "", DepSymlink:
+			if err := os.Symlink(source, target); err != nil {
+				fmt.Printf("Warning: Linking dependency cache '%s' failed: %v\n", rule.Path, err)
+				continue
+			}
+			fmt.Printf("Linked %s -> %s\n", rule.Path, source)
+		case DepCopy:
+			cmd := newCommand("cp", "-R", source, target)
+			cmd.Timeout = 0 // Large trees take a while
+			if output, err := cmd.CombinedOutput(); err != nil {
+				fmt.Printf("Warning: Copying dependency cache '%s' failed: %v\n%s", rule.Path, err, output)
+				continue
+			}
+			fmt.Printf("Copied %s from %s\n", rule.Path, source)
+		default:
+			fmt.Printf("Warning: Skipping dependency cache '%s' with unknown mode '%s' (use symlink or copy)\n", rule.Path, rule.Mode)
+		}
+	}
+}
+
+// envExports returns the shell commands setting env in the worker's pane,
+// or "". Values are double-quoted, so $VARIABLES in them are expanded by the
+// pane's shell.
+func envExports(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`")
+	var exports []string
+	for _, key := range keys {
+		exports = append(exports, fmt.Sprintf(`export %s="%s"`, key, escape.Replace(env[key])))
+	}
+	return strings.Join(exports, " && ")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyDependencyCaches(t *testing.T) {
+	project := t.TempDir()
+	os.MkdirAll(filepath.Join(project, "node_modules", "left-pad"), 0755)
+	os.MkdirAll(filepath.Join(project, "vendor"), 0755)
+	os.WriteFile(filepath.Join(project, "vendor", "lib.go"), []byte("package lib"), 0644)
+	worktree := filepath.Join(project, "worktree", "api")
+	os.MkdirAll(filepath.Join(worktree, "existing"), 0755)
+
+	config := &Config{
+		ProjectPath: project,
+		DependencyCaches: []DependencyCache{
+			{Path: "node_modules"},
+			{Path: "vendor", Mode: DepCopy},
+			{Path: "existing", Source: "vendor"},
+			{Path: "missing"},
+			{Path: "../escape", Source: "vendor"},
+		},
+	}
+	applyDependencyCaches(config, "", worktree)
+
+	if target, err := os.Readlink(filepath.Join(worktree, "node_modules")); err != nil || target != filepath.Join(project, "node_modules") {
+		t.Errorf("Expected node_modules to be linked, got %q, %v", target, err)
+	}
+	if info, err := os.Lstat(filepath.Join(worktree, "vendor", "lib.go")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("Expected vendor to be copied: %v", err)
+	}
+	if info, _ := os.Lstat(filepath.Join(worktree, "existing")); info.Mode()&os.ModeSymlink != 0 {
+		t.Error("Expected an existing path to be left alone")
+	}
+	for _, path := range []string{filepath.Join(worktree, "missing"), filepath.Join(project, "worktree", "escape")} {
+		if _, err := os.Lstat(path); err == nil {
+			t.Errorf("Expected %s not to be created", path)
+		}
+	}
+}
+
+func TestDependencyCachesForProfile(t *testing.T) {
+	config := &Config{
+		DependencyCaches: []DependencyCache{{Path: "node_modules"}},
+		WorkerEnv:        map[string]string{"GOMODCACHE": "/cache/go", "A": "1"},
+		Profiles: map[string]Profile{
+			"py":   {DependencyCaches: []DependencyCache{{Path: ".venv"}}, Env: map[string]string{"A": "2"}},
+			"bare": {},
+		},
+	}
+	if rules := config.dependencyCachesFor("py"); len(rules) != 1 || rules[0].Path != ".venv" {
+		t.Errorf("Expected the profile's rules, got %+v", rules)
+	}
+	if rules := config.dependencyCachesFor("bare"); len(rules) != 1 || rules[0].Path != "node_modules" {
+		t.Errorf("Expected the default rules for a profile without any, got %+v", rules)
+	}
+	if env := config.workerEnvFor("py"); env["A"] != "2" || env["GOMODCACHE"] != "/cache/go" {
+		t.Errorf("Unexpected env %v", env)
+	}
+}
+
+func TestEnvExports(t *testing.T) {
+	got := envExports(map[string]string{"GOMODCACHE": "$HOME/go/mod", "Q": `say "hi"`})
+	want := `export GOMODCACHE="$HOME/go/mod" && export Q="say \"hi\""`
+	if got != want {
+		t.Errorf("envExports() = %q, want %q", got, want)
+	}
+	if envExports(nil) != "" {
+		t.Error("Expected no exports for an empty env")
+	}
+}
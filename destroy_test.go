@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDestroySession(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	tests := []struct {
+		name          string
+		opts          RemoveOptions
+		wantWorkers   bool // Kept as detached
+		wantWorktrees bool // The clean worktree survives
+	}{
+		{"forget workers", RemoveOptions{Yes: true}, false, true},
+		{"keep worktrees", RemoveOptions{Yes: true, KeepWorktrees: true}, true, true},
+		{"keep branches", RemoveOptions{Yes: true, KeepBranches: true}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "demo")
+			os.Mkdir(dir, 0755)
+			t.Chdir(dir)
+			git := func(args ...string) error {
+				return exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...).Run()
+			}
+			for _, args := range [][]string{
+				{"init", "-q", "-b", "main"},
+				{"commit", "-q", "--allow-empty", "-m", "init"},
+				{"worktree", "add", "-q", "-b", "feature/one", "worktree/issue-1"},
+				{"worktree", "add", "-q", "-b", "issue-2", "worktree/issue-2"},
+			} {
+				if err := git(args...); err != nil {
+					t.Fatalf("git %v: %v", args, err)
+				}
+			}
+			os.WriteFile(filepath.Join("worktree", "issue-2", "draft.txt"), []byte("x"), 0644)
+
+			config := &Config{ProjectPath: dir, Workers: []Worker{
+				{ID: "issue-1", PaneID: "%1", WorktreePath: filepath.Join("worktree", "issue-1")},
+				{ID: "issue-2", PaneID: "%2", WorktreePath: filepath.Join("worktree", "issue-2")},
+			}}
+			if err := saveConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			fake := newSessionMultiplexer("%1", "%2")
+			fake.sessions["demo"] = true
+			useMultiplexer(t, fake)
+
+			destroySession(tt.opts)
+
+			if fake.sessions["demo"] {
+				t.Error("Expected the session to be killed")
+			}
+			saved, err := loadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantWorkers {
+				if len(saved.Workers) != 0 || saved.ProjectPath != "" {
+					t.Errorf("Expected the workers to be forgotten, got %+v", saved.Workers)
+				}
+			} else {
+				if len(saved.Workers) != 2 {
+					t.Fatalf("Expected 2 detached workers, got %+v", saved.Workers)
+				}
+				for _, worker := range saved.Workers {
+					if worker.Status != WorkerDetached || worker.PaneID != "" {
+						t.Errorf("Expected worker %s to be detached without a pane, got %+v", worker.ID, worker)
+					}
+				}
+				if saved.Workers[0].Branch != "feature/one" {
+					t.Errorf("Expected the branch to be recorded, got %q", saved.Workers[0].Branch)
+				}
+			}
+			if _, err := os.Stat(filepath.Join("worktree", "issue-1")); (err == nil) != tt.wantWorktrees {
+				t.Errorf("Expected the clean worktree to exist: %v, got %v", tt.wantWorktrees, err)
+			}
+			// Uncommitted changes and branches are never removed
+			if _, err := os.Stat(filepath.Join("worktree", "issue-2", "draft.txt")); err != nil {
+				t.Errorf("Expected the uncommitted changes to survive: %v", err)
+			}
+			if err := git("rev-parse", "--verify", "-q", "feature/one"); err != nil {
+				t.Errorf("Expected the branch to be kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestDetachWorkers(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	for _, removeWorktrees := range []bool{false, true} {
+		dir := t.TempDir()
+		t.Chdir(dir)
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "main"},
+			{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+			{"worktree", "add", "-q", "-b", "feature/one", "worktree/issue-1"},
+		} {
+			if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, output)
+			}
+		}
+
+		config := &Config{Workers: []Worker{{ID: "issue-1", PaneID: "%1", WorktreePath: filepath.Join("worktree", "issue-1")}}}
+		detachWorkers(config, removeWorktrees)
+
+		worker := config.Workers[0]
+		if worker.Status != WorkerDetached || worker.PaneID != "" || worker.Branch != "feature/one" {
+			t.Errorf("removeWorktrees=%v: unexpected worker %+v", removeWorktrees, worker)
+		}
+		if _, err := os.Stat(worker.WorktreePath); (err == nil) == removeWorktrees {
+			t.Errorf("removeWorktrees=%v: unexpected worktree state %v", removeWorktrees, err)
+		}
+		if err := exec.Command("git", "rev-parse", "--verify", "-q", "feature/one").Run(); err != nil {
+			t.Errorf("removeWorktrees=%v: expected the branch to be kept", removeWorktrees)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultArtifactDirs are removed by `gtw clean --artifacts` unless the
+// artifact_dirs config value says otherwise.
+var defaultArtifactDirs = []string{"node_modules", "target", ".venv", "dist", "build", "__pycache__", ".next"}
+
+// CleanOptions holds the settings given to `gtw clean`.
+type CleanOptions struct {
+	Artifacts bool
+	All       bool
+	Tags      []string
+	Yes       bool
+	DryRun    bool
+}
+
+func init() {
+	var duTags []string
+	duCmd := &cobra.Command{
+		Use:   "du",
+		Short: "Show disk usage of each worker's worktree",
+		Run:   func(cmd *cobra.Command, args []string) { showDiskUsage(duTags) },
+	}
+	duCmd.Flags().StringArrayVar(&duTags, "tag", nil, "Only show workers with this tag (repeatable, all must match)")
+	rootCmd.AddCommand(duCmd)
+
+	var opts CleanOptions
+	cleanCmd := &cobra.Command{
+		Use:   "clean [worker-id...]",
+		Short: "Delete build artifacts from worktrees",
+		Long: `Delete build artifact directories (artifact_dirs in the config, by default
+node_modules, target, .venv, dist, build, __pycache__ and .next) from the given
+workers' worktrees. Directories that git tracks files in are kept. Select
+workers by ID, with --tag, or with --all.`,
+		Run: func(cmd *cobra.Command, args []string) { cleanWorktrees(args, opts) },
+	}
+	cleanCmd.Flags().BoolVar(&opts.Artifacts, "artifacts", false, "Delete build artifact directories")
+	cleanCmd.Flags().BoolVar(&opts.All, "all", false, "Clean every worker")
+	cleanCmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Clean workers with this tag (repeatable, all must match)")
+	cleanCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Delete without asking")
+	cleanCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only show what would be deleted")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func artifactDirNames(config *Config) []string {
+	if len(config.ArtifactDirs) > 0 {
+		return config.ArtifactDirs
+	}
+	return defaultArtifactDirs
+}
+
+// hasTrackedFiles reports whether git tracks any file in dir, part of the
+// worktree root. When git cannot tell, the directory is treated as tracked.
+func hasTrackedFiles(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return true
+	}
+	output, err := gitCommand("-C", root, "ls-files", "-z", "--", filepath.ToSlash(rel)).Output()
+	return err != nil || len(output) > 0
+}
+
+// findArtifactDirs returns the directories under root whose name is one of
+// names and that git tracks no files in (e.g. a committed build/Dockerfile
+// keeps build/). Matched directories are not descended into and .git is
+// skipped.
+func findArtifactDirs(root string, names []string) ([]string, error) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var found []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d == nil {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if wanted[d.Name()] && !hasTrackedFiles(root, path) {
+			found = append(found, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return found, err
+}
+
+func showDiskUsage(tags []string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	type usage struct {
+		worker    Worker
+		size      int64
+		artifacts int64
+	}
+
+	var usages []usage
+	names := artifactDirNames(config)
+	for _, worker := range config.Workers {
+		if !hasAllTags(worker, tags) {
+			continue
+		}
+		u := usage{worker: worker, size: -1}
+		if size, err := dirSize(worker.WorktreePath); err == nil {
+			u.size = size
+			dirs, _ := findArtifactDirs(worker.WorktreePath, names)
+			for _, dir := range dirs {
+				if size, err := dirSize(dir); err == nil {
+					u.artifacts += size
+				}
+			}
+		}
+		usages = append(usages, u)
+	}
+
+	if len(usages) == 0 {
+		fmt.Println("No workers found")
+		return
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].size > usages[j].size })
+
+	fmt.Printf("%-20s %-10s %-10s %s\n", "ID", "SIZE", "ARTIFACTS", "WORKTREE PATH")
+	fmt.Println(strings.Repeat("-", 80))
+
+	var total, totalArtifacts int64
+	for _, u := range usages {
+		size, artifacts := "missing", "-"
+		if u.size >= 0 {
+			size = formatBytes(u.size)
+			artifacts = formatBytes(u.artifacts)
+			total += u.size
+			totalArtifacts += u.artifacts
+		}
+		fmt.Printf("%-20s %-10s %-10s %s\n", u.worker.ID, size, artifacts, u.worker.WorktreePath)
+	}
+
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-20s %-10s %s\n", "TOTAL", formatBytes(total), formatBytes(totalArtifacts))
+}
+
+func cleanWorktrees(ids []string, opts CleanOptions) {
+	if !opts.Artifacts {
+		fmt.Println("Error: Nothing to clean. Pass --artifacts to delete build artifact directories.")
+		return
+	}
+	if len(ids) == 0 && !opts.All && len(opts.Tags) == 0 {
+		fmt.Println("Error: Specify worker IDs, --tag or --all")
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	var workers []Worker
+	for _, id := range ids {
+		index := findWorkerIndex(config, id)
+		if index == -1 {
+			fmt.Printf("Worker '%s' not found\n", id)
+			return
+		}
+		workers = append(workers, config.Workers[index])
+	}
+	if len(ids) == 0 {
+		for _, worker := range config.Workers {
+			if hasAllTags(worker, opts.Tags) {
+				workers = append(workers, worker)
+			}
+		}
+	}
+
+	var targets []string
+	var total int64
+	names := artifactDirNames(config)
+	for _, worker := range workers {
+		dirs, err := findArtifactDirs(worker.WorktreePath, names)
+		if err != nil {
+			fmt.Printf("Warning: Could not scan worktree of '%s': %v\n", worker.ID, err)
+			continue
+		}
+		for _, dir := range dirs {
+			size, _ := dirSize(dir)
+			fmt.Printf("  %-10s %s\n", formatBytes(size), dir)
+			targets = append(targets, dir)
+			total += size
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No build artifacts found")
+		return
+	}
+	fmt.Printf("%d artifact directories, %s in total\n", len(targets), formatBytes(total))
+
+	if opts.DryRun {
+		return
+	}
+	if !opts.Yes && !confirm("Delete these directories?") {
+		fmt.Println("Aborted")
+		return
+	}
+
+	for _, dir := range targets {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Printf("❌ Error removing %s: %v\n", dir, err)
+		}
+	}
+	fmt.Printf("✅ Freed %s\n", formatBytes(total))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindArtifactDirs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	for _, dir := range []string{
+		"node_modules/pkg/node_modules",
+		"packages/web/node_modules",
+		"src",
+		".git/node_modules",
+		"rust/target/debug",
+	} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+	}
+	// Files with an artifact name are not directories to delete
+	os.WriteFile(filepath.Join(root, "src", "target"), nil, 0644)
+	// Directories git tracks files in are sources, not artifacts, though
+	// artifacts inside them still are
+	os.MkdirAll(filepath.Join(root, "build", "dist"), 0755)
+	os.WriteFile(filepath.Join(root, "build", "Dockerfile"), []byte("FROM scratch\n"), 0644)
+	git("add", "build/Dockerfile")
+	git("commit", "-q", "-m", "build")
+
+	found, err := findArtifactDirs(root, []string{"node_modules", "target", "build", "dist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(root, "build/dist"),
+		filepath.Join(root, "node_modules"),
+		filepath.Join(root, "packages/web/node_modules"),
+		filepath.Join(root, "rust/target"),
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("findArtifactDirs() = %v, want %v", found, want)
+	}
+}
+
+func TestArtifactDirNames(t *testing.T) {
+	if names := artifactDirNames(&Config{}); !reflect.DeepEqual(names, defaultArtifactDirs) {
+		t.Errorf("Expected defaults, got %v", names)
+	}
+	if names := artifactDirNames(&Config{ArtifactDirs: []string{"vendor"}}); !reflect.DeepEqual(names, []string{"vendor"}) {
+		t.Errorf("Expected configured dirs, got %v", names)
+	}
+}
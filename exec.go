@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultCommandTimeout = 60 * time.Second
+
+// commandTimeout bounds every git/tmux invocation so a hung `git fetch` or an
+// unresponsive tmux server cannot hang gtw forever. It is set from the
+// --cmd-timeout flag or the command_timeout config value.
+var commandTimeout = defaultCommandTimeout
+
+// Cmd describes an external command. It mirrors the parts of exec.Cmd that
+// gtw uses, but runs the command under a timeout and reports which command
+// timed out.
+type Cmd struct {
+	Name    string
+	Args    []string
+	Dir     string
+	Env     []string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Timeout time.Duration // Zero means no limit (e.g. interactive attach)
+	Retry   bool          // Retry transient failures (see retryPolicy); never done with Stdin
+}
+
+// TimeoutError is returned when a command exceeded its timeout.
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("command timed out after %s: %s", e.Timeout, e.Command)
+}
+
+func newCommand(name string, args ...string) *Cmd {
+	return &Cmd{Name: name, Args: args, Timeout: commandTimeout}
+}
+
+func tmuxCommand(args ...string) *Cmd {
+	cmd := newCommand("tmux", args...)
+	if tmuxViaWSL() {
+		cmd = newCommand("wsl.exe", wslCommandArgs("tmux", args)...)
+	}
+	cmd.Retry = true
+	return cmd
+}
+
+func gitCommand(args ...string) *Cmd {
+	cmd := newCommand("git", args...)
+	cmd.Retry = true
+	return cmd
+}
+
+// String returns the command line, for messages.
+func (c *Cmd) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// run runs the command with f, retrying it while f reports error output
+// that looks transient.
+func (c *Cmd) run(f func(*exec.Cmd) (string, error)) error {
+	for attempt := 1; ; attempt++ {
+		detail, err := c.runOnce(f)
+		if err == nil || !c.Retry || c.Stdin != nil || attempt >= retryPolicy.Attempts {
+			return err
+		}
+		reason := transientReason(detail)
+		if reason == "" {
+			return err
+		}
+		wait := retryPolicy.delay(attempt)
+		fmt.Fprintf(os.Stderr, "Warning: %s (%s); retrying in %s (%d/%d)\n", reason, c.summary(), wait.Round(time.Millisecond), attempt+1, retryPolicy.Attempts)
+		time.Sleep(wait)
+	}
+}
+
+// summary is the start of the command line, for retry messages.
+func (c *Cmd) summary() string {
+	s := c.String()
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
+
+// runOnce builds the underlying exec.Cmd, hands it to f and translates a
+// deadline into a TimeoutError.
+func (c *Cmd) runOnce(f func(*exec.Cmd) (string, error)) (string, error) {
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+
+	detail, err := f(cmd)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", &TimeoutError{Command: c.String(), Timeout: c.Timeout}
+	}
+	return detail, err
+}
+
+// The functions given to run return the error output of the command, which
+// decides whether a failure is retried.
+
+func (c *Cmd) Run() error {
+	return c.run(func(cmd *exec.Cmd) (string, error) {
+		var stderr bytes.Buffer
+		if cmd.Stderr == nil {
+			cmd.Stderr = &stderr
+		}
+		err := cmd.Run()
+		return stderr.String(), err
+	})
+}
+
+func (c *Cmd) Output() ([]byte, error) {
+	var output []byte
+	err := c.run(func(cmd *exec.Cmd) (string, error) {
+		var err error
+		output, err = cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return string(exitErr.Stderr), err
+		}
+		return "", err
+	})
+	return output, err
+}
+
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	var output []byte
+	err := c.run(func(cmd *exec.Cmd) (string, error) {
+		var err error
+		output, err = cmd.CombinedOutput()
+		return string(output), err
+	})
+	return output, err
+}
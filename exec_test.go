@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestCmdTimeout(t *testing.T) {
+	cmd := newCommand("sleep", "5")
+	cmd.Timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	err := cmd.Run()
+	if time.Since(started) > 3*time.Second {
+		t.Fatal("Command was not killed at the timeout")
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected TimeoutError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "sleep 5") {
+		t.Errorf("Timeout error should name the command, got %q", err.Error())
+	}
+}
+
+func TestCmdOutput(t *testing.T) {
+	output, err := newCommand("echo", "hello").Output()
+	if err != nil {
+		t.Fatalf("Output failed: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "hello" {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	if _, err := newCommand("false").Output(); err == nil {
+		t.Error("Expected error from failing command")
+	}
+}
+
+// A local flag named like a root persistent flag hides it on that command,
+// so the persistent flag could not be given there.
+func TestNoFlagShadowsRootFlags(t *testing.T) {
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if root := rootCmd.PersistentFlags().Lookup(f.Name); root != nil && root != f {
+					t.Errorf("'%s' defines --%s, which shadows the root flag", cmd.CommandPath(), f.Name)
+				}
+			})
+		}
+		for _, sub := range cmd.Commands() {
+			visit(sub)
+		}
+	}
+	for _, cmd := range rootCmd.Commands() {
+		visit(cmd)
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rootLocation names the project root in `gtw cp` and `gtw path`.
+const rootLocation = "root"
+
+// FileCopyOptions holds the settings given to `gtw cp`.
+type FileCopyOptions struct {
+	Force  bool
+	DryRun bool
+}
+
+// fileCopy is one file written by `gtw cp`.
+type fileCopy struct {
+	Src string
+	Dst string
+	Rel string // Dst relative to the destination worktree
+}
+
+func init() {
+	var opts FileCopyOptions
+	cpCmd := &cobra.Command{
+		Use:   "cp <worker>:<path> <worker>:<path>",
+		Short: "Copy files between worktrees",
+		Long: `Copy files between the worktrees of two workers, or the project root given
+as 'root:'. Paths are relative to the worktree; the source may be a glob
+(quote it so the shell does not expand it) or a directory, which is copied
+with its contents. With several sources, a directory source, or a
+destination that is a directory or ends in '/', files are copied into it.
+
+A destination file that differs and has uncommitted changes (or is
+untracked or ignored) is not overwritten unless --force is given.
+
+  gtw cp api:openapi.json web:src/api/
+  gtw cp api:'gen/*.ts' root:shared/
+  gtw cp root:.env issue-123:`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !copyFiles(args[0], args[1], opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	cpCmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Overwrite files with uncommitted changes")
+	cpCmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false, "Only list the files that would be copied")
+	rootCmd.AddCommand(cpCmd)
+}
+
+// parseCopyLocation splits "<worker>:<path>" into the worker (or root)
+// and the path, "." when empty.
+func parseCopyLocation(arg string) (string, string, error) {
+	name, path, ok := strings.Cut(arg, ":")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("'%s' is not <worker>:<path> (use 'root:<path>' for the project root)", arg)
+	}
+	if filepath.IsAbs(path) {
+		return "", "", fmt.Errorf("'%s': the path must be relative to the worktree", arg)
+	}
+	if path == "" {
+		path = "."
+	}
+	return name, path, nil
+}
+
+// locationDir returns the worktree of a worker, or the project root for
+// "root".
+func locationDir(config *Config, name string) (string, error) {
+	if name == rootLocation {
+		if config.ProjectPath != "" {
+			return config.ProjectPath, nil
+		}
+		return os.Getwd()
+	}
+	index := findWorkerIndex(config, name)
+	if index == -1 {
+		return "", fmt.Errorf("worker '%s' not found", name)
+	}
+	dir, err := filepath.Abs(config.Workers[index].WorktreePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("worktree of worker '%s' not found: %v", name, err)
+	}
+	return dir, nil
+}
+
+// withinDir reports whether path is dir or below it.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// planFileCopies lists the files copied from the glob srcPath in srcRoot to
+// dstPath in dstRoot. Directories are copied with their contents, except
+// for .git.
+func planFileCopies(srcRoot, srcPath, dstRoot, dstPath string) ([]fileCopy, error) {
+	matches, err := filepath.Glob(filepath.Join(srcRoot, srcPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %v", srcPath, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match '%s'", srcPath)
+	}
+	dst := filepath.Join(dstRoot, dstPath)
+	if !withinDir(dstRoot, dst) {
+		return nil, fmt.Errorf("'%s' is outside the destination worktree", dstPath)
+	}
+
+	into := len(matches) > 1 || strings.HasSuffix(dstPath, "/") || dst == dstRoot
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		into = true
+	}
+
+	var copies []fileCopy
+	add := func(src, target string) {
+		rel, _ := filepath.Rel(dstRoot, target)
+		copies = append(copies, fileCopy{Src: src, Dst: target, Rel: rel})
+	}
+	for _, match := range matches {
+		if !withinDir(srcRoot, match) {
+			return nil, fmt.Errorf("'%s' is outside the source worktree", srcPath)
+		}
+		target := dst
+		if into {
+			target = filepath.Join(dst, filepath.Base(match))
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(match, target)
+			continue
+		}
+		err = filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.Name() == ".git" {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil // The .git file of a nested worktree
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			rel, _ := filepath.Rel(match, path)
+			add(path, filepath.Join(target, rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range copies {
+		if c.Src == c.Dst {
+			return nil, fmt.Errorf("'%s' would be copied onto itself", c.Rel)
+		}
+	}
+	return copies, nil
+}
+
+// uncommittedPaths returns the paths in dir, relative to it, that git
+// reports as modified, untracked or ignored. Directories end in '/'.
+func uncommittedPaths(dir string, paths []string) ([]string, error) {
+	args := append([]string{"-C", dir, "status", "--porcelain", "-z", "--ignored", "--"}, paths...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git worktree: %v", dir, err)
+	}
+	var dirty []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		dirty = append(dirty, filepath.FromSlash(entry[3:]))
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++ // Followed by the original path
+		}
+	}
+	return dirty, nil
+}
+
+// isUncommitted reports whether rel is one of the dirty paths or lies in a
+// dirty directory.
+func isUncommitted(rel string, dirty []string) bool {
+	for _, path := range dirty {
+		if rel == path || (strings.HasSuffix(path, string(filepath.Separator)) && strings.HasPrefix(rel, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameContent reports whether the files at a and b hold the same bytes.
+func sameContent(a, b string) bool {
+	left, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	right, err := os.ReadFile(b)
+	return err == nil && bytes.Equal(left, right)
+}
+
+// copyFile writes src to dst with src's permissions, creating directories.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
+
+func copyFiles(from, to string, opts FileCopyOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	srcName, srcPath, err := parseCopyLocation(from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	dstName, dstPath, err := parseCopyLocation(to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	srcRoot, err := locationDir(config, srcName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	dstRoot, err := locationDir(config, dstName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	copies, err := planFileCopies(srcRoot, srcPath, dstRoot, dstPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	// Only files that exist and differ can lose work
+	var pending []fileCopy
+	var overwritten []string
+	unchanged := 0
+	for _, c := range copies {
+		if _, err := os.Stat(c.Dst); err != nil {
+			pending = append(pending, c)
+			continue
+		}
+		if sameContent(c.Src, c.Dst) {
+			unchanged++
+			continue
+		}
+		pending = append(pending, c)
+		overwritten = append(overwritten, c.Rel)
+	}
+	if len(overwritten) > 0 && !opts.Force {
+		dirty, err := uncommittedPaths(dstRoot, overwritten)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v; use --force to overwrite anyway\n", err)
+			return false
+		}
+		var refused []string
+		for _, rel := range overwritten {
+			if isUncommitted(rel, dirty) {
+				refused = append(refused, rel)
+			}
+		}
+		if len(refused) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: These files in %s differ and have uncommitted changes:\n", dstName)
+			for _, rel := range refused {
+				fmt.Fprintf(os.Stderr, "  %s\n", rel)
+			}
+			fmt.Fprintln(os.Stderr, "Commit them first, or use --force to overwrite them")
+			return false
+		}
+	}
+
+	for _, c := range pending {
+		if opts.DryRun {
+			fmt.Printf("Would copy %s:%s\n", dstName, filepath.ToSlash(c.Rel))
+			continue
+		}
+		if err := copyFile(c.Src, c.Dst); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to %s:%s: %v\n", dstName, filepath.ToSlash(c.Rel), err)
+			return false
+		}
+	}
+	if opts.DryRun {
+		return true
+	}
+	summary := fmt.Sprintf("✅ Copied %d files from %s to %s", len(pending), srcName, dstName)
+	if unchanged > 0 {
+		summary += fmt.Sprintf(" (%d unchanged)", unchanged)
+	}
+	fmt.Println(summary)
+	return true
+}
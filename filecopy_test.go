@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseCopyLocation(t *testing.T) {
+	tests := []struct {
+		arg, name, path string
+		ok              bool
+	}{
+		{"api:openapi.json", "api", "openapi.json", true},
+		{"root:shared/", "root", "shared/", true},
+		{"web:", "web", ".", true},
+		{"openapi.json", "", "", false},
+		{":file", "", "", false},
+		{"api:/etc/passwd", "", "", false},
+	}
+	for _, tt := range tests {
+		name, path, err := parseCopyLocation(tt.arg)
+		if (err == nil) != tt.ok || name != tt.name || path != tt.path {
+			t.Errorf("parseCopyLocation(%q) = %q, %q, %v", tt.arg, name, path, err)
+		}
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPlanFileCopies(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"openapi.json":    "{}",
+		"gen/a.ts":        "a",
+		"gen/b.ts":        "b",
+		"gen/c.go":        "c",
+		"gen/.git":        "gitdir: x",
+		"gen/sub/d.ts":    "d",
+		"docs/readme.txt": "r",
+	})
+	os.MkdirAll(filepath.Join(dst, "existing"), 0755)
+
+	rels := func(copies []fileCopy) []string {
+		var out []string
+		for _, c := range copies {
+			out = append(out, filepath.ToSlash(c.Rel))
+		}
+		sort.Strings(out)
+		return out
+	}
+	tests := []struct {
+		src, dst string
+		want     []string
+	}{
+		{"openapi.json", "api/spec.json", []string{"api/spec.json"}},
+		{"openapi.json", "api/", []string{"api/openapi.json"}},
+		{"openapi.json", "existing", []string{"existing/openapi.json"}},
+		{"openapi.json", ".", []string{"openapi.json"}},
+		{"gen/*.ts", "shared", []string{"shared/a.ts", "shared/b.ts"}},
+		{"gen", "out", []string{"out/a.ts", "out/b.ts", "out/c.go", "out/sub/d.ts"}},
+		{"gen", "existing", []string{"existing/gen/a.ts", "existing/gen/b.ts", "existing/gen/c.go", "existing/gen/sub/d.ts"}},
+	}
+	for _, tt := range tests {
+		copies, err := planFileCopies(src, tt.src, dst, tt.dst)
+		if err != nil {
+			t.Errorf("planFileCopies(%q, %q): %v", tt.src, tt.dst, err)
+			continue
+		}
+		if got := rels(copies); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("planFileCopies(%q, %q) = %v, expected %v", tt.src, tt.dst, got, tt.want)
+		}
+	}
+
+	for _, bad := range [][2]string{{"missing/*", "x"}, {"../*", "x"}, {"openapi.json", "../x"}} {
+		if _, err := planFileCopies(src, bad[0], dst, bad[1]); err == nil {
+			t.Errorf("Expected planFileCopies(%q, %q) to fail", bad[0], bad[1])
+		}
+	}
+	if _, err := planFileCopies(src, "openapi.json", src, "openapi.json"); err == nil {
+		t.Error("Expected copying a file onto itself to fail")
+	}
+}
+
+func TestCopyFilesRefusesUncommitted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	project := t.TempDir()
+	t.Chdir(project)
+	api, web := filepath.Join(project, "api"), filepath.Join(project, "web")
+	for _, dir := range []string{api, web} {
+		os.MkdirAll(dir, 0755)
+		if output, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+			t.Fatalf("git init: %v\n%s", err, output)
+		}
+	}
+	writeFiles(t, web, map[string]string{"committed.json": "old", "dirty.json": "old", ".gitignore": "local.env\n"})
+	exec.Command("git", "-C", web, "add", ".").Run()
+	commit := exec.Command("git", "-C", web, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "init")
+	if output, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, output)
+	}
+	writeFiles(t, web, map[string]string{"dirty.json": "edited", "local.env": "SECRET=1"})
+	writeFiles(t, api, map[string]string{"committed.json": "new", "dirty.json": "new", "local.env": "SECRET=2", "same.json": "x"})
+	writeFiles(t, web, map[string]string{"same.json": "x"})
+
+	if err := saveConfig(&Config{ProjectPath: project, Workers: []Worker{
+		{ID: "api", WorktreePath: api},
+		{ID: "web", WorktreePath: web},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(web, name))
+		return string(data)
+	}
+
+	// A committed file can be overwritten: git still has it
+	if !copyFiles("api:committed.json", "web:", FileCopyOptions{}) || read("committed.json") != "new" {
+		t.Errorf("Expected the committed file to be overwritten, got %q", read("committed.json"))
+	}
+	for _, name := range []string{"dirty.json", "local.env"} {
+		if copyFiles("api:"+name, "web:", FileCopyOptions{}) || read(name) == "new" || read(name) == "SECRET=2" {
+			t.Errorf("Expected %s with uncommitted changes to be kept", name)
+		}
+	}
+	if !copyFiles("api:*.json", "web:", FileCopyOptions{DryRun: true, Force: true}) || read("dirty.json") != "edited" {
+		t.Error("Expected --dry-run to leave the files alone")
+	}
+	if !copyFiles("api:dirty.json", "web:", FileCopyOptions{Force: true}) || read("dirty.json") != "new" {
+		t.Errorf("Expected --force to overwrite, got %q", read("dirty.json"))
+	}
+
+	// The project root is addressed as root:
+	if !copyFiles("api:same.json", "root:shared/", FileCopyOptions{}) {
+		t.Fatal("Expected the copy to the project root to succeed")
+	}
+	if _, err := os.Stat(filepath.Join(project, "shared", "same.json")); err != nil {
+		t.Errorf("Expected shared/same.json in the project root: %v", err)
+	}
+	if copyFiles("missing:x", "web:", FileCopyOptions{}) {
+		t.Error("Expected an unknown worker to fail")
+	}
+}
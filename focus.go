@@ -0,0 +1,52 @@
+package main
+
+import "fmt"
+
+// Values of the focus_on_add config and `gtw add --focus`.
+const (
+	FocusAlways      = "always"      // Select the new worker's pane
+	FocusNever       = "never"       // Stay on the current pane
+	FocusInteractive = "interactive" // Select the new pane unless gtw runs non-interactively
+)
+
+// validateFocusMode checks a --focus value.
+func validateFocusMode(mode string) error {
+	switch mode {
+	case "", FocusAlways, FocusNever, FocusInteractive:
+		return nil
+	}
+	return fmt.Errorf("invalid focus mode %q (expected always, never or interactive)", mode)
+}
+
+// resolveFocusMode picks the focus behavior for `gtw add` from the flags or
+// the focus_on_add config value. Run from a pane of the session itself
+// (nested), the default is to stay on that pane rather than jump away from
+// whoever typed the command.
+func resolveFocusMode(config *Config, opts AddOptions, nested bool) string {
+	if opts.Focus != "" {
+		return opts.Focus
+	}
+	switch config.FocusOnAdd {
+	case "":
+		if nested {
+			return FocusNever
+		}
+		return FocusAlways
+	case FocusAlways, FocusNever, FocusInteractive:
+		return config.FocusOnAdd
+	}
+	fmt.Printf("Warning: Invalid focus_on_add %q in config, using %s\n", config.FocusOnAdd, FocusAlways)
+	return FocusAlways
+}
+
+// shouldFocus reports whether the new pane is selected in the given mode;
+// interactive is whether gtw was run from a terminal rather than a script.
+func shouldFocus(mode string, interactive bool) bool {
+	switch mode {
+	case FocusNever:
+		return false
+	case FocusInteractive:
+		return interactive
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestResolveFocusMode(t *testing.T) {
+	tests := []struct {
+		config string
+		flag   string
+		nested bool
+		want   string
+	}{
+		{"", "", false, FocusAlways},
+		{FocusNever, "", false, FocusNever},
+		{FocusInteractive, "", false, FocusInteractive},
+		{FocusNever, FocusAlways, false, FocusAlways},
+		{"sometimes", "", false, FocusAlways},
+		{"", "", true, FocusNever},
+		{FocusAlways, "", true, FocusAlways},
+		{"", FocusAlways, true, FocusAlways},
+	}
+	for _, tt := range tests {
+		got := resolveFocusMode(&Config{FocusOnAdd: tt.config}, AddOptions{Focus: tt.flag}, tt.nested)
+		if got != tt.want {
+			t.Errorf("resolveFocusMode(%q, %q, %v) = %q, want %q", tt.config, tt.flag, tt.nested, got, tt.want)
+		}
+	}
+	if err := validateFocusMode("sometimes"); err == nil {
+		t.Error("Expected an error for an unknown --focus value")
+	}
+}
+
+func TestShouldFocus(t *testing.T) {
+	tests := []struct {
+		mode        string
+		interactive bool
+		want        bool
+	}{
+		{FocusAlways, false, true},
+		{FocusNever, true, false},
+		{FocusInteractive, true, true},
+		{FocusInteractive, false, false},
+	}
+	for _, tt := range tests {
+		if got := shouldFocus(tt.mode, tt.interactive); got != tt.want {
+			t.Errorf("shouldFocus(%q, %v) = %v, want %v", tt.mode, tt.interactive, got, tt.want)
+		}
+	}
+}
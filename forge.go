@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const forgeRequestTimeout = 30 * time.Second
+
+// ForgeConfig selects the code hosting service used for issues and pull
+// requests. Without it the forge is detected from the origin remote.
+type ForgeConfig struct {
+	Type     string `json:"type,omitempty"`      // github, gitlab or gitea
+	URL      string `json:"url,omitempty"`       // Web URL of a self-hosted instance (e.g. "https://gitlab.example.com")
+	TokenEnv string `json:"token_env,omitempty"` // Environment variable holding the API token
+}
+
+// Issue is an issue fetched from a forge.
+type Issue struct {
+	Number int
+	Title  string
+	URL    string
+}
+
+// PullRequest is a pull request (merge request on GitLab) to open.
+type PullRequest struct {
+	Head  string // Branch with the changes
+	Base  string // Branch to merge into
+	Title string
+	Body  string
+	Draft bool
+}
+
+// Forge is a code hosting service that gtw can fetch issues from and open
+// pull requests on.
+type Forge interface {
+	Name() string
+	Issue(number int) (*Issue, error)
+	CreatePullRequest(pr PullRequest) (string, error) // Returns the web URL
+}
+
+// remoteRepo is a repository location parsed from a git remote URL.
+type remoteRepo struct {
+	Host string
+	Path string // owner/repo, or group/subgroup/repo on GitLab
+}
+
+var scpRemotePattern = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// parseRemoteURL parses the https, ssh and scp-like (git@host:owner/repo)
+// remote URL forms.
+func parseRemoteURL(remote string) (remoteRepo, error) {
+	var repo remoteRepo
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return repo, err
+		}
+		repo = remoteRepo{Host: u.Hostname(), Path: u.Path}
+	} else if m := scpRemotePattern.FindStringSubmatch(remote); m != nil {
+		repo = remoteRepo{Host: m[1], Path: m[2]}
+	}
+	repo.Path = strings.TrimSuffix(strings.Trim(repo.Path, "/"), ".git")
+	if repo.Host == "" || !strings.Contains(repo.Path, "/") {
+		return remoteRepo{}, fmt.Errorf("cannot parse remote URL '%s'", remote)
+	}
+	return repo, nil
+}
+
+// forgeType returns the configured forge type or guesses it from the host.
+func forgeType(cfg *ForgeConfig, host string) (string, error) {
+	if cfg != nil && cfg.Type != "" {
+		switch cfg.Type {
+		case "github", "gitlab", "gitea":
+			return cfg.Type, nil
+		}
+		return "", fmt.Errorf("unknown forge type '%s' (use github, gitlab or gitea)", cfg.Type)
+	}
+	switch {
+	case host == "github.com" || strings.Contains(host, "github"):
+		return "github", nil
+	case strings.Contains(host, "gitlab"):
+		return "gitlab", nil
+	case strings.Contains(host, "gitea") || host == "codeberg.org":
+		return "gitea", nil
+	}
+	return "", fmt.Errorf("cannot tell which forge %s is; set forge.type in %s", host, configFile)
+}
+
+// newForge returns the forge driver for the repository at remote.
+func newForge(cfg *ForgeConfig, remote string) (Forge, error) {
+	repo, err := parseRemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := forgeType(cfg, repo.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	webURL := "https://" + repo.Host
+	tokenEnv := ""
+	if cfg != nil {
+		if cfg.URL != "" {
+			webURL = strings.TrimSuffix(cfg.URL, "/")
+		}
+		tokenEnv = cfg.TokenEnv
+	}
+	client := forgeClient{http: &http.Client{Timeout: forgeRequestTimeout}}
+
+	switch kind {
+	case "github":
+		client.token = forgeToken(tokenEnv, "GITHUB_TOKEN", "GH_TOKEN")
+		client.authHeader, client.authPrefix = "Authorization", "Bearer "
+		client.baseURL = "https://api.github.com"
+		if repo.Host != "github.com" {
+			client.baseURL = webURL + "/api/v3" // GitHub Enterprise Server
+		}
+		return &githubForge{client: client, repo: repo.Path}, nil
+	case "gitlab":
+		client.token = forgeToken(tokenEnv, "GITLAB_TOKEN")
+		client.authHeader = "PRIVATE-TOKEN"
+		client.baseURL = webURL + "/api/v4"
+		return &gitlabForge{client: client, project: strings.ReplaceAll(url.PathEscape(repo.Path), "/", "%2F")}, nil
+	default:
+		client.token = forgeToken(tokenEnv, "GITEA_TOKEN")
+		client.authHeader, client.authPrefix = "Authorization", "token "
+		client.baseURL = webURL + "/api/v1"
+		return &giteaForge{client: client, repo: repo.Path}, nil
+	}
+}
+
+// forgeToken returns the first non-empty environment variable of names,
+// preferring the configured one.
+func forgeToken(configured string, names ...string) string {
+	if configured != "" {
+		names = append([]string{configured}, names...)
+	}
+	for _, name := range names {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// forgeClient makes authenticated JSON requests to a forge's REST API.
+type forgeClient struct {
+	http       *http.Client
+	baseURL    string
+	token      string
+	authHeader string
+	authPrefix string
+}
+
+func (c forgeClient) do(method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(c.authHeader, c.authPrefix+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
+
+type githubForge struct {
+	client forgeClient
+	repo   string
+}
+
+func (f *githubForge) Name() string { return "GitHub" }
+
+func (f *githubForge) Issue(number int) (*Issue, error) {
+	var resp struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.client.do("GET", fmt.Sprintf("/repos/%s/issues/%d", f.repo, number), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &Issue{Number: number, Title: resp.Title, URL: resp.HTMLURL}, nil
+}
+
+func (f *githubForge) CreatePullRequest(pr PullRequest) (string, error) {
+	body := map[string]interface{}{"head": pr.Head, "base": pr.Base, "title": pr.Title, "body": pr.Body, "draft": pr.Draft}
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.client.do("POST", fmt.Sprintf("/repos/%s/pulls", f.repo), body, &resp); err != nil {
+		return "", err
+	}
+	return resp.HTMLURL, nil
+}
+
+type gitlabForge struct {
+	client  forgeClient
+	project string // URL-escaped project path
+}
+
+func (f *gitlabForge) Name() string { return "GitLab" }
+
+func (f *gitlabForge) Issue(number int) (*Issue, error) {
+	var resp struct {
+		Title  string `json:"title"`
+		WebURL string `json:"web_url"`
+	}
+	if err := f.client.do("GET", fmt.Sprintf("/projects/%s/issues/%d", f.project, number), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &Issue{Number: number, Title: resp.Title, URL: resp.WebURL}, nil
+}
+
+func (f *gitlabForge) CreatePullRequest(pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = "Draft: " + title
+	}
+	body := map[string]interface{}{"source_branch": pr.Head, "target_branch": pr.Base, "title": title, "description": pr.Body}
+	var resp struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := f.client.do("POST", fmt.Sprintf("/projects/%s/merge_requests", f.project), body, &resp); err != nil {
+		return "", err
+	}
+	return resp.WebURL, nil
+}
+
+type giteaForge struct {
+	client forgeClient
+	repo   string
+}
+
+func (f *giteaForge) Name() string { return "Gitea" }
+
+func (f *giteaForge) Issue(number int) (*Issue, error) {
+	var resp struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.client.do("GET", fmt.Sprintf("/repos/%s/issues/%d", f.repo, number), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &Issue{Number: number, Title: resp.Title, URL: resp.HTMLURL}, nil
+}
+
+func (f *giteaForge) CreatePullRequest(pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = "WIP: " + title
+	}
+	body := map[string]interface{}{"head": pr.Head, "base": pr.Base, "title": title, "body": pr.Body}
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.client.do("POST", fmt.Sprintf("/repos/%s/pulls", f.repo), body, &resp); err != nil {
+		return "", err
+	}
+	return resp.HTMLURL, nil
+}
+
+// projectForge returns the forge of the project's origin remote.
+func projectForge(config *Config) (Forge, error) {
+	output, err := gitCommand("remote", "get-url", "origin").Output()
+	if err != nil {
+		return nil, fmt.Errorf("the repository has no 'origin' remote")
+	}
+	return newForge(config.Forge, strings.TrimSpace(string(output)))
+}
+
+// PROptions holds the settings given to `gtw pr`.
+type PROptions struct {
+	Title string
+	Body  string
+	Base  string
+	Draft bool
+}
+
+func init() {
+	var opts PROptions
+	prCmd := &cobra.Command{
+		Use:   "pr <worker-id>",
+		Short: "Push a worker's branch and open a pull request (merge request on GitLab)",
+		Long: `Push the worker's branch to origin and open a pull request on GitHub, a
+merge request on GitLab or a pull request on Gitea. The forge is detected
+from the origin remote URL or set with "forge" in the config; the API token
+is read from GITHUB_TOKEN (or GH_TOKEN), GITLAB_TOKEN or GITEA_TOKEN. The
+URL is saved as the worker's pr_url key (see 'gtw kv').`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { createWorkerPR(args[0], opts) },
+	}
+	prCmd.Flags().StringVar(&opts.Title, "title", "", "Title (default: the worker's note or last commit subject)")
+	prCmd.Flags().StringVar(&opts.Body, "body", "", "Description")
+	prCmd.Flags().StringVar(&opts.Base, "base", "", "Branch to merge into (default: the worker's base or origin's default branch)")
+	prCmd.Flags().BoolVar(&opts.Draft, "draft", false, "Open as a draft")
+	rootCmd.AddCommand(prCmd)
+}
+
+// defaultRemoteBranch returns origin's default branch.
+func defaultRemoteBranch() string {
+	output, err := gitCommand("symbolic-ref", "--short", "refs/remotes/origin/HEAD").Output()
+	if err != nil {
+		return "main"
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+}
+
+func createWorkerPR(id string, opts PROptions) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := config.Workers[index]
+	branch := getWorktreeBranch(worker.WorktreePath, worker.branchName())
+
+	forge, err := projectForge(config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	pr := PullRequest{Head: branch, Base: opts.Base, Title: opts.Title, Body: opts.Body, Draft: opts.Draft}
+	if pr.Base == "" {
+		pr.Base = worker.Base
+		if pr.Base == "" {
+			pr.Base = defaultRemoteBranch()
+		}
+	}
+	if pr.Title == "" {
+		pr.Title = worker.Note
+	}
+	if pr.Title == "" {
+		output, _ := gitCommand("-C", worker.WorktreePath, "log", "-1", "--format=%s").Output()
+		pr.Title = strings.TrimSpace(string(output))
+	}
+	if pr.Title == "" {
+		pr.Title = branch
+	}
+
+	fmt.Printf("Pushing branch '%s' to origin...\n", branch)
+	if output, err := gitCommand("-C", worker.WorktreePath, "push", "-u", "origin", branch).CombinedOutput(); err != nil {
+		fmt.Printf("❌ Error pushing branch: %v\n", err)
+		fmt.Printf("Git output: %s\n", string(output))
+		return
+	}
+
+	prURL, err := forge.CreatePullRequest(pr)
+	if err != nil {
+		fmt.Printf("❌ Error creating pull request on %s: %v\n", forge.Name(), err)
+		return
+	}
+	if err := setWorkerMetadata(config, id, map[string]string{"pr_url": prURL}); err != nil {
+		fmt.Printf("Warning: Could not save pr_url: %v\n", err)
+	}
+	fmt.Printf("✅ Opened %s\n", prURL)
+}
+
+// applyIssue fetches the issue given with --issue and fills in the worker
+// ID (when not given), note and issue_url metadata.
+func applyIssue(id string, opts *AddOptions) (string, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("loading config: %v", err)
+	}
+	forge, err := projectForge(config)
+	if err != nil {
+		return "", err
+	}
+	issue, err := forge.Issue(opts.Issue)
+	if err != nil {
+		return "", fmt.Errorf("fetching issue #%d from %s: %v", opts.Issue, forge.Name(), err)
+	}
+	fmt.Printf("Issue #%d: %s\n", issue.Number, issue.Title)
+
+	if id == "" {
+		id = fmt.Sprintf("issue-%d", issue.Number)
+	}
+	if opts.Note == "" {
+		opts.Note = issue.Title
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]string)
+	}
+	opts.Metadata["issue_url"] = issue.URL
+	return id, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := map[string]remoteRepo{
+		"git@github.com:owner/repo.git":                 {Host: "github.com", Path: "owner/repo"},
+		"https://github.com/owner/repo":                 {Host: "github.com", Path: "owner/repo"},
+		"ssh://git@gitlab.example.com:2222/g/sub/r.git": {Host: "gitlab.example.com", Path: "g/sub/r"},
+		"https://user@gitea.example.com/o/r.git/":       {Host: "gitea.example.com", Path: "o/r"},
+	}
+	for remote, want := range tests {
+		got, err := parseRemoteURL(remote)
+		if err != nil || got != want {
+			t.Errorf("parseRemoteURL(%q) = %+v, %v; want %+v", remote, got, err, want)
+		}
+	}
+	for _, remote := range []string{"/local/path/repo", "github.com"} {
+		if _, err := parseRemoteURL(remote); err == nil {
+			t.Errorf("parseRemoteURL(%q): expected an error", remote)
+		}
+	}
+}
+
+func TestForgeType(t *testing.T) {
+	tests := map[string]string{
+		"github.com":         "github",
+		"gitlab.example.com": "gitlab",
+		"codeberg.org":       "gitea",
+	}
+	for host, want := range tests {
+		if got, err := forgeType(nil, host); err != nil || got != want {
+			t.Errorf("forgeType(%q) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+	if _, err := forgeType(nil, "git.example.com"); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+	if got, _ := forgeType(&ForgeConfig{Type: "gitlab"}, "git.example.com"); got != "gitlab" {
+		t.Errorf("Expected the configured type, got %q", got)
+	}
+	if _, err := forgeType(&ForgeConfig{Type: "svn"}, "git.example.com"); err == nil {
+		t.Error("Expected an error for an unknown configured type")
+	}
+}
+
+// fakeForge serves one issue and records the last pull request body.
+func fakeForge(t *testing.T, issuePath, prPath, authHeader string, issue map[string]string, created *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(authHeader) == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.URL.EscapedPath() == issuePath:
+			json.NewEncoder(w).Encode(issue)
+		case r.Method == "POST" && r.URL.EscapedPath() == prPath:
+			json.NewDecoder(r.Body).Decode(created)
+			json.NewEncoder(w).Encode(map[string]string{"html_url": "https://pr", "web_url": "https://pr"})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestForgeDrivers(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "t")
+	t.Setenv("GITLAB_TOKEN", "t")
+	t.Setenv("GITEA_TOKEN", "t")
+
+	tests := []struct {
+		kind, remote, issuePath, prPath, auth, titleKey, headKey string
+		issue                                                    map[string]string
+	}{
+		{"github", "git@ghe.example.com:o/r.git", "/api/v3/repos/o/r/issues/7", "/api/v3/repos/o/r/pulls", "Authorization", "title", "head",
+			map[string]string{"title": "Fix login", "html_url": "https://issue"}},
+		{"gitlab", "git@gitlab.example.com:g/sub/r.git", "/api/v4/projects/g%2Fsub%2Fr/issues/7", "/api/v4/projects/g%2Fsub%2Fr/merge_requests", "PRIVATE-TOKEN", "title", "source_branch",
+			map[string]string{"title": "Fix login", "web_url": "https://issue"}},
+		{"gitea", "https://gitea.example.com/o/r.git", "/api/v1/repos/o/r/issues/7", "/api/v1/repos/o/r/pulls", "Authorization", "title", "head",
+			map[string]string{"title": "Fix login", "html_url": "https://issue"}},
+	}
+	for _, tt := range tests {
+		var created map[string]interface{}
+		server := fakeForge(t, tt.issuePath, tt.prPath, tt.auth, tt.issue, &created)
+
+		forge, err := newForge(&ForgeConfig{Type: tt.kind, URL: server.URL}, tt.remote)
+		if err != nil {
+			t.Fatalf("%s: newForge() error: %v", tt.kind, err)
+		}
+		issue, err := forge.Issue(7)
+		if err != nil || issue.Title != "Fix login" || issue.URL != "https://issue" {
+			t.Errorf("%s: Issue() = %+v, %v", tt.kind, issue, err)
+		}
+		prURL, err := forge.CreatePullRequest(PullRequest{Head: "feature", Base: "main", Title: "Fix"})
+		if err != nil || prURL != "https://pr" {
+			t.Errorf("%s: CreatePullRequest() = %q, %v", tt.kind, prURL, err)
+		}
+		if created[tt.titleKey] != "Fix" || created[tt.headKey] != "feature" {
+			t.Errorf("%s: Unexpected request body %v", tt.kind, created)
+		}
+		server.Close()
+	}
+}
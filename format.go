@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// WorkerView is the data given to --format templates: the stored worker
+// with its live status.
+type WorkerView struct {
+	Worker
+	Status string   `json:"status"`          // Live state (see liveState)
+	Active bool     `json:"active"`          // Whether the worker's pane is running
+	Git    *GitMeta `json:"git,omitempty"`   // Branch and changes, with `gtw list --git`
+	Ready  string   `json:"ready,omitempty"` // Readiness of workers whose profile has checks (see readinessLabel)
+}
+
+// newWorkerView checks the worker's pane with alive (see livePaneCheck).
+func newWorkerView(worker Worker, alive func(paneID string) bool) WorkerView {
+	view := WorkerView{Worker: worker, Status: string(worker.state())}
+	if worker.ArchivedAt != nil {
+		return view
+	}
+	view.Active = alive(worker.PaneID)
+	view.Status = string(liveState(worker, alive))
+	return view
+}
+
+var formatFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseFormat compiles a --format template. The escapes \t and \n are
+// expanded so formats can be written inside single quotes.
+func parseFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	return template.New("format").Funcs(formatFuncs).Parse(format)
+}
+
+// printFormatted renders every view with the template, one per line.
+func printFormatted(format string, views []WorkerView) {
+	tmpl, err := parseFormat(format)
+	if err != nil {
+		fmt.Printf("Error parsing format: %v\n", err)
+		return
+	}
+
+	for _, view := range views {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, view); err != nil {
+			fmt.Printf("Error executing format: %v\n", err)
+			return
+		}
+		fmt.Println(b.String())
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	view := WorkerView{
+		Worker: Worker{ID: "issue-1", PaneID: "%3", Status: "active", Tags: []string{"a", "b"}},
+		Status: "inactive",
+	}
+
+	tests := map[string]string{
+		`{{.ID}}\t{{.PaneID}}\t{{.Status}}`: "issue-1\t%3\tinactive",
+		`{{join .Tags ","}}`:                "a,b",
+		`{{.Worker.Status}}`:                "active",
+		`{{json .Tags}}`:                    `["a","b"]`,
+		`{{upper .ID}}`:                     "ISSUE-1",
+	}
+
+	for format, want := range tests {
+		tmpl, err := parseFormat(format)
+		if err != nil {
+			t.Errorf("parseFormat(%q) failed: %v", format, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, view); err != nil {
+			t.Errorf("Execute(%q) failed: %v", format, err)
+			continue
+		}
+		if b.String() != want {
+			t.Errorf("Format %q rendered %q, want %q", format, b.String(), want)
+		}
+	}
+
+	if _, err := parseFormat("{{.ID"); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// GCOptions holds the settings given to `gtw gc`.
+type GCOptions struct {
+	TTL    time.Duration
+	Yes    bool
+	DryRun bool
+}
+
+// gcCandidate is a worker past its TTL together with the reasons it must be
+// kept, if any.
+type gcCandidate struct {
+	Worker  Worker
+	Age     time.Duration
+	Reasons []string
+}
+
+func init() {
+	var opts GCOptions
+	gcCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove workers older than worker_ttl that have no unsaved work",
+		Long: `Remove workers older than the worker_ttl config value (or --ttl). Workers
+with uncommitted changes or commits that exist only on their branch are
+reported but never removed.`,
+		Run: func(cmd *cobra.Command, args []string) { collectGarbage(opts) },
+	}
+	gcCmd.Flags().DurationVar(&opts.TTL, "ttl", 0, "Override worker_ttl from the config (e.g. 72h)")
+	gcCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Remove expired workers without asking")
+	gcCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only report expired workers")
+	rootCmd.AddCommand(gcCmd)
+}
+
+// resolveWorkerTTL returns the TTL from the flag or the worker_ttl config
+// value. Zero means no TTL is configured.
+func resolveWorkerTTL(config *Config, flagValue time.Duration) (time.Duration, error) {
+	if flagValue > 0 {
+		return flagValue, nil
+	}
+	if config.WorkerTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(config.WorkerTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid worker_ttl %q: %v", config.WorkerTTL, err)
+	}
+	return ttl, nil
+}
+
+// expiredWorkers returns the workers created more than ttl before now.
+func expiredWorkers(workers []Worker, ttl time.Duration, now time.Time) []Worker {
+	var expired []Worker
+	for _, worker := range workers {
+		if now.Sub(worker.CreatedAt) > ttl {
+			expired = append(expired, worker)
+		}
+	}
+	return expired
+}
+
+// worktreeDirty reports whether the worktree has uncommitted changes,
+// including untracked files.
+func worktreeDirty(worktreePath string) (bool, error) {
+	output, err := gitCommand("-C", worktreePath, "status", "--porcelain").Output()
+	if err != nil {
+		return false, fmt.Errorf("git status failed: %v", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// unsavedWork lists the reasons a worktree cannot be removed without losing
+// work: uncommitted changes, or commits not reachable from a remote or any
+// other local branch.
+func unsavedWork(worktreePath string) ([]string, error) {
+	var reasons []string
+
+	dirty, err := worktreeDirty(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		reasons = append(reasons, "uncommitted changes")
+	}
+
+	branch := getWorktreeBranch(worktreePath, "")
+	args := []string{"-C", worktreePath, "rev-list", "--count", "HEAD", "--not", "--remotes"}
+	if branch != "" {
+		args = append(args, "--exclude="+branch)
+	}
+	args = append(args, "--branches")
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list failed: %v", err)
+	}
+	if count, _ := strconv.Atoi(strings.TrimSpace(string(output))); count > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d unpushed commit(s)", count))
+	}
+
+	return reasons, nil
+}
+
+// findGCCandidates returns the expired workers, annotated with the reasons
+// they must be kept.
+func findGCCandidates(config *Config, ttl time.Duration) []gcCandidate {
+	now := time.Now()
+	var candidates []gcCandidate
+	for _, worker := range expiredWorkers(config.Workers, ttl, now) {
+		reasons, err := unsavedWork(worker.WorktreePath)
+		if err != nil {
+			reasons = []string{err.Error()}
+		}
+		candidates = append(candidates, gcCandidate{Worker: worker, Age: now.Sub(worker.CreatedAt), Reasons: reasons})
+	}
+	return candidates
+}
+
+func collectGarbage(opts GCOptions) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	ttl, err := resolveWorkerTTL(config, opts.TTL)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if ttl == 0 {
+		fmt.Println("No worker_ttl configured. Set worker_ttl in .tmux-workers.json or pass --ttl.")
+		return
+	}
+
+	candidates := findGCCandidates(config, ttl)
+	if len(candidates) == 0 {
+		fmt.Printf("No workers older than %s\n", ttl)
+		return
+	}
+
+	var removable []string
+	for _, c := range candidates {
+		age := c.Age.Truncate(time.Minute)
+		if len(c.Reasons) > 0 {
+			fmt.Printf("⚠️  %s (age %s): kept, %s\n", c.Worker.ID, age, strings.Join(c.Reasons, ", "))
+			continue
+		}
+		fmt.Printf("🗑  %s (age %s): expired, no unsaved work\n", c.Worker.ID, age)
+		removable = append(removable, c.Worker.ID)
+	}
+
+	if len(removable) == 0 || opts.DryRun {
+		return
+	}
+	if !opts.Yes && !confirm(fmt.Sprintf("Remove %d expired worker(s)?", len(removable))) {
+		fmt.Println("Aborted")
+		return
+	}
+
+	for _, id := range removable {
+		removeWorker(id, RemoveOptions{Yes: true})
+	}
+}
+
+// reportExpiredWorkers is the daemon's gc pass. Expired workers are only
+// reported unless remove is set.
+func reportExpiredWorkers(remove bool) {
+	config, err := loadConfig()
+	if err != nil {
+		return
+	}
+	ttl, err := resolveWorkerTTL(config, 0)
+	if err != nil || ttl == 0 {
+		return
+	}
+
+	for _, c := range findGCCandidates(config, ttl) {
+		if len(c.Reasons) > 0 {
+			fmt.Printf("Worker '%s' is past worker_ttl but has %s\n", c.Worker.ID, strings.Join(c.Reasons, ", "))
+			continue
+		}
+		if !remove {
+			fmt.Printf("Worker '%s' is past worker_ttl; run 'gtw gc' to remove it\n", c.Worker.ID)
+			continue
+		}
+		fmt.Printf("Removing expired worker '%s'\n", c.Worker.ID)
+		removeWorker(c.Worker.ID, RemoveOptions{Yes: true})
+	}
+}
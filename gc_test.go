@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpiredWorkers(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	workers := []Worker{
+		{ID: "old", CreatedAt: now.Add(-100 * time.Hour)},
+		{ID: "new", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	expired := expiredWorkers(workers, 72*time.Hour, now)
+	if len(expired) != 1 || expired[0].ID != "old" {
+		t.Errorf("Unexpected expired workers: %+v", expired)
+	}
+}
+
+func TestResolveWorkerTTL(t *testing.T) {
+	if ttl, err := resolveWorkerTTL(&Config{WorkerTTL: "72h"}, 0); err != nil || ttl != 72*time.Hour {
+		t.Errorf("resolveWorkerTTL() = %v, %v", ttl, err)
+	}
+	if ttl, _ := resolveWorkerTTL(&Config{WorkerTTL: "72h"}, time.Hour); ttl != time.Hour {
+		t.Errorf("Expected flag to win, got %v", ttl)
+	}
+	if ttl, err := resolveWorkerTTL(&Config{}, 0); err != nil || ttl != 0 {
+		t.Errorf("Expected no TTL, got %v, %v", ttl, err)
+	}
+	if _, err := resolveWorkerTTL(&Config{WorkerTTL: "3 days"}, 0); err == nil {
+		t.Error("Expected error for invalid worker_ttl")
+	}
+}
+
+func TestUnsavedWork(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	run("checkout", "-q", "-b", "feature")
+
+	if reasons, err := unsavedWork(dir); err != nil || len(reasons) != 0 {
+		t.Errorf("Expected a clean branch, got %v, %v", reasons, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0644)
+	if reasons, _ := unsavedWork(dir); len(reasons) != 1 || reasons[0] != "uncommitted changes" {
+		t.Errorf("Expected uncommitted changes, got %v", reasons)
+	}
+
+	run("add", "file")
+	run("commit", "-q", "-m", "work")
+	if reasons, _ := unsavedWork(dir); len(reasons) != 1 || reasons[0] != "1 unpushed commit(s)" {
+		t.Errorf("Expected an unpushed commit, got %v", reasons)
+	}
+}
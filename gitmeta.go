@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	gitCacheFile       = ".gtw/git-cache.json"
+	defaultGitCacheTTL = 30 * time.Second
+)
+
+// GitMeta is the git state of a worker shown by `gtw list --git`.
+type GitMeta struct {
+	Worktree  string    `json:"worktree"` // Worktree the entry was read from
+	Branch    string    `json:"branch"`
+	Base      string    `json:"base,omitempty"`
+	Ahead     int       `json:"ahead"`
+	Behind    int       `json:"behind"`
+	Dirty     bool      `json:"dirty"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GitCacheMode selects how `gtw list --git` uses the cache.
+type GitCacheMode int
+
+const (
+	GitCacheTTL  GitCacheMode = iota // Use entries younger than git_cache_ttl
+	GitCacheFast                     // Use any cached entry, query only workers never cached
+	GitCacheOff                      // Always query git
+)
+
+func init() {
+	refreshCmd := &cobra.Command{
+		Use:   "refresh [worker-id...]",
+		Short: "Refresh the cached git metadata shown by 'gtw list --git'",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !refreshGitCache(args) {
+				os.Exit(1)
+			}
+		},
+	}
+	rootCmd.AddCommand(refreshCmd)
+}
+
+// gitCacheTTL returns git_cache_ttl from the config or the default.
+func gitCacheTTL(config *Config) time.Duration {
+	if config.GitCacheTTL != "" {
+		if ttl, err := time.ParseDuration(config.GitCacheTTL); err == nil {
+			return ttl
+		}
+		fmt.Printf("Warning: Invalid git_cache_ttl %q, using %s\n", config.GitCacheTTL, defaultGitCacheTTL)
+	}
+	return defaultGitCacheTTL
+}
+
+func loadGitCache() map[string]GitMeta {
+	cache := map[string]GitMeta{}
+	data, err := os.ReadFile(gitCacheFile)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return map[string]GitMeta{}
+	}
+	return cache
+}
+
+func saveGitCache(cache map[string]GitMeta) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(gitCacheFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(gitCacheFile, data, 0644)
+}
+
+// workerBase returns the ref a worker's branch is compared with: its --base,
+// or the project's current branch.
+func workerBase(worker Worker) string {
+	if worker.Base != "" {
+		return worker.Base
+	}
+	output, err := gitCommand("rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// readGitMeta queries git for the worker's branch, its commits ahead of and
+// behind base, and whether the worktree has uncommitted changes.
+func readGitMeta(worker Worker, base string) GitMeta {
+	meta := GitMeta{Worktree: worker.WorktreePath, Base: base, UpdatedAt: time.Now()}
+	if _, err := os.Stat(worker.WorktreePath); err != nil {
+		meta.Error = "worktree is missing"
+		return meta
+	}
+	meta.Branch = getWorktreeBranch(worker.WorktreePath, worker.branchName())
+
+	output, err := gitCommand("-C", worker.WorktreePath, "status", "--porcelain").Output()
+	if err != nil {
+		meta.Error = fmt.Sprintf("git status failed: %v", err)
+		return meta
+	}
+	meta.Dirty = strings.TrimSpace(string(output)) != ""
+
+	if base != "" {
+		output, err := gitCommand("-C", worker.WorktreePath, "rev-list", "--left-right", "--count", base+"...HEAD").Output()
+		if err == nil {
+			if fields := strings.Fields(string(output)); len(fields) == 2 {
+				meta.Behind, _ = strconv.Atoi(fields[0])
+				meta.Ahead, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+	return meta
+}
+
+// gitMetaFor queries git (in parallel) for the workers whose cache entry
+// mode does not accept and stores the results in cache. It reports whether
+// any worker was queried so that the caller can save the cache.
+func gitMetaFor(workers []Worker, cache map[string]GitMeta, mode GitCacheMode, ttl time.Duration) bool {
+	var stale []Worker
+	for _, worker := range workers {
+		entry, ok := cache[worker.ID]
+		fresh := ok && entry.Worktree == worker.WorktreePath
+		switch mode {
+		case GitCacheTTL:
+			fresh = fresh && time.Since(entry.UpdatedAt) < ttl
+		case GitCacheOff:
+			fresh = false
+		}
+		if !fresh {
+			stale = append(stale, worker)
+		}
+	}
+	if len(stale) == 0 {
+		return false
+	}
+
+	// The project's branch is the same for every worker without --base
+	projectBase := workerBase(Worker{})
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, worker := range stale {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			base := worker.Base
+			if base == "" {
+				base = projectBase
+			}
+			meta := readGitMeta(worker, base)
+			mu.Lock()
+			cache[worker.ID] = meta
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return true
+}
+
+// pruneGitCache drops entries of workers that no longer exist in any
+// workspace.
+func pruneGitCache(config *Config, cache map[string]GitMeta) {
+	for id := range cache {
+		if _, elsewhere := config.otherWorkspaceOf(id); findWorkerIndex(config, id) == -1 && !elsewhere {
+			delete(cache, id)
+		}
+	}
+}
+
+// formatAheadBehind shows the commits ahead of and behind the base, e.g.
+// "↑2 ↓1".
+func (m GitMeta) formatAheadBehind() string {
+	if m.Error != "" || m.Base == "" {
+		return "-"
+	}
+	return fmt.Sprintf("↑%d ↓%d", m.Ahead, m.Behind)
+}
+
+func (m GitMeta) formatDirty() string {
+	switch {
+	case m.Error != "":
+		return "-"
+	case m.Dirty:
+		return "dirty"
+	}
+	return "clean"
+}
+
+// listGitMeta returns the git metadata of the workers for `gtw list`,
+// saving the entries it had to query.
+func listGitMeta(config *Config, workers []Worker, opts ListOptions) map[string]GitMeta {
+	mode := GitCacheTTL
+	switch {
+	case opts.Fast:
+		mode = GitCacheFast
+	case opts.NoCache:
+		mode = GitCacheOff
+	}
+	cache := loadGitCache()
+	if gitMetaFor(workers, cache, mode, gitCacheTTL(config)) {
+		pruneGitCache(config, cache)
+		if err := saveGitCache(cache); err != nil {
+			fmt.Printf("Warning: Could not save git cache: %v\n", err)
+		}
+	}
+	return cache
+}
+
+// listWorkersGit prints the workers with their git metadata.
+func listWorkersGit(workers []Worker, alive func(paneID string) bool, gitMeta map[string]GitMeta) {
+	fmt.Println(colorize(fmt.Sprintf("%-20s %-15s %-30s %-12s %-8s %s", "ID", "STATUS", "BRANCH", "AHEAD/BEHIND", "CHANGES", "NOTE"), "header"))
+	fmt.Println(colorize(strings.Repeat("-", 105), "separator"))
+
+	for _, worker := range workers {
+		status := string(liveState(worker, alive))
+		meta := gitMeta[worker.ID]
+		branch := meta.Branch
+		if meta.Error != "" {
+			branch = "(" + meta.Error + ")"
+		}
+		fmt.Printf("%-20s %s %-30s %-12s %-8s %s\n",
+			worker.ID,
+			colorize(fmt.Sprintf("%-15s", status), status),
+			branch,
+			meta.formatAheadBehind(),
+			meta.formatDirty(),
+			worker.Note)
+	}
+}
+
+// refreshStaleGitCache keeps the cache warm from `gtw daemon` so that
+// `gtw list --git` rarely has to query git itself.
+func refreshStaleGitCache(config *Config) {
+	cache := loadGitCache()
+	if gitMetaFor(config.Workers, cache, GitCacheTTL, gitCacheTTL(config)/2) {
+		pruneGitCache(config, cache)
+		saveGitCache(cache)
+	}
+}
+
+// refreshGitCache re-reads the git metadata of the given workers (or all)
+// into the cache.
+func refreshGitCache(ids []string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	workers := config.Workers
+	if len(ids) > 0 {
+		workers = nil
+		for _, id := range ids {
+			index := findWorkerIndex(config, id)
+			if index == -1 {
+				fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+				return false
+			}
+			workers = append(workers, config.Workers[index])
+		}
+	}
+
+	cache := loadGitCache()
+	pruneGitCache(config, cache)
+	gitMetaFor(workers, cache, GitCacheOff, 0)
+	if err := saveGitCache(cache); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving git cache: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Refreshed git metadata of %d worker(s)\n", len(workers))
+	return true
+}
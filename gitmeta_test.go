@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGitMetaForCacheModes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+
+	workers := []Worker{{ID: "api", WorktreePath: "worktree/api"}}
+	cached := func(age time.Duration) map[string]GitMeta {
+		return map[string]GitMeta{"api": {Worktree: "worktree/api", Branch: "cached", UpdatedAt: time.Now().Add(-age)}}
+	}
+
+	cache := cached(time.Second)
+	if gitMetaFor(workers, cache, GitCacheTTL, time.Minute) || cache["api"].Branch != "cached" {
+		t.Error("Expected a fresh entry to be used")
+	}
+	cache = cached(time.Hour)
+	if gitMetaFor(workers, cache, GitCacheFast, time.Minute) || cache["api"].Branch != "cached" {
+		t.Error("Expected --fast to use an old entry")
+	}
+	cache = cached(time.Hour)
+	if !gitMetaFor(workers, cache, GitCacheTTL, time.Minute) || cache["api"].Error != "worktree is missing" {
+		t.Errorf("Expected an old entry to be queried, got %+v", cache["api"])
+	}
+	cache = cached(time.Second)
+	if !gitMetaFor(workers, cache, GitCacheOff, time.Minute) || cache["api"].Branch == "cached" {
+		t.Error("Expected --no-cache to query git")
+	}
+	cache = map[string]GitMeta{"api": {Worktree: "elsewhere", UpdatedAt: time.Now()}}
+	if !gitMetaFor(workers, cache, GitCacheFast, time.Minute) {
+		t.Error("Expected an entry of another worktree to be queried")
+	}
+}
+
+func TestReadGitMeta(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		if output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	run("checkout", "-q", "-b", "api")
+	run("commit", "-q", "--allow-empty", "-m", "one")
+	run("commit", "-q", "--allow-empty", "-m", "two")
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644)
+
+	meta := readGitMeta(Worker{ID: "api", WorktreePath: dir}, "main")
+	if meta.Error != "" || meta.Branch != "api" || meta.Ahead != 2 || meta.Behind != 0 || !meta.Dirty {
+		t.Errorf("Unexpected metadata %+v", meta)
+	}
+	if got := meta.formatAheadBehind(); got != "↑2 ↓0" {
+		t.Errorf("Unexpected ahead/behind %q", got)
+	}
+}
+
+func TestGitCacheRoundTrip(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if len(loadGitCache()) != 0 {
+		t.Error("Expected an empty cache without a file")
+	}
+	cache := map[string]GitMeta{"api": {Branch: "api", Ahead: 1}, "gone": {}}
+	pruneGitCache(&Config{Workers: []Worker{{ID: "api"}}}, cache)
+	if err := saveGitCache(cache); err != nil {
+		t.Fatal(err)
+	}
+	loaded := loadGitCache()
+	if len(loaded) != 1 || loaded["api"].Ahead != 1 {
+		t.Errorf("Unexpected cache %+v", loaded)
+	}
+}
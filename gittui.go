@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultGitTUI = "lazygit"
+
+// GitTUIOptions holds the settings given to `gtw git`.
+type GitTUIOptions struct {
+	Tool  string // Overrides the git_tui config value
+	Split bool   // Open a split pane instead of a popup
+	Size  string // Popup width and height, or split height
+}
+
+func init() {
+	var opts GitTUIOptions
+	gitCmd := &cobra.Command{
+		Use:   "git [worker-id]",
+		Short: "Open lazygit (or another git TUI) in a worker's worktree",
+		Long: `Open a git TUI in a worker's worktree to review and commit its changes.
+Inside tmux it opens in a popup over the current window (tmux 3.2 or later)
+and closes with the TUI; with --split, or when popups are not supported, it
+opens in a split pane instead. Outside tmux it runs in the terminal.
+
+Without a worker ID the worker whose pane or worktree gtw runs in is used.
+The TUI is taken from --tool or the "git_tui" config value, and defaults
+to lazygit.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) == 1 {
+				id = args[0]
+			}
+			if !openGitTUI(id, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	gitCmd.Flags().StringVar(&opts.Tool, "tool", "", "Git TUI command (e.g. lazygit, tig, gitui)")
+	gitCmd.Flags().BoolVar(&opts.Split, "split", false, "Open the TUI in a split pane instead of a popup")
+	gitCmd.Flags().StringVar(&opts.Size, "size", "90%", "Size of the popup (or height of the split pane)")
+	rootCmd.AddCommand(gitCmd)
+}
+
+// resolveGitTUI picks the git TUI command from the flag or config.
+func resolveGitTUI(flagValue, configured string) string {
+	for _, candidate := range []string{flagValue, configured} {
+		if strings.TrimSpace(candidate) != "" {
+			return strings.TrimSpace(candidate)
+		}
+	}
+	return defaultGitTUI
+}
+
+func openGitTUI(id string, opts GitTUIOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+
+	index := -1
+	if id != "" {
+		if index = findWorkerIndex(config, id); index == -1 {
+			fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+			return false
+		}
+	} else {
+		paneID := ""
+		if mux.Name() == "tmux" {
+			paneID = os.Getenv("TMUX_PANE")
+		}
+		if index = findCurrentWorker(config, paneID, os.Getenv(workerIDEnv), invocationDir); index == -1 {
+			fmt.Fprintln(os.Stderr, "Error: Not inside a worker; give the worker ID")
+			return false
+		}
+	}
+	worker := config.Workers[index]
+	worktree, err := filepath.Abs(worker.WorktreePath)
+	if err == nil {
+		_, err = os.Stat(worktree)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Worktree of worker '%s' not found: %v\n", worker.ID, err)
+		return false
+	}
+
+	tool := resolveGitTUI(opts.Tool, config.GitTUI)
+	if _, err := exec.LookPath(strings.Fields(tool)[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s not found; install it, or set git_tui or --tool to another git TUI\n", strings.Fields(tool)[0])
+		return false
+	}
+	if mux.Name() != "tmux" || !mux.Inside() {
+		if nonInteractive {
+			fmt.Fprintf(os.Stderr, "Error: %s needs a terminal\n", tool)
+			return false
+		}
+		cmd := newCommand("sh", "-c", tool)
+		cmd.Dir = worktree
+		cmd.Timeout = 0 // Interactive; runs until the TUI exits
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", tool, err)
+			return false
+		}
+		return true
+	}
+
+	if !opts.Split {
+		popup := tmuxCommand(peekArgs(tool, muxPath(worktree), false, opts.Size)...)
+		popup.Timeout = 0 // display-popup -E waits until the TUI exits
+		err := popup.Run()
+		if err == nil {
+			return true
+		}
+		fmt.Printf("Could not open a popup (tmux 3.2 or later is needed): %v; opening a split pane instead\n", err)
+	}
+	if err := tmuxCommand(peekArgs(tool, muxPath(worktree), true, opts.Size)...).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", tool, err)
+		return false
+	}
+	return true
+}
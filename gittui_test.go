@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestResolveGitTUI(t *testing.T) {
+	if got := resolveGitTUI("", ""); got != "lazygit" {
+		t.Errorf("Expected lazygit by default, got %q", got)
+	}
+	if got := resolveGitTUI("", "tig"); got != "tig" {
+		t.Errorf("Expected the configured git_tui, got %q", got)
+	}
+	if got := resolveGitTUI(" gitui ", "tig"); got != "gitui" {
+		t.Errorf("Expected --tool to win, got %q", got)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Restart policies of a worker's health check
+const (
+	RestartNever     = "never"      // Only mark the worker failed and notify (default)
+	RestartOnFailure = "on-failure" // Restart when the pane dies or the check fails
+	RestartAlways    = "always"     // Also restart when the init command exits
+)
+
+const (
+	defaultHealthInterval   = 30 * time.Second
+	defaultHealthMaxRetries = 3
+	healthCommandTimeout    = 30 * time.Second
+	healthGrace             = 30 * time.Second // Checks are not run this soon after a (re)start
+	healthResetAfter        = 10 * time.Minute // Healthy for this long since the last restart forgets the restarts
+)
+
+// HealthCheck tells `gtw daemon` how to notice that a worker died or went
+// bad, and what to do about it.
+type HealthCheck struct {
+	Command    string `json:"command,omitempty"`     // Run in the worktree; a non-zero exit is unhealthy
+	FailOutput string `json:"fail_output,omitempty"` // Regexp that is unhealthy when the pane prints it
+	Interval   string `json:"interval,omitempty"`    // How often command runs (default: "30s")
+	Restart    string `json:"restart,omitempty"`     // never (default), on-failure or always
+	MaxRetries int    `json:"max_retries,omitempty"` // Restarts before giving up (default: 3)
+}
+
+// HealthOptions holds the settings given to `gtw health set`.
+type HealthOptions struct {
+	HealthCheck
+	Clear bool
+}
+
+func init() {
+	healthCmd := &cobra.Command{
+		Use:   "health",
+		Short: "Configure health checks and restart policies of workers",
+		Long: `A worker's health check is evaluated by 'gtw daemon': a command run in the
+worktree (a non-zero exit is unhealthy) and/or a regexp that is unhealthy
+when new pane output matches it. The restart policy decides what happens:
+
+  never       mark the worker failed and notify (default)
+  on-failure  re-run the init command when the check fails or the pane dies
+  always      also re-run it when the init command exits
+
+After --max-retries restarts in a row the worker is marked failed and left
+alone; the count is forgotten once it stays healthy for a while.`,
+	}
+
+	var opts HealthOptions
+	setCmd := &cobra.Command{
+		Use:   "set <worker-id>",
+		Short: "Set the health check and restart policy of a worker",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !setWorkerHealth(args[0], opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	setCmd.Flags().StringVar(&opts.Command, "command", "", "Command run in the worktree; a non-zero exit is unhealthy")
+	setCmd.Flags().StringVar(&opts.FailOutput, "fail-output", "", "Regexp that is unhealthy when the pane prints it")
+	setCmd.Flags().StringVar(&opts.Interval, "interval", "", "How often the command runs (default: 30s)")
+	setCmd.Flags().StringVar(&opts.Restart, "restart", RestartNever, "Restart policy: never, on-failure or always")
+	setCmd.Flags().IntVar(&opts.MaxRetries, "max-retries", defaultHealthMaxRetries, "Restarts in a row before giving up")
+	setCmd.Flags().BoolVar(&opts.Clear, "clear", false, "Remove the health check")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "List the workers with a health check and their restarts",
+		Args:  cobra.NoArgs,
+		Run:   func(cmd *cobra.Command, args []string) { showWorkerHealth() },
+	}
+
+	healthCmd.AddCommand(setCmd, showCmd)
+	rootCmd.AddCommand(healthCmd)
+}
+
+// validate checks the policy, interval and pattern of the health check.
+func (h HealthCheck) validate() error {
+	switch h.Restart {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("unknown restart policy '%s' (use %s, %s or %s)", h.Restart, RestartNever, RestartOnFailure, RestartAlways)
+	}
+	if h.Interval != "" {
+		if interval, err := time.ParseDuration(h.Interval); err != nil || interval <= 0 {
+			return fmt.Errorf("invalid interval %q", h.Interval)
+		}
+	}
+	if _, err := regexp.Compile(h.FailOutput); err != nil {
+		return fmt.Errorf("invalid fail_output: %v", err)
+	}
+	if h.MaxRetries < 0 {
+		return fmt.Errorf("max_retries cannot be negative")
+	}
+	return nil
+}
+
+func (h HealthCheck) interval() time.Duration {
+	if interval, err := time.ParseDuration(h.Interval); err == nil && interval > 0 {
+		return interval
+	}
+	return defaultHealthInterval
+}
+
+func (h HealthCheck) maxRetries() int {
+	if h.MaxRetries > 0 {
+		return h.MaxRetries
+	}
+	return defaultHealthMaxRetries
+}
+
+func (h HealthCheck) restartPolicy() string {
+	if h.Restart == "" {
+		return RestartNever
+	}
+	return h.Restart
+}
+
+func setWorkerHealth(id string, opts HealthOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Worker '%s' not found\n", id)
+		return false
+	}
+	worker := &config.Workers[index]
+
+	if opts.Clear {
+		worker.Health = nil
+	} else {
+		if opts.Command == "" && opts.FailOutput == "" && opts.Restart == RestartNever {
+			fmt.Fprintln(os.Stderr, "Error: give --command, --fail-output or a --restart policy")
+			return false
+		}
+		if err := opts.HealthCheck.validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		check := opts.HealthCheck
+		worker.Health = &check
+	}
+	worker.Restarts = 0
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		return false
+	}
+	if opts.Clear {
+		fmt.Printf("✅ Removed the health check of worker '%s'\n", id)
+	} else {
+		fmt.Printf("✅ Set the health check of worker '%s' (restart: %s); 'gtw daemon' applies it\n", id, worker.Health.restartPolicy())
+	}
+	return true
+}
+
+func showWorkerHealth() {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	found := false
+	for _, worker := range config.Workers {
+		if worker.Health == nil {
+			continue
+		}
+		if !found {
+			fmt.Printf("%-20s %-11s %-9s %-30s %s\n", "ID", "RESTART", "RESTARTS", "COMMAND", "FAIL OUTPUT")
+			found = true
+		}
+		h := worker.Health
+		fmt.Printf("%-20s %-11s %-9s %-30s %s\n", worker.ID, h.restartPolicy(),
+			fmt.Sprintf("%d/%d", worker.Restarts, h.maxRetries()), h.Command, h.FailOutput)
+	}
+	if !found {
+		fmt.Println("No workers have a health check")
+	}
+}
+
+// runHealthCommand runs the check's command in the worker's worktree and
+// returns why it failed, or "".
+func runHealthCommand(worker Worker, command string) string {
+	cmd := newCommand("sh", "-c", command)
+	cmd.Dir = worker.WorktreePath
+	cmd.Env = append(os.Environ(), workerIDEnv+"="+worker.ID)
+	cmd.Timeout = healthCommandTimeout
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return ""
+	}
+	detail := strings.TrimSpace(lastLines(string(output), 1))
+	if detail == "" {
+		return fmt.Sprintf("health command failed: %v", err)
+	}
+	return fmt.Sprintf("health command failed: %v: %s", err, detail)
+}
+
+// HealthWatcher evaluates the workers' health checks from the daemon and
+// applies their restart policies.
+type HealthWatcher struct {
+	running map[string]bool      // The worker's pane ran a program at the last check
+	output  map[string]string    // Pane output at the last check
+	checked map[string]time.Time // Last run of the health command
+	restart func(config *Config, worker *Worker) error
+}
+
+func NewHealthWatcher() *HealthWatcher {
+	return &HealthWatcher{
+		running: make(map[string]bool),
+		output:  make(map[string]string),
+		checked: make(map[string]time.Time),
+		restart: restartWorker,
+	}
+}
+
+// Run checks every worker that has a health check and reports whether the
+// config changed.
+func (w *HealthWatcher) Run(config *Config) bool {
+	changed := false
+	paneAlive := livePaneCheck()
+	now := time.Now()
+	for i := range config.Workers {
+		worker := &config.Workers[i]
+		if worker.Health == nil {
+			continue
+		}
+		switch worker.state() {
+		case WorkerPaused, WorkerDetached, StateArchived, StateCreating, StateRemoving:
+			continue
+		}
+		if worker.state() == StateFailed && worker.Restarts >= worker.Health.maxRetries() {
+			continue // Given up on
+		}
+
+		reason := w.diagnose(worker, paneAlive(worker.PaneID), now)
+		if reason == "" {
+			if worker.Restarts > 0 && worker.RestartedAt != nil && now.Sub(*worker.RestartedAt) >= healthResetAfter {
+				worker.Restarts = 0
+				changed = true
+			}
+			continue
+		}
+		if w.handle(config, worker, reason) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// diagnose returns why the worker needs attention under its policy, or "".
+func (w *HealthWatcher) diagnose(worker *Worker, alive bool, now time.Time) string {
+	h := worker.Health
+	if !alive {
+		delete(w.running, worker.ID)
+		delete(w.output, worker.ID)
+		if h.restartPolicy() == RestartNever {
+			return "" // Reported by the state and activity watchers
+		}
+		return reasonPaneGone
+	}
+
+	command, err := paneCurrentCommand(worker.PaneID)
+	if err != nil {
+		return ""
+	}
+	running := command != "" && !isShellCommand(command)
+	exited := w.running[worker.ID] && !running
+	w.running[worker.ID] = running
+
+	started := worker.CreatedAt
+	if worker.RestartedAt != nil {
+		started = *worker.RestartedAt
+	}
+	settling := now.Sub(started) < healthGrace
+
+	if h.FailOutput != "" {
+		if output, err := capturePane(worker.PaneID, 200); err == nil {
+			previous, seen := w.output[worker.ID]
+			w.output[worker.ID] = output
+			pattern, err := regexp.Compile(h.FailOutput)
+			if seen && err == nil && pattern.MatchString(newOutput(previous, output)) {
+				return fmt.Sprintf("pane printed %q", h.FailOutput)
+			}
+		}
+	}
+	if exited && h.restartPolicy() == RestartAlways {
+		return "init command exited"
+	}
+	if h.Command != "" && !settling && now.Sub(w.checked[worker.ID]) >= h.interval() {
+		w.checked[worker.ID] = now
+		return runHealthCommand(*worker, h.Command)
+	}
+	return ""
+}
+
+// handle applies the worker's restart policy to an unhealthy worker and
+// reports whether it changed.
+func (w *HealthWatcher) handle(config *Config, worker *Worker, reason string) bool {
+	h := worker.Health
+	if h.restartPolicy() == RestartNever {
+		if worker.state() == StateFailed {
+			return false // Already reported
+		}
+		worker.setState(StateFailed, reason)
+		fmt.Printf("Worker '%s' is unhealthy: %s\n", worker.ID, reason)
+		sendNotification(config, workerNotification(*worker, NotifyFailed,
+			fmt.Sprintf("gtw: %s is unhealthy", worker.ID), reason))
+		return true
+	}
+
+	if worker.Restarts >= h.maxRetries() {
+		worker.setState(StateFailed, fmt.Sprintf("%s; gave up after %d restart(s)", reason, worker.Restarts))
+		fmt.Printf("Worker '%s' is unhealthy (%s); not restarting it after %d restart(s)\n", worker.ID, reason, worker.Restarts)
+		sendNotification(config, workerNotification(*worker, NotifyFailed,
+			fmt.Sprintf("gtw: %s keeps failing", worker.ID),
+			fmt.Sprintf("Gave up restarting worker '%s' after %d restart(s): %s", worker.ID, worker.Restarts, reason)))
+		return true
+	}
+
+	now := time.Now()
+	worker.Restarts++
+	worker.RestartedAt = &now
+	fmt.Printf("Restarting worker '%s' (%d/%d): %s\n", worker.ID, worker.Restarts, h.maxRetries(), reason)
+	worker.setState(StateFailed, reason)
+	if err := w.restart(config, worker); err != nil {
+		fmt.Printf("Warning: Could not restart worker '%s': %v\n", worker.ID, err)
+		return true
+	}
+	delete(w.running, worker.ID)
+	delete(w.output, worker.ID)
+	recordEvent(EventWorkerRestarted, worker.ID, reason)
+	return true
+}
+
+// restartWorker re-runs the worker's init command: in its pane when the
+// pane sits at a shell prompt, else in a new pane that replaces it.
+func restartWorker(config *Config, worker *Worker) error {
+	if mux.PaneExists(worker.PaneID) {
+		command, err := paneCurrentCommand(worker.PaneID)
+		if err == nil && (command == "" || isShellCommand(command)) {
+			executeInitCommand(config, worker.WorktreePath, worker.PaneID, false)
+			return nil
+		}
+		if err := stopPane(worker.PaneID, resolveShutdownGrace(config)); err != nil {
+			return err
+		}
+	}
+
+	session := worker.TmuxSession
+	if session == "" {
+		session = getSessionName()
+	}
+	if !mux.HasSession(session) {
+		return fmt.Errorf("session '%s' is not running", session)
+	}
+	paneIndex, paneID, err := mux.NewPane(session, worker.WorktreePath, worker.ID)
+	if err != nil {
+		return err
+	}
+	worker.TmuxSession = session
+	worker.WindowIndex = paneWindowIndex(paneID)
+	worker.PaneID = paneID
+	worker.PaneIndex = paneIndex
+	labelWorkerPane(config, *worker)
+	executeInitCommand(config, worker.WorktreePath, paneID, false)
+	return nil
+}
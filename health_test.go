@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthCheckValidate(t *testing.T) {
+	valid := []HealthCheck{
+		{Command: "curl -sf localhost:3000/health", Restart: RestartOnFailure},
+		{FailOutput: "panic:", Restart: RestartAlways, Interval: "1m", MaxRetries: 5},
+		{},
+	}
+	for _, h := range valid {
+		if err := h.validate(); err != nil {
+			t.Errorf("validate(%+v) = %v", h, err)
+		}
+	}
+	invalid := []HealthCheck{
+		{Restart: "sometimes"},
+		{Interval: "soon"},
+		{FailOutput: "("},
+		{MaxRetries: -1},
+	}
+	for _, h := range invalid {
+		if err := h.validate(); err == nil {
+			t.Errorf("Expected an error for %+v", h)
+		}
+	}
+}
+
+func setupHealthTest(t *testing.T) (*stateMultiplexer, *HealthWatcher, *[]string) {
+	t.Chdir(t.TempDir())
+	fake := &stateMultiplexer{
+		captureMultiplexer: captureMultiplexer{output: map[string]string{"%1": "$ npm run dev\nlistening"}},
+		commands:           map[string]string{"%1": "node"},
+		alive:              map[string]bool{"%1": true},
+	}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	var restarted []string
+	w := NewHealthWatcher()
+	w.restart = func(config *Config, worker *Worker) error {
+		restarted = append(restarted, worker.ID)
+		worker.setState(StateInitializing, "init command sent")
+		return nil
+	}
+	return fake, w, &restarted
+}
+
+func TestHealthWatcherFailOutput(t *testing.T) {
+	fake, w, restarted := setupHealthTest(t)
+	config := &Config{Workers: []Worker{{ID: "web", PaneID: "%1", Status: StateBusy,
+		Health: &HealthCheck{FailOutput: "panic:", Restart: RestartOnFailure}}}}
+	worker := &config.Workers[0]
+
+	// A match that was already there when the watcher started does not count
+	fake.output["%1"] = "panic: old crash\n$ npm run dev\nlistening"
+	if w.Run(config) || len(*restarted) != 0 {
+		t.Fatalf("Expected no restart for old output, got %v", *restarted)
+	}
+	fake.output["%1"] = "panic: old crash\n$ npm run dev\nlistening\npanic: nil map"
+	if !w.Run(config) || len(*restarted) != 1 || worker.Restarts != 1 || worker.RestartedAt == nil {
+		t.Fatalf("Expected a restart, got %v (restarts %d)", *restarted, worker.Restarts)
+	}
+	if w.Run(config) || len(*restarted) != 1 {
+		t.Errorf("Expected the match to be handled once, got %v", *restarted)
+	}
+}
+
+func TestHealthWatcherAlwaysRestartsExitedCommand(t *testing.T) {
+	fake, w, restarted := setupHealthTest(t)
+	config := &Config{Workers: []Worker{
+		{ID: "agent", PaneID: "%1", Status: StateBusy, Health: &HealthCheck{Restart: RestartAlways}},
+	}}
+
+	w.Run(config)
+	fake.commands["%1"] = "zsh"
+	if !w.Run(config) || len(*restarted) != 1 {
+		t.Errorf("Expected the exited init command to be restarted, got %v", *restarted)
+	}
+
+	// on-failure leaves a command that exited alone
+	config.Workers[0].Health.Restart = RestartOnFailure
+	fake.commands["%1"] = "node"
+	w.Run(config)
+	fake.commands["%1"] = "zsh"
+	if w.Run(config) || len(*restarted) != 1 {
+		t.Errorf("Expected no restart with on-failure, got %v", *restarted)
+	}
+}
+
+func TestHealthWatcherPaneGoneAndRetries(t *testing.T) {
+	fake, w, restarted := setupHealthTest(t)
+	fake.alive["%1"] = false
+	config := &Config{Workers: []Worker{
+		{ID: "a", PaneID: "%1", Status: StateBusy, Health: &HealthCheck{Restart: RestartOnFailure, MaxRetries: 1}},
+		{ID: "b", PaneID: "%1", Status: StateBusy, Health: &HealthCheck{Restart: RestartNever}},
+	}}
+
+	w.Run(config)
+	if len(*restarted) != 1 || (*restarted)[0] != "a" {
+		t.Fatalf("Expected only the on-failure worker to restart, got %v", *restarted)
+	}
+	if config.Workers[1].Status != StateBusy {
+		t.Errorf("Expected never to leave a dead pane to the state watcher, got %s", config.Workers[1].Status)
+	}
+
+	// The restart did not bring the pane back: give up after max_retries
+	if !w.Run(config) || len(*restarted) != 1 || config.Workers[0].Status != StateFailed {
+		t.Errorf("Expected to give up, got %v (%s)", *restarted, config.Workers[0].Status)
+	}
+	if w.Run(config) || len(*restarted) != 1 {
+		t.Errorf("Expected a worker given up on to be left alone, got %v", *restarted)
+	}
+}
+
+func TestHealthWatcherCommand(t *testing.T) {
+	_, w, restarted := setupHealthTest(t)
+	long := time.Now().Add(-time.Hour)
+	config := &Config{Workers: []Worker{
+		{ID: "ok", PaneID: "%1", Status: StateReady, CreatedAt: long, WorktreePath: t.TempDir(), Health: &HealthCheck{Command: "true", Restart: RestartOnFailure}},
+		{ID: "bad", PaneID: "%1", Status: StateReady, CreatedAt: long, WorktreePath: t.TempDir(), Health: &HealthCheck{Command: "exit 3"}},
+		{ID: "new", PaneID: "%1", Status: StateReady, CreatedAt: time.Now(), WorktreePath: t.TempDir(), Health: &HealthCheck{Command: "exit 3", Restart: RestartOnFailure}},
+	}}
+
+	if !w.Run(config) || len(*restarted) != 0 {
+		t.Errorf("Expected no restarts, got %v", *restarted)
+	}
+	if config.Workers[1].Status != StateFailed {
+		t.Errorf("Expected the failing worker to be marked failed, got %s", config.Workers[1].Status)
+	}
+	if config.Workers[2].Status != StateReady {
+		t.Errorf("Expected a just-started worker not to be checked yet, got %s", config.Workers[2].Status)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const historyFile = ".gtw/history.jsonl"
+
+// Event types recorded in the history
+const (
+	EventSessionCreated   = "session_created"
+	EventSessionDestroyed = "session_destroyed"
+	EventWorkerAdded      = "worker_added"
+	EventWorkerRemoved    = "worker_removed"
+	EventWorkerArchived   = "worker_archived"
+	EventWorkerUnarchived = "worker_unarchived"
+	EventWorkerImported   = "worker_imported"
+	EventWorkerPaused     = "worker_paused"
+	EventWorkerResumed    = "worker_resumed"
+	EventInitRun          = "init_run"
+	EventInitFailed       = "init_failed"
+	EventRepair           = "repair"
+	EventTaskSent         = "task_sent"
+	EventPromptSent       = "prompt_sent"
+	EventNeedsAttention   = "needs_attention"
+	EventPromptApproved   = "prompt_approved"
+	EventBroadcast        = "broadcast"
+	EventUndo             = "undo"
+	EventCheckpoint       = "checkpoint"
+	EventWorktreeLocked   = "worktree_locked"
+	EventWorktreeUnlocked = "worktree_unlocked"
+	EventWorkerRestarted  = "worker_restarted"
+)
+
+// Event is one line of .gtw/history.jsonl.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Worker    string    `json:"worker,omitempty"`
+	Workspace string    `json:"workspace,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	Command   string    `json:"command,omitempty"` // The gtw invocation that caused the event
+
+	// Workers removed by the event, kept so that `gtw undo` can bring them back
+	Workers []SnapshotWorker `json:"workers,omitempty"`
+}
+
+// HistoryOptions holds the settings given to `gtw history`.
+type HistoryOptions struct {
+	Worker string
+	Since  time.Duration
+	Type   string
+	JSON   bool
+}
+
+func init() {
+	var opts HistoryOptions
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the timeline of worker, task and repair events",
+		Run:   func(cmd *cobra.Command, args []string) { showHistory(opts) },
+	}
+	historyCmd.Flags().StringVar(&opts.Worker, "worker", "", "Only show events of this worker")
+	historyCmd.Flags().DurationVar(&opts.Since, "since", 0, "Only show events newer than this (e.g. 2h)")
+	historyCmd.Flags().StringVar(&opts.Type, "type", "", "Only show events of this type (e.g. worker_added)")
+	historyCmd.Flags().BoolVar(&opts.JSON, "json", false, "Print events as JSON lines")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// recordEvent appends an event to the history. Failures only warn, as the
+// history must never get in the way of the operation itself.
+func recordEvent(eventType, worker, detail string) {
+	recordUndoableEvent(eventType, worker, detail, nil)
+}
+
+// recordUndoableEvent records an event along with the workers it removed.
+func recordUndoableEvent(eventType, worker, detail string, workers []SnapshotWorker) {
+	event := Event{
+		Time:      time.Now(),
+		Type:      eventType,
+		Worker:    worker,
+		Workspace: workspace,
+		Detail:    detail,
+		Command:   strings.Join(append([]string{"gtw"}, os.Args[1:]...), " "),
+		Workers:   workers,
+	}
+	store, err := openStore()
+	if err == nil {
+		err = store.AppendEvent(event)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Could not record history: %v\n", err)
+	}
+}
+
+func appendEvent(path string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// readEvents loads the history, skipping lines that cannot be parsed.
+func readEvents(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// filterEvents keeps the events matching the history filters.
+func filterEvents(events []Event, opts HistoryOptions, now time.Time) []Event {
+	var result []Event
+	for _, event := range events {
+		if opts.Worker != "" && event.Worker != opts.Worker {
+			continue
+		}
+		if opts.Type != "" && event.Type != opts.Type {
+			continue
+		}
+		if opts.Since > 0 && event.Time.Before(now.Add(-opts.Since)) {
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+func showHistory(opts HistoryOptions) {
+	events, err := readHistory(opts, time.Now())
+	if err != nil {
+		fmt.Printf("Error reading history: %v\n", err)
+		return
+	}
+
+	if opts.JSON {
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Println(string(data))
+		}
+		return
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No events found")
+		return
+	}
+
+	fmt.Printf("%-19s %-18s %-20s %s\n", "TIME", "EVENT", "WORKER", "DETAIL")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, event := range events {
+		worker := event.Worker
+		if event.Workspace != "" && worker != "" {
+			worker = event.Workspace + "/" + worker
+		}
+		line := fmt.Sprintf("%-19s %-18s %-20s %s",
+			event.Time.Local().Format("2006-01-02 15:04:05"),
+			event.Type,
+			worker,
+			event.Detail)
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gtw", "history.jsonl")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: now.Add(-3 * time.Hour), Type: EventWorkerAdded, Worker: "w1"},
+		{Time: now.Add(-time.Hour), Type: EventTaskSent, Worker: "w1", Detail: "#1 run tests"},
+		{Time: now.Add(-time.Minute), Type: EventWorkerAdded, Worker: "w2"},
+	}
+	for _, event := range events {
+		if err := appendEvent(path, event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	read, err := readEvents(path)
+	if err != nil || len(read) != 3 {
+		t.Fatalf("Expected 3 events, got %d (%v)", len(read), err)
+	}
+	if read[1].Detail != "#1 run tests" {
+		t.Errorf("Unexpected event: %+v", read[1])
+	}
+
+	if got := filterEvents(read, HistoryOptions{Worker: "w1"}, now); len(got) != 2 {
+		t.Errorf("Expected 2 events for w1, got %d", len(got))
+	}
+	if got := filterEvents(read, HistoryOptions{Since: 2 * time.Hour}, now); len(got) != 2 {
+		t.Errorf("Expected 2 events in the last 2h, got %d", len(got))
+	}
+	if got := filterEvents(read, HistoryOptions{Type: EventWorkerAdded, Since: 2 * time.Hour}, now); len(got) != 1 || got[0].Worker != "w2" {
+		t.Errorf("Expected only w2's creation, got %+v", got)
+	}
+}
+
+func TestLastUndoable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: now, Type: EventWorkerRemoved, Worker: "w1", Workers: []SnapshotWorker{{ID: "w1", Branch: "w1"}}},
+		{Time: now, Type: EventWorkerRemoved, Worker: "w2", Workspace: "review"},
+		{Time: now, Type: EventTaskSent, Worker: "w3"},
+	}
+
+	if event := lastUndoable(events, ""); event == nil || event.Worker != "w1" {
+		t.Fatalf("Expected w1's removal, got %+v", event)
+	}
+	if event := lastUndoable(events, "review"); event == nil || event.Worker != "w2" {
+		t.Errorf("Expected w2's removal in workspace review, got %+v", event)
+	}
+
+	events = append(events, Event{Time: now, Type: EventUndo, Worker: "w1"})
+	if event := lastUndoable(events, ""); event != nil {
+		t.Errorf("Expected nothing to undo after an undo, got %+v", event)
+	}
+	if event := lastUndoable(events, "review"); event == nil {
+		t.Error("Expected an undo in the default workspace not to affect workspace review")
+	}
+	if event := lastUndoable(nil, ""); event != nil {
+		t.Errorf("Expected nothing to undo without history, got %+v", event)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// shellCommands are foreground commands that mean the pane is sitting at a
+// shell prompt rather than running a program.
+var shellCommands = map[string]bool{
+	"bash": true,
+	"zsh":  true,
+	"fish": true,
+	"sh":   true,
+	"dash": true,
+	"ksh":  true,
+	"tcsh": true,
+	"csh":  true,
+}
+
+func isShellCommand(command string) bool {
+	return shellCommands[strings.TrimPrefix(command, "-")]
+}
+
+// paneCurrentCommand returns the foreground command running in the pane.
+func paneCurrentCommand(paneID string) (string, error) {
+	return mux.CurrentCommand(paneID)
+}
+
+// capturePane returns the last lines of the pane's visible output and
+// scrollback.
+func capturePane(paneID string, lines int) (string, error) {
+	return mux.Capture(paneID, lines)
+}
+
+// newOutput returns what current, a later capture of the same pane, shows
+// beyond previous: the lines after the longest overlap of the end of
+// previous with the start of current. This survives output scrolling out
+// of the captured lines, and a cleared pane shares nothing, so all of it is
+// new. The last line of previous does not count towards the overlap since
+// it may have been completed since (e.g. a prompt that got typed on); it is
+// only new if it changed.
+func newOutput(previous, current string) string {
+	prev := strings.Split(strings.TrimRight(previous, "\n "), "\n")
+	last := strings.TrimRight(prev[len(prev)-1], " ")
+	prev = prev[:len(prev)-1]
+	cur := strings.Split(strings.TrimRight(current, "\n "), "\n")
+	start := 0
+	for k := min(len(prev), len(cur)); k > 0; k-- {
+		if slices.Equal(prev[len(prev)-k:], cur[:k]) {
+			start = k
+			break
+		}
+	}
+	if (start > 0 || len(prev) == 0) && start < len(cur) && strings.TrimRight(cur[start], " ") == last {
+		start++
+	}
+	return strings.Join(cur[start:], "\n")
+}
+
+// IdleTracker decides whether worker panes are idle. A pane is idle when it
+// sits at a shell prompt, or when its output has not changed for the quiet
+// period (interactive agents keep running in the foreground, so the process
+// alone says nothing).
+type IdleTracker struct {
+	Quiet time.Duration
+	panes map[string]paneActivity
+}
+
+type paneActivity struct {
+	content string
+	since   time.Time
+}
+
+func NewIdleTracker(quiet time.Duration) *IdleTracker {
+	return &IdleTracker{Quiet: quiet, panes: make(map[string]paneActivity)}
+}
+
+// Observe samples the pane and reports whether it is idle.
+func (t *IdleTracker) Observe(paneID string) (bool, error) {
+	command, err := paneCurrentCommand(paneID)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := capturePane(paneID, 50)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	previous, seen := t.panes[paneID]
+	if !seen || previous.content != content {
+		t.panes[paneID] = paneActivity{content: content, since: now}
+		return isShellCommand(command), nil
+	}
+
+	return isShellCommand(command) || now.Sub(previous.since) >= t.Quiet, nil
+}
+
+// Touch marks the pane as just active, e.g. right after sending it input.
+func (t *IdleTracker) Touch(paneID string) {
+	activity := t.panes[paneID]
+	activity.since = time.Now()
+	t.panes[paneID] = activity
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     string
+	}{
+		{"first capture", "", "a\nb\n", "a\nb"},
+		{"appended", "a\nb\n$ ", "a\nb\n$ make\nDONE\n$ ", "$ make\nDONE\n$"},
+		{"nothing new", "a\nb\n$ ", "a\nb\n$ ", ""},
+		{"last line unchanged", "a\nDONE\n", "a\nDONE\n$ ", "$"},
+		{"single line", "$ ", "$ \nDONE\n", "DONE"},
+		{"scrolled", "a\nb\nc\n$ ", "b\nc\n$ run\nDONE\n", "$ run\nDONE"},
+		{"cleared", "old\nDONE\n$ ", "$ ", "$"},
+		{"blank lines below the cursor", "a\n$ \n\n\n", "a\n$ x\nDONE\n\n\n", "$ x\nDONE"},
+	}
+	for _, tt := range tests {
+		if got := newOutput(tt.previous, tt.current); got != tt.want {
+			t.Errorf("%s: newOutput() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIdleTracker(t *testing.T) {
+	fake := &paneMultiplexer{
+		commands: map[string]string{"%1": "zsh", "%2": "claude"},
+		output:   map[string]string{"%1": "$ ", "%2": "thinking"},
+	}
+	useMultiplexer(t, fake)
+	tracker := NewIdleTracker(20 * time.Millisecond)
+
+	observe := func(paneID string) bool {
+		t.Helper()
+		idle, err := tracker.Observe(paneID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idle
+	}
+
+	// A shell prompt is idle at once, even while its output changes
+	if !observe("%1") {
+		t.Error("Expected a pane at a shell to be idle")
+	}
+	fake.output["%1"] = "$ ls\n$ "
+	if !observe("%1") {
+		t.Error("Expected a pane at a shell to stay idle")
+	}
+
+	// An agent is busy until its output has not changed for the quiet period
+	if observe("%2") {
+		t.Error("Expected an agent to be busy when first seen")
+	}
+	fake.output["%2"] = "thinking."
+	time.Sleep(30 * time.Millisecond)
+	if observe("%2") {
+		t.Error("Expected an agent with new output to be busy")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !observe("%2") {
+		t.Error("Expected an agent with settled output to be idle")
+	}
+
+	// Touch restarts the quiet period
+	tracker.Touch("%2")
+	if observe("%2") {
+		t.Error("Expected an agent to be busy right after a touch")
+	}
+
+	if _, err := tracker.Observe("%9"); err == nil {
+		t.Error("Expected an error for a gone pane")
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportOptions holds the settings given to `gtw import tmux`.
+type ImportOptions struct {
+	Yes bool // Accept the suggested worker IDs and settings without asking
+}
+
+func init() {
+	var opts ImportOptions
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Bootstrap gtw from an existing setup",
+	}
+
+	importTmuxCmd := &cobra.Command{
+		Use:   "tmux [session]",
+		Short: "Adopt the worktrees and panes of an existing tmux session as workers",
+		Long: `Build the gtw config from a hand-rolled tmux/worktree setup. Every linked
+worktree from 'git worktree list' that no worker uses yet is offered as a
+worker, together with the pane of the session (default: the project's)
+working in it; worktrees without a pane get a new one. The session is
+renamed to the name gtw uses, and worktree_prefix is pointed at the
+directory holding the worktrees.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			session := ""
+			if len(args) == 1 {
+				session = args[0]
+			}
+			if !importTmux(session, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	importTmuxCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Import everything with the suggested worker IDs without asking")
+
+	importCmd.AddCommand(importTmuxCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// importCandidate is a worktree offered as a worker, with the pane working
+// in it if there is one.
+type importCandidate struct {
+	ID       string
+	Worktree WorktreeEntry
+	Pane     *paneInfo
+}
+
+var invalidWorkerIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// suggestImportID turns a worktree directory name into a free worker ID.
+func suggestImportID(name string, taken func(string) bool) string {
+	id := strings.Trim(invalidWorkerIDChars.ReplaceAllString(name, "-"), "-.")
+	id = strings.ReplaceAll(id, "..", ".")
+	id = strings.TrimSuffix(id, ".lock")
+	if id == "" {
+		id = "worker"
+	}
+	candidate := id
+	for n := 2; validateWorkerID(candidate) != nil || taken(candidate); n++ {
+		candidate = id + "-" + strconv.Itoa(n)
+		if n > 100 {
+			return ""
+		}
+	}
+	return candidate
+}
+
+// resolvePath returns the absolute path with symlinks resolved as far as
+// it exists, the form git lists worktrees in.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// pathInside reports whether path is dir or lies below it.
+func pathInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// planImport pairs the linked worktrees that no worker uses with the
+// panes working in them and suggests worker IDs. The main worktree, bare
+// entries, worktrees whose directory is gone and detached worktrees (a
+// worker needs a branch) are skipped.
+func planImport(config *Config, worktrees []WorktreeEntry, panes []paneInfo) []importCandidate {
+	used := make(map[string]bool)
+	claimed := make(map[string]bool)
+	for _, worker := range config.Workers {
+		used[resolvePath(worker.WorktreePath)] = true
+		claimed[worker.PaneID] = true
+	}
+
+	var candidates []importCandidate
+	taken := func(id string) bool {
+		for _, c := range candidates {
+			if c.ID == id {
+				return true
+			}
+		}
+		return checkNewWorkerID(config, id) != nil
+	}
+	for i, worktree := range worktrees {
+		if i == 0 || worktree.Bare || worktree.Prunable || worktree.Branch == "" || used[worktree.Path] {
+			continue
+		}
+		candidate := importCandidate{Worktree: worktree}
+		for j := range panes {
+			if !claimed[panes[j].ID] && pathInside(panes[j].Path, worktree.Path) {
+				candidate.Pane = &panes[j]
+				claimed[panes[j].ID] = true
+				break
+			}
+		}
+		candidate.ID = suggestImportID(filepath.Base(worktree.Path), taken)
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// importedWorktreePath stores worktrees inside the project relative to it,
+// like `gtw add` does, and others as absolute paths.
+func importedWorktreePath(projectPath, path string) string {
+	if rel, err := filepath.Rel(projectPath, path); err == nil && pathInside(path, projectPath) {
+		return rel
+	}
+	return path
+}
+
+// suggestWorktreePrefix returns the worktree_prefix matching the directory
+// that holds all the worktrees, or "" when they are spread out.
+func suggestWorktreePrefix(projectPath string, paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	parent := filepath.Dir(paths[0])
+	for _, path := range paths[1:] {
+		if filepath.Dir(path) != parent {
+			return ""
+		}
+	}
+	if parent == projectPath {
+		return ""
+	}
+	if pathInside(parent, projectPath) {
+		rel, _ := filepath.Rel(projectPath, parent)
+		return rel
+	}
+	return parent
+}
+
+func importTmux(session string, opts ImportOptions) bool {
+	if !requireTmux("import tmux") {
+		return false
+	}
+	if nonInteractive && !opts.Yes {
+		fmt.Fprintln(os.Stderr, "Error: gtw import tmux asks for each worker; pass --yes to import with the suggested IDs")
+		return false
+	}
+	if err := checkGitRepository(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	target := getSessionName()
+	if session == "" {
+		session = target
+	}
+	if !mux.HasSession(session) {
+		fmt.Fprintf(os.Stderr, "Error: Session '%s' does not exist (give the name of the session to import)\n", session)
+		return false
+	}
+	if session != target && mux.HasSession(target) {
+		fmt.Fprintf(os.Stderr, "Error: Session '%s' already exists; import into it or rename one of them\n", target)
+		return false
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		return false
+	}
+	projectPath := resolvePath(cwd)
+
+	worktrees, err := listWorktrees()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	panes, err := listSessionPanes(session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing panes of session '%s': %v\n", session, err)
+		return false
+	}
+	for i, worktree := range worktrees {
+		switch {
+		case worktree.Prunable:
+			fmt.Printf("⚠️  Skipping %s: %s (run 'git worktree prune')\n", worktree.Path, worktree.PruneReason)
+		case i > 0 && !worktree.Bare && worktree.Branch == "":
+			fmt.Printf("⚠️  Skipping %s: HEAD is detached (check out a branch to import it)\n", worktree.Path)
+		}
+	}
+
+	candidates := planImport(config, worktrees, panes)
+	if len(candidates) == 0 {
+		fmt.Println("No worktrees to import: every linked worktree already belongs to a worker")
+		return true
+	}
+
+	fmt.Printf("Found %d worktree(s) in session '%s':\n", len(candidates), session)
+	for _, c := range candidates {
+		pane := "no pane (one will be created)"
+		if c.Pane != nil {
+			pane = "pane " + c.Pane.ID
+		}
+		fmt.Printf("  %s (%s, %s)\n", c.Worktree.Path, c.Worktree.Branch, pane)
+	}
+
+	var selected []importCandidate
+	if opts.Yes {
+		for _, c := range candidates {
+			if c.ID != "" {
+				selected = append(selected, c)
+			}
+		}
+	} else {
+		prompter := &wizardPrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+		for _, c := range candidates {
+			id, err := prompter.ask(fmt.Sprintf("Worker ID for %s ('-' to skip)", filepath.Base(c.Worktree.Path)), c.ID, func(answer string) error {
+				if answer == "-" {
+					return nil
+				}
+				for _, s := range selected {
+					if s.ID == answer {
+						return fmt.Errorf("worker '%s' is already being imported", answer)
+					}
+				}
+				return checkNewWorkerID(config, answer)
+			})
+			if err != nil {
+				fmt.Println("Aborted")
+				return false
+			}
+			if id != "-" {
+				c.ID = id
+				selected = append(selected, c)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("Nothing imported")
+		return true
+	}
+
+	if session != target {
+		if !opts.Yes && !confirm(fmt.Sprintf("Rename session '%s' to '%s' so that gtw finds it?", session, target)) {
+			fmt.Println("Aborted: gtw only manages the session named after the project")
+			return false
+		}
+		if output, err := tmuxCommand("rename-session", "-t", session, target).CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error renaming session: %s\n", strings.TrimSpace(string(output)))
+			return false
+		}
+		fmt.Printf("Renamed session '%s' to '%s'\n", session, target)
+	}
+
+	if config.ProjectPath == "" {
+		config.ProjectPath = cwd
+	}
+	var paths []string
+	imported := 0
+	for _, c := range selected {
+		worker := Worker{
+			ID:           c.ID,
+			WorktreePath: importedWorktreePath(projectPath, c.Worktree.Path),
+			TmuxSession:  target,
+			Branch:       c.Worktree.Branch,
+			CreatedAt:    time.Now(),
+			Status:       StateCreating,
+		}
+		if c.Pane != nil {
+			worker.PaneID, worker.PaneIndex = c.Pane.ID, c.Pane.Index
+		} else {
+			paneIndex, paneID, err := mux.NewPane(target, c.Worktree.Path, c.ID)
+			if err != nil {
+				fmt.Printf("❌ Error creating a pane for '%s': %v\n", c.ID, err)
+				continue
+			}
+			worker.PaneID, worker.PaneIndex = paneID, paneIndex
+		}
+		worker.WindowIndex = paneWindowIndex(worker.PaneID)
+		worker.setState(StateReady, "imported from tmux")
+		config.Workers = append(config.Workers, worker)
+		labelWorkerPane(config, worker)
+		recordEvent(EventWorkerImported, worker.ID, c.Worktree.Path)
+		paths = append(paths, c.Worktree.Path)
+		imported++
+		fmt.Printf("✅ Imported worker '%s' (%s)\n", worker.ID, worker.WorktreePath)
+	}
+
+	prefix := suggestWorktreePrefix(projectPath, paths)
+	root := prefix
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(projectPath, prefix)
+	}
+	if prefix != "" && resolvePath(root) != resolvePath(worktreeRoot(config)) {
+		if opts.Yes || confirm(fmt.Sprintf("Set worktree_prefix to '%s' so that new workers and 'gtw check' use the same directory?", prefix)) {
+			config.WorktreePrefix = prefix
+			fmt.Printf("Set worktree prefix to: %s\n", prefix)
+		}
+	}
+
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		return false
+	}
+	registerProject(cwd)
+	configureTmuxSession(target, config)
+	fmt.Printf("Imported %d worker(s); run 'gtw check' to verify\n", imported)
+	return imported == len(selected)
+}
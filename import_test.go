@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestPlanImport(t *testing.T) {
+	config := &Config{Workers: []Worker{{ID: "taken", WorktreePath: "/src/app/.wt/taken", PaneID: "%9"}}}
+	worktrees := []WorktreeEntry{
+		{Path: "/src/app", Branch: "main"},
+		{Path: "/src/app/.wt/fix login", Branch: "fix-login"},
+		{Path: "/src/app/.wt/taken", Branch: "taken"},
+		{Path: "/src/app/.wt/gone", Branch: "gone", Prunable: true},
+		{Path: "/src/app/.wt/detached"},
+		{Path: "/src/other/taken", Branch: "other"},
+	}
+	panes := []paneInfo{
+		{ID: "%1", Path: "/src/app"},
+		{ID: "%2", Path: "/src/app/.wt/fix login/web"},
+		{ID: "%9", Path: "/src/app/.wt/taken"},
+	}
+
+	candidates := planImport(config, worktrees, panes)
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %+v", candidates)
+	}
+	if c := candidates[0]; c.ID != "fix-login" || c.Pane == nil || c.Pane.ID != "%2" {
+		t.Errorf("Expected fix-login in pane %%2, got %+v", c)
+	}
+	if c := candidates[1]; c.ID != "taken-2" || c.Pane != nil {
+		t.Errorf("Expected a new ID and no pane for the second 'taken', got %+v", c)
+	}
+}
+
+func TestSuggestImportID(t *testing.T) {
+	none := func(string) bool { return false }
+	tests := map[string]string{
+		"issue-123":   "issue-123",
+		"fix login":   "fix-login",
+		".hidden":     "hidden",
+		"a..b":        "a.b",
+		"x.lock":      "x",
+		"日本語":         "worker",
+		"feature/foo": "feature-foo",
+	}
+	for name, want := range tests {
+		if got := suggestImportID(name, none); got != want {
+			t.Errorf("suggestImportID(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSuggestWorktreePrefix(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"/src/app/.wt/a", "/src/app/.wt/b"}, ".wt"},
+		{[]string{"/src/wt/app/a"}, "/src/wt/app"},
+		{[]string{"/src/app/.wt/a", "/src/elsewhere/b"}, ""},
+		{[]string{"/src/app/a"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := suggestWorktreePrefix("/src/app", tt.paths); got != tt.want {
+			t.Errorf("suggestWorktreePrefix(%v) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestImportedWorktreePath(t *testing.T) {
+	if got := importedWorktreePath("/src/app", "/src/app/worktree/a"); got != "worktree/a" {
+		t.Errorf("Expected a path relative to the project, got %q", got)
+	}
+	if got := importedWorktreePath("/src/app", "/src/wt/a"); got != "/src/wt/a" {
+		t.Errorf("Expected an absolute path outside the project, got %q", got)
+	}
+}
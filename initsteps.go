@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InitStep is one command of the init_steps chain, run in the worker pane
+// after setup_script and before init_command. In the config a step is
+// either a string or {"run": "...", "on_error": "continue"}.
+type InitStep struct {
+	Run     string `json:"run"`
+	OnError string `json:"on_error,omitempty"` // abort (default) or continue
+}
+
+const (
+	StepAbort    = "abort"
+	StepContinue = "continue"
+)
+
+func (s *InitStep) UnmarshalJSON(data []byte) error {
+	var run string
+	if err := json.Unmarshal(data, &run); err == nil {
+		*s = InitStep{Run: run}
+		return nil
+	}
+	type plain InitStep
+	var step plain
+	if err := json.Unmarshal(data, &step); err != nil {
+		return err
+	}
+	if step.OnError != "" && step.OnError != StepAbort && step.OnError != StepContinue {
+		return fmt.Errorf("invalid on_error '%s' for init step '%s' (use abort or continue)", step.OnError, step.Run)
+	}
+	*s = InitStep(step)
+	return nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// setupScriptCommand returns the shell command that runs the setup script.
+// Scripts without the executable bit are run with sh.
+func setupScriptCommand(config *Config) string {
+	script := config.SetupScript
+	if !filepath.IsAbs(script) && config.ProjectPath != "" {
+		script = filepath.Join(config.ProjectPath, script)
+	}
+	if info, err := os.Stat(script); err == nil && info.Mode()&0111 != 0 {
+		return shellQuote(muxPath(script))
+	}
+	return "sh " + shellQuote(muxPath(script))
+}
+
+// buildInitCommand chains the setup script, the init steps and init_command
+// into one line typed into the pane. A failing step stops the chain (and
+// the interactive command) unless its on_error is "continue".
+func buildInitCommand(dir, setupScript string, steps []InitStep, initCommand string) string {
+	parts := []string{"cd " + shellQuote(dir)}
+	if setupScript != "" {
+		parts = append(parts, setupScript)
+	}
+	for _, step := range steps {
+		if strings.TrimSpace(step.Run) == "" {
+			continue
+		}
+		if step.OnError == StepContinue {
+			// Grouped so that "|| true" cannot rescue an earlier failed step
+			parts = append(parts, fmt.Sprintf("{ { %s; } || true; }", step.Run))
+		} else {
+			parts = append(parts, fmt.Sprintf("{ %s; }", step.Run))
+		}
+	}
+	if initCommand != "" {
+		parts = append(parts, initCommand)
+	}
+	return strings.Join(parts, " && ")
+}
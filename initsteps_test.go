@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitStepUnmarshal(t *testing.T) {
+	var steps []InitStep
+	data := `["npm ci", {"run": "cp ../.env .", "on_error": "continue"}]`
+	if err := json.Unmarshal([]byte(data), &steps); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(steps) != 2 || steps[0] != (InitStep{Run: "npm ci"}) || steps[1] != (InitStep{Run: "cp ../.env .", OnError: StepContinue}) {
+		t.Errorf("Unexpected steps: %+v", steps)
+	}
+
+	if err := json.Unmarshal([]byte(`[{"run": "x", "on_error": "retry"}]`), &steps); err == nil {
+		t.Error("Expected an invalid on_error to fail")
+	}
+}
+
+func TestBuildInitCommand(t *testing.T) {
+	steps := []InitStep{
+		{Run: "npm ci"},
+		{Run: "cp ../.env .", OnError: StepContinue},
+		{Run: "  "},
+	}
+	got := buildInitCommand("/p/worktree/it's", "sh '/p/setup.sh'", steps, "claude")
+	want := `cd '/p/worktree/it'\''s' && sh '/p/setup.sh' && { npm ci; } && { { cp ../.env .; } || true; } && claude`
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	if got := buildInitCommand("/p/w", "", nil, "claude"); got != "cd '/p/w' && claude" {
+		t.Errorf("Unexpected command: %s", got)
+	}
+}
+
+func TestShellQuoteHostileInputs(t *testing.T) {
+	inputs := []string{"", "a b", "it's", "$(touch pwned)", "`id`", "a\nb", `back\slash`, `"; exit 1; "`, "*"}
+	for _, input := range inputs {
+		output, err := exec.Command("sh", "-c", "printf %s "+shellQuote(input)).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", input, err)
+		}
+		if string(output) != input {
+			t.Errorf("shellQuote(%q) came back from sh as %q", input, output)
+		}
+	}
+}
+
+func TestBuildInitCommandHostilePaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, `it's a $(touch pwned) "dir"`)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(root, "set'up `touch pwned`.sh")
+	if err := os.WriteFile(script, []byte("echo setup-ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	setup := setupScriptCommand(&Config{SetupScript: script})
+	command := buildInitCommand(dir, setup, nil, "pwd")
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Init command failed: %v\n%s", err, command)
+	}
+
+	if got := strings.TrimSpace(string(output)); got != "setup-ok\n"+dir {
+		t.Errorf("Unexpected output %q for command %s", got, command)
+	}
+	for _, d := range []string{root, dir} {
+		if _, err := os.Stat(filepath.Join(d, "pwned")); err == nil {
+			t.Errorf("Command substitution in a path was executed: %s", command)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const (
+	jiraKeychainService = "gtw-jira"
+	maxSlugLength       = 40
+)
+
+// JiraConfig points gtw at a Jira site for `gtw add --jira`. Credentials are
+// never stored in the config: the API token comes from JIRA_API_TOKEN or the
+// system keychain.
+type JiraConfig struct {
+	URL   string `json:"url,omitempty"`   // Site URL (e.g. "https://example.atlassian.net")
+	Email string `json:"email,omitempty"` // Account email for Jira Cloud; empty for a personal access token
+}
+
+// JiraIssue is a ticket fetched from Jira.
+type JiraIssue struct {
+	Key     string
+	Summary string
+	URL     string
+}
+
+var jiraKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[0-9]+$`)
+
+// jiraSettings returns the Jira site URL and account, with JIRA_URL and
+// JIRA_EMAIL overriding the config.
+func jiraSettings(config *Config) (string, string) {
+	site, email := "", ""
+	if config.Jira != nil {
+		site, email = config.Jira.URL, config.Jira.Email
+	}
+	if value := os.Getenv("JIRA_URL"); value != "" {
+		site = value
+	}
+	if value := os.Getenv("JIRA_EMAIL"); value != "" {
+		email = value
+	}
+	return strings.TrimSuffix(site, "/"), email
+}
+
+// jiraToken returns the API token from JIRA_API_TOKEN, or from the macOS
+// keychain or the Secret Service (secret-tool) under the service "gtw-jira".
+func jiraToken(email string) string {
+	if token := os.Getenv("JIRA_API_TOKEN"); token != "" {
+		return token
+	}
+	var cmd *Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", jiraKeychainService, "-w"}
+		if email != "" {
+			args = append(args, "-a", email)
+		}
+		cmd = newCommand("security", args...)
+	case "linux":
+		cmd = newCommand("secret-tool", "lookup", "service", jiraKeychainService, "account", email)
+	default:
+		return ""
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// fetchJiraIssue fetches the ticket's summary. Jira Cloud authenticates with
+// the account email and an API token; Server and Data Center accept a
+// personal access token as a bearer token.
+func fetchJiraIssue(site, email, token, key string) (*JiraIssue, error) {
+	client := forgeClient{http: &http.Client{Timeout: forgeRequestTimeout}, baseURL: site, token: token, authHeader: "Authorization", authPrefix: "Bearer "}
+	if email != "" {
+		client.token = base64.StdEncoding.EncodeToString([]byte(email + ":" + token))
+		client.authPrefix = "Basic "
+	}
+	var resp struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := client.do("GET", "/rest/api/2/issue/"+key+"?fields=summary", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &JiraIssue{Key: resp.Key, Summary: resp.Fields.Summary, URL: site + "/browse/" + resp.Key}, nil
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns free text into a lower-case, dash-separated name of at most
+// maxLength characters, cut at a word boundary when possible.
+func slugify(text string, maxLength int) string {
+	slug := strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(slug) <= maxLength {
+		return slug
+	}
+	cut := slug[:maxLength]
+	if i := strings.LastIndex(cut, "-"); i > 0 && slug[maxLength] != '-' {
+		cut = cut[:i]
+	}
+	slug = cut
+	return strings.Trim(slug, "-")
+}
+
+// jiraWorkerID names a worker after its ticket, e.g. "proj-123-fix-login".
+func jiraWorkerID(issue *JiraIssue) string {
+	id := strings.ToLower(issue.Key)
+	if slug := slugify(issue.Summary, maxSlugLength); slug != "" {
+		id += "-" + slug
+	}
+	return id
+}
+
+// applyJira fetches the ticket given with --jira and fills in the worker ID
+// (when not given), note and jira_key/jira_url metadata.
+func applyJira(id string, opts *AddOptions) (string, error) {
+	key := strings.ToUpper(opts.Jira)
+	if !jiraKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("'%s' is not a Jira issue key (e.g. PROJ-123)", opts.Jira)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("loading config: %v", err)
+	}
+	site, email := jiraSettings(config)
+	if site == "" {
+		return "", fmt.Errorf("no Jira site configured; set jira.url in %s or JIRA_URL", configFile)
+	}
+	token := jiraToken(email)
+	if token == "" {
+		return "", fmt.Errorf("no Jira API token; set JIRA_API_TOKEN or store it in the keychain under the service '%s'", jiraKeychainService)
+	}
+
+	issue, err := fetchJiraIssue(site, email, token, key)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s from Jira: %v", key, err)
+	}
+	fmt.Printf("%s: %s\n", issue.Key, issue.Summary)
+
+	if id == "" {
+		id = jiraWorkerID(issue)
+	}
+	if opts.Note == "" {
+		opts.Note = issue.Key + ": " + issue.Summary
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]string)
+	}
+	opts.Metadata["jira_key"] = issue.Key
+	opts.Metadata["jira_url"] = issue.URL
+	return id, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"Fix login bug", 40, "fix-login-bug"},
+		{"  [API] Handle 500s / retries!  ", 40, "api-handle-500s-retries"},
+		{"Refactor the payment gateway integration layer", 20, "refactor-the-payment"},
+		{"日本語のみ", 40, ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.text, tt.max); got != tt.want {
+			t.Errorf("slugify(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestJiraWorkerID(t *testing.T) {
+	id := jiraWorkerID(&JiraIssue{Key: "PROJ-123", Summary: "Fix login bug"})
+	if id != "proj-123-fix-login-bug" {
+		t.Errorf("jiraWorkerID() = %q", id)
+	}
+	if err := validateWorkerID(id); err != nil {
+		t.Error(err)
+	}
+	if id := jiraWorkerID(&JiraIssue{Key: "PROJ-1"}); id != "proj-1" {
+		t.Errorf("jiraWorkerID() without summary = %q", id)
+	}
+}
+
+func TestFetchJiraIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		if !ok || user != "me@example.com" || token != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/issue/PROJ-7" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"key":    "PROJ-7",
+			"fields": map[string]string{"summary": "Fix login"},
+		})
+	}))
+	defer server.Close()
+
+	issue, err := fetchJiraIssue(server.URL, "me@example.com", "secret", "PROJ-7")
+	if err != nil {
+		t.Fatalf("fetchJiraIssue() error: %v", err)
+	}
+	if issue.Summary != "Fix login" || issue.URL != server.URL+"/browse/PROJ-7" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	if _, err := fetchJiraIssue(server.URL, "me@example.com", "wrong", "PROJ-7"); err == nil {
+		t.Error("Expected an error for bad credentials")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KeybindingsOptions holds the settings given to `gtw keybindings`.
+type KeybindingsOptions struct {
+	Key  string // Key pressed after the tmux prefix to reach the gtw bindings
+	File string // tmux config file that install writes to
+	Yes  bool
+}
+
+const (
+	defaultKeybindingKey = "g"
+	keybindingTable      = "gtw"
+
+	keybindingsBegin = "# >>> gtw keybindings >>>"
+	keybindingsEnd   = "# <<< gtw keybindings <<<"
+)
+
+func init() {
+	var opts KeybindingsOptions
+	keybindingsCmd := &cobra.Command{
+		Use:   "keybindings",
+		Short: "Print tmux key bindings for common gtw actions",
+		Long: `Print tmux key bindings for gtw, reached by pressing the tmux prefix and
+then the key given with --key (default: g):
+
+  f  pick a worker in a popup and jump to it
+  n  focus the next worker
+  p  focus the previous worker
+  a  ask for an ID and add a worker
+  g  open lazygit (or git_tui) in the current worker's worktree
+
+Commands run from the directory of the current pane, so the bindings work
+for every project. Add them to your tmux config with 'gtw keybindings install'.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			snippet, err := keybindingSnippet(opts.Key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Print(snippet)
+		},
+	}
+	keybindingsCmd.PersistentFlags().StringVar(&opts.Key, "key", defaultKeybindingKey, "Key pressed after the tmux prefix to reach the gtw bindings")
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Add the gtw key bindings to your tmux config",
+		Long: `Append the gtw key bindings to your tmux config (~/.tmux.conf, or
+~/.config/tmux/tmux.conf when only that exists), replacing the ones added
+earlier, and load them into the running tmux server.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !installKeybindings(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	installCmd.Flags().StringVar(&opts.File, "file", "", "tmux config file to write (default: ~/.tmux.conf)")
+	installCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Write the file without asking")
+
+	keybindingsCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(keybindingsCmd)
+}
+
+// keybindingSnippet returns the tmux config lines binding the gtw actions
+// in their own key table, entered with prefix + key.
+func keybindingSnippet(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, " \t\n'\"\\;#") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	// Paths reach the shell through #{q:}, and the new worker's ID is read
+	// by the popup's shell, so neither is ever parsed as a command
+	cd := `cd #{q:pane_current_path} && `
+	add := `printf "New worker ID: "; read -r id && [ -n "$id" ] || exit 0; gtw add "$id" || read -r _`
+	lines := []string{
+		keybindingsBegin,
+		fmt.Sprintf("# prefix + %s, then: f pick, n next, p prev, a add, g git", key),
+		fmt.Sprintf("bind-key %s switch-client -T %s", key, keybindingTable),
+		fmt.Sprintf(`bind-key -T %s f display-popup -E -d "#{pane_current_path}" -w 80%% -h 60%% "gtw pick attach"`, keybindingTable),
+		fmt.Sprintf(`bind-key -T %s n run-shell "%sgtw next"`, keybindingTable, cd),
+		fmt.Sprintf(`bind-key -T %s p run-shell "%sgtw prev"`, keybindingTable, cd),
+		fmt.Sprintf(`bind-key -T %s a display-popup -E -d "#{pane_current_path}" -w 60%% -h 30%% '%s'`, keybindingTable, add),
+		fmt.Sprintf(`bind-key -T %s g run-shell -b "%sgtw git"`, keybindingTable, cd),
+		keybindingsEnd,
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// replaceKeybindings puts snippet in place of the gtw block of a tmux
+// config, or appends it when there is none.
+func replaceKeybindings(config, snippet string) string {
+	start := strings.Index(config, keybindingsBegin)
+	if start >= 0 {
+		if end := strings.Index(config[start:], keybindingsEnd); end >= 0 {
+			rest := strings.TrimPrefix(config[start+end+len(keybindingsEnd):], "\n")
+			return config[:start] + snippet + rest
+		}
+	}
+	if config != "" && !strings.HasSuffix(config, "\n") {
+		config += "\n"
+	}
+	if config != "" {
+		config += "\n"
+	}
+	return config + snippet
+}
+
+// defaultTmuxConfig returns ~/.tmux.conf unless only the XDG location
+// exists.
+func defaultTmuxConfig() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(home, ".tmux.conf")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		xdg := filepath.Join(home, ".config", "tmux", "tmux.conf")
+		if _, err := os.Stat(xdg); err == nil {
+			return xdg, nil
+		}
+	}
+	return file, nil
+}
+
+func installKeybindings(opts KeybindingsOptions) bool {
+	snippet, err := keybindingSnippet(opts.Key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	file := userPath(opts.File)
+	if file == "" {
+		if file, err = defaultTmuxConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	}
+	if nonInteractive && !opts.Yes {
+		fmt.Fprintln(os.Stderr, "Error: gtw keybindings install asks before writing; pass --yes")
+		return false
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", file, err)
+		return false
+	}
+	updated := replaceKeybindings(string(existing), snippet)
+	if updated == string(existing) {
+		fmt.Printf("✅ The gtw key bindings in %s are up to date\n", file)
+	} else {
+		fmt.Print(snippet)
+		if !opts.Yes && !confirm(fmt.Sprintf("Write these bindings to %s?", file)) {
+			fmt.Println("Aborted")
+			return false
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		if err := os.WriteFile(file, []byte(updated), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", file, err)
+			return false
+		}
+		fmt.Printf("✅ Added the gtw key bindings to %s\n", file)
+	}
+
+	// Load just the bindings, so the rest of the config is not run again
+	if tmuxCommand("list-sessions").Run() == nil {
+		source := tmuxCommand("source-file", "-")
+		source.Stdin = strings.NewReader(snippet)
+		if output, err := source.CombinedOutput(); err != nil {
+			fmt.Printf("Warning: Could not load the bindings into tmux: %s\n", strings.TrimSpace(string(output)))
+			fmt.Printf("Run 'tmux source-file %s' to load them\n", file)
+			return true
+		}
+		fmt.Printf("Loaded them into tmux; press the prefix and %s, then f, n, p, a or g\n", opts.Key)
+	}
+	return true
+}
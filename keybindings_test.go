@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeybindingSnippet(t *testing.T) {
+	snippet, err := keybindingSnippet("G")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"bind-key G switch-client -T gtw", "gtw pick attach", "gtw next", "gtw prev", `gtw add "$id"`, "gtw git"} {
+		if !strings.Contains(snippet, want) {
+			t.Errorf("Expected %q in the snippet:\n%s", want, snippet)
+		}
+	}
+	for _, key := range []string{"", "a b", "'"} {
+		if _, err := keybindingSnippet(key); err == nil {
+			t.Errorf("Expected an error for key %q", key)
+		}
+	}
+}
+
+func TestReplaceKeybindings(t *testing.T) {
+	old, _ := keybindingSnippet("g")
+	snippet, _ := keybindingSnippet("h")
+
+	if got := replaceKeybindings("", snippet); got != snippet {
+		t.Errorf("Expected just the snippet in a new file, got:\n%s", got)
+	}
+	if got := replaceKeybindings("set -g mouse on", snippet); got != "set -g mouse on\n\n"+snippet {
+		t.Errorf("Expected the snippet to be appended, got:\n%s", got)
+	}
+
+	config := "set -g mouse on\n\n" + old + "set -g base-index 1\n"
+	got := replaceKeybindings(config, snippet)
+	if got != "set -g mouse on\n\n"+snippet+"set -g base-index 1\n" {
+		t.Errorf("Expected the old bindings to be replaced in place, got:\n%s", got)
+	}
+	if replaceKeybindings(got, snippet) != got {
+		t.Error("Expected installing the same bindings again to change nothing")
+	}
+}
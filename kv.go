@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	workerStateDir = ".gtw/workers"
+	kvFile         = "kv.json"
+)
+
+func init() {
+	kvCmd := &cobra.Command{
+		Use:   "kv",
+		Short: "Store small key/value metadata for a worker",
+		Long: `Store small pieces of metadata for a worker (task URL, PR number, status
+notes) in .gtw/workers/<worker-id>/kv.json, outside the main config. The
+directory is created with the worker and can also be used as its scratch
+space; 'gtw kv path <worker-id>' prints it.`,
+	}
+
+	kvSetCmd := &cobra.Command{
+		Use:   "set <worker-id> <key> <value>",
+		Short: "Set a key",
+		Args:  cobra.ExactArgs(3),
+		Run:   func(cmd *cobra.Command, args []string) { setWorkerKV(args[0], args[1], args[2]) },
+	}
+
+	kvGetCmd := &cobra.Command{
+		Use:   "get <worker-id> <key>",
+		Short: "Print a key's value (exits 1 if it is not set)",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !getWorkerKV(args[0], args[1]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	var listJSON bool
+	kvListCmd := &cobra.Command{
+		Use:   "list <worker-id>",
+		Short: "Print all keys and values",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { listWorkerKV(args[0], listJSON) },
+	}
+	kvListCmd.Flags().BoolVar(&listJSON, "json", false, "Print the keys as a JSON object")
+
+	kvDeleteCmd := &cobra.Command{
+		Use:   "delete <worker-id> <key>",
+		Short: "Delete a key",
+		Args:  cobra.ExactArgs(2),
+		Run:   func(cmd *cobra.Command, args []string) { deleteWorkerKV(args[0], args[1]) },
+	}
+
+	kvPathCmd := &cobra.Command{
+		Use:   "path <worker-id>",
+		Short: "Print the worker's state directory",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { printWorkerStateDir(args[0]) },
+	}
+
+	kvCmd.AddCommand(kvSetCmd, kvGetCmd, kvListCmd, kvDeleteCmd, kvPathCmd)
+	rootCmd.AddCommand(kvCmd)
+}
+
+// workerStatePath returns the state directory of a worker.
+func workerStatePath(config *Config, id string) string {
+	dir := config.ProjectPath
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return filepath.Join(dir, workerStateDir, id)
+}
+
+// createWorkerState creates the worker's state directory.
+func createWorkerState(config *Config, id string) error {
+	return os.MkdirAll(workerStatePath(config, id), 0755)
+}
+
+// removeWorkerState deletes the worker's state directory and its data.
+func removeWorkerState(config *Config, id string) error {
+	return os.RemoveAll(workerStatePath(config, id))
+}
+
+func loadKV(path string) (map[string]string, error) {
+	values := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return values, nil
+}
+
+// saveKV writes the values through a temporary file so that readers never
+// see a partial file.
+func saveKV(path string, values map[string]string) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// kvPath returns the kv file of a known (active or archived) worker.
+func kvPath(id string) (string, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("loading config: %v", err)
+	}
+	if findWorkerIndex(config, id) == -1 && findArchivedIndex(config, id) == -1 {
+		return "", fmt.Errorf("worker '%s' not found", id)
+	}
+	return filepath.Join(workerStatePath(config, id), kvFile), nil
+}
+
+func setWorkerKV(id, key, value string) {
+	path, err := kvPath(id)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	values, err := loadKV(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	values[key] = value
+	if err := saveKV(path, values); err != nil {
+		fmt.Printf("Error saving %s: %v\n", path, err)
+		return
+	}
+	fmt.Printf("✅ Set %s for worker '%s'\n", key, id)
+}
+
+func getWorkerKV(id, key string) bool {
+	path, err := kvPath(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	values, err := loadKV(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	value, ok := values[key]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Key '%s' is not set for worker '%s'\n", key, id)
+		return false
+	}
+	fmt.Println(value)
+	return true
+}
+
+func listWorkerKV(id string, asJSON bool) {
+	path, err := kvPath(id)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	values, err := loadKV(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if asJSON {
+		data, _ := json.MarshalIndent(values, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	if len(values) == 0 {
+		fmt.Printf("No keys set for worker '%s'\n", id)
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s=%s\n", key, values[key])
+	}
+}
+
+func deleteWorkerKV(id, key string) {
+	path, err := kvPath(id)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	values, err := loadKV(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if _, ok := values[key]; !ok {
+		fmt.Printf("Key '%s' is not set for worker '%s'\n", key, id)
+		return
+	}
+	delete(values, key)
+	if err := saveKV(path, values); err != nil {
+		fmt.Printf("Error saving %s: %v\n", path, err)
+		return
+	}
+	fmt.Printf("✅ Deleted %s for worker '%s'\n", key, id)
+}
+
+func printWorkerStateDir(id string) {
+	path, err := kvPath(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Workers created before state directories existed get one on demand
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	fmt.Println(dir)
+}
+
+// setWorkerMetadata merges values into the worker's kv file.
+func setWorkerMetadata(config *Config, id string, values map[string]string) error {
+	path := filepath.Join(workerStatePath(config, id), kvFile)
+	current, err := loadKV(path)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		current[key] = value
+	}
+	return saveKV(path, current)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKVRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gtw", "workers", "w1", kvFile)
+
+	values, err := loadKV(path)
+	if err != nil || len(values) != 0 {
+		t.Fatalf("Expected no values for a missing file, got %v, %v", values, err)
+	}
+
+	values["pr"] = "42"
+	values["url"] = "https://example.com/issues/1"
+	if err := saveKV(path, values); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected the temporary file to be renamed away")
+	}
+
+	loaded, err := loadKV(path)
+	if err != nil || loaded["pr"] != "42" || loaded["url"] != "https://example.com/issues/1" {
+		t.Errorf("Unexpected values after reload: %v, %v", loaded, err)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadKV(path); err == nil {
+		t.Error("Expected an error for a corrupt file")
+	}
+}
+
+func TestWorkerStatePath(t *testing.T) {
+	config := &Config{ProjectPath: filepath.FromSlash("/src/app")}
+	want := filepath.FromSlash("/src/app/.gtw/workers/w1")
+	if got := workerStatePath(config, "w1"); got != want {
+		t.Errorf("workerStatePath() = %s, want %s", got, want)
+	}
+}
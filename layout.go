@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Split directions for worker panes. "auto" tries a vertical split and falls
+// back to a horizontal one when the window is too small.
+const (
+	SplitAuto       = "auto"
+	SplitVertical   = "vertical"
+	SplitHorizontal = "horizontal"
+)
+
+// PaneLayout controls where and how tmux worker panes are created.
+type PaneLayout struct {
+	Direction string // auto, vertical (stacked) or horizontal (side by side)
+	Size      string // Size of the new pane, e.g. "25%" or "80" cells
+	Window    string // Window index or name that holds worker panes
+}
+
+// paneLayout is the active layout, resolved from the config and overridden
+// by `gtw add` flags.
+var paneLayout = PaneLayout{Direction: SplitAuto, Window: "0"}
+
+var paneSizePattern = regexp.MustCompile(`^[1-9][0-9]*%?$`)
+
+// normalizeSplitDirection accepts the long names and tmux's -v/-h letters.
+func normalizeSplitDirection(direction string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(direction, "-")) {
+	case "", SplitAuto:
+		return SplitAuto, nil
+	case "v", SplitVertical:
+		return SplitVertical, nil
+	case "h", SplitHorizontal:
+		return SplitHorizontal, nil
+	}
+	return "", fmt.Errorf("invalid split direction %q (expected vertical, horizontal or auto)", direction)
+}
+
+// resolvePaneLayout builds the layout from the config. Invalid values are
+// reported and replaced by the defaults.
+func resolvePaneLayout(config *Config) PaneLayout {
+	layout := PaneLayout{Direction: SplitAuto, Window: "0"}
+
+	if direction, err := normalizeSplitDirection(config.SplitDirection); err != nil {
+		fmt.Printf("Warning: %v, using %s\n", err, SplitAuto)
+	} else {
+		layout.Direction = direction
+	}
+
+	if config.PaneSize != "" {
+		if paneSizePattern.MatchString(config.PaneSize) {
+			layout.Size = config.PaneSize
+		} else {
+			fmt.Printf("Warning: Invalid pane_size %q in config, ignoring\n", config.PaneSize)
+		}
+	}
+
+	if config.WorkerWindow != "" {
+		layout.Window = config.WorkerWindow
+	}
+	return layout
+}
+
+// applyLayoutFlags overrides the layout with `gtw add` flags.
+func applyLayoutFlags(layout PaneLayout, direction, size, window string) (PaneLayout, error) {
+	if direction != "" {
+		d, err := normalizeSplitDirection(direction)
+		if err != nil {
+			return layout, err
+		}
+		layout.Direction = d
+	}
+	if size != "" {
+		if !paneSizePattern.MatchString(size) {
+			return layout, fmt.Errorf("invalid pane size %q (expected e.g. 25%% or 80)", size)
+		}
+		layout.Size = size
+	}
+	if window != "" {
+		layout.Window = window
+	}
+	return layout, nil
+}
+
+// splitArgs returns the split-window arguments for one split direction.
+func (l PaneLayout) splitArgs(direction, target, dir, id string) []string {
+	flag := "-v"
+	if direction == SplitHorizontal {
+		flag = "-h"
+	}
+	// -d: gtw add selects the pane itself, depending on focus_on_add
+	args := []string{"split-window", "-d", flag, "-t", target, "-c", dir, "-e", workerIDEnv + "=" + id, "-P", "-F", "#{pane_index}:#{pane_id}"}
+	if l.Size != "" {
+		args = append(args, "-l", l.Size)
+	}
+	return args
+}
+
+// parsePaneIndexID parses "#{pane_index}:#{pane_id}" output.
+func parsePaneIndexID(output string) (int, string, error) {
+	parts := strings.Split(strings.TrimSpace(output), ":")
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("parsing pane info: %s", output)
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("parsing pane info: %s", output)
+	}
+	return index, parts[1], nil
+}
+
+// ensureWorkerWindow returns the target of the window that holds worker
+// panes. When a named window does not exist yet it is created, and its first
+// pane is returned so it can host worker id instead of being split.
+func ensureWorkerWindow(session, window, dir, id string) (string, int, string, error) {
+	target := fmt.Sprintf("%s:%s", session, window)
+	// display-message falls back to the current window, list-panes does not
+	if tmuxCommand("list-panes", "-t", target).Run() == nil {
+		return target, 0, "", nil
+	}
+
+	args := []string{"new-window", "-d", "-t", session + ":", "-c", dir, "-e", workerIDEnv + "=" + id, "-P", "-F", "#{pane_index}:#{pane_id}"}
+	if _, err := strconv.Atoi(window); err == nil {
+		args[3] = target
+	} else {
+		args = append(args, "-n", window)
+	}
+	output, err := tmuxCommand(args...).Output()
+	if err != nil {
+		return "", 0, "", fmt.Errorf("creating window %s: %v", window, err)
+	}
+	index, paneID, err := parsePaneIndexID(string(output))
+	if config, loadErr := loadConfig(); err == nil && loadErr == nil && !config.NoTmuxOptions {
+		if err := applyTmuxOptions(session); err != nil {
+			fmt.Printf("Warning: Could not apply tmux options: %v\n", err)
+		}
+	}
+	return target, index, paneID, err
+}
+
+// paneWindowIndex returns the index of the window that holds the pane.
+func paneWindowIndex(paneID string) int {
+	if mux.Name() != "tmux" {
+		return 0
+	}
+	output, err := tmuxCommand("display-message", "-t", paneID, "-p", "#{window_index}").Output()
+	if err != nil {
+		return 0
+	}
+	index, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return index
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSplitDirection(t *testing.T) {
+	tests := map[string]string{
+		"":           SplitAuto,
+		"auto":       SplitAuto,
+		"-v":         SplitVertical,
+		"vertical":   SplitVertical,
+		"h":          SplitHorizontal,
+		"Horizontal": SplitHorizontal,
+	}
+	for input, want := range tests {
+		if got, err := normalizeSplitDirection(input); err != nil || got != want {
+			t.Errorf("normalizeSplitDirection(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := normalizeSplitDirection("diagonal"); err == nil {
+		t.Error("Expected error for invalid direction")
+	}
+}
+
+func TestResolvePaneLayout(t *testing.T) {
+	layout := resolvePaneLayout(&Config{SplitDirection: "h", PaneSize: "25%", WorkerWindow: "agents"})
+	want := PaneLayout{Direction: SplitHorizontal, Size: "25%", Window: "agents"}
+	if layout != want {
+		t.Errorf("resolvePaneLayout() = %+v, want %+v", layout, want)
+	}
+
+	layout = resolvePaneLayout(&Config{PaneSize: "quarter"})
+	if layout != (PaneLayout{Direction: SplitAuto, Window: "0"}) {
+		t.Errorf("Expected defaults for invalid config, got %+v", layout)
+	}
+}
+
+func TestApplyLayoutFlags(t *testing.T) {
+	base := PaneLayout{Direction: SplitAuto, Window: "0"}
+
+	layout, err := applyLayoutFlags(base, "-h", "30%", "1")
+	if err != nil || layout != (PaneLayout{Direction: SplitHorizontal, Size: "30%", Window: "1"}) {
+		t.Errorf("applyLayoutFlags() = %+v, %v", layout, err)
+	}
+
+	if _, err := applyLayoutFlags(base, "", "0%", ""); err == nil {
+		t.Error("Expected error for invalid size")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	layout := PaneLayout{Direction: SplitHorizontal, Size: "25%"}
+	got := layout.splitArgs(SplitHorizontal, "proj:0", "/src/proj/worktree/a", "a")
+	want := []string{"split-window", "-d", "-h", "-t", "proj:0", "-c", "/src/proj/worktree/a", "-e", "GTW_WORKER_ID=a", "-P", "-F", "#{pane_index}:#{pane_id}", "-l", "25%"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePaneIndexID(t *testing.T) {
+	index, id, err := parsePaneIndexID("3:%12\n")
+	if err != nil || index != 3 || id != "%12" {
+		t.Errorf("parsePaneIndexID() = %d, %q, %v", index, id, err)
+	}
+	if _, _, err := parsePaneIndexID("garbage"); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	logDir       = ".gtw/logs"
+	logMaxSize   = 10 << 20 // Rotate worker logs at 10 MiB
+	logKeep      = 3        // Rotated files kept (<id>.log.1 ... <id>.log.3)
+	logPollEvery = 500 * time.Millisecond
+)
+
+// LogsOptions holds the settings given to `gtw logs`.
+type LogsOptions struct {
+	Follow bool
+	Tail   int
+	Raw    bool
+}
+
+func init() {
+	var opts LogsOptions
+	logsCmd := &cobra.Command{
+		Use:   "logs <worker-id>",
+		Short: "Show the recorded output of a worker",
+		Long: `Show the output recorded from a worker's pane. Recording uses tmux pipe-pane
+and is started by 'gtw logs enable <worker-id>', or on 'gtw add' when
+record_logs is set in the config. Logs are written to .gtw/logs/<worker-id>.log
+and rotated at 10 MiB.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { showLogs(args[0], opts) },
+	}
+	logsCmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Keep printing new output")
+	logsCmd.Flags().IntVar(&opts.Tail, "tail", 100, "Number of lines to show from the end (0 shows all)")
+	logsCmd.Flags().BoolVar(&opts.Raw, "raw", false, "Keep terminal escape sequences")
+
+	logsEnableCmd := &cobra.Command{
+		Use:   "enable <worker-id>",
+		Short: "Start recording a worker's output",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { setWorkerLogging(args[0], true) },
+	}
+
+	logsDisableCmd := &cobra.Command{
+		Use:   "disable <worker-id>",
+		Short: "Stop recording a worker's output",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { setWorkerLogging(args[0], false) },
+	}
+
+	// Runs inside tmux pipe-pane with the pane output on stdin
+	logsPipeCmd := &cobra.Command{
+		Use:              "pipe <file>",
+		Hidden:           true,
+		Args:             cobra.ExactArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			w := &rotatingWriter{path: args[0], maxSize: logMaxSize, keep: logKeep}
+			defer w.Close()
+			io.Copy(w, os.Stdin)
+		},
+	}
+
+	logsCmd.AddCommand(logsEnableCmd, logsDisableCmd, logsPipeCmd)
+	rootCmd.AddCommand(logsCmd)
+}
+
+// rotatingWriter appends to path and shifts it to path.1, path.2, ... once
+// it grows past maxSize, keeping at most keep rotated files.
+type rotatingWriter struct {
+	path    string
+	maxSize int64
+	keep    int
+	file    *os.File
+	size    int64
+}
+
+func (w *rotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *rotatingWriter) rotate() error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+	for i := w.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if w.keep > 0 {
+		if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		os.Remove(w.path)
+	}
+	return w.open()
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// workerLogPath returns the log file of a worker.
+func workerLogPath(config *Config, id string) string {
+	dir := config.ProjectPath
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return filepath.Join(dir, logDir, id+".log")
+}
+
+// startPaneLog pipes the pane's output into the worker's log. It does
+// nothing if the pane is already piped.
+func startPaneLog(config *Config, id, paneID string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	command := fmt.Sprintf("exec %s logs pipe %s", shellQuote(exe), shellQuote(workerLogPath(config, id)))
+	return tmuxCommand("pipe-pane", "-o", "-t", paneID, command).Run()
+}
+
+// stopPaneLog closes the pane's pipe.
+func stopPaneLog(paneID string) error {
+	return tmuxCommand("pipe-pane", "-t", paneID).Run()
+}
+
+// paneLogging reports whether the pane's output is being piped.
+func paneLogging(paneID string) bool {
+	output, err := tmuxCommand("display-message", "-t", paneID, "-p", "#{pane_pipe}").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "1"
+}
+
+func setWorkerLogging(id string, enable bool) {
+	if !requireTmux("logs") {
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := config.Workers[index]
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Printf("Error: Pane %s of worker '%s' not found\n", worker.PaneID, id)
+		return
+	}
+
+	if !enable {
+		if err := stopPaneLog(worker.PaneID); err != nil {
+			fmt.Printf("Error stopping log: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Stopped recording worker '%s'\n", id)
+		return
+	}
+
+	if paneLogging(worker.PaneID) {
+		fmt.Printf("Worker '%s' is already being recorded\n", id)
+		return
+	}
+	if err := startPaneLog(config, id, worker.PaneID); err != nil {
+		fmt.Printf("Error starting log: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Recording worker '%s' to %s\n", id, workerLogPath(config, id))
+}
+
+// ansiPattern matches CSI and OSC escape sequences and other single-character
+// escapes emitted by terminal programs.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>78cDEHM]`)
+
+// cleanLogText strips escape sequences and carriage returns so that logs
+// read like the terminal looked.
+func cleanLogText(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
+func showLogs(id string, opts LogsOptions) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	path := workerLogPath(config, id)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Printf("No log for worker '%s'. Run 'gtw logs enable %s' to start recording.\n", id, id)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error reading log: %v\n", err)
+		return
+	}
+
+	format := func(s string) string {
+		if opts.Raw {
+			return s
+		}
+		return cleanLogText(s)
+	}
+	text := format(string(data))
+	if opts.Tail > 0 && text != "" {
+		text = lastLines(text, opts.Tail)
+	}
+	fmt.Print(text)
+
+	if opts.Follow {
+		followLog(path, int64(len(data)), format)
+	}
+}
+
+// followLog prints whatever is appended to path after offset, starting
+// over when the file is rotated.
+func followLog(path string, offset int64, format func(string) string) {
+	out := bufio.NewWriter(os.Stdout)
+	for {
+		time.Sleep(logPollEvery)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			offset = 0 // Rotated
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		file.Seek(offset, io.SeekStart)
+		data, _ := io.ReadAll(file)
+		file.Close()
+		offset += int64(len(data))
+
+		out.WriteString(format(string(data)))
+		out.Flush()
+	}
+}
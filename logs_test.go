@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "w1.log")
+	w := &rotatingWriter{path: path, maxSize: 10, keep: 2}
+	for _, chunk := range []string{"aaaaaa", "bbbbbb", "cccccc", "dddddd"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	w.Close()
+
+	want := map[string]string{path: "dddddd", path + ".1": "cccccc", path + ".2": "bbbbbb"}
+	for file, content := range want {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to contain %q, got %q", file, content, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 rotated files to be kept")
+	}
+}
+
+func TestCleanLogText(t *testing.T) {
+	raw := "\x1b[?2004h\x1b]0;title\x07$ ls\r\n\x1b[01;34mdir\x1b[0m  file\r\n"
+	got := cleanLogText(raw)
+	if got != "$ ls\ndir  file\n" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+	if strings.Contains(got, "\x1b") {
+		t.Errorf("Expected escape sequences to be removed")
+	}
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,14 +23,144 @@ type Worker struct {
 	PaneID       string    `json:"pane_id"`       // Stable pane identifier
 	PaneIndex    int       `json:"pane_index"`    // For backwards compatibility
 	CreatedAt    time.Time `json:"created_at"`
-	Status       string    `json:"status"` // active, inactive
+	Status       WorkerState `json:"status"` // See state.go
+	Note         string    `json:"note,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	Tasks        []Task    `json:"tasks,omitempty"` // FIFO task queue
+	Branch       string     `json:"branch,omitempty"`      // Git branch (older workers: recorded when archived or detached)
+	Base         string     `json:"base,omitempty"`        // Commit or branch the worker's branch was created from
+	Profile      string     `json:"profile,omitempty"`     // Name of the profile in config.Profiles
+	Agent        string     `json:"agent,omitempty"`       // Agent started with `gtw add --agent`
+	Policy       string     `json:"policy,omitempty"`      // Name of the policy in config.Policies for permission prompts
+	TasksHeld    bool       `json:"tasks_held,omitempty"`  // Task queue held by a pause policy until `gtw task resume`
+	PausedFrom   WorkerState `json:"paused_from,omitempty"` // State before `gtw pause`, restored by `gtw resume`
+	AutoCheckpoint bool     `json:"auto_checkpoint,omitempty"` // Checkpointed by `gtw daemon`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	StateSince   *time.Time `json:"state_since,omitempty"`   // When Status was entered
+	StateHistory []StateChange `json:"state_history,omitempty"` // Latest state transitions
+	Health       *HealthCheck `json:"health,omitempty"`       // Evaluated by `gtw daemon` (see health.go)
+	Restarts     int          `json:"restarts,omitempty"`     // Restarts by the health check since it was last healthy for a while
+	RestartedAt  *time.Time   `json:"restarted_at,omitempty"`
+}
+
+// WorkerDetached marks a worker whose session was destroyed with
+// --keep-worktrees or --keep-branches.
+const WorkerDetached WorkerState = "detached"
+
+// AddOptions holds the optional settings for creating a worker.
+type AddOptions struct {
+	Tags      []string
+	Note      string
+	Split     string // Overrides split_direction
+	Size      string // Overrides pane_size
+	Window    string // Overrides worker_window
+	WaitReady bool   // Overrides wait_for_ready
+	Base      string // Create the branch from this commit or branch instead of HEAD
+	Profile   string // Profile whose init command replaces init_command
+	Issue     int    // Forge issue the worker is for (--issue)
+	Jira      string // Jira ticket the worker is for (--jira)
+	AutoCheckpoint bool // Let `gtw daemon` checkpoint the worker
+	Prompt    string // Text typed into the pane after the init command
+	Agent     string // Agent launched instead of the init command
+	Policy    string // Policy for the agent's permission prompts
+	Interactive bool // Ask for the settings (`gtw add -i`)
+	FromStash string // Apply this stash to the new worktree
+	ApplyPatch string // Apply this patch file to the new worktree
+	Metadata  map[string]string // Initial kv values
+	Focus     string // Overrides focus_on_add
+	NoFocus   bool   // Same as --focus never
 }
 
 type Config struct {
 	Workers         []Worker `json:"workers"`
+	Archived        []Worker `json:"archived,omitempty"`          // Workers parked with `gtw archive`
 	InitCommand     string   `json:"init_command,omitempty"`      // Command to execute when worker is created
+	InitSteps       []InitStep `json:"init_steps,omitempty"`      // Commands run before init_command
+	SetupScript     string   `json:"setup_script,omitempty"`      // Script run in the worktree before init_steps
+	Submodules      bool     `json:"submodules,omitempty"`        // Run `git submodule update --init --recursive` in new worktrees
+	LFS             bool     `json:"lfs,omitempty"`               // Run `git lfs pull` in new worktrees
+	DependencyCaches []DependencyCache `json:"dependency_caches,omitempty"` // Shared dependency directories linked or copied into new worktrees
+	WorkerEnv       map[string]string `json:"worker_env,omitempty"` // Environment exported in worker panes (e.g. GOMODCACHE)
+	WaitForReady    bool     `json:"wait_for_ready,omitempty"`    // Wait for the pane's shell before sending the init command
+	ReadyPattern    string   `json:"ready_pattern,omitempty"`     // Regexp the pane prints once the init command accepts a --prompt
+	UsageCommand    string   `json:"usage_command,omitempty"`     // Prints a worker's token/cost usage as JSON for `gtw stats --costs`
+	RecordLogs      bool     `json:"record_logs,omitempty"`       // Record worker output to .gtw/logs on add
 	WorktreePrefix  string   `json:"worktree_prefix,omitempty"`   // Directory prefix for worktrees (default: "worktree")
 	ProjectPath     string   `json:"project_path,omitempty"`      // Directory where session was initialized
+	CommandTimeout  string   `json:"command_timeout,omitempty"`   // Timeout for each git/tmux command (e.g. "60s")
+	RetryAttempts   int      `json:"retry_attempts,omitempty"`    // Attempts of a git/tmux command failing for a transient reason (default: 3)
+	RetryBackoff    string   `json:"retry_backoff,omitempty"`     // Wait before the first retry, doubled for each further one (default: "200ms")
+	Multiplexer     string   `json:"multiplexer,omitempty"`       // tmux (default), zellij or screen
+	Editor          string   `json:"editor,omitempty"`            // Editor used by `gtw open`
+	GitTUI          string   `json:"git_tui,omitempty"`           // Git TUI used by `gtw git` (default: lazygit)
+	WindowName      string   `json:"window_name,omitempty"`       // Name of the session's first window
+	NoTmuxOptions   bool     `json:"no_tmux_options,omitempty"`   // Do not set pane title options on tmux windows
+	PaneTitle       string   `json:"pane_title,omitempty"`        // Template for tmux pane titles (default: "{{.ID}}")
+	CheckIgnore     []string `json:"check_ignore,omitempty"`      // Pane title/worktree name patterns skipped by check and repair
+	SplitDirection  string   `json:"split_direction,omitempty"`   // auto (default), vertical or horizontal
+	PaneSize        string   `json:"pane_size,omitempty"`         // Size of new worker panes (e.g. "25%")
+	FocusOnAdd      string   `json:"focus_on_add,omitempty"`      // always (default), never or interactive: whether `gtw add` selects the new pane
+	WorkerWindow    string   `json:"worker_window,omitempty"`     // Window index or name for worker panes (default: "0")
+	WorkerTTL       string   `json:"worker_ttl,omitempty"`        // Age after which `gtw gc` removes clean workers (e.g. "72h")
+	SessionIdleTTL  string   `json:"session_idle_ttl,omitempty"`  // Time without pane output after which `gtw daemon` closes the session (e.g. "8h")
+	SessionIdleAction string `json:"session_idle_action,omitempty"` // destroy (default) or archive
+	ArtifactDirs    []string `json:"artifact_dirs,omitempty"`     // Directory names deleted by `gtw clean --artifacts`
+	BackupOnRemove  string   `json:"backup_on_remove,omitempty"`  // ask (default), always or never
+	BranchTemplate  string   `json:"branch_template,omitempty"`   // Branch name for new workers (e.g. "feature/{{.ID}}")
+	ShutdownGrace   string   `json:"shutdown_grace,omitempty"`    // Wait after SIGINT before SIGTERM on remove (default: "10s")
+	Profiles        map[string]Profile `json:"profiles,omitempty"` // Named init commands selected with `gtw add --profile`
+	Agents          map[string]string `json:"agents,omitempty"`   // Commands for `gtw add --agent` (e.g. {"claude": "claude --model opus"})
+	Policies        map[string]Policy `json:"policies,omitempty"` // What `gtw daemon` does at agent permission prompts, by name
+	Notifications   *NotificationConfig `json:"notifications,omitempty"` // Where `gtw daemon` reports finished, failed and waiting workers
+	Forge           *ForgeConfig `json:"forge,omitempty"`          // Forge for --issue and `gtw pr` (default: detected from origin)
+	Jira            *JiraConfig `json:"jira,omitempty"`            // Jira site for `gtw add --jira`
+	Checkpoint      *CheckpointConfig `json:"checkpoint,omitempty"` // Automatic checkpoints of workers with auto_checkpoint
+	TestCommand     string   `json:"test_command,omitempty"`      // Command run in each worktree by `gtw test`
+	Theme           map[string]string `json:"theme,omitempty"`     // Output colors by status (e.g. {"inactive": "magenta"})
+	GitCacheTTL     string   `json:"git_cache_ttl,omitempty"`     // How long `gtw list --git` reuses cached git metadata (default: "30s")
+	Counters        *Counters `json:"counters,omitempty"`         // Cumulative counts exposed as metrics
+	Workspaces      map[string]*Workspace `json:"workspaces,omitempty"` // Named workspaces created with --workspace
+	Store           string   `json:"store,omitempty"`             // Where workers and history are kept: json (default) or sqlite
+
+	defaultWorkers []Worker // Workers of the default workspace while another one is selected
+	stored         *storedState // State as loaded by the SQLite store
+}
+
+// RemoveOptions holds the settings given to `gtw remove` and `gtw destroy`.
+type RemoveOptions struct {
+	Yes      bool // Skip confirmation prompts
+	Force    bool // Skip safety checks and force-remove worktrees
+	Backup   bool // Always save unsaved work to a backup branch
+	NoBackup bool // Never offer a backup branch
+	Now      bool // Kill the pane without interrupting its program first
+
+	KillRunning bool // Stop programs running in the panes instead of refusing; unlike Force, keeps the other checks
+
+	KeepWorktrees bool // destroy: keep workers (and their worktrees) to recreate later
+	KeepBranches  bool // destroy: remove worktrees but keep workers and their branches
+}
+
+// ListOptions holds the settings given to `gtw list`.
+type ListOptions struct {
+	Tags     []string
+	Wide     bool
+	Archived bool
+	Format   string
+	Git      bool // Show branch, ahead/behind and uncommitted changes
+	Fast     bool // Use cached git metadata regardless of its age
+	NoCache  bool // Query git for every worker
+}
+
+// InitOptions holds the settings given to `gtw init`.
+type InitOptions struct {
+	Command        string
+	WorktreePrefix string
+	Multiplexer    string
+	Attach         bool
+	WindowName     string
+	NoTmuxOptions  bool
+	BareClone      string // Clone this URL as a bare repository into the current directory first
+	Gitignore      bool   // Ignore the worktree directory in .gitignore instead of .git/info/exclude
 }
 
 const configFile = ".tmux-workers.json"
@@ -39,66 +172,236 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
+	var timeout time.Duration
+	rootCmd.PersistentFlags().DurationVar(&timeout, "cmd-timeout", defaultCommandTimeout, "Timeout for each git/tmux command (overrides command_timeout in config)")
+	var project string
+	rootCmd.PersistentFlags().StringVar(&project, "project", "", "Run against a registered project (name) or project directory instead of the current directory")
+	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "Use a named workspace: its own session (<project>-<workspace>) and workers")
+	var noColor bool
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also with the NO_COLOR environment variable)")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if err := validateWorkspaceName(workspace); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if project != "" {
+			if err := enterProject(project); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		} else if err := enterProjectRoot(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			config = &Config{}
+		}
+		commandTimeout = resolveCommandTimeout(config, timeout, cmd.Root().PersistentFlags().Changed("cmd-timeout"))
+		retryPolicy = resolveRetryPolicy(config)
+		mux = resolveMultiplexer(config.Multiplexer)
+		paneLayout = resolvePaneLayout(config)
+		nonInteractive = resolveNonInteractive()
+		colorEnabled = resolveColor(noColor)
+		if err := validateTheme(config.Theme); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		colorTheme = resolveTheme(config)
+
+		// Pane IDs change when the tmux server restarts; follow the panes
+		if err == nil && revalidatePanes(config) {
+			if err := saveConfig(config); err != nil {
+				fmt.Printf("Warning: Could not save re-linked panes: %v\n", err)
+			}
+		}
+	}
+
 	// Init command with flags
-	var initCommand string
-	var initWorktreePrefix string
+	var initOpts InitOptions
 	
 	initCmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize tmux session",
 		Long:  "Initialize a new tmux session with configurable initialization command and worktree prefix",
 		Run: func(cmd *cobra.Command, args []string) {
-			initSession(initCommand, initWorktreePrefix)
+			initSession(initOpts)
 		},
 	}
 	
-	initCmd.Flags().StringVar(&initCommand, "command", "", "Default initialization command")
-	initCmd.Flags().StringVar(&initWorktreePrefix, "worktree-prefix", "", "Prefix for worktree directories (default: 'worktree')")
+	initCmd.Flags().StringVar(&initOpts.Command, "command", "", "Default initialization command")
+	initCmd.Flags().StringVar(&initOpts.WorktreePrefix, "worktree-prefix", "", "Prefix for worktree directories (default: 'worktree')")
+	initCmd.Flags().StringVar(&initOpts.Multiplexer, "multiplexer", "", "Terminal multiplexer backend: tmux (default), zellij or screen")
+	initCmd.Flags().BoolVar(&initOpts.Attach, "attach", false, "Attach to the session right after creating it")
+	initCmd.Flags().StringVar(&initOpts.WindowName, "window-name", "", "Name of the session's first window (tmux)")
+	initCmd.Flags().BoolVar(&initOpts.NoTmuxOptions, "no-tmux-options", false, "Do not set pane-border-status, pane-border-format or renaming options on the session (tmux)")
+	initCmd.Flags().BoolVar(&initOpts.Gitignore, "gitignore", false, "Add the worktree directory to .gitignore without asking (default: .git/info/exclude)")
+	initCmd.Flags().StringVar(&initOpts.BareClone, "bare-clone", "", "Clone this repository URL as a bare repository (.bare) into the current directory and use it for worktrees")
 	
 	rootCmd.AddCommand(initCmd)
 	
 	// Other commands
-	rootCmd.AddCommand(&cobra.Command{
+	var destroyOpts RemoveOptions
+	destroyCmd := &cobra.Command{
 		Use:   "destroy",
 		Short: "Destroy tmux session",
-		Run:   func(cmd *cobra.Command, args []string) { destroySession() },
-	})
+		Run:   func(cmd *cobra.Command, args []string) { destroySession(destroyOpts) },
+	}
+	destroyCmd.Flags().BoolVarP(&destroyOpts.Yes, "yes", "y", false, "Do not ask for confirmation")
+	destroyCmd.Flags().BoolVarP(&destroyOpts.Force, "force", "f", false, "Destroy immediately, even if workers are running programs")
+	destroyCmd.Flags().BoolVar(&destroyOpts.KillRunning, "kill-running", false, "Stop programs running in the panes instead of refusing; still asks for confirmation")
+	destroyCmd.Flags().BoolVar(&destroyOpts.KeepWorktrees, "keep-worktrees", false, "Only kill the session; keep the workers as detached to recreate with 'gtw attach --recreate'")
+	destroyCmd.Flags().BoolVar(&destroyOpts.KeepBranches, "keep-branches", false, "Also remove the worktrees, but keep the workers as detached and their branches to recreate later")
+	destroyCmd.MarkFlagsMutuallyExclusive("keep-worktrees", "keep-branches")
+	rootCmd.AddCommand(destroyCmd)
 	
+	var addOpts AddOptions
 	addCmd := &cobra.Command{
 		Use:   "add <worker-id>",
 		Short: "Create a new worker",
-		Args:  cobra.ExactArgs(1),
-		Run:   func(cmd *cobra.Command, args []string) { addWorker(args[0]) },
+		Long: `Create a new worker: a git worktree and branch, and a pane running the init
+command. With --issue, the worker ID defaults to issue-<number>, the issue
+title becomes its note and the issue URL is saved as its issue_url key.
+With --jira, the ID is built from the ticket key and summary (e.g.
+proj-123-fix-login) and the ticket is saved as its jira_key and jira_url
+keys.`,
+		Args:  cobra.RangeArgs(0, 1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if addOpts.NoFocus {
+				addOpts.Focus = FocusNever
+			}
+			if err := validateFocusMode(addOpts.Focus); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			layout, err := applyLayoutFlags(paneLayout, addOpts.Split, addOpts.Size, addOpts.Window)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			paneLayout = layout
+			id := ""
+			if len(args) == 1 {
+				id = args[0]
+			}
+			if addOpts.Issue > 0 {
+				if id, err = applyIssue(id, &addOpts); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			if addOpts.Jira != "" {
+				if id, err = applyJira(id, &addOpts); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			if addOpts.Interactive && nonInteractive {
+				fmt.Printf("Error: -i needs a terminal (unset %s or run gtw from a terminal)\n", nonInteractiveEnv)
+				return
+			}
+			if addOpts.Interactive {
+				config, err := loadConfig()
+				if err != nil {
+					fmt.Printf("Error loading config: %v\n", err)
+					return
+				}
+				prompter := &wizardPrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+				if id, addOpts, err = runAddWizard(prompter, config, id, addOpts, checkBaseRef); err != nil {
+					fmt.Printf("\n%v\n", err)
+					return
+				}
+			}
+			if id == "" {
+				fmt.Println("Error: A worker ID is required (or use --issue, --jira or -i)")
+				return
+			}
+			addWorker(id, addOpts)
+		},
 	}
+	addCmd.Flags().StringArrayVar(&addOpts.Tags, "tag", nil, "Tag to attach to the worker (repeatable)")
+	addCmd.Flags().StringVar(&addOpts.Note, "note", "", "Free-form note describing what the worker is doing")
+	addCmd.Flags().StringVar(&addOpts.Split, "split", "", "Split direction: vertical (-v, stacked), horizontal (-h, side by side) or auto")
+	addCmd.Flags().StringVar(&addOpts.Size, "size", "", "Size of the new pane (e.g. 25% or 80 cells)")
+	addCmd.Flags().StringVar(&addOpts.Window, "window", "", "Window index or name for the worker pane (created if missing)")
+	addCmd.Flags().StringVar(&addOpts.Focus, "focus", "", "Select the new pane: always, never or interactive (only when run from a terminal)")
+	addCmd.Flags().BoolVar(&addOpts.NoFocus, "no-focus", false, "Stay on the current pane (same as --focus never)")
+	addCmd.MarkFlagsMutuallyExclusive("focus", "no-focus")
+	addCmd.Flags().BoolVar(&addOpts.WaitReady, "wait-ready", false, "Wait until the pane's shell is ready before sending the init command")
+	addCmd.Flags().StringVar(&addOpts.Base, "base", "", "Commit or branch to create the worker's branch from (default: HEAD)")
+	addCmd.Flags().StringVar(&addOpts.Profile, "profile", "", "Profile from the config whose init command to run instead of init_command")
+	addCmd.Flags().IntVar(&addOpts.Issue, "issue", 0, "Create the worker for an issue on the project's forge (GitHub, GitLab or Gitea)")
+	addCmd.Flags().StringVar(&addOpts.Jira, "jira", "", "Create the worker for a Jira ticket (e.g. PROJ-123)")
+	addCmd.MarkFlagsMutuallyExclusive("issue", "jira")
+	addCmd.Flags().StringVar(&addOpts.Prompt, "prompt", "", "Text to type into the pane after the init command (e.g. a task for the agent)")
+	addCmd.Flags().StringVar(&addOpts.Agent, "agent", "", "Launch this agent ("+strings.Join(agentNames(), ", ")+") and send --prompt once it is ready")
+	addCmd.Flags().StringVar(&addOpts.Policy, "policy", "", "Policy from the config for the agent's permission prompts (see 'gtw policy')")
+	addCmd.Flags().BoolVarP(&addOpts.Interactive, "interactive", "i", false, "Ask for the worker ID, base branch, profile and initial prompt")
+	addCmd.Flags().StringVar(&addOpts.FromStash, "from-stash", "", "Apply this stash (e.g. stash@{0}) to the new worktree")
+	addCmd.Flags().StringVar(&addOpts.ApplyPatch, "apply-patch", "", "Apply this patch file (git diff output) to the new worktree")
+	addCmd.Flags().BoolVar(&addOpts.AutoCheckpoint, "checkpoint", false, "Let 'gtw daemon' commit the worker's uncommitted work periodically (see 'gtw checkpoint')")
 	rootCmd.AddCommand(addCmd)
 	
-	rootCmd.AddCommand(&cobra.Command{
+	var listOpts ListOptions
+	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all workers",
-		Run:   func(cmd *cobra.Command, args []string) { listWorkers() },
-	})
+		Run:   func(cmd *cobra.Command, args []string) { listWorkers(listOpts) },
+	}
+	listCmd.Flags().StringArrayVar(&listOpts.Tags, "tag", nil, "Only show workers with this tag (repeatable, all must match)")
+	listCmd.Flags().BoolVar(&listOpts.Wide, "wide", false, "Also show CPU/memory of each pane's processes and worktree disk usage")
+	listCmd.Flags().BoolVar(&listOpts.Archived, "archived", false, "List archived workers instead")
+	listCmd.Flags().StringVar(&listOpts.Format, "format", "", "Print each worker with a Go template (e.g. '{{.ID}}\\t{{.PaneID}}\\t{{.Status}}')")
+	listCmd.Flags().BoolVar(&listOpts.Git, "git", false, "Show each worker's branch, commits ahead/behind its base and uncommitted changes")
+	listCmd.Flags().BoolVar(&listOpts.Fast, "fast", false, "With --git, use cached git metadata however old (see 'gtw refresh')")
+	listCmd.Flags().BoolVar(&listOpts.NoCache, "no-cache", false, "With --git, query git for every worker instead of using the cache")
+	listCmd.MarkFlagsMutuallyExclusive("fast", "no-cache")
+	rootCmd.AddCommand(listCmd)
 	
+	var removeOpts RemoveOptions
 	removeCmd := &cobra.Command{
 		Use:   "remove <worker-id>",
 		Short: "Remove a worker",
+		Long:  "Remove a worker's pane and worktree. Asks for confirmation when the worktree has uncommitted changes.",
 		Args:  cobra.ExactArgs(1),
-		Run:   func(cmd *cobra.Command, args []string) { removeWorker(args[0]) },
-	}
+		Run:   func(cmd *cobra.Command, args []string) { removeWorker(args[0], removeOpts) },
+	}
+	removeCmd.Flags().BoolVarP(&removeOpts.Yes, "yes", "y", false, "Do not ask for confirmation")
+	removeCmd.Flags().BoolVarP(&removeOpts.Force, "force", "f", false, "Remove even if the pane is running a program, and force-remove the worktree without checking for uncommitted changes")
+	removeCmd.Flags().BoolVar(&removeOpts.KillRunning, "kill-running", false, "Stop a program running in the pane instead of refusing; uncommitted changes are still checked")
+	removeCmd.Flags().BoolVar(&removeOpts.Backup, "backup", false, "Save uncommitted/unpushed work to a gtw/backup/<id>/<timestamp> branch first")
+	removeCmd.Flags().BoolVar(&removeOpts.NoBackup, "no-backup", false, "Do not offer a backup branch")
+	removeCmd.Flags().BoolVar(&removeOpts.Now, "now", false, "Kill the pane immediately instead of interrupting its program first")
 	rootCmd.AddCommand(removeCmd)
 	
+	var statusFormat string
 	statusCmd := &cobra.Command{
-		Use:   "status <worker-id>",
-		Short: "Show worker status",
-		Args:  cobra.ExactArgs(1),
-		Run:   func(cmd *cobra.Command, args []string) { showWorkerStatus(args[0]) },
+		Use:   "status [worker-id]",
+		Short: "Show worker status, or a session-wide summary without a worker ID",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				if statusFormat != "" {
+					fmt.Println("Error: --format requires a worker ID")
+					return
+				}
+				showSessionSummary()
+				return
+			}
+			showWorkerStatus(args[0], statusFormat)
+		},
 	}
+	statusCmd.Flags().StringVar(&statusFormat, "format", "", "Print the worker with a Go template (e.g. '{{.Status}}')")
 	rootCmd.AddCommand(statusCmd)
 	
-	rootCmd.AddCommand(&cobra.Command{
+	var attachRecreate bool
+	attachCmd := &cobra.Command{
 		Use:   "attach",
 		Short: "Attach to the tmux session",
-		Run:   func(cmd *cobra.Command, args []string) { attachSession() },
-	})
+		Run:   func(cmd *cobra.Command, args []string) { attachSession(attachRecreate) },
+	}
+	attachCmd.Flags().BoolVar(&attachRecreate, "recreate", false, "Rebuild the session and worker panes from config without asking if the session is gone")
+	rootCmd.AddCommand(attachCmd)
 	
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "detach",
@@ -106,17 +409,18 @@ func init() {
 		Run:   func(cmd *cobra.Command, args []string) { detachSession() },
 	})
 	
-	rootCmd.AddCommand(&cobra.Command{
+	var checkJSON bool
+	checkCmd := &cobra.Command{
 		Use:   "check",
 		Short: "Check worktree/pane consistency",
-		Run:   func(cmd *cobra.Command, args []string) { checkConsistency() },
-	})
-	
-	rootCmd.AddCommand(&cobra.Command{
-		Use:   "repair",
-		Short: "Repair worktree/pane inconsistencies",
-		Run:   func(cmd *cobra.Command, args []string) { repairInconsistencies() },
-	})
+		Long: `Check that every worker has a pane and a worktree, and that no pane or
+worktree is left without a worker.
+
+Exit codes: 0 consistent, 1 inconsistencies found, 2 the check could not run.`,
+		Run: func(cmd *cobra.Command, args []string) { os.Exit(checkConsistency(checkJSON)) },
+	}
+	checkCmd.Flags().BoolVar(&checkJSON, "json", false, "Print the inconsistencies as JSON")
+	rootCmd.AddCommand(checkCmd)
 	
 	// Config command with subcommands
 	configCmd := &cobra.Command{
@@ -125,21 +429,99 @@ func init() {
 		Run:   func(cmd *cobra.Command, args []string) { showConfig() },
 	}
 	
+	var presetName, presetProfile string
 	configSetCmd := &cobra.Command{
-		Use:   "set <command>",
-		Short: "Set initialization command",
-		Args:  cobra.ExactArgs(1),
-		Run:   func(cmd *cobra.Command, args []string) { setConfigCommand(args[0]) },
+		Use:   "set [<key>] <value>",
+		Short: "Set a config value (the initialization command when no key is given)",
+		Long: `Set a config value by key, e.g. worktree_prefix, checkpoint.interval or
+profiles.fast.init_command (see 'gtw config schema'). Text values are taken
+as they are; other values are written as JSON: true, 5, '["node_modules"]'.
+The change is checked like 'gtw config validate' before it is saved.
+
+With only a value, the initialization command is set. With --preset, the
+init command and ready pattern of a built-in preset are set (see 'gtw config
+presets'); an optional value replaces the preset's command.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if presetName != "" {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.RangeArgs(1, 2)(cmd, args)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if presetName != "" {
+				command := ""
+				if len(args) == 1 {
+					command = args[0]
+				}
+				if !setPresetCommand(presetName, command, presetProfile) {
+					os.Exit(1)
+				}
+				return
+			}
+			if presetProfile != "" {
+				fmt.Fprintln(os.Stderr, "Error: --profile needs --preset; use 'gtw config set profiles.<name>.init_command <command>'")
+				os.Exit(1)
+			}
+			if len(args) == 1 {
+				setConfigCommand(args[0])
+				return
+			}
+			if !setConfigKeyCommand(args[0], args[1]) {
+				os.Exit(1)
+			}
+		},
 	}
 	
+	configSetCmd.Flags().StringVar(&presetName, "preset", "", "Apply a built-in preset (claude, aider, codex, npm-dev, pytest-watch)")
+	configSetCmd.Flags().StringVar(&presetProfile, "profile", "", "Apply the preset to this profile instead of the default init command")
+	
 	configGetCmd := &cobra.Command{
-		Use:   "get",
-		Short: "Get initialization command",
-		Run:   func(cmd *cobra.Command, args []string) { getConfigCommand() },
+		Use:   "get [<key>]",
+		Short: "Get a config value (the initialization command when no key is given)",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				getConfigCommand()
+				return
+			}
+			if !getConfigKeyCommand(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	configUnsetCmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a config value, restoring its default",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !unsetConfigKeyCommand(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	configEditCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config in $VISUAL or $EDITOR, validating it before saving",
+		Long: `Open a copy of the config in $VISUAL or $EDITOR (default vi). When the
+editor exits the copy is checked like 'gtw config validate' and saved only
+if it has no new errors; otherwise you can edit it again or discard it.
+GUI editors must wait for the file to be closed (e.g. EDITOR="code -w").`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !editConfig() {
+				os.Exit(1)
+			}
+		},
 	}
 	
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configPresetsCommand())
+	configCmd.AddCommand(configCheckCommands()...)
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -152,24 +534,26 @@ func main() {
 
 
 func loadConfig() (*Config, error) {
-	config := &Config{Workers: []Worker{}}
-
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		// Initialize with default values
+		config := &Config{Workers: []Worker{}}
 		config.InitCommand = getDefaultInitCommand()
 		config.WorktreePrefix = getDefaultWorktreePrefix()
+		config.selectWorkspace(workspace)
 		return config, nil
 	}
 
-	data, err := os.ReadFile(configFile)
+	config, err := readConfigFile()
 	if err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(data, config)
+	store, err := newStore(config.Store, ".")
 	if err != nil {
 		return nil, err
 	}
+	if err := store.Load(config); err != nil {
+		return nil, fmt.Errorf("could not load state from the %s store: %v", store.Name(), err)
+	}
 
 	// Ensure init command has default if empty
 	if config.InitCommand == "" {
@@ -181,9 +565,30 @@ func loadConfig() (*Config, error) {
 		config.WorktreePrefix = getDefaultWorktreePrefix()
 	}
 
+	config.selectWorkspace(workspace)
+	config.migrateStates()
 	return config, err
 }
 
+// resolveCommandTimeout picks the --cmd-timeout flag when given, otherwise the
+// configured command_timeout, otherwise the default.
+func resolveCommandTimeout(config *Config, flagValue time.Duration, flagSet bool) time.Duration {
+	if flagSet {
+		return flagValue
+	}
+
+	if config.CommandTimeout == "" {
+		return defaultCommandTimeout
+	}
+
+	timeout, err := time.ParseDuration(config.CommandTimeout)
+	if err != nil {
+		fmt.Printf("Warning: Invalid command_timeout %q in config, using %s\n", config.CommandTimeout, defaultCommandTimeout)
+		return defaultCommandTimeout
+	}
+	return timeout
+}
+
 func getDefaultInitCommand() string {
 	return "echo 'Hello, worker!'"
 }
@@ -192,35 +597,108 @@ func getDefaultWorktreePrefix() string {
 	return "worktree"
 }
 
-func executeInitCommand(config *Config, worktreePath, paneID string) {
-	// Execute initialization command
-	if config.InitCommand != "" {
+func executeInitCommand(config *Config, worktreePath, paneID string, waitReady bool) {
+	workerID, initCommand, profile := "", config.InitCommand, ""
+	var worker *Worker
+	for i := range config.Workers {
+		if config.Workers[i].PaneID == paneID {
+			worker = &config.Workers[i]
+			workerID = worker.ID
+			initCommand = config.initCommandFor(*worker)
+			profile = worker.Profile
+		}
+	}
+	exports := envExports(config.workerEnvFor(profile))
+	setState := func(state WorkerState, reason string) {
+		if worker != nil {
+			worker.setState(state, reason)
+		}
+	}
+
+	// Execute setup script, init steps and initialization command
+	if initCommand != "" || config.SetupScript != "" || len(config.InitSteps) > 0 || exports != "" {
+		if waitReady || config.WaitForReady {
+			// A command typed before the shell starts can be lost
+			if err := waitForReady(paneID, worktreePath, readyTimeout); err != nil {
+				fmt.Printf("Warning: %v; sending the init command anyway\n", err)
+			}
+		}
+
 		fmt.Printf("Initializing worker pane %s...\n", paneID)
 		
-		// Get absolute path to worktree directory
-		absWorktreePath, err := filepath.Abs(worktreePath)
-		if err != nil {
-			absWorktreePath = worktreePath
+		setupScript := ""
+		if config.SetupScript != "" {
+			setupScript = setupScriptCommand(config)
 		}
-		
-		// Change to worktree directory and execute init command
-		command := fmt.Sprintf("cd %s && %s", absWorktreePath, config.InitCommand)
-		cmd := exec.Command("tmux", "send-keys", "-t", paneID, command, "Enter")
-		if err := cmd.Run(); err != nil {
+
+		// Change to worktree directory (as seen by the pane's shell) and run the chain
+		command := buildInitCommand(muxPath(worktreePath), setupScript, config.InitSteps, initCommand)
+		if exports != "" {
+			// Exported first so that the setup script and steps see them too
+			command = exports + " && " + command
+		}
+		if err := sendWithRetry(paneID, command); err != nil {
 			fmt.Printf("Warning: Worker initialization failed: %v\n", err)
+			config.counters().InitFailures++
+			recordEvent(EventInitFailed, workerID, err.Error())
+			setState(StateFailed, "init command could not be sent")
+			return
 		}
+		recordEvent(EventInitRun, workerID, command)
+		setState(StateInitializing, "init command sent")
+		return
+	}
+	setState(StateReady, "nothing to initialize")
+}
+
+// readConfigFile reads the config file as stored, without defaults.
+func readConfigFile() (*Config, error) {
+	config := &Config{Workers: []Worker{}}
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
 	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 func saveConfig(config *Config) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeConfig(config)
+}
+
+// writeConfig saves config; the caller holds the config lock.
+func writeConfig(config *Config) error {
+	store, err := newStore(config.Store, ".")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(configFile, data, 0644)
+	return store.Save(config.persisted(workspace))
+}
+
+var workerIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateWorkerID rejects IDs that are unsafe as a directory name, pane
+// title and branch name.
+func validateWorkerID(id string) error {
+	if !workerIDPattern.MatchString(id) || strings.Contains(id, "..") || strings.HasSuffix(id, ".lock") {
+		return fmt.Errorf("invalid worker ID '%s' (use letters, digits, '.', '-' and '_')", id)
+	}
+	return nil
 }
 
-func addWorker(id string) {
+func addWorker(id string, opts AddOptions) {
+	if err := validateWorkerID(id); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
 	// Check if we're currently inside a worktree directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -228,19 +706,23 @@ func addWorker(id string) {
 		return
 	}
 	
-	// Check if current directory is inside a worktree path
-	if strings.Contains(cwd, "/worktree/") {
-		fmt.Printf("Error: Cannot create worker from within a worktree directory (%s)\n", cwd)
-		fmt.Printf("Please run this command from the project root directory\n")
-		return
-	}
-
 	config, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return
 	}
 
+	// Check if gtw was started inside a worktree path
+	startDir := invocationDir
+	if startDir == "" {
+		startDir = cwd
+	}
+	if isInsideWorktreeDir(startDir, config.ProjectPath, resolvedWorktreePrefix(config)) {
+		fmt.Printf("Error: Cannot create worker from within a worktree directory (%s)\n", startDir)
+		fmt.Printf("Please run this command from the project root directory\n")
+		return
+	}
+
 	// Check if we're in the correct project directory
 	if config.ProjectPath != "" {
 		if cwd != config.ProjectPath {
@@ -259,111 +741,106 @@ func addWorker(id string) {
 			return
 		}
 	}
+	if findArchivedIndex(config, id) != -1 {
+		fmt.Printf("Worker '%s' is archived. Run 'gtw unarchive %s' to restore it\n", id, id)
+		return
+	}
+	if name, exists := config.otherWorkspaceOf(id); exists {
+		fmt.Printf("Worker '%s' already exists in workspace '%s'\n", id, name)
+		return
+	}
+
+	if opts.Profile != "" {
+		if _, ok := config.Profiles[opts.Profile]; !ok {
+			fmt.Printf("Error: Unknown profile '%s' (available: %s)\n", opts.Profile, strings.Join(config.profileNames(), ", "))
+			return
+		}
+	}
+
+	if opts.Agent != "" {
+		if _, err := findAgent(config, opts.Agent); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+
+	if opts.Policy != "" {
+		if _, ok := config.Policies[opts.Policy]; !ok {
+			fmt.Printf("Error: Unknown policy '%s'\n", opts.Policy)
+			return
+		}
+	}
+
+	if err := checkInitialChanges(opts); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	branch, err := workerBranchName(config, id)
+	if err != nil {
+		fmt.Printf("Error: Invalid branch_template: %v\n", err)
+		return
+	}
 
 	fmt.Printf("Creating worker '%s'...\n", id)
 
 	// Create worktree path using configured prefix
-	worktreePath := filepath.Join("./"+config.WorktreePrefix, id)
+	worktreePath := workerWorktreePath(config, id)
+	if err := ensureWorktreesExcluded(config); err != nil {
+		fmt.Printf("Warning: Could not exclude the worktree directory from git: %v\n", err)
+	}
 
 	// Step 1: Create git worktree
-	fmt.Printf("Creating git worktree at %s...\n", worktreePath)
+	fmt.Printf("Creating git worktree at %s (branch: %s)...\n", worktreePath, branch)
 	
-	// Create worktree with new branch (simpler approach)
-	cmd := exec.Command("git", "worktree", "add", "-b", id, worktreePath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// If branch already exists, try without creating new branch
-		fmt.Printf("Branch might exist, trying without -b flag...\n")
-		cmd = exec.Command("git", "worktree", "add", worktreePath, id)
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			fmt.Printf("Error creating git worktree: %v\n", err)
-			fmt.Printf("Git output: %s\n", string(output))
-			return
-		}
+	if output, err := createWorktree(branch, worktreePath, opts.Base); err != nil {
+		fmt.Printf("Error creating git worktree: %v\n", err)
+		fmt.Printf("Git output: %s\n", string(output))
+		return
+	}
+	prepareWorktree(config, worktreePath)
+	if err := applyInitialChanges(worktreePath, opts); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Printf("Removing worktree %s (branch '%s' is kept)\n", worktreePath, branch)
+		gitCommand("worktree", "remove", "--force", worktreePath).Run()
+		return
 	}
 
 	// Step 2: Check session exists and create window
 	sessionName := getSessionName()
 	if sessionName == "" {
-		exec.Command("git", "worktree", "remove", worktreePath).Run()
+		gitCommand("worktree", "remove", worktreePath).Run()
 		return
 	}
 	
 	// Check if session exists
-	cmd = exec.Command("tmux", "has-session", "-t", sessionName)
-	if cmd.Run() != nil {
+	if !mux.HasSession(sessionName) {
 		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
-		exec.Command("git", "worktree", "remove", worktreePath).Run()
+		gitCommand("worktree", "remove", worktreePath).Run()
 		return
 	}
 	
-	// Always use window 0
-	windowIndex := 0
-	windowTarget := fmt.Sprintf("%s:%d", sessionName, windowIndex)
-	
-	fmt.Printf("Adding pane to window %d in session '%s'...\n", windowIndex, sessionName)
-	
-	// Step 3: Create a new pane by splitting window 0
-	// Try vertical split first, then horizontal if that fails
-	cmd = exec.Command("tmux", "split-window", "-v", "-t", windowTarget, "-c", worktreePath)
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Vertical split failed, trying horizontal split...\n")
-		
-		// Try horizontal split as fallback
-		cmd = exec.Command("tmux", "split-window", "-h", "-t", windowTarget, "-c", worktreePath)
-		if err := cmd.Run(); err != nil {
-			// Get detailed error information
-			output, _ := cmd.CombinedOutput()
-			fmt.Printf("Error creating pane (both splits failed): %v\n", err)
-			fmt.Printf("Tmux output: %s\n", string(output))
-			
-			// Check current window size and pane count
-			sizeCmd := exec.Command("tmux", "display-message", "-t", windowTarget, "-p", "#{window_width}x#{window_height}")
-			if sizeOutput, sizeErr := sizeCmd.Output(); sizeErr == nil {
-				fmt.Printf("Current window size: %s", string(sizeOutput))
-			}
-			
-			paneCountCmd := exec.Command("tmux", "list-panes", "-t", windowTarget)
-			if paneOutput, paneErr := paneCountCmd.Output(); paneErr == nil {
-				paneCount := len(strings.Split(strings.TrimSpace(string(paneOutput)), "\n"))
-				fmt.Printf("Current pane count: %d\n", paneCount)
-			}
-			
-			exec.Command("git", "worktree", "remove", worktreePath).Run()
-			return
-		}
-	}
+	fmt.Printf("Adding pane to window %s in session '%s'...\n", paneLayout.Window, sessionName)
 	
-	// Get the newly created pane ID and index (the currently active pane after split)
-	cmd = exec.Command("tmux", "display-message", "-t", windowTarget, "-p", "#{pane_index}:#{pane_id}")
-	paneOutput, err := cmd.Output()
+	// Step 3: Create a new pane (titled with the worker ID) in the worker window
+	paneIndexNum, paneID, err := mux.NewPane(sessionName, worktreePath, id)
 	if err != nil {
-		fmt.Printf("Error getting new pane info: %v\n", err)
-		exec.Command("git", "worktree", "remove", worktreePath).Run()
+		fmt.Printf("Error creating pane: %v\n", err)
+		gitCommand("worktree", "remove", worktreePath).Run()
 		return
 	}
 	
-	parts := strings.Split(strings.TrimSpace(string(paneOutput)), ":")
-	if len(parts) != 2 {
-		fmt.Printf("Error parsing pane info: %s\n", string(paneOutput))
-		exec.Command("git", "worktree", "remove", worktreePath).Run()
-		return
-	}
-	
-	var paneIndexNum int
-	fmt.Sscanf(parts[0], "%d", &paneIndexNum)
-	paneID := parts[1]
-	
 	fmt.Printf("Created pane %d (ID: %s), setting up workspace...\n", paneIndexNum, paneID)
+	applyDependencyCaches(config, opts.Profile, worktreePath)
+	windowIndex := paneWindowIndex(paneID)
 	
-	// Set pane title using pane ID
-	exec.Command("tmux", "select-pane", "-t", paneID, "-T", fmt.Sprintf("%s", id)).Run()
-	
-	// Focus on the new pane
-	exec.Command("tmux", "select-pane", "-t", paneID).Run()
+	// Focus on the new pane, unless gtw runs in the background or in a pane of the session
+	if shouldFocus(resolveFocusMode(config, opts, insideSession(sessionName)), !nonInteractive) {
+		mux.Focus(paneID)
+	}
 
 	// Add worker to config
+	now := time.Now()
 	worker := Worker{
 		ID:           id,
 		WorktreePath: worktreePath,
@@ -371,60 +848,291 @@ func addWorker(id string) {
 		WindowIndex:  windowIndex,
 		PaneID:       paneID,
 		PaneIndex:    paneIndexNum,
-		CreatedAt:    time.Now(),
-		Status:       "active",
+		CreatedAt:    now,
+		Status:       StateCreating,
+		StateSince:   &now,
+		Note:         opts.Note,
+		Tags:         normalizeTags(opts.Tags),
+		Branch:       branch,
+		Base:         opts.Base,
+		Profile:      opts.Profile,
+		Agent:        opts.Agent,
+		Policy:       opts.Policy,
+		AutoCheckpoint: opts.AutoCheckpoint,
 	}
 
 	config.Workers = append(config.Workers, worker)
+	config.counters().WorkersAdded++
+	labelWorkerPane(config, worker)
+
+	if err := createWorkerState(config, id); err != nil {
+		fmt.Printf("Warning: Could not create state directory: %v\n", err)
+	}
+	if len(opts.Metadata) > 0 {
+		if err := setWorkerMetadata(config, id, opts.Metadata); err != nil {
+			fmt.Printf("Warning: Could not save worker metadata: %v\n", err)
+		}
+	}
+
+	// Record the pane output from the start
+	if config.RecordLogs && mux.Name() == "tmux" {
+		if err := startPaneLog(config, id, paneID); err != nil {
+			fmt.Printf("Warning: Could not start recording output: %v\n", err)
+		}
+	}
+
+	// Execute initialization command
+	executeInitCommand(config, worktreePath, paneID, opts.WaitReady)
+	if opts.Prompt != "" {
+		sendPrompt(config, worker, opts.Prompt)
+	}
 
 	if err := saveConfig(config); err != nil {
 		fmt.Printf("Error saving config: %v\n", err)
 		return
 	}
 
-	// Execute initialization command
-	executeInitCommand(config, worktreePath, paneID)
-
+	recordEvent(EventWorkerAdded, id, worktreePath)
+	sendNotification(config, workerNotification(worker, NotifyAdded,
+		fmt.Sprintf("gtw: %s added", id), fmt.Sprintf("Worker '%s' started on branch %s", id, branch)))
 	fmt.Printf("Worker '%s' created successfully!\n", id)
 	fmt.Printf("Tmux session: %s\n", sessionName)
 	fmt.Printf("Worktree path: %s\n", worktreePath)
 	fmt.Printf("To attach: tmux attach-session -t %s\n", sessionName)
 }
 
-func listWorkers() {
+// createWorktree creates a git worktree for the given branch, creating the
+// branch from base (HEAD when empty) first if it does not exist yet.
+func createWorktree(branch, worktreePath, base string) ([]byte, error) {
+	// Create worktree with new branch (simpler approach)
+	args := []string{"worktree", "add", "-b", branch, worktreePath}
+	if base != "" {
+		args = append(args, base)
+	}
+	cmd := gitCommand(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// If branch already exists, try without creating new branch
+		fmt.Printf("Branch might exist, trying without -b flag...\n")
+		cmd = gitCommand("worktree", "add", worktreePath, branch)
+		output, err = cmd.CombinedOutput()
+	}
+	return output, err
+}
+
+// splitWorkerPane splits the target window to create the pane of worker id
+// rooted at worktreePath and returns the new pane's index and ID.
+func splitWorkerPane(windowTarget, worktreePath, id string) (int, string, error) {
+	// "auto" tries a vertical split first, then horizontal if that fails
+	directions := []string{paneLayout.Direction}
+	if paneLayout.Direction == SplitAuto {
+		directions = []string{SplitVertical, SplitHorizontal}
+	}
+
+	var err error
+	var output []byte
+	for i, direction := range directions {
+		if i > 0 {
+			fmt.Printf("Vertical split failed, trying horizontal split...\n")
+		}
+		cmd := tmuxCommand(paneLayout.splitArgs(direction, windowTarget, muxPath(worktreePath), id)...)
+		if output, err = cmd.CombinedOutput(); err == nil {
+			return parsePaneIndexID(string(output))
+		}
+	}
+
+	// Get detailed error information
+	fmt.Printf("Tmux output: %s\n", string(output))
+	
+	// Check current window size and pane count
+	sizeCmd := tmuxCommand("display-message", "-t", windowTarget, "-p", "#{window_width}x#{window_height}")
+	if sizeOutput, sizeErr := sizeCmd.Output(); sizeErr == nil {
+		fmt.Printf("Current window size: %s", string(sizeOutput))
+	}
+	
+	paneCountCmd := tmuxCommand("list-panes", "-t", windowTarget)
+	if paneOutput, paneErr := paneCountCmd.Output(); paneErr == nil {
+		paneCount := len(strings.Split(strings.TrimSpace(string(paneOutput)), "\n"))
+		fmt.Printf("Current pane count: %d\n", paneCount)
+	}
+	
+	if len(directions) > 1 {
+		return 0, "", fmt.Errorf("both splits failed: %v", err)
+	}
+	return 0, "", fmt.Errorf("%s split failed: %v", directions[0], err)
+}
+
+func listWorkers(opts ListOptions) {
 	config, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return
 	}
 
-	if len(config.Workers) == 0 {
+	if opts.Archived {
+		listArchivedWorkers(config, opts)
+		return
+	}
+
+	var workers []Worker
+	for _, worker := range config.Workers {
+		if hasAllTags(worker, opts.Tags) {
+			workers = append(workers, worker)
+		}
+	}
+
+	// One pane listing is shared by all workers
+	alive := livePaneCheck()
+
+	var gitMeta map[string]GitMeta
+	if opts.Git || opts.Fast || opts.NoCache {
+		gitMeta = listGitMeta(config, workers, opts)
+	}
+
+	// Programs get one parseable line per worker
+	if opts.Format == "" && nonInteractive {
+		opts.Format = terseListFormat
+	}
+
+	if opts.Format != "" {
+		var views []WorkerView
+		for _, worker := range workers {
+			view := newWorkerView(worker, alive)
+			if meta, ok := gitMeta[worker.ID]; ok {
+				view.Git = &meta
+			}
+			if len(config.readinessFor(worker)) > 0 {
+				view.Ready = readinessLabel(config, worker)
+			}
+			views = append(views, view)
+		}
+		printFormatted(opts.Format, views)
+		return
+	}
+
+	if len(workers) == 0 {
 		fmt.Println("No workers found")
 		return
 	}
 
-	fmt.Printf("%-20s %-15s %-30s %-25s %-10s %s\n", "ID", "STATUS", "WORKTREE PATH", "TMUX SESSION", "PANE", "CREATED")
-	fmt.Println(strings.Repeat("-", 105))
+	if gitMeta != nil {
+		listWorkersGit(workers, alive, gitMeta)
+		return
+	}
 
-	for _, worker := range config.Workers {
+	if opts.Wide {
+		listWorkersWide(workers, alive)
+		return
+	}
+
+	// Readiness is only shown when a profile in use defines checks
+	showReady := slices.ContainsFunc(workers, func(w Worker) bool { return len(config.readinessFor(w)) > 0 })
+	readyHeader := ""
+	if showReady {
+		readyHeader = fmt.Sprintf("%-24s ", "READY")
+	}
+	fmt.Println(colorize(fmt.Sprintf("%-20s %-15s %-30s %-25s %-10s %-17s %-20s %s%s", "ID", "STATUS", "WORKTREE PATH", "TMUX SESSION", "PANE", "CREATED", "TAGS", readyHeader, "NOTE"), "header"))
+	fmt.Println(colorize(strings.Repeat("-", 150+len(readyHeader)), "separator"))
+
+	for _, worker := range workers {
 		// Check if tmux pane is actually running by pane ID
-		status := worker.Status
-		cmd := exec.Command("tmux", "list-panes", "-t", fmt.Sprintf("%s:%d", worker.TmuxSession, worker.WindowIndex), "-f", fmt.Sprintf("#{==:#{pane_id},%s}", worker.PaneID))
-		if err := cmd.Run(); err != nil {
-			status = "inactive"
+		status := string(liveState(worker, alive))
+		ready := ""
+		if showReady {
+			ready = fmt.Sprintf("%-24s ", readinessLabel(config, worker))
 		}
 
-		fmt.Printf("%-20s %-15s %-30s %-25s %-10s %s\n",
+		fmt.Printf("%-20s %s %-30s %-25s %-10s %-17s %-20s %s%s\n",
 			worker.ID,
-			status,
+			colorize(fmt.Sprintf("%-15s", status), status),
 			worker.WorktreePath,
 			worker.TmuxSession,
 			fmt.Sprintf("%s", worker.PaneID),
-			worker.CreatedAt.Format("2006-01-02 15:04"))
+			worker.CreatedAt.Format("2006-01-02 15:04"),
+			strings.Join(worker.Tags, ","),
+			ready,
+			worker.Note)
+	}
+}
+
+// listArchivedWorkers prints the workers parked with `gtw archive`.
+func listArchivedWorkers(config *Config, opts ListOptions) {
+	var workers []Worker
+	for _, worker := range config.Archived {
+		if hasAllTags(worker, opts.Tags) {
+			workers = append(workers, worker)
+		}
+	}
+
+	if opts.Format != "" {
+		var views []WorkerView
+		for _, worker := range workers {
+			views = append(views, newWorkerView(worker, mux.PaneExists))
+		}
+		printFormatted(opts.Format, views)
+		return
+	}
+
+	if len(workers) == 0 {
+		fmt.Println("No archived workers found")
+		return
+	}
+
+	fmt.Printf("%-20s %-30s %-17s %-20s %s\n", "ID", "BRANCH", "ARCHIVED", "TAGS", "NOTE")
+	fmt.Println(strings.Repeat("-", 110))
+
+	for _, worker := range workers {
+		archivedAt := "-"
+		if worker.ArchivedAt != nil {
+			archivedAt = worker.ArchivedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-20s %-30s %-17s %-20s %s\n",
+			worker.ID,
+			worker.Branch,
+			archivedAt,
+			strings.Join(worker.Tags, ","),
+			worker.Note)
+	}
+}
+
+// listWorkersWide prints the worker list with resource usage columns.
+func listWorkersWide(workers []Worker, alive func(paneID string) bool) {
+	// One process listing is shared by all workers
+	table, _ := readProcessTable()
+
+	fmt.Println(colorize(fmt.Sprintf("%-20s %-15s %-30s %-10s %-8s %-10s %-6s %-10s %s", "ID", "STATUS", "WORKTREE PATH", "PANE", "CPU%", "MEM", "PROCS", "DISK", "TAGS"), "header"))
+	fmt.Println(colorize(strings.Repeat("-", 135), "separator"))
+
+	for _, worker := range workers {
+		status := string(liveState(worker, alive))
+		cpu, mem, procs := "-", "-", "-"
+		if alive(worker.PaneID) && table != nil {
+			if usage, err := paneResourceUsage(worker.PaneID, table); err == nil {
+				cpu = fmt.Sprintf("%.1f", usage.CPU)
+				mem = formatBytes(usage.RSSKB * 1024)
+				procs = fmt.Sprintf("%d", usage.Processes)
+			}
+		}
+
+		disk := "-"
+		if size, err := dirSize(worker.WorktreePath); err == nil {
+			disk = formatBytes(size)
+		}
+
+		fmt.Printf("%-20s %s %-30s %-10s %-8s %-10s %-6s %-10s %s\n",
+			worker.ID,
+			colorize(fmt.Sprintf("%-15s", status), status),
+			worker.WorktreePath,
+			worker.PaneID,
+			cpu,
+			mem,
+			procs,
+			disk,
+			strings.Join(worker.Tags, ","))
 	}
 }
 
-func removeWorker(id string) {
+func removeWorker(id string, opts RemoveOptions) {
 	config, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
@@ -447,36 +1155,124 @@ func removeWorker(id string) {
 		return
 	}
 
+	// Locked worktrees (e.g. on a network mount) are never removed, even with --force
+	if refuseLocked(id, worker.WorktreePath) {
+		return
+	}
+
+	// Killing an agent mid-write can leave files half written
+	if !opts.Force && !opts.KillRunning && warnRunningPrograms(runningPrograms([]Worker{worker})) {
+		fmt.Printf("Run 'gtw remove %s --kill-running' to stop it and remove the worker anyway\n", id)
+		return
+	}
+
+	// Recorded before the worktree is gone so that 'gtw undo' knows the branch
+	removed := snapshotWorkers([]Worker{worker})
+	backup := ""
+
+	// Offer a backup branch when the worktree holds work that exists nowhere
+	// else; backup_on_remove: always applies even with --force
+	force := opts.Force
+	mode := resolveBackupMode(config, opts)
+	if _, err := os.Stat(worker.WorktreePath); err == nil && mode != BackupNever && (!force || mode == BackupAlways) {
+		reasons, err := unsavedWork(worker.WorktreePath)
+		if err != nil {
+			fmt.Printf("Warning: Could not check worktree for changes: %v\n", err)
+		}
+		if len(reasons) > 0 {
+			fmt.Printf("Worker '%s' has %s\n", id, strings.Join(reasons, " and "))
+			if mode == BackupAlways || opts.Yes || confirm("Create a backup branch before removing?") {
+				branch := backupBranchName(id, time.Now())
+				if err := backupWorktree(worker.WorktreePath, branch); err != nil {
+					fmt.Printf("❌ Error creating backup branch: %v\n", err)
+					return
+				}
+				fmt.Printf("✅ Saved work to branch '%s'\n", branch)
+				backup = branch
+				force = true
+			}
+		}
+	}
+
+	// Uncommitted changes are lost with the worktree, so ask first
+	if !force {
+		if _, err := os.Stat(worker.WorktreePath); err == nil {
+			dirty, err := worktreeDirty(worker.WorktreePath)
+			if err != nil {
+				fmt.Printf("Warning: Could not check worktree for changes: %v\n", err)
+			}
+			if dirty {
+				fmt.Printf("⚠️  Worktree '%s' has uncommitted changes that will be lost\n", worker.WorktreePath)
+				if !opts.Yes && !confirm(fmt.Sprintf("Remove worker '%s' anyway?", id)) {
+					fmt.Println("Aborted")
+					return
+				}
+				force = true
+			}
+		}
+	}
+
 	fmt.Printf("Removing worker '%s'...\n", id)
 
+	// Saved first so that a removal cut short shows up in 'gtw check'
+	if err := config.Workers[workerIndex].transition(StateRemoving, "gtw remove"); err == nil {
+		saveConfig(config)
+	}
+
 	// Kill tmux pane using pane ID
+	grace := resolveShutdownGrace(config)
+	if opts.Now {
+		grace = 0
+	}
 	fmt.Printf("Killing tmux pane '%s' (ID: %s)...\n", worker.ID, worker.PaneID)
-	cmd := exec.Command("tmux", "kill-pane", "-t", worker.PaneID)
-	if err := cmd.Run(); err != nil {
+	if err := stopPane(worker.PaneID, grace); err != nil {
 		fmt.Printf("Warning: Could not kill tmux pane: %v\n", err)
 	}
 
 	// Remove git worktree
 	fmt.Printf("Removing git worktree '%s'...\n", worker.WorktreePath)
-	cmd = exec.Command("git", "worktree", "remove", worker.WorktreePath)
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Warning: Could not remove git worktree: %v\n", err)
-		// Try force remove
-		exec.Command("git", "worktree", "remove", "--force", worker.WorktreePath).Run()
+	args := []string{"worktree", "remove", worker.WorktreePath}
+	if force {
+		args = []string{"worktree", "remove", "--force", worker.WorktreePath}
 	}
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		if _, statErr := os.Stat(worker.WorktreePath); statErr == nil {
+			fmt.Printf("❌ Error removing git worktree: %v\n", err)
+			fmt.Printf("Git output: %s\n", string(output))
+			fmt.Printf("Run 'gtw remove %s --force' to remove it anyway\n", id)
+			config.Workers[workerIndex].setState(StateFailed, "worktree could not be removed")
+			saveConfig(config)
+			return
+		}
+	}
+	// Drops the entry of a worktree whose directory was already gone
+	gitCommand("worktree", "prune").Run()
 
 	// Remove from config
 	config.Workers = append(config.Workers[:workerIndex], config.Workers[workerIndex+1:]...)
+	config.counters().WorkersRemoved++
+
+	if err := removeWorkerState(config, id); err != nil {
+		fmt.Printf("Warning: Could not remove state directory: %v\n", err)
+	}
 
 	if err := saveConfig(config); err != nil {
 		fmt.Printf("Error saving config: %v\n", err)
 		return
 	}
 
+	detail := ""
+	if backup != "" {
+		detail = "backup: " + backup
+	}
+	recordUndoableEvent(EventWorkerRemoved, id, detail, removed)
+	worker.PaneID = "" // Already closed
+	sendNotification(config, workerNotification(worker, NotifyRemoved,
+		fmt.Sprintf("gtw: %s removed", id), fmt.Sprintf("Worker '%s' was removed", id)))
 	fmt.Printf("Worker '%s' removed successfully!\n", id)
 }
 
-func showWorkerStatus(id string) {
+func showWorkerStatus(id, format string) {
 	config, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
@@ -496,6 +1292,11 @@ func showWorkerStatus(id string) {
 		return
 	}
 
+	if format != "" {
+		printFormatted(format, []WorkerView{newWorkerView(*worker, mux.PaneExists)})
+		return
+	}
+
 	fmt.Printf("Worker: %s\n", worker.ID)
 	fmt.Printf("Created: %s\n", worker.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Worktree: %s\n", worker.WorktreePath)
@@ -503,18 +1304,35 @@ func showWorkerStatus(id string) {
 	fmt.Printf("Window Index: %d\n", worker.WindowIndex)
 	fmt.Printf("Pane ID: %s\n", worker.PaneID)
 	fmt.Printf("Pane Index: %d\n", worker.PaneIndex)
+	if len(worker.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(worker.Tags, ", "))
+	}
+	if worker.Note != "" {
+		fmt.Printf("Note: %s\n", worker.Note)
+	}
 
 	// Check if tmux pane exists by pane ID
-	cmd := exec.Command("tmux", "list-panes", "-t", fmt.Sprintf("%s:%d", worker.TmuxSession, worker.WindowIndex), "-f", fmt.Sprintf("#{==:#{pane_id},%s}", worker.PaneID))
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Status: inactive (tmux pane not found)\n")
+	now := time.Now()
+	state := liveState(*worker, mux.PaneExists)
+	status := colorize(describeState(*worker, state, now), string(state))
+	if state == WorkerDetached {
+		fmt.Printf("Status: %s (run 'gtw attach --recreate' to recreate its pane)\n", status)
+	} else if !mux.PaneExists(worker.PaneID) {
+		fmt.Printf("Status: %s (tmux pane not found)\n", status)
 	} else {
-		fmt.Printf("Status: active\n")
+		fmt.Printf("Status: %s\n", status)
 
 		// Show tmux pane info using pane ID
-		cmd = exec.Command("tmux", "list-panes", "-t", worker.PaneID, "-F", "#{pane_index}: #{pane_title} (#{pane_current_command}) [#{pane_id}]")
-		if output, err := cmd.Output(); err == nil {
-			fmt.Printf("Pane info:\n%s", string(output))
+		if mux.Name() == "tmux" {
+			cmd := tmuxCommand("list-panes", "-t", worker.PaneID, "-F", "#{pane_index}: #{pane_title} (#{pane_current_command}) [#{pane_id}]")
+			if output, err := cmd.Output(); err == nil {
+				fmt.Printf("Pane info:\n%s", string(output))
+			}
+		}
+
+		if usage, err := paneResourceUsage(worker.PaneID, nil); err == nil {
+			fmt.Printf("CPU: %.1f%%\n", usage.CPU)
+			fmt.Printf("Memory: %s (%d processes)\n", formatBytes(usage.RSSKB*1024), usage.Processes)
 		}
 	}
 
@@ -523,6 +1341,23 @@ func showWorkerStatus(id string) {
 		fmt.Printf("Worktree: missing\n")
 	} else {
 		fmt.Printf("Worktree: exists\n")
+		if size, err := dirSize(worker.WorktreePath); err == nil {
+			fmt.Printf("Disk usage: %s\n", formatBytes(size))
+		}
+	}
+	if reason, locked := worktreeLocked(worker.WorktreePath); locked {
+		fmt.Printf("Lock: 🔒 %s (run 'gtw unlock %s' to allow removing it)\n", describeLock(reason), worker.ID)
+	}
+
+	if history := recentStateChanges(worker.StateHistory, 5); len(history) > 0 {
+		fmt.Println("Recent states:")
+		for _, change := range history {
+			line := fmt.Sprintf("  %s  %s -> %s", change.At.Format("2006-01-02 15:04:05"), change.From, change.To)
+			if change.Reason != "" {
+				line += " (" + change.Reason + ")"
+			}
+			fmt.Println(line)
+		}
 	}
 }
 
@@ -540,33 +1375,57 @@ func getSessionName() string {
 	if projectName == "" {
 		return ""
 	}
+	if workspace != "" {
+		return projectName + "-" + workspace
+	}
 	return projectName
 }
 
-func initSession(initCommand, worktreePrefix string) {
+func initSession(opts InitOptions) {
 	sessionName := getSessionName()
 	if sessionName == "" {
 		return
 	}
 
+	if opts.Multiplexer != "" {
+		m, err := newMultiplexer(opts.Multiplexer)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		mux = m
+	}
+
 	// Check if session already exists
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	if cmd.Run() == nil {
+	if mux.HasSession(sessionName) {
 		fmt.Printf("Session '%s' already exists\n", sessionName)
 		return
 	}
 
-	fmt.Printf("Creating tmux session '%s'...\n", sessionName)
-	// Create new tmux session in detached mode
-	cmd = exec.Command("tmux", "new-session", "-d", "-s", sessionName)
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Error creating tmux session: %v\n", err)
+	if opts.BareClone != "" {
+		if err := bareClone(opts.BareClone); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	// Worktrees cannot be created later without a repository and a commit
+	if err := checkGitRepository(); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
-	// Set title for the initial pane (project root)
-	projectName := getCurrentProjectName()
-	exec.Command("tmux", "select-pane", "-t", sessionName+":0.0", "-T", projectName).Run()
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error getting current directory: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Creating %s session '%s'...\n", mux.Name(), sessionName)
+	// Create new session in detached mode, titling the initial pane (project root)
+	if err := mux.NewSession(sessionName, cwd, getCurrentProjectName()); err != nil {
+		fmt.Printf("Error creating %s session: %v\n", mux.Name(), err)
+		return
+	}
 
 	// Save project path and configuration to config
 	config, err := loadConfig()
@@ -578,101 +1437,256 @@ func initSession(initCommand, worktreePrefix string) {
 			fmt.Printf("Warning: Failed to get current directory: %v\n", err)
 		} else {
 			config.ProjectPath = cwd
+			if isWSL() && strings.HasPrefix(cwd, "/mnt/") {
+				fmt.Printf("Warning: %s is on a Windows drive; git worktrees are much faster on the WSL filesystem (e.g. ~/src)\n", cwd)
+			}
 			
 			// Set custom values if provided
-			if initCommand != "" {
-				config.InitCommand = initCommand
-				fmt.Printf("Set initialization command to: %s\n", initCommand)
+			if opts.Command != "" {
+				config.InitCommand = opts.Command
+				fmt.Printf("Set initialization command to: %s\n", opts.Command)
+			}
+			if opts.WorktreePrefix != "" {
+				config.WorktreePrefix = opts.WorktreePrefix
+				fmt.Printf("Set worktree prefix to: %s\n", opts.WorktreePrefix)
+			}
+			if opts.Multiplexer != "" {
+				config.Multiplexer = mux.Name()
+				fmt.Printf("Set multiplexer to: %s\n", mux.Name())
+			}
+			if opts.WindowName != "" {
+				config.WindowName = opts.WindowName
 			}
-			if worktreePrefix != "" {
-				config.WorktreePrefix = worktreePrefix
-				fmt.Printf("Set worktree prefix to: %s\n", worktreePrefix)
+			if opts.NoTmuxOptions {
+				config.NoTmuxOptions = true
 			}
 			
 			if err := saveConfig(config); err != nil {
 				fmt.Printf("Warning: Failed to save project configuration: %v\n", err)
 			}
+			registerProject(cwd)
+			ignoreWorktrees(config, opts.Gitignore)
 		}
 	}
+	if config == nil {
+		config = &Config{WindowName: opts.WindowName, NoTmuxOptions: opts.NoTmuxOptions}
+	}
+	configureTmuxSession(sessionName, config)
 
+	recordEvent(EventSessionCreated, "", sessionName)
 	fmt.Printf("Session '%s' created successfully!\n", sessionName)
-	fmt.Printf("To attach: tmux attach-session -t %s\n", sessionName)
+	if opts.Attach {
+		attachSession(false)
+		return
+	}
+	if workspace != "" {
+		fmt.Printf("To attach: gtw attach --workspace %s\n", workspace)
+		return
+	}
+	fmt.Printf("To attach: gtw attach\n")
 }
 
-func destroySession() {
+func destroySession(opts RemoveOptions) {
 	sessionName := getSessionName()
 	if sessionName == "" {
 		return
 	}
 
 	// Check if session exists
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	if cmd.Run() != nil {
+	if !mux.HasSession(sessionName) {
 		fmt.Printf("Session '%s' does not exist\n", sessionName)
 		return
 	}
 
-	fmt.Printf("Destroying tmux session '%s'...\n", sessionName)
-	cmd = exec.Command("tmux", "kill-session", "-t", sessionName)
-	if err := cmd.Run(); err != nil {
+	if !opts.Force && !opts.KillRunning {
+		if config, err := loadConfig(); err == nil && warnRunningPrograms(runningPrograms(config.Workers)) {
+			fmt.Println("Run 'gtw destroy --kill-running' to stop them and destroy the session anyway")
+			return
+		}
+	}
+
+	keep := opts.KeepWorktrees || opts.KeepBranches
+	if !opts.Yes && !opts.Force {
+		question := fmt.Sprintf("Destroy session '%s'?", sessionName)
+		if config, err := loadConfig(); err == nil && len(config.Workers) > 0 {
+			switch {
+			case opts.KeepBranches:
+				question = fmt.Sprintf("Destroy session '%s' and remove the worktrees of its %d worker(s)?", sessionName, len(config.Workers))
+			case keep:
+				question = fmt.Sprintf("Destroy session '%s' (its %d worker(s) are kept)?", sessionName, len(config.Workers))
+			default:
+				question = fmt.Sprintf("Destroy session '%s' and forget its %d worker(s)?", sessionName, len(config.Workers))
+			}
+		}
+		if !confirm(question) {
+			fmt.Println("Aborted")
+			return
+		}
+	}
+
+	var removed []SnapshotWorker
+	if config, err := loadConfig(); err == nil {
+		removed = snapshotWorkers(config.Workers)
+	}
+
+	fmt.Printf("Destroying %s session '%s'...\n", mux.Name(), sessionName)
+	if err := mux.KillSession(sessionName); err != nil {
 		fmt.Printf("Error destroying tmux session: %v\n", err)
 		return
 	}
 
-	// Clear project path and workers from config
 	config, err := loadConfig()
 	if err == nil {
-		config.ProjectPath = ""
-		config.Workers = []Worker{}
+		if keep {
+			detachWorkers(config, opts.KeepBranches)
+		} else {
+			// Clear project path and workers from config
+			config.ProjectPath = ""
+			config.Workers = []Worker{}
+		}
 		if err := saveConfig(config); err != nil {
 			fmt.Printf("Warning: Failed to clear project configuration: %v\n", err)
 		}
 	}
 
+	recordUndoableEvent(EventSessionDestroyed, "", sessionName, removed)
 	fmt.Printf("Session '%s' destroyed successfully!\n", sessionName)
+	if keep && err == nil && len(config.Workers) > 0 {
+		fmt.Printf("%d worker(s) are detached. Run 'gtw attach --recreate' to bring them back.\n", len(config.Workers))
+	}
+}
+
+// detachWorkers marks the workers of a destroyed session as detached so that
+// recreateSession can bring them back, removing their worktrees when
+// removeWorktrees is set. Worktrees with uncommitted changes are kept.
+func detachWorkers(config *Config, removeWorktrees bool) {
+	for i := range config.Workers {
+		worker := &config.Workers[i]
+		worker.Branch = getWorktreeBranch(worker.WorktreePath, worker.branchName())
+		worker.setState(WorkerDetached, "session destroyed")
+		worker.PaneID = ""
+
+		if !removeWorktrees {
+			continue
+		}
+		fmt.Printf("Removing git worktree '%s'...\n", worker.WorktreePath)
+		if output, err := gitCommand("worktree", "remove", worker.WorktreePath).CombinedOutput(); err != nil {
+			fmt.Printf("⚠️  Keeping worktree '%s': %s\n", worker.WorktreePath, strings.TrimSpace(string(output)))
+		}
+	}
 }
 
-func attachSession() {
+func attachSession(recreate bool) {
 	sessionName := getSessionName()
 	if sessionName == "" {
 		return
 	}
 
 	// Check if session exists
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	if cmd.Run() != nil {
-		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+	if !mux.HasSession(sessionName) {
+		if !recreateSession(sessionName, recreate) {
+			return
+		}
+	}
+
+	// Check if we're already inside a session; tmux switches the client instead
+	if mux.Inside() && mux.Name() != "tmux" {
+		fmt.Printf("Error: Already inside a %s session.\n", mux.Name())
+		return
+	}
+
+	if nonInteractive {
+		fmt.Printf("Not attaching to session '%s' in non-interactive mode\n", sessionName)
 		return
 	}
 
-	// Check if we're already inside a tmux session
-	if os.Getenv("TMUX") != "" {
-		fmt.Printf("Error: Already inside a tmux session. Use 'tmux switch-client -t %s' instead.\n", sessionName)
+	if mux.Inside() {
+		if insideSession(sessionName) {
+			fmt.Printf("Already in session '%s'\n", sessionName)
+			return
+		}
+		fmt.Printf("Switching to session '%s'...\n", sessionName)
+		if err := switchClient(sessionName); err != nil {
+			fmt.Printf("Error switching to session: %v\n", err)
+		}
 		return
 	}
 
 	fmt.Printf("Attaching to session '%s'...\n", sessionName)
-	// Use syscall.Exec to replace current process with tmux attach
-	cmd = exec.Command("tmux", "attach-session", "-t", sessionName)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
+	err := mux.Attach(sessionName)
 	if err != nil {
 		fmt.Printf("Error attaching to session: %v\n", err)
 	}
 }
 
+// recreateSession rebuilds a session that disappeared (e.g. tmux server
+// restart) along with the panes of all workers still recorded in config.
+// Unless force is set the user is asked first. It reports whether the
+// session exists afterwards.
+func recreateSession(sessionName string, force bool) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return false
+	}
+
+	if len(config.Workers) == 0 {
+		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+		return false
+	}
+
+	fmt.Printf("Session '%s' does not exist, but %d worker(s) are recorded in config.\n", sessionName, len(config.Workers))
+	if !force && !confirm("Recreate the session and worker panes?") {
+		fmt.Println("Run 'gtw attach --recreate' to rebuild it, or 'gtw init' to start over.")
+		return false
+	}
+
+	dir := config.ProjectPath
+	if dir == "" {
+		if dir, err = os.Getwd(); err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return false
+		}
+	}
+
+	if err := ensureSession(sessionName, dir); err != nil {
+		fmt.Printf("Error creating tmux session: %v\n", err)
+		return false
+	}
+
+	restored := restoreWorkers(config, sessionName, snapshotWorkers(config.Workers), true)
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return false
+	}
+
+	fmt.Printf("✅ Recreated session '%s' with %d worker(s)\n", sessionName, restored)
+	return true
+}
+
+// confirm asks a yes/no question on stdin and defaults to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	if nonInteractive {
+		// Nobody is there to answer; pass --yes to go ahead
+		fmt.Println("n (non-interactive)")
+		return false
+	}
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func detachSession() {
-	// Check if we're inside a tmux session
-	if os.Getenv("TMUX") == "" {
-		fmt.Println("Error: Not currently inside a tmux session.")
+	// Check if we're inside a session
+	if !mux.Inside() {
+		fmt.Printf("Error: Not currently inside a %s session.\n", mux.Name())
 		return
 	}
 
-	fmt.Println("Detaching from tmux session...")
-	cmd := exec.Command("tmux", "detach-client")
-	if err := cmd.Run(); err != nil {
+	fmt.Printf("Detaching from %s session...\n", mux.Name())
+	if err := mux.Detach(); err != nil {
 		fmt.Printf("Error detaching from session: %v\n", err)
 	}
 }
@@ -684,61 +1698,98 @@ const (
 	MissingPane
 	OrphanedWorktree
 	OrphanedPane
+	StuckState
+	TrackedWorktree
+	PrunableWorktree
 )
 
-type Inconsistency struct {
-	Type        InconsistencyType
-	WorkerID    string
-	Description string
+// String returns the name used for the type in `gtw check --json`.
+func (t InconsistencyType) String() string {
+	switch t {
+	case MissingWorktree:
+		return "missing_worktree"
+	case MissingPane:
+		return "missing_pane"
+	case OrphanedWorktree:
+		return "orphaned_worktree"
+	case StuckState:
+		return "stuck_state"
+	case TrackedWorktree:
+		return "tracked_worktree"
+	case PrunableWorktree:
+		return "prunable_worktree"
+	default:
+		return "orphaned_pane"
+	}
 }
 
-func checkConsistency() {
-	sessionName := getSessionName()
-	if sessionName == "" {
-		return
-	}
+func (t InconsistencyType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
 
-	// Check if session exists
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	if cmd.Run() != nil {
-		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
-		return
+// Severity is "error" when a worker is broken and "warning" for leftovers
+// that do not affect any worker.
+func (t InconsistencyType) Severity() string {
+	if t == MissingWorktree || t == MissingPane {
+		return "error"
 	}
+	return "warning"
+}
 
-	config, err := loadConfig()
-	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		return
-	}
+type Inconsistency struct {
+	Type        InconsistencyType `json:"type"`
+	Severity    string            `json:"severity"`
+	WorkerID    string            `json:"worker_id"`
+	PaneID      string            `json:"pane_id,omitempty"` // Orphaned pane
+	Path        string            `json:"path,omitempty"`    // Worktree path of an orphaned pane or worktree, an unregistered worktree directory or the tracked worktree directory
+	Locked      bool              `json:"locked,omitempty"`  // The worktree is locked with `git worktree lock`
+	Description string            `json:"description"`
+}
 
-	fmt.Println("Checking worktree/pane consistency...")
-	
+// CheckReport is the output of `gtw check --json`.
+type CheckReport struct {
+	Session         string          `json:"session"`
+	Consistent      bool            `json:"consistent"`
+	Inconsistencies []Inconsistency `json:"inconsistencies"`
+}
+
+// Exit codes of `gtw check`
+const (
+	CheckExitOK           = 0 // No inconsistencies
+	CheckExitInconsistent = 1 // Inconsistencies found
+	CheckExitError        = 2 // The check could not run
+)
+
+// findInconsistencies compares the workers in config with the panes of the
+// session and the worktrees git lists. Workers are matched to panes by pane
+// ID, then by their @gtw_worker_id option, pane title and working directory.
+func findInconsistencies(sessionName string, config *Config) ([]Inconsistency, error) {
 	var inconsistencies []Inconsistency
+	now := time.Now()
 
-	// Get all panes with IDs and titles
-	windowTarget := fmt.Sprintf("%s:0", sessionName)
-	cmd = exec.Command("tmux", "list-panes", "-t", windowTarget, "-F", "#{pane_id}:#{pane_title}")
-	output, err := cmd.Output()
+	panes, err := listSessionPanes(sessionName)
 	if err != nil {
-		fmt.Printf("Error listing panes: %v\n", err)
-		return
+		return nil, err
+	}
+	worktrees, err := listWorktrees()
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse panes - map title to pane ID
-	paneMap := make(map[string]string) // title -> pane_id
-	projectName := getCurrentProjectName()
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 && parts[1] != "" && parts[1] != projectName && !strings.Contains(parts[1], "GX3V2YXM92") {
-			paneMap[parts[1]] = parts[0] // title -> pane_id
-		}
+	live := make(map[string]bool)
+	for _, pane := range panes {
+		live[pane.ID] = true
 	}
+	matches := matchStalePanes(config.Workers, panes, func(w Worker) string { return muxPath(w.WorktreePath) })
+	claimed := make(map[string]bool)
 
 	// Check workers in config
-	for _, worker := range config.Workers {
-		// Check if pane exists by title
-		if _, exists := paneMap[worker.ID]; !exists {
+	for i, worker := range config.Workers {
+		if live[worker.PaneID] {
+			claimed[worker.PaneID] = true
+		} else if pane, ok := matches[i]; ok {
+			claimed[pane.ID] = true
+		} else {
 			inconsistencies = append(inconsistencies, Inconsistency{
 				Type:        MissingPane,
 				WorkerID:    worker.ID,
@@ -746,265 +1797,128 @@ func checkConsistency() {
 			})
 		}
 
-		// Check if worktree exists
-		if _, err := os.Stat(worker.WorktreePath); os.IsNotExist(err) {
+		// A gtw command that died half way leaves the worker creating or removing
+		if isStuck(worker, now) {
 			inconsistencies = append(inconsistencies, Inconsistency{
-				Type:        MissingWorktree,
+				Type:        StuckState,
 				WorkerID:    worker.ID,
-				Description: fmt.Sprintf("Worker '%s' has pane but missing worktree", worker.ID),
+				Description: fmt.Sprintf("Worker '%s' is stuck %s", worker.ID, describeState(worker, worker.state(), now)),
 			})
 		}
 	}
 
-	// Check for orphaned panes (panes without workers in config)
+	// Missing, orphaned and prunable worktrees
+	inconsistencies = append(inconsistencies, worktreeInconsistencies(config, worktrees)...)
+
+	// Check for orphaned panes (panes working in a worktree without a worker in config)
 	configWorkers := make(map[string]bool)
 	for _, worker := range config.Workers {
 		configWorkers[worker.ID] = true
 	}
 
-	for paneTitle := range paneMap {
-		if !configWorkers[paneTitle] {
-			inconsistencies = append(inconsistencies, Inconsistency{
-				Type:        OrphanedPane,
-				WorkerID:    paneTitle,
-				Description: fmt.Sprintf("Pane '%s' exists but no worker in config", paneTitle),
-			})
-		}
+	root := worktreeRoot(config)
+	orphans := orphanedPanes(panes, claimed, muxPath(root), config.CheckIgnore)
+	names := make([]string, 0, len(orphans))
+	for name := range orphans {
+		names = append(names, name)
 	}
-
-	// Check for orphaned worktrees
-	if worktreeDir, err := os.Open("worktree"); err == nil {
-		defer worktreeDir.Close()
-		if entries, err := worktreeDir.Readdir(-1); err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					workerID := entry.Name()
-					if !configWorkers[workerID] {
-						inconsistencies = append(inconsistencies, Inconsistency{
-							Type:        OrphanedWorktree,
-							WorkerID:    workerID,
-							Description: fmt.Sprintf("Worktree '%s' exists but no worker in config", workerID),
-						})
-					}
-				}
-			}
+	sort.Strings(names)
+	for _, name := range names {
+		if configWorkers[name] {
+			continue
 		}
+		inconsistencies = append(inconsistencies, Inconsistency{
+			Type:        OrphanedPane,
+			WorkerID:    name,
+			PaneID:      orphans[name].ID,
+			Path:        workerWorktreePath(config, name),
+			Description: fmt.Sprintf("Pane %s (%s) exists but no worker in config", orphans[name].ID, name),
+		})
 	}
 
-	// Report results
-	if len(inconsistencies) == 0 {
-		fmt.Println("✅ No inconsistencies found. All worktrees and panes are in sync.")
-		return
+	// Worktrees committed by a `git add .` before the directory was ignored
+	if rel, files := trackedWorktreeFiles(config); len(files) > 0 {
+		inconsistencies = append(inconsistencies, Inconsistency{
+			Type:        TrackedWorktree,
+			Path:        rel,
+			Description: fmt.Sprintf("%d file(s) under '%s/' are tracked by git and would be committed to the project", len(files), rel),
+		})
 	}
 
-	fmt.Printf("❌ Found %d inconsistency(ies):\n\n", len(inconsistencies))
-	for i, inc := range inconsistencies {
-		fmt.Printf("%d. %s\n", i+1, inc.Description)
+	for i := range inconsistencies {
+		inconsistencies[i].Severity = inconsistencies[i].Type.Severity()
 	}
-	
-	fmt.Println("\nRun 'gtw repair' to fix these inconsistencies.")
+	return inconsistencies, nil
 }
 
-func repairInconsistencies() {
+func checkConsistency(asJSON bool) int {
+	if !requireTmux("check") {
+		return CheckExitError
+	}
+
 	sessionName := getSessionName()
 	if sessionName == "" {
-		return
+		return CheckExitError
 	}
 
 	// Check if session exists
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	cmd := tmuxCommand("has-session", "-t", sessionName)
 	if cmd.Run() != nil {
-		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
-		return
+		fmt.Fprintf(os.Stderr, "Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+		return CheckExitError
 	}
 
 	config, err := loadConfig()
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		return
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return CheckExitError
 	}
 
-	fmt.Println("Repairing worktree/pane inconsistencies...")
-	
-	repairCount := 0
+	if !asJSON {
+		fmt.Println("Checking worktree/pane consistency...")
+	}
 
-	// Get all panes with IDs and titles
-	windowTarget := fmt.Sprintf("%s:0", sessionName)
-	cmd = exec.Command("tmux", "list-panes", "-t", windowTarget, "-F", "#{pane_id}:#{pane_title}")
-	output, err := cmd.Output()
+	inconsistencies, err := findInconsistencies(sessionName, config)
 	if err != nil {
-		fmt.Printf("Error listing panes: %v\n", err)
-		return
+		fmt.Fprintf(os.Stderr, "Error listing panes: %v\n", err)
+		return CheckExitError
 	}
-
-	// Parse panes - map title to pane ID
-	paneMap := make(map[string]string) // title -> pane_id
-	projectName := getCurrentProjectName()
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 && parts[1] != "" && parts[1] != projectName && !strings.Contains(parts[1], "GX3V2YXM92") {
-			paneMap[parts[1]] = parts[0] // title -> pane_id
-		}
+	exitCode := CheckExitOK
+	if len(inconsistencies) > 0 {
+		exitCode = CheckExitInconsistent
 	}
 
-	// Repair missing panes for existing workers
-	for i, worker := range config.Workers {
-		if _, exists := paneMap[worker.ID]; !exists {
-			fmt.Printf("🔧 Adding missing pane for worker '%s'...\n", worker.ID)
-			
-			// Create pane
-			cmd = exec.Command("tmux", "split-window", "-v", "-t", windowTarget, "-c", worker.WorktreePath)
-			if err := cmd.Run(); err != nil {
-				fmt.Printf("❌ Error creating pane: %v\n", err)
-				continue
-			}
-			
-			// Get the new pane ID and index
-			cmd = exec.Command("tmux", "list-panes", "-t", windowTarget, "-F", "#{pane_index}:#{pane_id}")
-			output, err := cmd.Output()
-			if err != nil {
-				fmt.Printf("❌ Error getting pane info: %v\n", err)
-				continue
-			}
-			
-			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-			newPaneIndex := len(lines) - 1
-			lastLine := lines[newPaneIndex]
-			parts := strings.Split(lastLine, ":")
-			if len(parts) != 2 {
-				fmt.Printf("❌ Error parsing pane info: %s\n", lastLine)
-				continue
-			}
-			
-			paneIndexNum := newPaneIndex
-			newPaneID := parts[1]
-			fmt.Sscanf(parts[0], "%d", &paneIndexNum)
-			
-			// Set pane title using pane ID
-			exec.Command("tmux", "select-pane", "-t", newPaneID, "-T", worker.ID).Run()
-			
-			// Update worker config
-			config.Workers[i].PaneIndex = paneIndexNum
-			config.Workers[i].PaneID = newPaneID
-			
-			repairCount++
-		}
-
-		// Repair missing worktree
-		if _, err := os.Stat(worker.WorktreePath); os.IsNotExist(err) {
-			fmt.Printf("🔧 Adding missing worktree for worker '%s'...\n", worker.ID)
-			
-			// Create worktree
-			cmd = exec.Command("git", "worktree", "add", "-b", worker.ID, worker.WorktreePath)
-			if err := cmd.Run(); err != nil {
-				// Branch might exist, try without -b
-				cmd = exec.Command("git", "worktree", "add", worker.WorktreePath, worker.ID)
-				if err := cmd.Run(); err != nil {
-					fmt.Printf("❌ Error creating worktree: %v\n", err)
-					continue
-				}
-			}
-			
-			repairCount++
+	if asJSON {
+		report := CheckReport{
+			Session:         sessionName,
+			Consistent:      len(inconsistencies) == 0,
+			Inconsistencies: inconsistencies,
 		}
-	}
-
-	// Handle orphaned panes (add them to config)
-	configWorkers := make(map[string]bool)
-	for _, worker := range config.Workers {
-		configWorkers[worker.ID] = true
-	}
-
-	for paneTitle := range paneMap {
-		if !configWorkers[paneTitle] {
-			fmt.Printf("🔧 Adding orphaned pane '%s' to config...\n", paneTitle)
-			
-			worktreePath := filepath.Join("./worktree", paneTitle)
-			
-			// Create worktree if it doesn't exist
-			if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-				cmd = exec.Command("git", "worktree", "add", "-b", paneTitle, worktreePath)
-				if err := cmd.Run(); err != nil {
-					cmd = exec.Command("git", "worktree", "add", worktreePath, paneTitle)
-					if err := cmd.Run(); err != nil {
-						fmt.Printf("❌ Error creating worktree for orphaned pane: %v\n", err)
-						continue
-					}
-				}
-			}
-			
-			// Find pane ID and index
-			cmd = exec.Command("tmux", "list-panes", "-t", windowTarget, "-F", "#{pane_index}:#{pane_id}:#{pane_title}")
-			output, err := cmd.Output()
-			if err != nil {
-				fmt.Printf("❌ Error finding pane info: %v\n", err)
-				continue
-			}
-			
-			paneIndex := -1
-			paneID := ""
-			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-			for _, line := range lines {
-				parts := strings.SplitN(line, ":", 3)
-				if len(parts) == 3 && parts[2] == paneTitle {
-					fmt.Sscanf(parts[0], "%d", &paneIndex)
-					paneID = parts[1]
-					break
-				}
-			}
-			
-			if paneIndex >= 0 && paneID != "" {
-				// Add to config
-				worker := Worker{
-					ID:           paneTitle,
-					WorktreePath: worktreePath,
-					TmuxSession:  sessionName,
-					WindowIndex:  0,
-					PaneID:       paneID,
-					PaneIndex:    paneIndex,
-					CreatedAt:    time.Now(),
-					Status:       "active",
-				}
-				config.Workers = append(config.Workers, worker)
-				repairCount++
-			}
+		if report.Inconsistencies == nil {
+			report.Inconsistencies = []Inconsistency{}
 		}
-	}
-
-	// Handle orphaned worktrees (remove them or add panes)
-	if worktreeDir, err := os.Open("worktree"); err == nil {
-		defer worktreeDir.Close()
-		if entries, err := worktreeDir.Readdir(-1); err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					workerID := entry.Name()
-					_, paneExists := paneMap[workerID]
-					if !configWorkers[workerID] && !paneExists {
-						fmt.Printf("🔧 Removing orphaned worktree '%s'...\n", workerID)
-						worktreePath := filepath.Join("worktree", workerID)
-						cmd = exec.Command("git", "worktree", "remove", worktreePath)
-						if err := cmd.Run(); err != nil {
-							exec.Command("git", "worktree", "remove", "--force", worktreePath).Run()
-						}
-						repairCount++
-					}
-				}
-			}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+			return CheckExitError
 		}
+		fmt.Println(string(data))
+		return exitCode
 	}
 
-	// Save updated config
-	if err := saveConfig(config); err != nil {
-		fmt.Printf("❌ Error saving config: %v\n", err)
-		return
+	// Report results
+	if len(inconsistencies) == 0 {
+		fmt.Println("✅ No inconsistencies found. All worktrees and panes are in sync.")
+		return exitCode
 	}
 
-	if repairCount == 0 {
-		fmt.Println("✅ No repairs needed. All worktrees and panes are already in sync.")
-	} else {
-		fmt.Printf("✅ Repaired %d inconsistency(ies). All worktrees and panes are now in sync.\n", repairCount)
+	fmt.Printf("❌ Found %d inconsistency(ies):\n\n", len(inconsistencies))
+	for i, inc := range inconsistencies {
+		fmt.Printf("%d. %s\n", i+1, inc.Description)
 	}
+	
+	fmt.Println("\nRun 'gtw repair' to fix these inconsistencies.")
+	return exitCode
 }
 
 func showConfig() {
@@ -1018,7 +1932,101 @@ func showConfig() {
 	fmt.Println()
 	
 	fmt.Printf("  Initialization command: %s\n", config.InitCommand)
+	if config.ReadyPattern != "" {
+		fmt.Printf("  Ready pattern:          %s\n", config.ReadyPattern)
+	}
+	if config.UsageCommand != "" {
+		fmt.Printf("  Usage command:          %s\n", config.UsageCommand)
+	}
 	fmt.Printf("  Worktree prefix:        %s\n", config.WorktreePrefix)
+	if config.SetupScript != "" {
+		fmt.Printf("  Setup script:           %s\n", config.SetupScript)
+	}
+	for i, step := range config.InitSteps {
+		onError := step.OnError
+		if onError == "" {
+			onError = StepAbort
+		}
+		fmt.Printf("  Init step %d:            %s (on error: %s)\n", i+1, step.Run, onError)
+	}
+	if config.CommandTimeout != "" {
+		fmt.Printf("  Command timeout:        %s\n", config.CommandTimeout)
+	}
+	if config.RetryAttempts != 0 || config.RetryBackoff != "" {
+		policy := resolveRetryPolicy(config)
+		fmt.Printf("  Retries:                %d attempts, %s backoff\n", policy.Attempts, policy.Backoff)
+	}
+	if config.Store != "" {
+		fmt.Printf("  Store:                  %s\n", config.Store)
+	}
+	if config.WorkerTTL != "" {
+		fmt.Printf("  Worker TTL:             %s\n", config.WorkerTTL)
+	}
+	if config.SessionIdleTTL != "" {
+		action := config.SessionIdleAction
+		if action == "" {
+			action = SessionIdleDestroy
+		}
+		fmt.Printf("  Session idle TTL:       %s (then %s)\n", config.SessionIdleTTL, action)
+	}
+	if config.BranchTemplate != "" {
+		fmt.Printf("  Branch template:        %s\n", config.BranchTemplate)
+	}
+	if config.ShutdownGrace != "" {
+		fmt.Printf("  Shutdown grace:         %s\n", config.ShutdownGrace)
+	}
+	if config.Submodules {
+		fmt.Printf("  Submodules:             update on add\n")
+	}
+	if config.LFS {
+		fmt.Printf("  Git LFS:                pull on add\n")
+	}
+	for _, rule := range config.DependencyCaches {
+		mode := rule.Mode
+		if mode == "" {
+			mode = DepSymlink
+		}
+		fmt.Printf("  Dependency cache:       %s (%s from %s)\n", rule.Path, mode, dependencySource(config, rule))
+	}
+	for _, key := range sortedKeys(config.WorkerEnv) {
+		fmt.Printf("  Worker env:             %s=%s\n", key, config.WorkerEnv[key])
+	}
+	if config.TestCommand != "" {
+		fmt.Printf("  Test command:           %s\n", config.TestCommand)
+	}
+	for _, key := range sortedKeys(config.Theme) {
+		fmt.Printf("  Theme %-17s %s\n", key+":", config.Theme[key])
+	}
+	if config.Forge != nil && config.Forge.Type != "" {
+		fmt.Printf("  Forge:                  %s %s\n", config.Forge.Type, config.Forge.URL)
+	}
+	if config.Jira != nil && config.Jira.URL != "" {
+		fmt.Printf("  Jira:                   %s\n", config.Jira.URL)
+	}
+	for _, name := range config.profileNames() {
+		fmt.Printf("  Profile %-15s %s\n", name+":", config.Profiles[name].InitCommand)
+	}
+	if config.Editor != "" {
+		fmt.Printf("  Editor:                 %s\n", config.Editor)
+	}
+	if config.GitTUI != "" {
+		fmt.Printf("  Git TUI:                %s\n", config.GitTUI)
+	}
+	if config.SplitDirection != "" {
+		fmt.Printf("  Split direction:        %s\n", config.SplitDirection)
+	}
+	if config.PaneSize != "" {
+		fmt.Printf("  Pane size:              %s\n", config.PaneSize)
+	}
+	if config.WorkerWindow != "" {
+		fmt.Printf("  Worker window:          %s\n", config.WorkerWindow)
+	}
+	if config.PaneTitle != "" {
+		fmt.Printf("  Pane title:             %s\n", config.PaneTitle)
+	}
+	if config.FocusOnAdd != "" {
+		fmt.Printf("  Focus on add:           %s\n", config.FocusOnAdd)
+	}
 	if config.ProjectPath != "" {
 		fmt.Printf("  Project path:           %s\n", config.ProjectPath)
 	}
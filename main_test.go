@@ -54,7 +54,7 @@ func cleanupTest(t *testing.T, tc *TestConfig) {
 	}
 
 	// Destroy session if exists
-	cmd := exec.Command(tc.BinaryPath, "destroy")
+	cmd := exec.Command(tc.BinaryPath, "destroy", "--yes")
 	cmd.Run() // Ignore errors
 
 	// Clean up any remaining worktrees
@@ -224,7 +224,7 @@ func TestSessionLifecycle(t *testing.T) {
 	verifyTmuxPane(t, tc.SessionName, tc.ProjectName)
 
 	// Test session destruction
-	cmd = exec.Command(tc.BinaryPath, "destroy")
+	cmd = exec.Command(tc.BinaryPath, "destroy", "--yes")
 	if err := cmd.Run(); err != nil {
 		t.Errorf("Failed to destroy session: %v", err)
 	}
@@ -358,11 +358,11 @@ func TestConsistencyCheckAndRepair(t *testing.T) {
 		t.Logf("Warning: Failed to remove worktree for test: %v", err)
 	}
 
-	// Run consistency check
+	// Run consistency check - exits with 1 when inconsistencies are found
 	cmd = exec.Command(tc.BinaryPath, "check")
 	output, err := cmd.Output()
-	if err != nil {
-		t.Errorf("Failed to run check: %v", err)
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Errorf("Expected check to exit with 1, got: %v", err)
 		return
 	}
 
@@ -545,4 +545,86 @@ func BenchmarkWorkerCreation(b *testing.B) {
 			b.Errorf("Failed to remove worker: %v", err)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateWorkerID(t *testing.T) {
+	for _, id := range []string{"issue-123", "feature_auth", "v1.2", "A"} {
+		if err := validateWorkerID(id); err != nil {
+			t.Errorf("Expected '%s' to be valid: %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "a b", "it's", "$(id)", "../x", "a/b", "-rf", ".hidden", "a..b", "x.lock", "a;b"} {
+		if err := validateWorkerID(id); err == nil {
+			t.Errorf("Expected '%s' to be rejected", id)
+		}
+	}
+}
+
+func TestRecreateSession(t *testing.T) {
+	project := t.TempDir()
+	t.Chdir(project)
+	config := &Config{ProjectPath: project, InitCommand: "claude", Workers: []Worker{
+		{ID: "issue-1", PaneID: "%1", Branch: "feature/one", Note: "keep me"},
+		{ID: "issue-2", PaneID: "%2", Tags: []string{"backend"}},
+	}}
+	for i := range config.Workers {
+		config.Workers[i].WorktreePath = filepath.Join(project, "worktree", config.Workers[i].ID)
+		os.MkdirAll(config.Workers[i].WorktreePath, 0755)
+	}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	fake := newSessionMultiplexer()
+	useMultiplexer(t, fake)
+
+	// Without --yes nobody confirms in a non-interactive run
+	previous := nonInteractive
+	nonInteractive = true
+	t.Cleanup(func() { nonInteractive = previous })
+	if recreateSession("demo", false) {
+		t.Fatal("Expected the session not to be recreated without confirmation")
+	}
+	if fake.sessions["demo"] {
+		t.Fatal("Expected no session to be created without confirmation")
+	}
+
+	if !recreateSession("demo", true) {
+		t.Fatal("Expected the session to be recreated")
+	}
+	if !fake.sessions["demo"] {
+		t.Error("Expected the session to be created")
+	}
+
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"%100", "%101"}
+	for i, worker := range saved.Workers {
+		if worker.PaneID != want[i] || worker.TmuxSession != "demo" {
+			t.Errorf("Worker %s: expected pane %s in session demo, got %s in %q", worker.ID, want[i], worker.PaneID, worker.TmuxSession)
+		}
+	}
+	if saved.Workers[0].Note != "keep me" || saved.Workers[0].Branch != "feature/one" || len(saved.Workers[1].Tags) != 1 {
+		t.Errorf("Expected the workers to keep their metadata, got %+v", saved.Workers)
+	}
+	if len(fake.sent) != 2 || !strings.HasPrefix(fake.sent[0], "%100 ") || !strings.HasSuffix(fake.sent[0], "claude") {
+		t.Errorf("Expected the init command sent to the new panes, got %v", fake.sent)
+	}
+}
+
+func TestRecreateSessionWithoutWorkers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := saveConfig(&Config{}); err != nil {
+		t.Fatal(err)
+	}
+	fake := newSessionMultiplexer()
+	useMultiplexer(t, fake)
+
+	if recreateSession("demo", true) {
+		t.Error("Expected nothing to recreate without recorded workers")
+	}
+	if fake.sessions["demo"] {
+		t.Error("Expected no session to be created")
+	}
+}
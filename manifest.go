@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultManifestFile = "gtw-manifest.yaml"
+	manifestVersion     = 1
+)
+
+// Manifest is the team-shareable list of desired workers. It holds no
+// machine state (panes, paths, timestamps) so that it can be committed.
+type Manifest struct {
+	Version int              `yaml:"version"`
+	Workers []ManifestWorker `yaml:"workers"`
+}
+
+// ManifestWorker is a desired worker in the manifest.
+type ManifestWorker struct {
+	ID        string   `yaml:"id"`
+	Base      string   `yaml:"base,omitempty"`    // Commit or branch to create the branch from
+	Profile   string   `yaml:"profile,omitempty"` // Name of a profile in the config
+	Note      string   `yaml:"note,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+	DependsOn []string `yaml:"depends_on,omitempty"` // Workers `gtw up` creates and waits to be ready first
+}
+
+// SyncOptions holds the settings given to `gtw sync`.
+type SyncOptions struct {
+	Yes    bool
+	DryRun bool
+	Prune  bool // Remove workers that are not in the manifest
+	Force  bool // Passed to remove for pruned workers
+}
+
+// syncPlan is what `gtw sync` changes to make the workers match a manifest.
+type syncPlan struct {
+	Add    []ManifestWorker
+	Update []ManifestWorker // Existing workers whose note, tags or profile differ
+	Remove []Worker         // Workers not in the manifest
+}
+
+func (p syncPlan) empty() bool {
+	return len(p.Add) == 0 && len(p.Update) == 0 && len(p.Remove) == 0
+}
+
+func init() {
+	manifestCmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Export or import the worker list as a shareable YAML manifest",
+		Long: `A manifest lists the desired workers (ID, base branch, profile, note and
+tags) without machine state such as panes or paths, so that it can be
+committed and shared by a team. Use 'gtw sync' to make the local workers
+match it.`,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Write the current workers to a manifest (default: " + defaultManifestFile + ", '-' for stdout)",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exportManifest(manifestArg(args))
+		},
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Create the workers of a manifest that do not exist yet",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			syncManifest(manifestArg(args), SyncOptions{Yes: true}, true)
+		},
+	}
+
+	manifestCmd.AddCommand(exportCmd, importCmd)
+	rootCmd.AddCommand(manifestCmd)
+
+	var syncOpts SyncOptions
+	syncCmd := &cobra.Command{
+		Use:   "sync [file]",
+		Short: "Reconcile the local workers with a manifest",
+		Long: `Create the workers listed in the manifest (default: ` + defaultManifestFile + `) that
+do not exist yet and update the note, tags and profile of the ones that do.
+With --prune, workers missing from the manifest are removed as with
+'gtw remove', saving unsaved work to a backup branch first.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			syncManifest(manifestArg(args), syncOpts, false)
+		},
+	}
+	syncCmd.Flags().BoolVarP(&syncOpts.Yes, "yes", "y", false, "Apply the changes without asking")
+	syncCmd.Flags().BoolVar(&syncOpts.DryRun, "dry-run", false, "Only show the changes")
+	syncCmd.Flags().BoolVar(&syncOpts.Prune, "prune", false, "Remove workers that are not in the manifest")
+	syncCmd.Flags().BoolVarP(&syncOpts.Force, "force", "f", false, "Prune workers even if they are running a program")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// manifestArg returns the manifest file given on the command line, resolved
+// against the directory gtw was started in.
+func manifestArg(args []string) string {
+	if len(args) == 0 {
+		return defaultManifestFile
+	}
+	if args[0] == "-" {
+		return "-"
+	}
+	return userPath(args[0])
+}
+
+// buildManifest converts workers into a manifest.
+func buildManifest(workers []Worker) *Manifest {
+	manifest := &Manifest{Version: manifestVersion, Workers: []ManifestWorker{}}
+	for _, worker := range workers {
+		manifest.Workers = append(manifest.Workers, ManifestWorker{
+			ID:      worker.ID,
+			Base:    worker.Base,
+			Profile: worker.Profile,
+			Note:    worker.Note,
+			Tags:    worker.Tags,
+		})
+	}
+	return manifest
+}
+
+// parseManifest decodes and validates a manifest.
+func parseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d (expected %d)", manifest.Version, manifestVersion)
+	}
+	seen := make(map[string]bool)
+	for _, worker := range manifest.Workers {
+		if err := validateWorkerID(worker.ID); err != nil {
+			return nil, err
+		}
+		if seen[worker.ID] {
+			return nil, fmt.Errorf("worker '%s' is listed twice", worker.ID)
+		}
+		seen[worker.ID] = true
+	}
+	if _, err := dependencyOrder(manifest.Workers); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// dependencyOrder sorts workers so that each one comes after the workers
+// it depends on, otherwise keeping their order.
+func dependencyOrder(workers []ManifestWorker) ([]ManifestWorker, error) {
+	byID := make(map[string]ManifestWorker)
+	for _, worker := range workers {
+		byID[worker.ID] = worker
+	}
+
+	var ordered []ManifestWorker
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+	var visit func(worker ManifestWorker) error
+	visit = func(worker ManifestWorker) error {
+		if done[worker.ID] {
+			return nil
+		}
+		if visiting[worker.ID] {
+			return fmt.Errorf("worker '%s' is part of a dependency cycle", worker.ID)
+		}
+		visiting[worker.ID] = true
+		for _, id := range worker.DependsOn {
+			dependency, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("worker '%s' depends on '%s', which is not listed", worker.ID, id)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[worker.ID] = false
+		done[worker.ID] = true
+		ordered = append(ordered, worker)
+		return nil
+	}
+	for _, worker := range workers {
+		if err := visit(worker); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return manifest, nil
+}
+
+// planSync compares the workers with the manifest.
+func planSync(workers []Worker, manifest *Manifest) syncPlan {
+	var plan syncPlan
+	wanted := make(map[string]bool)
+	for _, desired := range manifest.Workers {
+		wanted[desired.ID] = true
+		index := slices.IndexFunc(workers, func(w Worker) bool { return w.ID == desired.ID })
+		if index == -1 {
+			plan.Add = append(plan.Add, desired)
+			continue
+		}
+		worker := workers[index]
+		if worker.Note != desired.Note || worker.Profile != desired.Profile ||
+			!slices.Equal(worker.Tags, normalizeTags(desired.Tags)) {
+			plan.Update = append(plan.Update, desired)
+		}
+	}
+	for _, worker := range workers {
+		if !wanted[worker.ID] {
+			plan.Remove = append(plan.Remove, worker)
+		}
+	}
+	return plan
+}
+
+func exportManifest(path string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(buildManifest(config.Workers)); err != nil {
+		fmt.Printf("Error encoding manifest: %v\n", err)
+		return
+	}
+	data := buf.Bytes()
+	if path == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Exported %d worker(s) to %s\n", len(config.Workers), path)
+}
+
+// checkManifestProfiles reports a worker of the manifest using a profile
+// that the config does not define.
+func checkManifestProfiles(config *Config, manifest *Manifest) error {
+	for _, desired := range manifest.Workers {
+		if _, ok := config.Profiles[desired.Profile]; desired.Profile != "" && !ok {
+			return fmt.Errorf("worker '%s' uses unknown profile '%s' (available: %s)",
+				desired.ID, desired.Profile, strings.Join(config.profileNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// syncManifest makes the workers match the manifest at path. With addOnly
+// (`gtw manifest import`) existing workers are left untouched.
+func syncManifest(path string, opts SyncOptions, addOnly bool) {
+	manifest, err := loadManifest(path)
+	if err != nil {
+		fmt.Printf("Error loading manifest: %v\n", err)
+		return
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	if err := checkManifestProfiles(config, manifest); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	plan := planSync(config.Workers, manifest)
+	if addOnly {
+		plan.Update, plan.Remove = nil, nil
+	}
+	if !opts.Prune {
+		if len(plan.Remove) > 0 {
+			fmt.Printf("%d worker(s) are not in the manifest; run with --prune to remove them\n", len(plan.Remove))
+		}
+		plan.Remove = nil
+	}
+	if plan.empty() {
+		fmt.Println("✅ Workers already match the manifest")
+		return
+	}
+
+	for _, desired := range plan.Add {
+		fmt.Printf("  + %s\n", desired.ID)
+	}
+	for _, desired := range plan.Update {
+		fmt.Printf("  ~ %s\n", desired.ID)
+	}
+	for _, worker := range plan.Remove {
+		fmt.Printf("  - %s\n", worker.ID)
+	}
+	if opts.DryRun {
+		return
+	}
+	if !opts.Yes && !confirm("Apply these changes?") {
+		fmt.Println("Aborted")
+		return
+	}
+
+	applySyncPlan(config, plan, opts.Force)
+}
+
+// applySyncPlan updates, removes and creates the workers of plan and
+// returns how many workers could not be created.
+func applySyncPlan(config *Config, plan syncPlan, force bool) int {
+	if len(plan.Update) > 0 {
+		for _, desired := range plan.Update {
+			worker := &config.Workers[findWorkerIndex(config, desired.ID)]
+			worker.Note = desired.Note
+			worker.Tags = normalizeTags(desired.Tags)
+			worker.Profile = desired.Profile
+		}
+		if err := saveConfig(config); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return len(plan.Add)
+		}
+		fmt.Printf("✅ Updated %d worker(s)\n", len(plan.Update))
+	}
+
+	for _, worker := range plan.Remove {
+		removeWorker(worker.ID, RemoveOptions{Yes: true, Force: force})
+	}
+
+	failed := 0
+	for _, desired := range plan.Add {
+		if !addManifestWorker(desired) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("❌ %d worker(s) could not be created\n", failed)
+	}
+	return failed
+}
+
+// addManifestWorker creates a worker of a manifest and reports whether it
+// exists afterwards.
+func addManifestWorker(desired ManifestWorker) bool {
+	addWorker(desired.ID, AddOptions{
+		Tags:    desired.Tags,
+		Note:    desired.Note,
+		Base:    desired.Base,
+		Profile: desired.Profile,
+	})
+	config, err := loadConfig()
+	return err == nil && findWorkerIndex(config, desired.ID) != -1
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	workers := []Worker{
+		{ID: "api", Base: "main", Profile: "claude", Note: "fix auth", Tags: []string{"backend"}, PaneID: "%3", WorktreePath: "worktree/api"},
+		{ID: "docs"},
+	}
+
+	data, err := yaml.Marshal(buildManifest(workers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "%3") || strings.Contains(string(data), "worktree/api") {
+		t.Errorf("Manifest contains machine state:\n%s", data)
+	}
+
+	manifest, err := parseManifest(data)
+	if err != nil {
+		t.Fatalf("parseManifest() error: %v", err)
+	}
+	if len(manifest.Workers) != 2 || manifest.Workers[0].Profile != "claude" || manifest.Workers[0].Base != "main" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	tests := map[string]string{
+		"version":    "version: 2\nworkers: []\n",
+		"duplicate":  "version: 1\nworkers:\n  - id: a\n  - id: a\n",
+		"invalid":    "version: 1\nworkers:\n  - id: ../a\n",
+		"unknown":    "version: 1\nworkers:\n  - id: a\n    pane_id: '%1'\n",
+		"dependency": "version: 1\nworkers:\n  - id: a\n    depends_on: [b]\n",
+		"cycle":      "version: 1\nworkers:\n  - id: a\n    depends_on: [b]\n  - id: b\n    depends_on: [a]\n",
+	}
+	for name, data := range tests {
+		if _, err := parseManifest([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	ordered, err := dependencyOrder([]ManifestWorker{
+		{ID: "web", DependsOn: []string{"api"}},
+		{ID: "docs"},
+		{ID: "api", DependsOn: []string{"db"}},
+		{ID: "db"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, worker := range ordered {
+		ids = append(ids, worker.ID)
+	}
+	if got := strings.Join(ids, ","); got != "db,api,web,docs" {
+		t.Errorf("Expected dependencies first, got %s", got)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	workers := []Worker{
+		{ID: "same", Note: "n", Tags: []string{"a"}},
+		{ID: "changed", Note: "old"},
+		{ID: "extra"},
+	}
+	manifest := &Manifest{Version: 1, Workers: []ManifestWorker{
+		{ID: "same", Note: "n", Tags: []string{" a", "a"}},
+		{ID: "changed", Note: "new"},
+		{ID: "missing", Base: "main"},
+	}}
+
+	plan := planSync(workers, manifest)
+	if len(plan.Add) != 1 || plan.Add[0].ID != "missing" {
+		t.Errorf("Add = %+v", plan.Add)
+	}
+	if len(plan.Update) != 1 || plan.Update[0].ID != "changed" {
+		t.Errorf("Update = %+v", plan.Update)
+	}
+	if len(plan.Remove) != 1 || plan.Remove[0].ID != "extra" {
+		t.Errorf("Remove = %+v", plan.Remove)
+	}
+
+	if plan := planSync(workers[:1], &Manifest{Version: 1, Workers: manifest.Workers[:1]}); !plan.empty() {
+		t.Errorf("Expected an empty plan, got %+v", plan)
+	}
+}
+
+func TestInitCommandFor(t *testing.T) {
+	config := &Config{
+		InitCommand: "default",
+		Profiles:    map[string]Profile{"claude": {InitCommand: "claude"}},
+	}
+	if got := config.initCommandFor(Worker{Profile: "claude"}); got != "claude" {
+		t.Errorf("initCommandFor(claude) = %q", got)
+	}
+	if got := config.initCommandFor(Worker{}); got != "default" {
+		t.Errorf("initCommandFor() = %q", got)
+	}
+	if got := config.initCommandFor(Worker{Profile: "gone"}); got != "default" {
+		t.Errorf("initCommandFor(gone) = %q", got)
+	}
+}
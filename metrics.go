@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Counters are cumulative event counts persisted in the config file so they
+// survive across gtw invocations and daemon restarts.
+type Counters struct {
+	WorkersAdded   int64 `json:"workers_added,omitempty"`
+	WorkersRemoved int64 `json:"workers_removed,omitempty"`
+	InitFailures   int64 `json:"init_failures,omitempty"`
+}
+
+// counters returns the config's counters, creating them on first use.
+func (c *Config) counters() *Counters {
+	if c.Counters == nil {
+		c.Counters = &Counters{}
+	}
+	return c.Counters
+}
+
+// renderMetrics formats the workspace state in the Prometheus text
+// exposition format. alive reports whether a worker's pane is running.
+func renderMetrics(config *Config, now time.Time, alive func(paneID string) bool) string {
+	var b strings.Builder
+
+	workers := append([]Worker(nil), config.Workers...)
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+
+	active := 0
+	for _, worker := range workers {
+		if alive(worker.PaneID) {
+			active++
+		}
+	}
+
+	b.WriteString("# HELP gtw_workers Number of workers by pane state.\n")
+	b.WriteString("# TYPE gtw_workers gauge\n")
+	fmt.Fprintf(&b, "gtw_workers{state=\"active\"} %d\n", active)
+	fmt.Fprintf(&b, "gtw_workers{state=\"inactive\"} %d\n", len(workers)-active)
+
+	b.WriteString("# HELP gtw_worker_age_seconds Seconds since the worker was created.\n")
+	b.WriteString("# TYPE gtw_worker_age_seconds gauge\n")
+	for _, worker := range workers {
+		fmt.Fprintf(&b, "gtw_worker_age_seconds{worker=\"%s\"} %.0f\n", escapeLabelValue(worker.ID), now.Sub(worker.CreatedAt).Seconds())
+	}
+
+	counters := Counters{}
+	if config.Counters != nil {
+		counters = *config.Counters
+	}
+	writeCounter(&b, "gtw_workers_added_total", "Workers created with gtw add.", counters.WorkersAdded)
+	writeCounter(&b, "gtw_workers_removed_total", "Workers removed with gtw remove.", counters.WorkersRemoved)
+	writeCounter(&b, "gtw_init_failures_total", "Initialization commands that could not be sent to a pane.", counters.InitFailures)
+
+	return b.String()
+}
+
+func writeCounter(b *strings.Builder, name, help string, value int64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	fmt.Fprintf(b, "%s %d\n", name, value)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// serveMetrics exposes /metrics on addr until the process exits. The config
+// is re-read on every scrape because other gtw invocations modify it.
+func serveMetrics(addr string) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		config, err := loadConfig()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error loading config: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, renderMetrics(config, time.Now(), livePaneCheck()))
+	})
+
+	fmt.Printf("Serving metrics on http://%s/metrics\n", addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		fmt.Printf("Warning: Metrics server stopped: %v\n", err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderMetrics(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	config := &Config{
+		Workers: []Worker{
+			{ID: "b", PaneID: "%2", CreatedAt: now.Add(-time.Hour)},
+			{ID: "a", PaneID: "%1", CreatedAt: now.Add(-90 * time.Second)},
+			{ID: `c"x`, PaneID: "%3", CreatedAt: now},
+		},
+		Counters: &Counters{WorkersAdded: 5, WorkersRemoved: 2, InitFailures: 1},
+	}
+	alive := func(paneID string) bool { return paneID != "%3" }
+
+	output := renderMetrics(config, now, alive)
+
+	for _, want := range []string{
+		`gtw_workers{state="active"} 2`,
+		`gtw_workers{state="inactive"} 1`,
+		`gtw_worker_age_seconds{worker="a"} 90`,
+		`gtw_worker_age_seconds{worker="b"} 3600`,
+		`gtw_worker_age_seconds{worker="c\"x"} 0`,
+		"gtw_workers_added_total 5",
+		"gtw_workers_removed_total 2",
+		"gtw_init_failures_total 1",
+		"# TYPE gtw_workers_added_total counter",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	if strings.Index(output, `worker="a"`) > strings.Index(output, `worker="b"`) {
+		t.Errorf("Expected workers to be sorted by ID")
+	}
+}
+
+func TestRenderMetricsWithoutCounters(t *testing.T) {
+	output := renderMetrics(&Config{}, time.Now(), func(string) bool { return true })
+
+	if !strings.Contains(output, "gtw_workers_added_total 0") {
+		t.Errorf("Expected zero counters when none are recorded, got:\n%s", output)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Multiplexer is the terminal multiplexer backend that hosts the session and
+// worker panes. tmux is the default; zellij and GNU screen cover the core
+// worker workflow (init, add, remove, list, attach, send). Pane IDs are
+// opaque strings owned by the backend.
+type Multiplexer interface {
+	Name() string
+
+	HasSession(session string) bool
+	NewSession(session, dir, title string) error
+	KillSession(session string) error
+	Attach(session string) error
+	Detach() error
+	// Inside reports whether gtw runs inside a client of this multiplexer.
+	Inside() bool
+
+	// NewPane creates the pane of worker id rooted at dir, titled with the
+	// ID and, where the backend allows, with GTW_WORKER_ID set. It returns the
+	// pane's index and ID.
+	NewPane(session, dir, id string) (int, string, error)
+	KillPane(paneID string) error
+	PaneExists(paneID string) bool
+	Focus(paneID string) error
+	// SendKeys types text literally into the pane and presses Enter.
+	SendKeys(paneID, text string) error
+	// Capture returns the last lines of the pane's output.
+	Capture(paneID string, lines int) (string, error)
+	// CurrentCommand returns the pane's foreground command, or "" when the
+	// backend cannot tell.
+	CurrentCommand(paneID string) (string, error)
+}
+
+const defaultMultiplexer = "tmux"
+
+// mux is the active backend, selected from GTW_MULTIPLEXER or the
+// multiplexer config value.
+var mux Multiplexer = &TmuxMultiplexer{}
+
+func newMultiplexer(name string) (Multiplexer, error) {
+	switch strings.ToLower(name) {
+	case "", "tmux":
+		return &TmuxMultiplexer{}, nil
+	case "zellij":
+		return &ZellijMultiplexer{}, nil
+	case "screen":
+		return &ScreenMultiplexer{}, nil
+	}
+	return nil, fmt.Errorf("unknown multiplexer %q (expected tmux, zellij or screen)", name)
+}
+
+// PaneLister is implemented by backends that can list every live pane with
+// a single command.
+type PaneLister interface {
+	ListPanes() (map[string]bool, error)
+}
+
+// Paster is implemented by backends that can paste text into a pane as one
+// block, so that its newlines do not submit each line on its own.
+type Paster interface {
+	Paste(paneID, text string) error
+}
+
+// Observer is implemented by backends that can attach a read-only client
+// showing a pane, for watching a worker without typing into it.
+type Observer interface {
+	Observe(paneID string) error
+}
+
+// livePaneCheck returns a function reporting whether a pane is running. With
+// a PaneLister the panes are listed once up front, so checking many workers
+// costs one command instead of one per worker.
+func livePaneCheck() func(paneID string) bool {
+	if lister, ok := mux.(PaneLister); ok {
+		if panes, err := lister.ListPanes(); err == nil {
+			return func(paneID string) bool { return panes[paneID] }
+		}
+	}
+	return mux.PaneExists
+}
+
+// resolveMultiplexer picks the backend from the environment or config.
+func resolveMultiplexer(configured string) Multiplexer {
+	name := configured
+	if env := os.Getenv("GTW_MULTIPLEXER"); env != "" {
+		name = env
+	}
+
+	m, err := newMultiplexer(name)
+	if err != nil {
+		fmt.Printf("Warning: %v, using %s\n", err, defaultMultiplexer)
+		return &TmuxMultiplexer{}
+	}
+	return m
+}
+
+// requireTmux reports whether the active backend is tmux, printing an
+// explanation for features that rely on tmux internals.
+func requireTmux(feature string) bool {
+	if mux.Name() == "tmux" {
+		return true
+	}
+	fmt.Printf("Error: '%s' requires the tmux multiplexer (current: %s)\n", feature, mux.Name())
+	return false
+}
+
+// splitQualifiedPane splits a "<session>:<name>" pane ID used by backends
+// whose panes are only addressable within a session.
+func splitQualifiedPane(paneID string) (string, string, error) {
+	session, name, ok := strings.Cut(paneID, ":")
+	if !ok || session == "" || name == "" {
+		return "", "", fmt.Errorf("invalid pane ID %q", paneID)
+	}
+	return session, name, nil
+}
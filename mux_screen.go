@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ScreenMultiplexer places each worker in its own GNU screen window, titled
+// after the worker. Pane IDs have the form "<session>:<window title>".
+// Support is minimal: screen cannot report foreground commands, so idle
+// detection relies on output changes only.
+type ScreenMultiplexer struct{}
+
+func screenCommand(args ...string) *Cmd {
+	return newCommand("screen", args...)
+}
+
+// screenWindowCommand runs a screen command against one window.
+func screenWindowCommand(session, window string, args ...string) *Cmd {
+	return screenCommand(append([]string{"-S", session, "-p", window, "-X"}, args...)...)
+}
+
+func (s *ScreenMultiplexer) Name() string { return "screen" }
+
+func (s *ScreenMultiplexer) HasSession(session string) bool {
+	return screenCommand("-S", session, "-Q", "select", ".").Run() == nil
+}
+
+func (s *ScreenMultiplexer) NewSession(session, dir, title string) error {
+	cmd := screenCommand("-dmS", session, "-t", title)
+	cmd.Dir = dir
+	return cmd.Run()
+}
+
+func (s *ScreenMultiplexer) KillSession(session string) error {
+	return screenCommand("-S", session, "-X", "quit").Run()
+}
+
+func (s *ScreenMultiplexer) Attach(session string) error {
+	cmd := screenCommand("-r", session)
+	cmd.Timeout = 0
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (s *ScreenMultiplexer) Detach() error {
+	return screenCommand("-S", os.Getenv("STY"), "-X", "detach").Run()
+}
+
+func (s *ScreenMultiplexer) Inside() bool {
+	return os.Getenv("STY") != ""
+}
+
+func (s *ScreenMultiplexer) NewPane(session, dir, title string) (int, string, error) {
+	// New windows start in screen's current directory
+	if err := screenCommand("-S", session, "-X", "chdir", muxPath(dir)).Run(); err != nil {
+		return 0, "", err
+	}
+	// New windows inherit screen's environment
+	if err := screenCommand("-S", session, "-X", "setenv", workerIDEnv, title).Run(); err != nil {
+		return 0, "", err
+	}
+	if err := screenCommand("-S", session, "-X", "screen", "-t", title).Run(); err != nil {
+		return 0, "", err
+	}
+
+	index := 0
+	if output, err := screenWindowCommand(session, title, "number").Output(); err == nil {
+		index, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+	}
+	return index, session + ":" + title, nil
+}
+
+func (s *ScreenMultiplexer) KillPane(paneID string) error {
+	session, window, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return err
+	}
+	return screenWindowCommand(session, window, "kill").Run()
+}
+
+func (s *ScreenMultiplexer) PaneExists(paneID string) bool {
+	session, window, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return false
+	}
+	output, err := screenCommand("-S", session, "-Q", "windows", "%t|").Output()
+	if err != nil {
+		return false
+	}
+	for _, title := range strings.Split(string(output), "|") {
+		if strings.TrimSpace(title) == window {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ScreenMultiplexer) Focus(paneID string) error {
+	session, window, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return err
+	}
+	return screenCommand("-S", session, "-X", "select", window).Run()
+}
+
+func (s *ScreenMultiplexer) SendKeys(paneID, text string) error {
+	session, window, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return err
+	}
+	return screenWindowCommand(session, window, "stuff", screenStuffEscape(text)+"\r").Run()
+}
+
+// screenStuffEscape protects text from stuff's own escapes, which would
+// turn "^C" into a control character and eat backslashes.
+func screenStuffEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "^", `\^`).Replace(text)
+}
+
+func (s *ScreenMultiplexer) Capture(paneID string, lines int) (string, error) {
+	session, window, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return "", err
+	}
+
+	dump, err := os.CreateTemp("", "gtw-screen-*.txt")
+	if err != nil {
+		return "", err
+	}
+	dump.Close()
+	defer os.Remove(dump.Name())
+
+	if err := screenWindowCommand(session, window, "hardcopy", "-h", dump.Name()).Run(); err != nil {
+		return "", fmt.Errorf("capturing %s: %v", paneID, err)
+	}
+	data, err := os.ReadFile(dump.Name())
+	if err != nil {
+		return "", err
+	}
+	return lastLines(string(data), lines), nil
+}
+
+func (s *ScreenMultiplexer) CurrentCommand(paneID string) (string, error) {
+	if !s.PaneExists(paneID) {
+		return "", fmt.Errorf("pane %s not found", paneID)
+	}
+	return "", nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewMultiplexer(t *testing.T) {
+	for _, name := range []string{"", "tmux", "zellij", "Screen"} {
+		if _, err := newMultiplexer(name); err != nil {
+			t.Errorf("newMultiplexer(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := newMultiplexer("byobu"); err == nil {
+		t.Error("Expected error for unknown multiplexer")
+	}
+}
+
+func TestSplitQualifiedPane(t *testing.T) {
+	session, name, err := splitQualifiedPane("myproject:issue-1")
+	if err != nil || session != "myproject" || name != "issue-1" {
+		t.Errorf("splitQualifiedPane() = %q, %q, %v", session, name, err)
+	}
+
+	for _, bad := range []string{"%1", ":issue-1", "myproject:"} {
+		if _, _, err := splitQualifiedPane(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\n", 2); got != "b\nc\n" {
+		t.Errorf("lastLines() = %q", got)
+	}
+	if got := lastLines("a\n", 5); got != "a\n" {
+		t.Errorf("lastLines() = %q", got)
+	}
+}
+
+func TestScreenStuffEscape(t *testing.T) {
+	if got := screenStuffEscape(`echo ^C \n`); got != `echo \^C \\n` {
+		t.Errorf("screenStuffEscape() = %q", got)
+	}
+}
+
+// listingMultiplexer lists its panes at once and counts the calls.
+type listingMultiplexer struct {
+	fakeMultiplexer
+	lists int
+}
+
+func (l *listingMultiplexer) ListPanes() (map[string]bool, error) {
+	l.lists++
+	return l.alive, nil
+}
+
+func TestLivePaneCheck(t *testing.T) {
+	previous := mux
+	t.Cleanup(func() { mux = previous })
+
+	lister := &listingMultiplexer{fakeMultiplexer: fakeMultiplexer{alive: map[string]bool{"%1": true}}}
+	mux = lister
+	alive := livePaneCheck()
+	if !alive("%1") || alive("%2") || alive("") {
+		t.Error("Unexpected liveness from the pane listing")
+	}
+	if lister.lists != 1 {
+		t.Errorf("Expected the panes to be listed once, got %d", lister.lists)
+	}
+
+	// Backends without a listing are checked pane by pane
+	mux = &ScreenMultiplexer{}
+	if livePaneCheck()("%1") {
+		t.Error("Expected the screen backend's own check to be used")
+	}
+}
+
+// sessionMultiplexer keeps sessions and panes in memory, for commands that
+// build and tear down sessions. New panes are numbered from %100.
+type sessionMultiplexer struct {
+	fakeMultiplexer
+	sessions map[string]bool
+	created  []string // New panes, in order
+	killed   []string
+	attached []string
+}
+
+func newSessionMultiplexer(alive ...string) *sessionMultiplexer {
+	s := &sessionMultiplexer{fakeMultiplexer: fakeMultiplexer{alive: map[string]bool{}}, sessions: map[string]bool{}}
+	for _, paneID := range alive {
+		s.alive[paneID] = true
+	}
+	return s
+}
+
+func (s *sessionMultiplexer) Name() string                   { return "fake" }
+func (s *sessionMultiplexer) HasSession(session string) bool { return s.sessions[session] }
+
+func (s *sessionMultiplexer) NewSession(session, dir, title string) error {
+	s.sessions[session] = true
+	return nil
+}
+
+func (s *sessionMultiplexer) KillSession(session string) error {
+	delete(s.sessions, session)
+	return nil
+}
+
+func (s *sessionMultiplexer) NewPane(session, dir, id string) (int, string, error) {
+	if !s.sessions[session] {
+		return 0, "", fmt.Errorf("session %s not found", session)
+	}
+	paneID := fmt.Sprintf("%%%d", 100+len(s.created))
+	s.created = append(s.created, paneID)
+	s.alive[paneID] = true
+	return len(s.created), paneID, nil
+}
+
+func (s *sessionMultiplexer) KillPane(paneID string) error {
+	delete(s.alive, paneID)
+	s.killed = append(s.killed, paneID)
+	return nil
+}
+
+func (s *sessionMultiplexer) Attach(session string) error {
+	s.attached = append(s.attached, session)
+	return nil
+}
+
+func (s *sessionMultiplexer) Capture(paneID string, lines int) (string, error) { return "$ ", nil }
+
+func (s *sessionMultiplexer) CurrentCommand(paneID string) (string, error) {
+	if !s.alive[paneID] {
+		return "", fmt.Errorf("pane %s not found", paneID)
+	}
+	return "zsh", nil
+}
+
+func (s *sessionMultiplexer) ListPanes() (map[string]bool, error) { return s.alive, nil }
+
+// useMultiplexer makes m the backend for the rest of the test.
+func useMultiplexer(t *testing.T, m Multiplexer) {
+	previous := mux
+	mux = m
+	t.Cleanup(func() { mux = previous })
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TmuxMultiplexer places workers as panes in the worker window (window 0
+// unless worker_window says otherwise) of the session.
+type TmuxMultiplexer struct{}
+
+func (t *TmuxMultiplexer) Name() string { return "tmux" }
+
+func (t *TmuxMultiplexer) HasSession(session string) bool {
+	return tmuxCommand("has-session", "-t", session).Run() == nil
+}
+
+func (t *TmuxMultiplexer) NewSession(session, dir, title string) error {
+	if err := tmuxCommand("new-session", "-d", "-s", session, "-c", muxPath(dir)).Run(); err != nil {
+		return err
+	}
+	// Set title for the initial pane (project root)
+	tmuxCommand("select-pane", "-t", session+":0.0", "-T", title).Run()
+	return nil
+}
+
+func (t *TmuxMultiplexer) KillSession(session string) error {
+	return tmuxCommand("kill-session", "-t", session).Run()
+}
+
+func (t *TmuxMultiplexer) Attach(session string) error {
+	cmd := tmuxCommand("attach-session", "-t", session)
+	cmd.Timeout = 0 // Interactive; runs until the client detaches
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Detach detaches the client gtw was run from, not whichever client tmux
+// considers current.
+func (t *TmuxMultiplexer) Detach() error {
+	if client := invokingClient(); client != "" {
+		return tmuxCommand("detach-client", "-t", client).Run()
+	}
+	return tmuxCommand("detach-client").Run()
+}
+
+func (t *TmuxMultiplexer) Inside() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+func (t *TmuxMultiplexer) NewPane(session, dir, id string) (int, string, error) {
+	target, paneIndex, paneID, err := ensureWorkerWindow(session, paneLayout.Window, muxPath(dir), id)
+	if err != nil {
+		return 0, "", err
+	}
+	if paneID == "" {
+		if paneIndex, paneID, err = splitWorkerPane(target, dir, id); err != nil {
+			return 0, "", err
+		}
+	}
+	tmuxCommand("select-pane", "-t", paneID, "-T", id).Run()
+	return paneIndex, paneID, nil
+}
+
+func (t *TmuxMultiplexer) KillPane(paneID string) error {
+	return tmuxCommand("kill-pane", "-t", paneID).Run()
+}
+
+// PaneExists checks all panes of the server. list-panes succeeds even when
+// the filter matches nothing, so the output has to be inspected.
+func (t *TmuxMultiplexer) PaneExists(paneID string) bool {
+	if paneID == "" {
+		return false
+	}
+	output, err := tmuxCommand("list-panes", "-a", "-f", fmt.Sprintf("#{==:#{pane_id},%s}", paneID), "-F", "#{pane_id}").Output()
+	return err == nil && strings.TrimSpace(string(output)) == paneID
+}
+
+// ListPanes returns the IDs of all panes of the server.
+func (t *TmuxMultiplexer) ListPanes() (map[string]bool, error) {
+	output, err := tmuxCommand("list-panes", "-a", "-F", "#{pane_id}").Output()
+	if err != nil {
+		return nil, err
+	}
+	panes := map[string]bool{}
+	for _, paneID := range strings.Fields(string(output)) {
+		panes[paneID] = true
+	}
+	return panes, nil
+}
+
+func (t *TmuxMultiplexer) Focus(paneID string) error {
+	return tmuxCommand("select-pane", "-t", paneID).Run()
+}
+
+func (t *TmuxMultiplexer) SendKeys(paneID, text string) error {
+	if err := tmuxCommand("send-keys", "-t", paneID, "-l", text).Run(); err != nil {
+		return err
+	}
+	return tmuxCommand("send-keys", "-t", paneID, "Enter").Run()
+}
+
+// Paste loads text into a buffer of its own and pastes it with -p, which
+// wraps it in bracketed paste when the program in the pane asked for that.
+func (t *TmuxMultiplexer) Paste(paneID, text string) error {
+	buffer := fmt.Sprintf("gtw-prompt-%d", os.Getpid())
+	load := tmuxCommand("load-buffer", "-b", buffer, "-")
+	load.Stdin = strings.NewReader(text)
+	if err := load.Run(); err != nil {
+		return err
+	}
+	return tmuxCommand("paste-buffer", "-d", "-p", "-b", buffer, "-t", paneID).Run()
+}
+
+// Observe attaches a read-only client to a session grouped with the
+// pane's session, so that selecting the pane's window does not switch the
+// window of the other clients. ignore-size keeps the observer's terminal
+// from resizing the panes.
+func (t *TmuxMultiplexer) Observe(paneID string) error {
+	output, err := tmuxCommand("display-message", "-t", paneID, "-p", "#{session_name}|#{window_id}").Output()
+	if err != nil {
+		return fmt.Errorf("pane %s not found: %v", paneID, err)
+	}
+	session, window, _ := strings.Cut(strings.TrimSpace(string(output)), "|")
+
+	observer := fmt.Sprintf("%s-observe-%d", session, os.Getpid())
+	if err := tmuxCommand("new-session", "-d", "-t", session, "-s", observer).Run(); err != nil {
+		return fmt.Errorf("creating observer session: %v", err)
+	}
+	defer tmuxCommand("kill-session", "-t", observer).Run()
+	tmuxCommand("select-window", "-t", observer+":"+window).Run()
+
+	// destroy-unattached is set once attached: it would kill the session
+	// right away before
+	cmd := tmuxCommand("attach-session", "-f", "read-only,ignore-size", "-t", observer,
+		";", "set-option", "-t", observer, "destroy-unattached", "on")
+	cmd.Timeout = 0 // Interactive; runs until the client detaches
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (t *TmuxMultiplexer) Capture(paneID string, lines int) (string, error) {
+	output, err := tmuxCommand("capture-pane", "-p", "-t", paneID, "-S", fmt.Sprintf("-%d", lines)).Output()
+	if err != nil {
+		return "", fmt.Errorf("capturing pane %s: %v", paneID, err)
+	}
+	return string(output), nil
+}
+
+func (t *TmuxMultiplexer) CurrentCommand(paneID string) (string, error) {
+	output, err := tmuxCommand("display-message", "-t", paneID, "-p", "#{pane_current_command}").Output()
+	if err != nil {
+		return "", fmt.Errorf("pane %s not found: %v", paneID, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ZellijMultiplexer places each worker in its own tab, because zellij's CLI
+// can address tabs by name but not individual panes. Pane IDs have the form
+// "<session>:<tab name>".
+type ZellijMultiplexer struct{}
+
+func zellijCommand(args ...string) *Cmd {
+	return newCommand("zellij", args...)
+}
+
+// zellijAction runs `zellij --session <session> action ...`.
+func zellijAction(session string, args ...string) *Cmd {
+	return zellijCommand(append([]string{"--session", session, "action"}, args...)...)
+}
+
+func (z *ZellijMultiplexer) Name() string { return "zellij" }
+
+func (z *ZellijMultiplexer) HasSession(session string) bool {
+	output, err := zellijCommand("list-sessions", "--short", "--no-formatting").Output()
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == session {
+			return true
+		}
+	}
+	return false
+}
+
+func (z *ZellijMultiplexer) NewSession(session, dir, title string) error {
+	cmd := zellijCommand("attach", "--create-background", session)
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	zellijAction(session, "rename-tab", title).Run()
+	return nil
+}
+
+func (z *ZellijMultiplexer) KillSession(session string) error {
+	return zellijCommand("kill-session", session).Run()
+}
+
+func (z *ZellijMultiplexer) Attach(session string) error {
+	cmd := zellijCommand("attach", session)
+	cmd.Timeout = 0
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (z *ZellijMultiplexer) Detach() error {
+	return fmt.Errorf("zellij has no CLI detach; press Ctrl-o d")
+}
+
+func (z *ZellijMultiplexer) Inside() bool {
+	return os.Getenv("ZELLIJ") != ""
+}
+
+func (z *ZellijMultiplexer) NewPane(session, dir, title string) (int, string, error) {
+	if err := zellijAction(session, "new-tab", "--name", title, "--cwd", muxPath(dir)).Run(); err != nil {
+		return 0, "", err
+	}
+	names, _ := z.tabNames(session)
+	return len(names) - 1, session + ":" + title, nil
+}
+
+func (z *ZellijMultiplexer) tabNames(session string) ([]string, error) {
+	output, err := zellijAction(session, "query-tab-names").Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
+func (z *ZellijMultiplexer) KillPane(paneID string) error {
+	if err := z.Focus(paneID); err != nil {
+		return err
+	}
+	session, _, _ := splitQualifiedPane(paneID)
+	return zellijAction(session, "close-tab").Run()
+}
+
+func (z *ZellijMultiplexer) PaneExists(paneID string) bool {
+	session, name, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return false
+	}
+	names, err := z.tabNames(session)
+	if err != nil {
+		return false
+	}
+	for _, tab := range names {
+		if strings.TrimSpace(tab) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (z *ZellijMultiplexer) Focus(paneID string) error {
+	session, name, err := splitQualifiedPane(paneID)
+	if err != nil {
+		return err
+	}
+	return zellijAction(session, "go-to-tab-name", name).Run()
+}
+
+func (z *ZellijMultiplexer) SendKeys(paneID, text string) error {
+	if err := z.Focus(paneID); err != nil {
+		return err
+	}
+	session, _, _ := splitQualifiedPane(paneID)
+	if err := zellijAction(session, "write-chars", text).Run(); err != nil {
+		return err
+	}
+	// 13 is carriage return
+	return zellijAction(session, "write", "13").Run()
+}
+
+func (z *ZellijMultiplexer) Capture(paneID string, lines int) (string, error) {
+	if err := z.Focus(paneID); err != nil {
+		return "", err
+	}
+	session, _, _ := splitQualifiedPane(paneID)
+
+	dump, err := os.CreateTemp("", "gtw-zellij-*.txt")
+	if err != nil {
+		return "", err
+	}
+	dump.Close()
+	defer os.Remove(dump.Name())
+
+	if err := zellijAction(session, "dump-screen", "--full", filepath.Clean(dump.Name())).Run(); err != nil {
+		return "", fmt.Errorf("capturing %s: %v", paneID, err)
+	}
+	data, err := os.ReadFile(dump.Name())
+	if err != nil {
+		return "", err
+	}
+	return lastLines(string(data), lines), nil
+}
+
+func (z *ZellijMultiplexer) CurrentCommand(paneID string) (string, error) {
+	if !z.PaneExists(paneID) {
+		return "", fmt.Errorf("pane %s not found", paneID)
+	}
+	return "", nil
+}
+
+// lastLines returns at most n trailing lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
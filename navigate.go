@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	nextCmd := &cobra.Command{
+		Use:   "next",
+		Short: "Focus the next worker's pane",
+		Long: `Focus the pane of the worker after the current one, in config order,
+skipping workers whose pane is gone and wrapping around at the end. The
+current worker is the one whose pane gtw runs in, or else the active pane
+of the session, so that the command works from a tmux key binding:
+
+  bind-key -n M-] run-shell "cd #{q:pane_current_path} && gtw next"
+  bind-key -n M-[ run-shell "cd #{q:pane_current_path} && gtw prev"`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !focusAdjacentWorker(1) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	prevCmd := &cobra.Command{
+		Use:   "prev",
+		Short: "Focus the previous worker's pane",
+		Long: `Focus the pane of the worker before the current one, in config order,
+skipping workers whose pane is gone and wrapping around at the start. See
+'gtw next --help' for key bindings.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !focusAdjacentWorker(-1) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	rootCmd.AddCommand(nextCmd, prevCmd)
+}
+
+// adjacentWorker returns the index of the live worker step (1 or -1)
+// places from the worker whose pane is current, wrapping around. From a
+// pane that is no worker's, next starts at the first worker and prev at
+// the last.
+func adjacentWorker(workers []Worker, current string, alive func(string) bool, step int) (int, bool) {
+	n := len(workers)
+	start := -1
+	if step < 0 {
+		start = n
+	}
+	for i, worker := range workers {
+		if current != "" && worker.PaneID == current {
+			start = i
+			break
+		}
+	}
+	for k := 1; k <= n; k++ {
+		i := ((start+step*k)%n + n) % n
+		if alive(workers[i].PaneID) && workers[i].PaneID != current {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// currentPane returns the pane gtw runs in when it belongs to session, or
+// else the active pane of the session (a key binding's run-shell has no
+// pane of its own).
+func currentPane(session string) string {
+	if pane := os.Getenv("TMUX_PANE"); pane != "" && insideSession(session) {
+		return pane
+	}
+	output, err := tmuxCommand("display-message", "-t", session+":", "-p", "#{pane_id}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func focusAdjacentWorker(step int) bool {
+	if !requireTmux("next/prev") {
+		return false
+	}
+	sessionName := getSessionName()
+	if !mux.HasSession(sessionName) {
+		fmt.Fprintf(os.Stderr, "Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+
+	i, ok := adjacentWorker(config.Workers, currentPane(sessionName), livePaneCheck(), step)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Error: No other worker has a live pane")
+		return false
+	}
+	focusWorker(config.Workers[i])
+	return true
+}
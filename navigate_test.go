@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestAdjacentWorker(t *testing.T) {
+	workers := []Worker{{ID: "a", PaneID: "%1"}, {ID: "b", PaneID: "%2"}, {ID: "c", PaneID: "%3"}, {ID: "d", PaneID: "%4"}}
+	alive := func(paneID string) bool { return paneID != "%3" }
+
+	tests := []struct {
+		current string
+		step    int
+		want    string
+	}{
+		{"%1", 1, "b"},
+		{"%2", 1, "d"}, // c's pane is gone
+		{"%4", 1, "a"}, // Wraps around
+		{"%1", -1, "d"},
+		{"%4", -1, "b"},
+		{"%0", 1, "a"}, // Not a worker's pane
+		{"%0", -1, "d"},
+		{"", 1, "a"},
+	}
+	for _, tt := range tests {
+		i, ok := adjacentWorker(workers, tt.current, alive, tt.step)
+		if !ok || workers[i].ID != tt.want {
+			t.Errorf("adjacentWorker(%q, %d) = %d, %v, want %s", tt.current, tt.step, i, ok, tt.want)
+		}
+	}
+
+	if _, ok := adjacentWorker(workers[:1], "%1", alive, 1); ok {
+		t.Error("Expected no other worker when only the current one is live")
+	}
+	if _, ok := adjacentWorker(nil, "", alive, 1); ok {
+		t.Error("Expected no worker without workers")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tmuxClient is a client attached to the tmux server, from list-clients.
+type tmuxClient struct {
+	Name     string // client_name, the target of -c and detach-client -t
+	Session  string
+	Activity int64
+}
+
+// parseTmuxClients parses list-clients output formatted by
+// tmuxClientFormat.
+func parseTmuxClients(output string) []tmuxClient {
+	var clients []tmuxClient
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		activity, _ := strconv.ParseInt(fields[2], 10, 64)
+		clients = append(clients, tmuxClient{Name: fields[0], Session: fields[1], Activity: activity})
+	}
+	return clients
+}
+
+const tmuxClientFormat = "#{client_name}\t#{session_name}\t#{client_activity}"
+
+// pickClient returns the most recently active client showing session,
+// which is the one a command typed into a pane of that session came from.
+func pickClient(clients []tmuxClient, session string) string {
+	var best *tmuxClient
+	for i := range clients {
+		if clients[i].Session == session && (best == nil || clients[i].Activity > best.Activity) {
+			best = &clients[i]
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Name
+}
+
+// invokingSession returns the tmux session of the pane gtw runs in, or ""
+// outside tmux.
+func invokingSession() string {
+	pane := os.Getenv("TMUX_PANE")
+	if mux.Name() != "tmux" || !mux.Inside() || pane == "" {
+		return ""
+	}
+	output, err := tmuxCommand("display-message", "-t", pane, "-p", "#{session_name}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// invokingClient returns the client gtw was run from. tmux guesses the
+// same way when -c/-t is left out, but it looks at every client of the
+// server, so with a second terminal or an observer attached it may pick
+// the wrong one.
+func invokingClient() string {
+	session := invokingSession()
+	if session == "" {
+		return ""
+	}
+	output, err := tmuxCommand("list-clients", "-F", tmuxClientFormat).Output()
+	if err != nil {
+		return ""
+	}
+	return pickClient(parseTmuxClients(string(output)), session)
+}
+
+// insideSession reports whether gtw runs in a pane of session, e.g. a
+// worker calling gtw itself.
+func insideSession(session string) bool {
+	return session != "" && invokingSession() == session
+}
+
+// switchClient moves the client gtw was run from to target (a session or
+// pane), for running gtw inside tmux where attaching would nest sessions.
+func switchClient(target string) error {
+	args := []string{"switch-client", "-t", target}
+	if client := invokingClient(); client != "" {
+		args = []string{"switch-client", "-c", client, "-t", target}
+	}
+	return tmuxCommand(args...).Run()
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestPickClient(t *testing.T) {
+	clients := parseTmuxClients("/dev/pts/1\tproj\t1700000100\n/dev/pts/2\tother\t1700000300\n/dev/pts/3\tproj\t1700000200\n")
+	if len(clients) != 3 || clients[1].Session != "other" || clients[2].Activity != 1700000200 {
+		t.Fatalf("Unexpected clients: %+v", clients)
+	}
+	if got := pickClient(clients, "proj"); got != "/dev/pts/3" {
+		t.Errorf("Expected the most recently active client of proj, got %q", got)
+	}
+	if got := pickClient(clients, "missing"); got != "" {
+		t.Errorf("Expected no client, got %q", got)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// nonInteractiveEnv forces non-interactive mode on ("1") or off ("0").
+const nonInteractiveEnv = "GTW_NONINTERACTIVE"
+
+// nonInteractive is set when gtw is driven by another program: output is
+// terse, prompts are answered "no" and gtw never attaches to a session.
+var nonInteractive bool
+
+// terseListFormat is the `gtw list` output in non-interactive mode: one
+// tab-separated line per worker, without a header.
+const terseListFormat = `{{.ID}}	{{.Status}}	{{.WorktreePath}}	{{.PaneID}}	{{join .Tags ","}}{{with .Git}}	{{.Branch}}	{{.Ahead}}	{{.Behind}}	{{.Dirty}}{{end}}`
+
+// resolveNonInteractive decides the mode from GTW_NONINTERACTIVE or, when it
+// is unset, from whether stdout is a terminal.
+func resolveNonInteractive() bool {
+	if value := os.Getenv(nonInteractiveEnv); value != "" {
+		if on, err := strconv.ParseBool(value); err == nil {
+			return on
+		}
+		return true
+	}
+	return !isTerminal(os.Stdout)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolveNonInteractive(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, "true": true, "0": false, "false": false, "yes": true} {
+		t.Setenv(nonInteractiveEnv, value)
+		if got := resolveNonInteractive(); got != want {
+			t.Errorf("%s=%s: resolveNonInteractive() = %v, want %v", nonInteractiveEnv, value, got, want)
+		}
+	}
+
+	// Without the variable, it depends on stdout
+	t.Setenv(nonInteractiveEnv, "")
+	if got, want := resolveNonInteractive(), !isTerminal(os.Stdout); got != want {
+		t.Errorf("resolveNonInteractive() = %v, want %v", got, want)
+	}
+}
+
+func TestConfirmNonInteractive(t *testing.T) {
+	nonInteractive = true
+	defer func() { nonInteractive = false }()
+	if confirm("Remove everything?") {
+		t.Error("Expected confirm to decline in non-interactive mode")
+	}
+}
+
+func TestTerseListFormat(t *testing.T) {
+	tmpl, err := parseFormat(terseListFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := WorkerView{Worker: Worker{ID: "w1", WorktreePath: "worktree/w1", PaneID: "%1", Tags: []string{"api", "db"}}, Status: "active"}
+	var b strings.Builder
+	tmpl.Execute(&b, view)
+	if want := "w1\tactive\tworktree/w1\t%1\tapi,db"; b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+
+	view.Git = &GitMeta{Branch: "w1", Ahead: 2, Dirty: true}
+	b.Reset()
+	tmpl.Execute(&b, view)
+	if want := "w1\tactive\tworktree/w1\t%1\tapi,db\tw1\t2\t0\ttrue"; b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+}
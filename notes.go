@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var noteClear bool
+	noteCmd := &cobra.Command{
+		Use:   "note <worker-id> [text]",
+		Short: "Show or set a worker's note",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 1 && !noteClear {
+				showWorkerNote(args[0])
+				return
+			}
+			text := ""
+			if len(args) == 2 {
+				text = args[1]
+			}
+			setWorkerNote(args[0], text)
+		},
+	}
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "Remove the worker's note")
+	rootCmd.AddCommand(noteCmd)
+
+	var tagRemove bool
+	tagCmd := &cobra.Command{
+		Use:   "tag <worker-id> <tag>...",
+		Short: "Add or remove worker tags",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   func(cmd *cobra.Command, args []string) { tagWorker(args[0], args[1:], tagRemove) },
+	}
+	tagCmd.Flags().BoolVar(&tagRemove, "remove", false, "Remove the given tags instead of adding them")
+	rootCmd.AddCommand(tagCmd)
+}
+
+// normalizeTags trims, deduplicates and sorts tags, dropping empty ones.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// hasAllTags reports whether the worker carries every tag in tags.
+func hasAllTags(worker Worker, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range worker.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func findWorkerIndex(config *Config, id string) int {
+	for i, w := range config.Workers {
+		if w.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func showWorkerNote(id string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	if config.Workers[index].Note == "" {
+		fmt.Printf("Worker '%s' has no note\n", id)
+		return
+	}
+	fmt.Println(config.Workers[index].Note)
+}
+
+func setWorkerNote(id, text string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	config.Workers[index].Note = text
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	// The pane title may show the note
+	labelWorkerPane(config, config.Workers[index])
+
+	if text == "" {
+		fmt.Printf("✅ Cleared note for worker '%s'\n", id)
+	} else {
+		fmt.Printf("✅ Set note for worker '%s'\n", id)
+	}
+}
+
+func tagWorker(id string, tags []string, remove bool) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	worker := &config.Workers[index]
+	if remove {
+		drop := make(map[string]bool)
+		for _, tag := range tags {
+			drop[strings.TrimSpace(tag)] = true
+		}
+		var kept []string
+		for _, tag := range worker.Tags {
+			if !drop[tag] {
+				kept = append(kept, tag)
+			}
+		}
+		worker.Tags = kept
+	} else {
+		worker.Tags = normalizeTags(append(worker.Tags, tags...))
+	}
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	labelWorkerPane(config, *worker)
+
+	if len(worker.Tags) == 0 {
+		fmt.Printf("✅ Worker '%s' has no tags\n", id)
+	} else {
+		fmt.Printf("✅ Worker '%s' tags: %s\n", id, strings.Join(worker.Tags, ", "))
+	}
+}
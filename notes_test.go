@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := normalizeTags([]string{"urgent", " backend ", "", "urgent", "api"})
+	want := []string{"api", "backend", "urgent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeTags() = %v, want %v", got, want)
+	}
+}
+
+func TestHasAllTags(t *testing.T) {
+	worker := Worker{ID: "w1", Tags: []string{"backend", "urgent"}}
+
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"urgent"}, true},
+		{[]string{"backend", "urgent"}, true},
+		{[]string{"frontend"}, false},
+		{[]string{"urgent", "frontend"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := hasAllTags(worker, tt.tags); got != tt.want {
+			t.Errorf("hasAllTags(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Events that notifications are sent for
+const (
+	NotifyAdded          = "added"           // gtw add created a worker
+	NotifyRemoved        = "removed"         // gtw remove removed a worker
+	NotifyCompleted      = "completed"       // A busy worker went idle
+	NotifyFailed         = "failed"          // A worker's pane disappeared
+	NotifyNeedsAttention = "needs_attention" // A worker waits at a permission prompt
+)
+
+var notifyEvents = []string{NotifyAdded, NotifyRemoved, NotifyCompleted, NotifyFailed, NotifyNeedsAttention}
+
+// notifyByDefault are the events sent unless turned off; the lifecycle
+// events have to be turned on.
+var notifyByDefault = map[string]bool{NotifyCompleted: true, NotifyFailed: true, NotifyNeedsAttention: true}
+
+// notifyOutputLines is how much pane output a notification quotes.
+const notifyOutputLines = 10
+
+// NotificationConfig selects where `gtw daemon` sends notifications and for
+// which events.
+type NotificationConfig struct {
+	Desktop  bool            `json:"desktop,omitempty"`  // Desktop notifications (notify-send, terminal-notifier or osascript)
+	Webhooks []Webhook       `json:"webhooks,omitempty"` // Slack or Discord incoming webhooks
+	Events   map[string]bool `json:"events,omitempty"`   // Per-event toggles (default: completed, failed and needs_attention)
+}
+
+// Notification is one message about a worker. It is also the data given to
+// webhook message templates.
+type Notification struct {
+	Event   string
+	Worker  string
+	Branch  string
+	Project string
+	Title   string
+	Message string
+	Output  string // The last lines of the worker's pane, if it is still there
+}
+
+// workerNotification describes an event of the worker, quoting the end of
+// its pane output.
+func workerNotification(worker Worker, event, title, message string) Notification {
+	n := Notification{
+		Event:   event,
+		Worker:  worker.ID,
+		Branch:  worker.branchName(),
+		Project: getCurrentProjectName(),
+		Title:   title,
+		Message: message,
+	}
+	if worker.PaneID != "" {
+		if output, err := capturePane(worker.PaneID, 50); err == nil {
+			n.Output = strings.Trim(lastLines(strings.TrimRight(output, "\n "), notifyOutputLines), "\n")
+		}
+	}
+	return n
+}
+
+// Notifier delivers notifications to one destination.
+type Notifier interface {
+	Name() string
+	Notify(n Notification) error
+}
+
+// enabled reports whether notifications are sent for the event.
+func (c *NotificationConfig) enabled(event string) bool {
+	if c == nil {
+		return false
+	}
+	if on, listed := c.Events[event]; listed {
+		return on
+	}
+	return notifyByDefault[event]
+}
+
+// desktopNotifier shows notifications with the desktop's own tool.
+type desktopNotifier struct {
+	goos     string
+	lookPath func(string) (string, error)
+}
+
+func newDesktopNotifier() desktopNotifier {
+	return desktopNotifier{goos: runtime.GOOS, lookPath: exec.LookPath}
+}
+
+func (d desktopNotifier) Name() string { return "desktop" }
+
+// command returns the command that shows the notification: notify-send on
+// Linux; on macOS terminal-notifier when installed, osascript otherwise.
+func (d desktopNotifier) command(n Notification) ([]string, error) {
+	switch d.goos {
+	case "darwin":
+		if _, err := d.lookPath("terminal-notifier"); err == nil {
+			return []string{"terminal-notifier", "-title", n.Title, "-message", n.Message, "-group", "gtw-" + n.Worker}, nil
+		}
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`, quote.Replace(n.Message), quote.Replace(n.Title))
+		return []string{"osascript", "-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := d.lookPath("notify-send"); err != nil {
+			return nil, fmt.Errorf("notify-send not found (install libnotify)")
+		}
+		return []string{"notify-send", "-a", "gtw", "--", n.Title, n.Message}, nil
+	}
+	return nil, fmt.Errorf("desktop notifications are not supported on %s", d.goos)
+}
+
+func (d desktopNotifier) Notify(n Notification) error {
+	command, err := d.command(n)
+	if err != nil {
+		return err
+	}
+	return newCommand(command[0], command[1:]...).Run()
+}
+
+// configuredNotifiers returns the notifiers enabled in the config.
+func configuredNotifiers(config *Config) []Notifier {
+	var notifiers []Notifier
+	if config.Notifications == nil {
+		return notifiers
+	}
+	if config.Notifications.Desktop {
+		notifiers = append(notifiers, newDesktopNotifier())
+	}
+	for _, webhook := range config.Notifications.Webhooks {
+		notifiers = append(notifiers, webhook)
+	}
+	return notifiers
+}
+
+// sendNotification delivers n to every configured notifier, if its event is
+// turned on.
+func sendNotification(config *Config, n Notification) {
+	if !config.Notifications.enabled(n.Event) {
+		return
+	}
+	for _, notifier := range configuredNotifiers(config) {
+		if err := notifier.Notify(n); err != nil {
+			fmt.Printf("Warning: Could not send %s notification: %v\n", notifier.Name(), err)
+		}
+	}
+}
+
+// ActivityWatcher notices from the daemon when workers finish or fail.
+type ActivityWatcher struct {
+	tracker *IdleTracker
+	busy    map[string]bool
+	alive   map[string]bool
+}
+
+func NewActivityWatcher(quiet time.Duration) *ActivityWatcher {
+	return &ActivityWatcher{tracker: NewIdleTracker(quiet), busy: make(map[string]bool), alive: make(map[string]bool)}
+}
+
+// Run samples every worker and notifies when a busy one went idle or its
+// pane disappeared.
+func (w *ActivityWatcher) Run(config *Config) {
+	if config.Notifications == nil {
+		return
+	}
+	paneAlive := livePaneCheck()
+	for _, worker := range config.Workers {
+		if worker.Status == WorkerPaused || worker.Status == WorkerDetached {
+			continue
+		}
+		alive := paneAlive(worker.PaneID)
+		if !alive {
+			if w.alive[worker.ID] {
+				sendNotification(config, workerNotification(worker, NotifyFailed,
+					fmt.Sprintf("gtw: %s failed", worker.ID),
+					fmt.Sprintf("The pane of worker '%s' is gone", worker.ID)))
+			}
+			w.alive[worker.ID] = false
+			w.busy[worker.ID] = false
+			continue
+		}
+		w.alive[worker.ID] = true
+
+		idle, err := w.tracker.Observe(worker.PaneID)
+		if err != nil {
+			continue
+		}
+		if idle && w.busy[worker.ID] && !waitingAtPrompt(worker.PaneID) {
+			sendNotification(config, workerNotification(worker, NotifyCompleted,
+				fmt.Sprintf("gtw: %s is done", worker.ID),
+				fmt.Sprintf("Worker '%s' (%s) went idle", worker.ID, worker.branchName())))
+		}
+		w.busy[worker.ID] = !idle
+	}
+}
+
+// waitingAtPrompt reports whether the pane stopped at a permission prompt,
+// which is reported as needs_attention rather than completed.
+func waitingAtPrompt(paneID string) bool {
+	output, err := capturePane(paneID, 50)
+	return err == nil && detectPermissionPrompt(output) != nil
+}
+
+func init() {
+	notifyCmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Test the notifications sent by gtw daemon",
+	}
+	notifyTestCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test notification to every configured destination",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !testNotifications() {
+				os.Exit(1)
+			}
+		},
+	}
+	notifyCmd.AddCommand(notifyTestCmd)
+	rootCmd.AddCommand(notifyCmd)
+}
+
+func testNotifications() bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	notifiers := configuredNotifiers(config)
+	if len(notifiers) == 0 {
+		fmt.Fprintln(os.Stderr, "No notifications configured (e.g. gtw config set notifications.desktop true)")
+		return false
+	}
+	ok := true
+	project := getCurrentProjectName()
+	n := Notification{Event: "test", Project: project, Title: "gtw", Message: fmt.Sprintf("Test notification from %s", project)}
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(n); err != nil {
+			fmt.Printf("❌ %s: %v\n", notifier.Name(), err)
+			ok = false
+			continue
+		}
+		fmt.Printf("✅ %s\n", notifier.Name())
+	}
+	return ok
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNotificationEventsEnabled(t *testing.T) {
+	var none *NotificationConfig
+	if none.enabled(NotifyCompleted) {
+		t.Error("Expected no notifications without config")
+	}
+	config := &NotificationConfig{Desktop: true, Events: map[string]bool{NotifyCompleted: false}}
+	if config.enabled(NotifyCompleted) {
+		t.Error("Expected completed to be turned off")
+	}
+	if !config.enabled(NotifyFailed) || !config.enabled(NotifyNeedsAttention) {
+		t.Error("Expected failed and needs_attention to be on by default")
+	}
+	if config.enabled(NotifyAdded) {
+		t.Error("Expected lifecycle events to be off by default")
+	}
+	config.Events[NotifyAdded] = true
+	if !config.enabled(NotifyAdded) {
+		t.Error("Expected added to be turned on")
+	}
+}
+
+func TestDesktopNotifierCommand(t *testing.T) {
+	n := Notification{Worker: "w1", Title: "gtw: w1 is done", Message: `Worker "w1" went idle`}
+	found := func(string) (string, error) { return "/usr/bin/x", nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	tests := []struct {
+		name     string
+		notifier desktopNotifier
+		want     []string
+	}{
+		{"linux", desktopNotifier{"linux", found}, []string{"notify-send", "-a", "gtw", "--", n.Title, n.Message}},
+		{"macOS with terminal-notifier", desktopNotifier{"darwin", found}, []string{"terminal-notifier", "-title", n.Title, "-message", n.Message, "-group", "gtw-w1"}},
+		{"macOS", desktopNotifier{"darwin", missing}, []string{"osascript", "-e", `display notification "Worker \"w1\" went idle" with title "gtw: w1 is done"`}},
+	}
+	for _, tt := range tests {
+		got, err := tt.notifier.command(n)
+		if err != nil {
+			t.Errorf("%s: command returned error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: command = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := (desktopNotifier{"linux", missing}).command(n); err == nil {
+		t.Error("Expected an error without notify-send")
+	}
+	if _, err := (desktopNotifier{"windows", found}).command(n); err == nil {
+		t.Error("Expected an error on Windows")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ObserveOptions holds the settings given to `gtw observe`.
+type ObserveOptions struct {
+	Stream   bool
+	Interval time.Duration
+	Lines    int
+}
+
+// defaultObserveLines is how much of the pane the stream shows when the
+// terminal size is unknown.
+const defaultObserveLines = 40
+
+func init() {
+	var opts ObserveOptions
+	observeCmd := &cobra.Command{
+		Use:   "observe <worker-id>",
+		Short: "Watch a worker's pane without being able to type into it",
+		Long: `Watch a worker's pane read-only, e.g. to review an agent session without
+risking keystrokes into it.
+
+Outside tmux, gtw attaches a read-only tmux client showing the worker's
+window (tmux 3.2 or later). The client has its own session grouped with the
+worker's, so it does not switch the window of other clients, and it does not
+resize the panes. Detach with the usual prefix + d.
+
+Inside tmux, with --stream or with other multiplexers, the pane's output is
+redrawn in the terminal every --interval until Ctrl-C.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !observeWorker(args[0], opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	observeCmd.Flags().BoolVar(&opts.Stream, "stream", false, "Redraw the pane's output in this terminal instead of attaching a read-only client")
+	observeCmd.Flags().DurationVar(&opts.Interval, "interval", time.Second, "How often the stream is refreshed")
+	observeCmd.Flags().IntVarP(&opts.Lines, "lines", "n", 0, "Lines of the pane shown by the stream (default: the terminal height)")
+	rootCmd.AddCommand(observeCmd)
+}
+
+func observeWorker(id string, opts ObserveOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+	worker := config.Workers[index]
+	if worker.Status == WorkerDetached || !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' has no running pane\n", id)
+		return false
+	}
+
+	observer, ok := mux.(Observer)
+	if !opts.Stream && ok && !mux.Inside() && !nonInteractive {
+		fmt.Printf("Observing worker '%s' read-only (detach with prefix + d)...\n", id)
+		if err := observer.Observe(worker.PaneID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		return true
+	}
+	return streamPane(worker, opts, nil)
+}
+
+// streamPane redraws the pane's output whenever it changes, until the pane
+// is gone, stop is closed or the user presses Ctrl-C.
+func streamPane(worker Worker, opts ObserveOptions, stop <-chan struct{}) bool {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	lines := opts.Lines
+	if lines <= 0 {
+		lines = defaultObserveLines
+		if rows := terminalRows(); rows > 2 {
+			lines = rows - 2 // Room for the header
+		}
+	}
+	clear := isTerminal(os.Stdout)
+	hint := "read-only, Ctrl-C to stop"
+	if stop != nil {
+		hint = "read-only, press any key to close"
+	}
+
+	previous := ""
+	for {
+		output, err := capturePane(worker.PaneID, lines)
+		if err != nil {
+			fmt.Printf("\nThe pane of worker '%s' is gone\n", worker.ID)
+			return true
+		}
+		if output != previous {
+			previous = output
+			fmt.Print(observeFrame(worker.ID, hint, output, lines, clear))
+		}
+		select {
+		case <-stop:
+			return true
+		case <-time.After(interval):
+		}
+	}
+}
+
+// observeFrame renders one refresh of the stream: a header and the last
+// lines of the pane, on a cleared screen when clear is set.
+func observeFrame(id, hint, output string, lines int, clear bool) string {
+	var b strings.Builder
+	if clear {
+		b.WriteString("\x1b[H\x1b[2J")
+	}
+	fmt.Fprintf(&b, "── %s (%s) ──\n", id, hint)
+	b.WriteString(lastLines(strings.TrimRight(output, "\n "), lines))
+	return b.String()
+}
+
+// terminalRows returns the height of the terminal on stdin, or 0.
+func terminalRows() int {
+	if !isTerminal(os.Stdin) {
+		return 0
+	}
+	output, err := sttyCommand("size").Output()
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0
+	}
+	rows, _ := strconv.Atoi(fields[0])
+	return rows
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObserveFrame(t *testing.T) {
+	output := "line 1\nline 2\nline 3\n\n\n"
+	frame := observeFrame("w1", "read-only, Ctrl-C to stop", output, 2, false)
+	want := "── w1 (read-only, Ctrl-C to stop) ──\nline 2\nline 3\n"
+	if frame != want {
+		t.Errorf("observeFrame() = %q, want %q", frame, want)
+	}
+
+	if frame := observeFrame("w1", "read-only, Ctrl-C to stop", output, 10, true); !strings.HasPrefix(frame, "\x1b[H\x1b[2J── w1") {
+		t.Errorf("Expected the screen to be cleared first, got %q", frame)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// terminalEditors run inside the worker's pane instead of opening a window.
+var terminalEditors = map[string]bool{
+	"nvim":  true,
+	"vim":   true,
+	"vi":    true,
+	"nano":  true,
+	"hx":    true,
+	"helix": true,
+	"micro": true,
+	"kak":   true,
+	"emacs": true,
+}
+
+func init() {
+	var editor string
+	openCmd := &cobra.Command{
+		Use:   "open <worker-id>",
+		Short: "Open a worker's worktree in an editor",
+		Long: `Open a worker's worktree in an editor. GUI editors (code, cursor, ...) are
+launched with the worktree path; terminal editors (nvim, vim, ...) are started
+inside the worker's pane.
+
+The editor is taken from --editor, the "editor" config value, $VISUAL or
+$EDITOR, in that order, and defaults to code.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { openWorker(args[0], editor) },
+	}
+	openCmd.Flags().StringVar(&editor, "editor", "", "Editor command (e.g. code, cursor, nvim)")
+	rootCmd.AddCommand(openCmd)
+}
+
+// resolveEditor picks the editor command from the flag, config or
+// environment.
+func resolveEditor(flagValue, configured string) string {
+	for _, candidate := range []string{flagValue, configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(candidate) != "" {
+			return strings.TrimSpace(candidate)
+		}
+	}
+	return "code"
+}
+
+// isTerminalEditor reports whether the editor command runs in a terminal.
+func isTerminalEditor(editor string) bool {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return false
+	}
+	name := filepath.Base(fields[0])
+	if name == "emacs" {
+		// Plain emacs opens a GUI frame unless told otherwise
+		for _, arg := range fields[1:] {
+			if arg == "-nw" || arg == "--no-window-system" {
+				return true
+			}
+		}
+		return false
+	}
+	return terminalEditors[name]
+}
+
+func openWorker(id, editorFlag string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := config.Workers[index]
+
+	absWorktreePath, err := filepath.Abs(worker.WorktreePath)
+	if err != nil {
+		absWorktreePath = worker.WorktreePath
+	}
+	if _, err := os.Stat(absWorktreePath); os.IsNotExist(err) {
+		fmt.Printf("Error: Worktree '%s' does not exist\n", worker.WorktreePath)
+		return
+	}
+
+	editor := resolveEditor(editorFlag, config.Editor)
+
+	if isTerminalEditor(editor) {
+		if !mux.PaneExists(worker.PaneID) {
+			fmt.Printf("Error: Pane for worker '%s' is not running\n", id)
+			return
+		}
+		fmt.Printf("Starting %s in pane %s...\n", editor, worker.PaneID)
+		if err := mux.SendKeys(worker.PaneID, editor+" ."); err != nil {
+			fmt.Printf("Error sending editor command: %v\n", err)
+			return
+		}
+		mux.Focus(worker.PaneID)
+		return
+	}
+
+	fields := strings.Fields(editor)
+	fmt.Printf("Opening %s in %s...\n", absWorktreePath, fields[0])
+	cmd := newCommand(fields[0], append(fields[1:], absWorktreePath)...)
+	cmd.Timeout = 0
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error opening editor: %v\n", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestResolveEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "vim")
+
+	if got := resolveEditor("cursor", "nvim"); got != "cursor" {
+		t.Errorf("Flag should win, got %q", got)
+	}
+	if got := resolveEditor("", "nvim"); got != "nvim" {
+		t.Errorf("Config should win over environment, got %q", got)
+	}
+	if got := resolveEditor("", ""); got != "vim" {
+		t.Errorf("Expected $EDITOR, got %q", got)
+	}
+
+	t.Setenv("EDITOR", "")
+	if got := resolveEditor("", ""); got != "code" {
+		t.Errorf("Expected default code, got %q", got)
+	}
+}
+
+func TestIsTerminalEditor(t *testing.T) {
+	tests := map[string]bool{
+		"nvim":          true,
+		"/usr/bin/vim":  true,
+		"code":          false,
+		"cursor --wait": false,
+		"emacs":         false,
+		"emacs -nw":     true,
+		"hx":            true,
+		"":              false,
+	}
+
+	for editor, want := range tests {
+		if got := isTerminalEditor(editor); got != want {
+			t.Errorf("isTerminalEditor(%q) = %v, want %v", editor, got, want)
+		}
+	}
+}
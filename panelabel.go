@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// tmux user options set on every worker pane, so that other tmux tooling
+// (status lines, scripts, plugins) can tell gtw panes apart without relying
+// on titles, e.g. tmux list-panes -a -F '#{pane_id} #{@gtw_worker_id}'.
+const (
+	paneWorkerIDOption = "@gtw_worker_id"
+	paneBranchOption   = "@gtw_branch"
+	paneWorktreeOption = "@gtw_worktree"
+	paneProjectOption  = "@gtw_project"
+)
+
+// PaneTitleData is the data given to the pane_title config value.
+type PaneTitleData struct {
+	ID        string
+	Branch    string
+	Base      string
+	Profile   string
+	Note      string
+	Tags      []string
+	Project   string // Project directory name
+	Workspace string // --workspace, empty for the default workspace
+}
+
+// renderPaneTitle expands a pane title template such as
+// "{{.ID}} [{{.Branch}}]". An empty template titles the pane with the ID.
+func renderPaneTitle(titleTemplate string, data PaneTitleData) (string, error) {
+	if titleTemplate == "" {
+		return data.ID, nil
+	}
+	tmpl, err := template.New("pane_title").Funcs(formatFuncs).Parse(titleTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	if title := strings.TrimSpace(b.String()); title != "" {
+		return title, nil
+	}
+	return data.ID, nil
+}
+
+// paneTitleData describes the worker for the pane title template.
+func paneTitleData(worker Worker) PaneTitleData {
+	return PaneTitleData{
+		ID:        worker.ID,
+		Branch:    worker.branchName(),
+		Base:      worker.Base,
+		Profile:   worker.Profile,
+		Note:      worker.Note,
+		Tags:      worker.Tags,
+		Project:   getCurrentProjectName(),
+		Workspace: workspace,
+	}
+}
+
+// labelWorkerPane titles the worker's tmux pane with pane_title and sets
+// the @gtw_* user options on it. Other multiplexers identify panes by their
+// title, which stays the worker ID.
+func labelWorkerPane(config *Config, worker Worker) {
+	if mux.Name() != "tmux" || worker.PaneID == "" {
+		return
+	}
+	title, err := renderPaneTitle(config.PaneTitle, paneTitleData(worker))
+	if err != nil {
+		fmt.Printf("Warning: Invalid pane_title: %v\n", err)
+		title = worker.ID
+	}
+	tmuxCommand("select-pane", "-t", worker.PaneID, "-T", title).Run()
+
+	worktree, _ := filepath.Abs(worker.WorktreePath)
+	options := [][2]string{
+		{paneWorkerIDOption, worker.ID},
+		{paneBranchOption, worker.branchName()},
+		{paneWorktreeOption, worktree},
+		{paneProjectOption, getCurrentProjectName()},
+	}
+	for _, option := range options {
+		// Pane options need tmux 3.0 or later
+		if err := tmuxCommand("set-option", "-p", "-t", worker.PaneID, option[0], option[1]).Run(); err != nil {
+			fmt.Printf("Warning: Could not set %s on pane %s: %v\n", option[0], worker.PaneID, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestRenderPaneTitle(t *testing.T) {
+	data := PaneTitleData{ID: "issue-1", Branch: "feature/issue-1", Tags: []string{"api", "urgent"}}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "issue-1"},
+		{"{{.ID}} [{{.Branch}}]", "issue-1 [feature/issue-1]"},
+		{"{{.ID}} {{join .Tags \",\"}}", "issue-1 api,urgent"},
+		{"{{.Note}}", "issue-1"}, // Blank titles fall back to the ID
+	}
+	for _, tt := range tests {
+		got, err := renderPaneTitle(tt.template, data)
+		if err != nil {
+			t.Errorf("renderPaneTitle(%q) returned error: %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("renderPaneTitle(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if _, err := renderPaneTitle("{{.ID", data); err == nil {
+		t.Error("Expected an error for an unterminated template")
+	}
+	if _, err := renderPaneTitle("{{.Missing}}", data); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkInitialChanges reports problems with --from-stash or --apply-patch
+// before anything is created.
+func checkInitialChanges(opts AddOptions) error {
+	if opts.FromStash != "" {
+		if err := gitCommand("rev-parse", "--verify", "--quiet", opts.FromStash).Run(); err != nil {
+			return fmt.Errorf("stash '%s' not found (see 'git stash list')", opts.FromStash)
+		}
+	}
+	if opts.ApplyPatch != "" {
+		if _, err := os.Stat(userPath(opts.ApplyPatch)); err != nil {
+			return fmt.Errorf("patch file: %v", err)
+		}
+	}
+	return nil
+}
+
+// applyInitialChanges applies the stash or patch given to `gtw add` to the
+// new worktree, leaving the changes uncommitted on the worker's branch.
+func applyInitialChanges(worktreePath string, opts AddOptions) error {
+	if opts.FromStash != "" {
+		fmt.Printf("Applying %s...\n", opts.FromStash)
+		if output, err := gitCommand("-C", worktreePath, "stash", "apply", opts.FromStash).CombinedOutput(); err != nil {
+			return fmt.Errorf("applying %s failed: %v\n%s", opts.FromStash, err, strings.TrimSpace(string(output)))
+		}
+	}
+	if opts.ApplyPatch != "" {
+		patch, err := filepath.Abs(userPath(opts.ApplyPatch))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applying %s...\n", opts.ApplyPatch)
+		if output, err := gitCommand("-C", worktreePath, "apply", patch).CombinedOutput(); err != nil {
+			return fmt.Errorf("applying %s failed: %v\n%s", opts.ApplyPatch, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
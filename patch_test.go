@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyInitialChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) string {
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+	git("init", "-q", "-b", "main")
+	os.WriteFile("a.txt", []byte("one\n"), 0644)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	// A stash and a patch with different changes
+	os.WriteFile("a.txt", []byte("stashed\n"), 0644)
+	git("stash", "-q")
+	os.WriteFile("a.txt", []byte("patched\n"), 0644)
+	os.WriteFile("change.diff", []byte(git("diff")), 0644)
+	git("checkout", "-q", "a.txt")
+
+	if err := checkInitialChanges(AddOptions{FromStash: "stash@{5}"}); err == nil {
+		t.Error("Expected an error for a missing stash")
+	}
+	if err := checkInitialChanges(AddOptions{ApplyPatch: "missing.diff"}); err == nil {
+		t.Error("Expected an error for a missing patch")
+	}
+
+	git("worktree", "add", "-q", "-b", "s", "wt-stash")
+	if err := applyInitialChanges("wt-stash", AddOptions{FromStash: "stash@{0}"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join("wt-stash", "a.txt")); string(data) != "stashed\n" {
+		t.Errorf("Expected the stash to be applied, got %q", data)
+	}
+
+	git("worktree", "add", "-q", "-b", "p", "wt-patch")
+	if err := applyInitialChanges("wt-patch", AddOptions{ApplyPatch: "change.diff"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join("wt-patch", "a.txt")); string(data) != "patched\n" {
+		t.Errorf("Expected the patch to be applied, got %q", data)
+	}
+	if err := applyInitialChanges("wt-patch", AddOptions{ApplyPatch: "change.diff"}); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("Expected a patch that does not apply to fail, got %v", err)
+	}
+}
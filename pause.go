@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const WorkerPaused WorkerState = "paused"
+
+func init() {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "pause <worker-id>",
+		Short: "Freeze the processes running in a worker's pane (SIGSTOP)",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { pauseWorker(args[0], true) },
+	})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "resume <worker-id>",
+		Short: "Continue a paused worker (SIGCONT)",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { pauseWorker(args[0], false) },
+	})
+}
+
+// paneChildPIDs returns the processes started from the pane's shell. The
+// shell itself is left running so the pane stays usable.
+func paneChildPIDs(paneID string) ([]int, error) {
+	pid, err := panePID(paneID)
+	if err != nil {
+		return nil, err
+	}
+	table, err := readProcessTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, p := range processTree(table, pid) {
+		if p.PID != pid {
+			pids = append(pids, p.PID)
+		}
+	}
+	return pids, nil
+}
+
+// signalProcesses sends signal (e.g. "STOP") to every PID with kill(1).
+func signalProcesses(signal string, pids []int) error {
+	args := []string{"-" + signal}
+	for _, pid := range pids {
+		args = append(args, strconv.Itoa(pid))
+	}
+	output, err := newCommand("kill", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, string(output))
+	}
+	return nil
+}
+
+func pauseWorker(id string, pause bool) {
+	if !requireTmux("pause") {
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := &config.Workers[index]
+
+	if pause && worker.Status == WorkerPaused {
+		fmt.Printf("Worker '%s' is already paused\n", id)
+		return
+	}
+	if !pause && worker.Status != WorkerPaused {
+		fmt.Printf("Worker '%s' is not paused\n", id)
+		return
+	}
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Printf("Error: Pane for worker '%s' is not running\n", id)
+		return
+	}
+
+	pids, err := paneChildPIDs(worker.PaneID)
+	if err != nil {
+		fmt.Printf("Error listing pane processes: %v\n", err)
+		return
+	}
+
+	signal, status, verb := "STOP", WorkerPaused, "Paused"
+	if !pause {
+		// Back to the state the worker was paused in; workers paused by
+		// older versions did not record it
+		signal, status, verb = "CONT", worker.PausedFrom, "Resumed"
+		if !canTransition(WorkerPaused, status) {
+			status = StateReady
+		}
+	}
+
+	if len(pids) > 0 {
+		if err := signalProcesses(signal, pids); err != nil {
+			// Some processes may have exited in the meantime
+			fmt.Printf("Warning: Could not signal every process: %v\n", err)
+		}
+	}
+
+	// A stopped foreground job is moved to the background by the shell, so
+	// bring it back to the terminal or interactive programs stop again
+	if !pause && len(pids) > 0 {
+		if command, err := paneCurrentCommand(worker.PaneID); err == nil && isShellCommand(command) {
+			sendToPane(worker.PaneID, "fg")
+		}
+	}
+
+	previous := worker.state()
+	worker.setState(status, "gtw "+strings.ToLower(verb))
+	if pause {
+		worker.PausedFrom = previous
+	}
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	if pause {
+		recordEvent(EventWorkerPaused, id, fmt.Sprintf("%d process(es)", len(pids)))
+	} else {
+		recordEvent(EventWorkerResumed, id, fmt.Sprintf("%d process(es)", len(pids)))
+	}
+	fmt.Printf("✅ %s worker '%s' (%d process(es))\n", verb, id, len(pids))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPauseAndResumeWorker(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	// A server of our own, without the user's tmux.conf
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	t.Cleanup(func() { exec.Command("tmux", "kill-server").Run() })
+	t.Chdir(t.TempDir())
+	useMultiplexer(t, &TmuxMultiplexer{})
+
+	// The shell waits for its child, so the pane keeps running sleep
+	output, err := exec.Command("tmux", "new-session", "-d", "-s", "demo", "-P", "-F", "#{pane_id}", "sh -c 'sleep 300; exit'").CombinedOutput()
+	if err != nil {
+		t.Fatalf("tmux new-session: %v\n%s", err, output)
+	}
+	paneID := strings.TrimSpace(string(output))
+
+	tests := []struct {
+		name  string
+		state WorkerState
+	}{
+		{"busy", StateBusy},
+		{"needs attention", StateNeedsAttention},
+		{"older config", "active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := saveConfig(&Config{Workers: []Worker{{ID: "a", PaneID: paneID, Status: tt.state}}}); err != nil {
+				t.Fatal(err)
+			}
+
+			pauseWorker("a", true)
+			config, err := loadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			worker := config.Workers[0]
+			want := (&Worker{Status: tt.state}).state()
+			if worker.Status != WorkerPaused || worker.PausedFrom != want {
+				t.Fatalf("Expected paused from %s, got %s from %q", want, worker.Status, worker.PausedFrom)
+			}
+
+			pauseWorker("a", false)
+			if config, err = loadConfig(); err != nil {
+				t.Fatal(err)
+			}
+			worker = config.Workers[0]
+			if worker.Status != want || worker.PausedFrom != "" {
+				t.Errorf("Expected to resume as %s, got %s (paused from %q)", want, worker.Status, worker.PausedFrom)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// PeekOptions holds the settings given to `gtw peek`.
+type PeekOptions struct {
+	Split bool   // Open a split pane instead of a popup
+	Size  string // Popup width and height, or split height
+	View  bool   // Internal: draw the view inside the popup or split
+}
+
+func init() {
+	var opts PeekOptions
+	peekCmd := &cobra.Command{
+		Use:   "peek <worker-id>",
+		Short: "Show a worker's output in a popup over the current window",
+		Long: `Show a live, read-only view of a worker's pane in a tmux popup over the
+current window (tmux 3.2 or later), so its progress can be checked without
+switching away. Any key closes the popup. With --split, or when popups are
+not supported, the view opens in a split pane instead.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ok := false
+			if opts.View {
+				ok = viewPeek(args[0])
+			} else {
+				ok = peekWorker(args[0], opts)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	}
+	peekCmd.Flags().BoolVar(&opts.Split, "split", false, "Open the view in a split pane instead of a popup")
+	peekCmd.Flags().StringVar(&opts.Size, "size", "80%", "Size of the popup (or height of the split pane)")
+	peekCmd.Flags().BoolVar(&opts.View, "view", false, "Draw the view (run by the popup)")
+	peekCmd.Flags().MarkHidden("view")
+	rootCmd.AddCommand(peekCmd)
+}
+
+// peekArgs returns the tmux command that opens the view: a popup, or a
+// split pane below the current one.
+func peekArgs(command, dir string, split bool, size string) []string {
+	if split {
+		return []string{"split-window", "-v", "-l", size, "-c", dir, command}
+	}
+	return []string{"display-popup", "-E", "-d", dir, "-w", size, "-h", size, command}
+}
+
+// peekViewCommand is the shell command the popup runs.
+func peekViewCommand(exe, id, workspace string) string {
+	args := []string{shellQuote(exe), "peek", "--view"}
+	if workspace != "" {
+		args = append(args, "--workspace", shellQuote(workspace))
+	}
+	return strings.Join(append(args, shellQuote(id)), " ")
+}
+
+func peekWorker(id string, opts PeekOptions) bool {
+	if mux.Name() != "tmux" || !mux.Inside() {
+		fmt.Fprintln(os.Stderr, "Error: 'gtw peek' runs inside tmux; use 'gtw observe' elsewhere")
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+	if worker := config.Workers[index]; worker.Status == WorkerDetached || !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' has no running pane\n", id)
+		return false
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	dir, _ := os.Getwd()
+	command := peekViewCommand(exe, id, workspace)
+
+	if !opts.Split {
+		popup := tmuxCommand(peekArgs(command, dir, false, opts.Size)...)
+		popup.Timeout = 0 // display-popup -E waits until the popup closes
+		err := popup.Run()
+		if err == nil {
+			return true
+		}
+		fmt.Printf("Could not open a popup (tmux 3.2 or later is needed): %v; opening a split pane instead\n", err)
+	}
+	if err := tmuxCommand(peekArgs(command, dir, true, opts.Size)...).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening the view: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// viewPeek draws the worker's output until a key is pressed.
+func viewPeek(id string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+
+	// Read single keys without echoing them (gtw has no terminal library)
+	if saved, err := sttyCommand("-g").Output(); err == nil {
+		sttyCommand("-icanon", "-echo", "min", "1", "time", "0").Run()
+		defer sttyCommand(strings.TrimSpace(string(saved))).Run()
+	}
+	stop := make(chan struct{})
+	go func() {
+		buf := make([]byte, 16)
+		os.Stdin.Read(buf)
+		close(stop)
+	}()
+
+	streamPane(config.Workers[index], ObserveOptions{Interval: 500 * time.Millisecond}, stop)
+	<-stop
+	return true
+}
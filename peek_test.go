@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeekArgs(t *testing.T) {
+	popup := peekArgs("gtw peek --view w1", "/repo", false, "80%")
+	want := []string{"display-popup", "-E", "-d", "/repo", "-w", "80%", "-h", "80%", "gtw peek --view w1"}
+	if !reflect.DeepEqual(popup, want) {
+		t.Errorf("peekArgs() = %v, want %v", popup, want)
+	}
+
+	split := peekArgs("gtw peek --view w1", "/repo", true, "40%")
+	want = []string{"split-window", "-v", "-l", "40%", "-c", "/repo", "gtw peek --view w1"}
+	if !reflect.DeepEqual(split, want) {
+		t.Errorf("peekArgs() = %v, want %v", split, want)
+	}
+}
+
+func TestPeekViewCommand(t *testing.T) {
+	if got := peekViewCommand("/usr/local/bin/gtw", "w1", ""); got != "'/usr/local/bin/gtw' peek --view 'w1'" {
+		t.Errorf("peekViewCommand() = %q", got)
+	}
+	if got := peekViewCommand("/opt/my tools/gtw", "w1", "review"); got != "'/opt/my tools/gtw' peek --view --workspace 'review' 'w1'" {
+		t.Errorf("peekViewCommand() = %q", got)
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+const pickerHeight = 10 // Rows of matches shown at once
+
+var errPickCanceled = errors.New("canceled")
+
+func init() {
+	pickCmd := &cobra.Command{
+		Use:   "pick [remove|attach|status|send]",
+		Short: "Choose a worker from a fuzzy-searchable list and act on it",
+		Long: `Show the workers in a list that narrows down as you type (matching the ID,
+note and tags), and run the action on the chosen worker. Without an action
+the worker ID is printed, e.g. for 'gtw logs $(gtw pick)'.
+
+Keys: type to filter, Up/Down or Ctrl-P/Ctrl-N to move, Enter to choose,
+Esc or Ctrl-C to cancel.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"remove", "attach", "status", "send"},
+		Run: func(cmd *cobra.Command, args []string) {
+			action := ""
+			if len(args) == 1 {
+				action = args[0]
+			}
+			if !pickWorker(action) {
+				os.Exit(1)
+			}
+		},
+	}
+	rootCmd.AddCommand(pickCmd)
+}
+
+// fuzzyScore reports whether the letters of query appear in order in text
+// (ignoring case) and scores the match: consecutive letters and letters at
+// the start of a word score higher.
+func fuzzyScore(text, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	textRunes := []rune(strings.ToLower(text))
+	queryRunes := []rune(strings.ToLower(query))
+
+	score, qi, last := 0, 0, -2
+	for ti, r := range textRunes {
+		if qi == len(queryRunes) {
+			break
+		}
+		if r != queryRunes[qi] {
+			continue
+		}
+		score++
+		if ti == last+1 {
+			score += 3
+		}
+		if ti == 0 || !unicode.IsLetter(textRunes[ti-1]) && !unicode.IsDigit(textRunes[ti-1]) {
+			score += 5
+		}
+		last = ti
+		qi++
+	}
+	return score, qi == len(queryRunes)
+}
+
+// pickItem is a worker in the picker.
+type pickItem struct {
+	ID   string
+	Text string // What the query is matched against
+	Line string // What is shown
+}
+
+func newPickItems(workers []Worker) []pickItem {
+	items := make([]pickItem, 0, len(workers))
+	for _, worker := range workers {
+		var details []string
+		if worker.Note != "" {
+			details = append(details, worker.Note)
+		}
+		for _, tag := range worker.Tags {
+			details = append(details, "#"+tag)
+		}
+		description := strings.Join(details, " ")
+		items = append(items, pickItem{
+			ID:   worker.ID,
+			Text: strings.TrimSpace(worker.ID + " " + description),
+			Line: strings.TrimRight(fmt.Sprintf("%-20s %-15s %s", worker.ID, worker.state(), description), " "),
+		})
+	}
+	return items
+}
+
+// filterPickItems returns the items matching query, best match first.
+func filterPickItems(items []pickItem, query string) []pickItem {
+	type scored struct {
+		item  pickItem
+		score int
+	}
+	var matches []scored
+	for _, item := range items {
+		if score, ok := fuzzyScore(item.Text, query); ok {
+			matches = append(matches, scored{item, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	result := make([]pickItem, len(matches))
+	for i, match := range matches {
+		result[i] = match.item
+	}
+	return result
+}
+
+// picker is the state of the interactive list.
+type picker struct {
+	items    []pickItem
+	query    string
+	matches  []pickItem
+	selected int
+}
+
+func newPicker(items []pickItem) *picker {
+	return &picker{items: items, matches: items}
+}
+
+// handle applies a key press and returns the chosen ID once Enter is
+// pressed, or errPickCanceled.
+func (p *picker) handle(key []byte) (string, error) {
+	switch {
+	case len(key) == 1 && (key[0] == 3 || key[0] == 27): // Ctrl-C, Esc
+		return "", errPickCanceled
+	case len(key) == 1 && (key[0] == '\r' || key[0] == '\n'):
+		if len(p.matches) == 0 {
+			return "", nil
+		}
+		return p.matches[p.selected].ID, nil
+	case string(key) == "\x1b[A" || string(key) == "\x1bOA" || len(key) == 1 && key[0] == 16: // Up, Ctrl-P
+		if p.selected > 0 {
+			p.selected--
+		}
+	case string(key) == "\x1b[B" || string(key) == "\x1bOB" || len(key) == 1 && key[0] == 14: // Down, Ctrl-N
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+	case len(key) == 1 && (key[0] == 127 || key[0] == 8): // Backspace
+		if p.query != "" {
+			_, size := utf8.DecodeLastRuneInString(p.query)
+			p.setQuery(p.query[:len(p.query)-size])
+		}
+	case len(key) == 1 && key[0] == 21: // Ctrl-U
+		p.setQuery("")
+	case key[0] >= 32 && key[0] != 127 && utf8.Valid(key):
+		p.setQuery(p.query + string(key))
+	}
+	return "", nil
+}
+
+func (p *picker) setQuery(query string) {
+	p.query = query
+	p.matches = filterPickItems(p.items, query)
+	p.selected = 0
+}
+
+// render draws the list ending with the query line; it returns the number of
+// lines above the query line so that the next frame can overwrite them.
+func (p *picker) render(w io.Writer) int {
+	lines := 0
+	start := 0
+	if p.selected >= pickerHeight {
+		start = p.selected - pickerHeight + 1
+	}
+	for i := start; i < len(p.matches) && i < start+pickerHeight; i++ {
+		marker := "  "
+		if i == p.selected {
+			marker = "▶ "
+		}
+		fmt.Fprintf(w, "\x1b[K%s%s\n", marker, p.matches[i].Line)
+		lines++
+	}
+	fmt.Fprintf(w, "\x1b[K  %d/%d\n", len(p.matches), len(p.items))
+	fmt.Fprintf(w, "\x1b[K> %s", p.query)
+	return lines + 1
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runPicker shows the interactive list on stderr, reading keys from the
+// terminal in non-canonical mode (set with stty, as gtw has no terminal
+// library).
+func runPicker(items []pickItem) (string, error) {
+	saved, err := sttyCommand("-g").Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot configure the terminal: %v", err)
+	}
+	if err := sttyCommand("-icanon", "-echo", "-isig", "min", "1", "time", "0").Run(); err != nil {
+		return "", fmt.Errorf("cannot configure the terminal: %v", err)
+	}
+	defer sttyCommand(strings.TrimSpace(string(saved))).Run()
+
+	out := os.Stderr
+	p := newPicker(items)
+	drawn := p.render(out)
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		id, err := p.handle(buf[:n])
+		if err != nil || id != "" {
+			fmt.Fprint(out, "\r\x1b[K\n")
+			return id, err
+		}
+		// Move to the first line of the previous frame and redraw
+		fmt.Fprintf(out, "\r\x1b[%dA\x1b[J", drawn)
+		drawn = p.render(out)
+	}
+}
+
+func sttyCommand(args ...string) *Cmd {
+	cmd := newCommand("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd
+}
+
+// pickByNumber is the picker for input that is not a terminal: the workers
+// are listed with numbers and a number (or a query matching one worker) is
+// read from in.
+func pickByNumber(items []pickItem, in *bufio.Reader, out io.Writer) (string, error) {
+	for i, item := range items {
+		fmt.Fprintf(out, "%3d) %s\n", i+1, item.Line)
+	}
+	fmt.Fprint(out, "Worker number or name: ")
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", errPickCanceled
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return "", errPickCanceled
+	}
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(items) {
+			return "", fmt.Errorf("no worker number %d", n)
+		}
+		return items[n-1].ID, nil
+	}
+	matches := filterPickItems(items, answer)
+	for _, match := range matches {
+		if match.ID == answer {
+			return match.ID, nil
+		}
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("%d workers match '%s'", len(matches), answer)
+	}
+	return matches[0].ID, nil
+}
+
+// focusWorker switches to the worker's pane, attaching to the session when
+// run outside of it.
+func focusWorker(worker Worker) {
+	if mux.Name() == "tmux" {
+		tmuxCommand("select-window", "-t", worker.PaneID).Run()
+	}
+	if err := mux.Focus(worker.PaneID); err != nil {
+		fmt.Printf("Error focusing worker '%s': %v\n", worker.ID, err)
+		return
+	}
+	if mux.Inside() {
+		if mux.Name() == "tmux" {
+			switchClient(worker.PaneID)
+		}
+		return
+	}
+	attachSession(false)
+}
+
+func pickWorker(action string) bool {
+	switch action {
+	case "", "remove", "attach", "status", "send":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown action '%s' (use remove, attach, status or send)\n", action)
+		return false
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if len(config.Workers) == 0 {
+		fmt.Fprintln(os.Stderr, "No workers found")
+		return false
+	}
+
+	items := newPickItems(config.Workers)
+	in := bufio.NewReader(os.Stdin)
+	var id string
+	if isTerminal(os.Stdin) && !nonInteractive {
+		id, err = runPicker(items)
+	} else {
+		id, err = pickByNumber(items, in, os.Stderr)
+	}
+	if err != nil {
+		if err != errPickCanceled {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return false
+	}
+	worker := config.Workers[findWorkerIndex(config, id)]
+
+	switch action {
+	case "":
+		fmt.Println(id)
+	case "remove":
+		removeWorker(id, RemoveOptions{})
+	case "attach":
+		focusWorker(worker)
+	case "status":
+		showWorkerStatus(id, "")
+	case "send":
+		prompter := &wizardPrompter{in: in, out: os.Stdout}
+		text, err := prompter.ask(fmt.Sprintf("Send to '%s'", id), "", nil)
+		if err != nil || text == "" {
+			return false
+		}
+		if err := sendToPane(worker.PaneID, text); err != nil {
+			fmt.Printf("❌ Error sending to worker '%s': %v\n", id, err)
+			return false
+		}
+		fmt.Printf("✅ Sent to worker '%s'\n", id)
+	}
+	return true
+}
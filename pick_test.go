@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("feature-auth", "fa"); !ok {
+		t.Error("Expected 'fa' to match 'feature-auth'")
+	}
+	if _, ok := fuzzyScore("feature-auth", "af"); ok {
+		t.Error("Expected 'af' not to match 'feature-auth'")
+	}
+	if _, ok := fuzzyScore("API", "api"); !ok {
+		t.Error("Expected matching to ignore case")
+	}
+	prefix, _ := fuzzyScore("auth-fix", "auth")
+	scattered, _ := fuzzyScore("a-unit-thing", "auth")
+	if prefix <= scattered {
+		t.Errorf("Expected a word match to score higher: %d <= %d", prefix, scattered)
+	}
+}
+
+func TestFilterPickItems(t *testing.T) {
+	items := newPickItems([]Worker{
+		{ID: "docs", Note: "update auth docs"},
+		{ID: "auth", Tags: []string{"backend"}},
+		{ID: "ui"},
+	})
+	matches := filterPickItems(items, "auth")
+	if len(matches) != 2 || matches[0].ID != "auth" {
+		t.Errorf("Unexpected matches %+v", matches)
+	}
+	if matches := filterPickItems(items, "#backend"); len(matches) != 1 || matches[0].ID != "auth" {
+		t.Errorf("Expected to match tags, got %+v", matches)
+	}
+	if matches := filterPickItems(items, ""); len(matches) != 3 {
+		t.Errorf("Expected an empty query to match everything, got %d", len(matches))
+	}
+}
+
+func TestPickerKeys(t *testing.T) {
+	p := newPicker(newPickItems([]Worker{{ID: "api"}, {ID: "docs"}, {ID: "ui"}}))
+
+	p.handle([]byte("\x1b[B"))
+	p.handle([]byte("\x1b[B"))
+	p.handle([]byte("\x1b[B")) // Stays on the last item
+	if id, _ := p.handle([]byte("\r")); id != "ui" {
+		t.Errorf("Expected 'ui', got %q", id)
+	}
+
+	p.handle([]byte("d"))
+	p.handle([]byte("x"))
+	p.handle([]byte{127})
+	if p.query != "d" || len(p.matches) != 1 {
+		t.Errorf("Unexpected state: query %q, %d matches", p.query, len(p.matches))
+	}
+	if id, _ := p.handle([]byte("\n")); id != "docs" {
+		t.Errorf("Expected 'docs', got %q", id)
+	}
+	if _, err := p.handle([]byte{3}); err != errPickCanceled {
+		t.Errorf("Expected Ctrl-C to cancel, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if lines := p.render(&out); lines != 2 || !strings.Contains(out.String(), "▶ docs") {
+		t.Errorf("Unexpected render (%d lines): %q", lines, out.String())
+	}
+}
+
+func TestPickByNumber(t *testing.T) {
+	items := newPickItems([]Worker{{ID: "api"}, {ID: "docs"}})
+	pick := func(input string) (string, error) {
+		return pickByNumber(items, bufio.NewReader(strings.NewReader(input)), &bytes.Buffer{})
+	}
+	if id, err := pick("2\n"); err != nil || id != "docs" {
+		t.Errorf("pick(2) = %q, %v", id, err)
+	}
+	if id, err := pick("ap\n"); err != nil || id != "api" {
+		t.Errorf("pick(ap) = %q, %v", id, err)
+	}
+	if _, err := pick("9\n"); err == nil {
+		t.Error("Expected an error for an out-of-range number")
+	}
+	if _, err := pick("\n"); err != errPickCanceled {
+		t.Errorf("Expected an empty answer to cancel, got %v", err)
+	}
+}
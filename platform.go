@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// tmux does not run natively on Windows. When gtw itself runs on Windows
+// every tmux command is proxied through wsl.exe, and paths handed to tmux are
+// translated to their /mnt/<drive>/ form. GTW_WSL_DISTRO selects a
+// non-default distribution.
+
+// tmuxViaWSL reports whether tmux commands must be proxied through wsl.exe.
+func tmuxViaWSL() bool {
+	return runtime.GOOS == "windows" || os.Getenv("GTW_TMUX_VIA_WSL") == "1"
+}
+
+// isWSL reports whether gtw is running inside a WSL Linux distribution.
+func isWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false
+	}
+	release := strings.ToLower(string(data))
+	return strings.Contains(release, "microsoft") || strings.Contains(release, "wsl")
+}
+
+// wslCommandArgs prefixes a command with the wsl.exe invocation.
+func wslCommandArgs(name string, args []string) []string {
+	prefix := []string{}
+	if distro := os.Getenv("GTW_WSL_DISTRO"); distro != "" {
+		prefix = append(prefix, "-d", distro)
+	}
+	// --exec runs the command directly; otherwise wsl.exe hands the command
+	// line to the login shell, which would expand text sent with send-keys
+	prefix = append(prefix, "--exec", name)
+	return append(prefix, args...)
+}
+
+// windowsToWSLPath converts a Windows path such as C:\src\app into the path
+// WSL mounts it at (/mnt/c/src/app). Other paths only get forward slashes.
+func windowsToWSLPath(path string) string {
+	slashed := strings.ReplaceAll(path, `\`, "/")
+	if len(slashed) >= 2 && slashed[1] == ':' {
+		drive := strings.ToLower(slashed[:1])
+		rest := strings.TrimPrefix(slashed[2:], "/")
+		if rest == "" {
+			return "/mnt/" + drive
+		}
+		return "/mnt/" + drive + "/" + rest
+	}
+	return slashed
+}
+
+// muxPath converts a local path into one the multiplexer's shell can cd
+// into. tmux resolves relative paths against its server's directory, so the
+// path is made absolute first.
+func muxPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if tmuxViaWSL() {
+		return windowsToWSLPath(path)
+	}
+	return path
+}
+
+// isInsideWorktreeDir reports whether dir lies inside the worktree
+// directory. It compares path components rather than assuming '/'
+// separators.
+func isInsideWorktreeDir(dir, projectPath, prefix string) bool {
+	if prefix == "" {
+		prefix = getDefaultWorktreePrefix()
+	}
+
+	if projectPath != "" {
+		root := prefix
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(projectPath, prefix)
+		}
+		rel, err := filepath.Rel(root, dir)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+
+	// Without a recorded project path, look for the prefix as a path component
+	slashed := "/" + strings.Trim(filepath.ToSlash(dir), "/") + "/"
+	return strings.Contains(slashed, "/"+strings.Trim(filepath.ToSlash(prefix), "/")+"/")
+}
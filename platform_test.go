@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowsToWSLPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`C:\Users\dev\project`, "/mnt/c/Users/dev/project"},
+		{`D:\`, "/mnt/d"},
+		{`d:/work/repo`, "/mnt/d/work/repo"},
+		{`worktree\issue-1`, "worktree/issue-1"},
+		{"/home/dev/project", "/home/dev/project"},
+	}
+
+	for _, tt := range tests {
+		if got := windowsToWSLPath(tt.in); got != tt.want {
+			t.Errorf("windowsToWSLPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsInsideWorktreeDir(t *testing.T) {
+	project := filepath.FromSlash("/src/app")
+
+	tests := []struct {
+		dir         string
+		projectPath string
+		prefix      string
+		want        bool
+	}{
+		{"/src/app", project, "worktree", false},
+		{"/src/app/worktree", project, "worktree", true},
+		{"/src/app/worktree/issue-1/pkg", project, "worktree", true},
+		{"/src/app/worktree-old", project, "worktree", false},
+		{"/src/app/work/issue-1", project, "work", true},
+		{"/src/app/worktree/issue-1", "", "worktree", true},
+		{"/src/app", "", "worktree", false},
+	}
+
+	for _, tt := range tests {
+		if got := isInsideWorktreeDir(filepath.FromSlash(tt.dir), tt.projectPath, tt.prefix); got != tt.want {
+			t.Errorf("isInsideWorktreeDir(%q, %q, %q) = %v, want %v", tt.dir, tt.projectPath, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestWSLCommandArgs(t *testing.T) {
+	t.Setenv("GTW_WSL_DISTRO", "Ubuntu")
+	got := wslCommandArgs("tmux", []string{"send-keys", "-l", "a; rm -rf $HOME"})
+	want := []string{"-d", "Ubuntu", "--exec", "tmux", "send-keys", "-l", "a; rm -rf $HOME"}
+	if len(got) != len(want) {
+		t.Fatalf("wslCommandArgs() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wslCommandArgs() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Actions a policy takes when a worker waits at a permission prompt
+const (
+	PolicyNotify  = "notify"  // Tell the user (default)
+	PolicyApprove = "approve" // Answer yes to prompts on the approve list, notify otherwise
+	PolicyPause   = "pause"   // Hold the worker's task queue and notify
+)
+
+// defaultPolicyName is the policy of workers that have none set.
+const defaultPolicyName = "default"
+
+// promptContextLines is how much of the end of the pane is searched for a
+// permission prompt and matched against the approve list.
+const promptContextLines = 15
+
+// Policy decides what happens when a worker stops at a permission or
+// confirmation prompt of its agent.
+type Policy struct {
+	Action  string   `json:"action,omitempty"`  // notify (default), approve or pause
+	Approve []string `json:"approve,omitempty"` // Regexps matching a prompt's whole command or question, answered yes with action approve, e.g. "npm (run )?test"
+}
+
+// PermissionPrompt is a kind of prompt that agent CLIs stop at, with the
+// keys that answer it with yes.
+type PermissionPrompt struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Keys    []string // tmux key names sent to approve
+}
+
+// permissionPrompts are the prompts gtw recognizes, most specific first.
+var permissionPrompts = []PermissionPrompt{
+	{
+		// Claude Code's menu: "Do you want to proceed?" / "❯ 1. Yes"
+		Name:    "claude",
+		Pattern: regexp.MustCompile(`Do you want to (proceed|make this edit|create|allow|run)[^\n]*\?[\s\S]*1\. Yes`),
+		Keys:    []string{"1"},
+	},
+	{
+		// aider: "Add file to the chat? (Y)es/(N)o/... [Yes]:"
+		Name:    "aider",
+		Pattern: regexp.MustCompile(`\(Y\)es/\(N\)o[^\n]*\[(Yes|No)\]: *$`),
+		Keys:    []string{"y", "Enter"},
+	},
+	{
+		// Codex CLI: "Allow command?" with "Yes (y)"
+		Name:    "codex",
+		Pattern: regexp.MustCompile(`(Allow|Approve) (command|this|edit)[^\n]*\?[\s\S]*[Yy]es`),
+		Keys:    []string{"y"},
+	},
+	{
+		Name:    "yes/no",
+		Pattern: regexp.MustCompile(`(?i)(\[y/n\]|\(y/n\)|\[yes/no\]|\(yes/no\))[?:]? *$`),
+		Keys:    []string{"y", "Enter"},
+	},
+}
+
+// promptTail returns the end of the pane output, without the blank lines
+// below the cursor.
+func promptTail(output string) string {
+	return strings.TrimRight(lastLines(strings.TrimRight(output, "\n "), promptContextLines), "\n")
+}
+
+// detectPermissionPrompt returns the prompt the pane output ends with, or
+// nil. Only the last lines are searched so that answered prompts further up
+// do not count, and of several prompts there the one that ends last wins.
+func detectPermissionPrompt(output string) *PermissionPrompt {
+	tail := promptTail(output)
+	var found *PermissionPrompt
+	end := -1
+	for i := range permissionPrompts {
+		matches := permissionPrompts[i].Pattern.FindAllStringIndex(tail, -1)
+		if len(matches) > 0 && matches[len(matches)-1][1] > end {
+			found, end = &permissionPrompts[i], matches[len(matches)-1][1]
+		}
+	}
+	return found
+}
+
+// policyFor returns the worker's policy: the one it names, else "default",
+// else notify.
+func (c *Config) policyFor(worker Worker) (string, Policy) {
+	name := worker.Policy
+	if name == "" {
+		name = defaultPolicyName
+	}
+	if policy, ok := c.Policies[name]; ok {
+		if policy.Action == "" {
+			policy.Action = PolicyNotify
+		}
+		return name, policy
+	}
+	return "", Policy{Action: PolicyNotify}
+}
+
+// approves reports whether a pattern of the approve list matches the whole
+// subject of a prompt (see promptSubject). Invalid patterns never match.
+func (p Policy) approves(subject string) bool {
+	if subject == "" {
+		return false
+	}
+	for _, pattern := range p.Approve {
+		if re, err := regexp.Compile(`^(?:` + pattern + `)$`); err == nil && re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandHeaders are the lines of Claude Code's prompt box above a command.
+var commandHeaders = map[string]bool{"Bash command": true, "Command": true}
+
+// promptSubject returns what a prompt asks to approve: the command of a
+// command prompt, else its question. Only the lines of the prompt itself
+// are used, so earlier output in the tail cannot get a prompt approved.
+func promptSubject(prompt *PermissionPrompt, text string) string {
+	raw := strings.Split(text, "\n")
+	lines := make([]string, len(raw))
+	for i, line := range raw {
+		lines[i] = strings.TrimSpace(strings.Trim(line, "│ "))
+	}
+	q := questionIndex(lines)
+
+	switch prompt.Name {
+	case "claude":
+		// The command sits between the box's header and the question
+		for i := q - 1; i >= 0; i-- {
+			if commandHeaders[lines[i]] {
+				return joinCommand(lines[i+1 : q])
+			}
+			if strings.HasPrefix(strings.TrimSpace(raw[i]), "╭") {
+				break
+			}
+		}
+	case "codex":
+		// "$ <command>" follows the question
+		for i := q + 1; i < len(lines); i++ {
+			if command, ok := strings.CutPrefix(lines[i], "$ "); ok {
+				return joinCommand(append([]string{command}, lines[i+1:]...))
+			}
+		}
+	}
+	return lines[q]
+}
+
+// joinCommand returns the command on the first non-blank line, joined with
+// the lines it continues on: after a trailing backslash or operator, or
+// when the next line starts with an operator or option (a long command
+// wrapped by the box). Other lines are Claude Code's description.
+func joinCommand(lines []string) string {
+	command := ""
+	for _, line := range lines {
+		if line == "" {
+			if command == "" {
+				continue
+			}
+			break
+		}
+		if command == "" {
+			command = line
+			continue
+		}
+		if !continuesCommand(command, line) {
+			break
+		}
+		command = strings.TrimSuffix(command, "\\") + " " + line
+	}
+	return strings.TrimSpace(command)
+}
+
+func continuesCommand(command, next string) bool {
+	if strings.ContainsAny(command[len(command)-1:], "\\&|;") {
+		return true
+	}
+	return strings.ContainsAny(next[:1], "&|;<>-")
+}
+
+// PermissionWatcher applies the policies from the daemon. It remembers the
+// prompt each worker is waiting at, so that every prompt is handled once.
+type PermissionWatcher struct {
+	waiting map[string]string
+}
+
+func NewPermissionWatcher() *PermissionWatcher {
+	return &PermissionWatcher{waiting: make(map[string]string)}
+}
+
+// Run checks every worker for a permission prompt and applies its policy.
+// It reports whether the config changed (a task queue was held).
+func (w *PermissionWatcher) Run(config *Config) bool {
+	changed := false
+	for i := range config.Workers {
+		worker := &config.Workers[i]
+		if worker.Status == WorkerPaused || worker.Status == WorkerDetached {
+			continue
+		}
+		output, err := capturePane(worker.PaneID, 50)
+		if err != nil {
+			continue
+		}
+		prompt := detectPermissionPrompt(output)
+		if prompt == nil {
+			delete(w.waiting, worker.ID)
+			continue
+		}
+		text := promptTail(output)
+		if w.waiting[worker.ID] == text {
+			continue
+		}
+		w.waiting[worker.ID] = text
+		if applyPolicy(config, worker, prompt, text) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// applyPolicy handles a prompt the worker waits at. It reports whether the
+// worker was changed.
+func applyPolicy(config *Config, worker *Worker, prompt *PermissionPrompt, text string) bool {
+	name, policy := config.policyFor(*worker)
+	question := promptQuestion(text)
+
+	switch policy.Action {
+	case PolicyApprove:
+		if mux.Name() == "tmux" && policy.approves(promptSubject(prompt, text)) {
+			args := append([]string{"send-keys", "-t", worker.PaneID}, prompt.Keys...)
+			if err := tmuxCommand(args...).Run(); err != nil {
+				fmt.Printf("Warning: Could not approve the prompt of worker '%s': %v\n", worker.ID, err)
+				break
+			}
+			fmt.Printf("✅ Approved %s prompt of worker '%s' (policy %s): %s\n", prompt.Name, worker.ID, name, question)
+			recordEvent(EventPromptApproved, worker.ID, question)
+			return false
+		}
+	case PolicyPause:
+		if !worker.TasksHeld {
+			worker.TasksHeld = true
+			fmt.Printf("⏸️  Holding the task queue of worker '%s' until 'gtw task resume %s'\n", worker.ID, worker.ID)
+			notifyAttention(config, *worker, prompt, question)
+			return true
+		}
+	}
+	notifyAttention(config, *worker, prompt, question)
+	return false
+}
+
+// promptQuestion picks the line of the prompt that asks the question, for
+// messages and the history.
+func promptQuestion(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.Trim(line, "│ "))
+	}
+	return lines[questionIndex(lines)]
+}
+
+// questionIndex returns the index of the last line that asks a question,
+// else of the last line.
+func questionIndex(lines []string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], "?") || strings.HasSuffix(lines[i], ":") {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// notifyAttention tells the user that the worker waits for an answer: on
+// the daemon's output, in the history, in the tmux status line and with the
+// configured notifications.
+func notifyAttention(config *Config, worker Worker, prompt *PermissionPrompt, question string) {
+	fmt.Printf("⚠️  Worker '%s' is waiting at a %s prompt: %s\n", worker.ID, prompt.Name, question)
+	recordEvent(EventNeedsAttention, worker.ID, question)
+	if mux.Name() == "tmux" {
+		tmuxCommand("display-message", fmt.Sprintf("gtw: %s is waiting for an answer", worker.ID)).Run()
+	}
+	sendNotification(config, workerNotification(worker, NotifyNeedsAttention,
+		fmt.Sprintf("gtw: %s needs attention", worker.ID), question))
+}
+
+func init() {
+	policyCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Handle agent permission prompts with per-worker policies",
+		Long: `'gtw daemon' watches worker panes for the permission and confirmation prompts
+of agent CLIs (Claude Code, aider, Codex and generic [y/n] prompts) and
+applies the worker's policy from the policies config:
+
+  notify   report the prompt (default)
+  approve  answer yes when the prompt matches one of the approve regexps,
+           report it otherwise
+  pause    hold the worker's task queue until 'gtw task resume', and report it
+
+Workers use the policy named "default" unless another one is set.`,
+	}
+
+	policySetCmd := &cobra.Command{
+		Use:   "set <policy> <worker-id>...",
+		Short: "Set the policy of workers (\"\" for the default policy)",
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !setWorkerPolicy(args[0], args[1:]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	policyCheckCmd := &cobra.Command{
+		Use:   "check [worker-id...]",
+		Short: "Show which workers wait at a permission prompt and what their policy would do",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !checkPermissionPrompts(args) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	policyCmd.AddCommand(policySetCmd, policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+func setWorkerPolicy(name string, ids []string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if _, ok := config.Policies[name]; !ok && name != "" {
+		names := make([]string, 0, len(config.Policies))
+		for policy := range config.Policies {
+			names = append(names, policy)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Error: Unknown policy '%s' (configured: %s)\n", name, strings.Join(names, ", "))
+		return false
+	}
+	for _, id := range ids {
+		if findWorkerIndex(config, id) == -1 {
+			fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+			return false
+		}
+	}
+	for _, id := range ids {
+		config.Workers[findWorkerIndex(config, id)].Policy = name
+	}
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		return false
+	}
+	if name == "" {
+		name = defaultPolicyName
+	}
+	fmt.Printf("✅ Set policy '%s' for %s\n", name, strings.Join(ids, ", "))
+	return true
+}
+
+func checkPermissionPrompts(ids []string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	workers, err := selectWorkers(config, ids, len(ids) == 0, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	waiting := 0
+	for _, worker := range workers {
+		name, policy := config.policyFor(worker)
+		if name == "" {
+			name = "(none)"
+		}
+		output, err := capturePane(worker.PaneID, 50)
+		if err != nil {
+			fmt.Printf("%-20s %v\n", worker.ID, err)
+			continue
+		}
+		prompt := detectPermissionPrompt(output)
+		if prompt == nil {
+			fmt.Printf("%-20s no prompt (policy %s: %s)\n", worker.ID, name, policy.Action)
+			continue
+		}
+		waiting++
+		text := promptTail(output)
+		outcome := policy.Action
+		if policy.Action == PolicyApprove && !policy.approves(text) {
+			outcome = "notify (not on the approve list)"
+		}
+		fmt.Printf("%-20s %s prompt: %s → %s (policy %s)\n", worker.ID, prompt.Name, promptQuestion(text), outcome, name)
+	}
+	if waiting > 0 {
+		fmt.Printf("\n%d worker(s) waiting for an answer\n", waiting)
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestDetectPermissionPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"claude bash", "╭────╮\n│ Bash command\n│   npm test\n│ Do you want to proceed?\n│ ❯ 1. Yes\n│   2. No, and tell Claude what to do differently\n╰────╯\n\n\n", "claude"},
+		{"claude edit", "Do you want to make this edit to main.go?\n❯ 1. Yes\n  2. Yes, allow all edits during this session\n  3. No", "claude"},
+		{"aider", "Add main.go to the chat? (Y)es/(N)o/(D)on't ask again [Yes]: ", "aider"},
+		{"codex", "Allow command?\n  $ go test ./...\n▶ Yes (y)  No (n)", "codex"},
+		{"generic", "Overwrite config.json? [y/N] ", "yes/no"},
+		{"none", "Running tests...\nok  \tpkg\t0.3s\n$ ", ""},
+		{"answered further up", "Overwrite? [y/N] y\nwrote config.json\n$ ", ""},
+		{"claude box above a newer question", "│ Do you want to proceed?\n│ ❯ 1. Yes\n╰────╯\nOverwrite config.json? [y/N] ", "yes/no"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if prompt := detectPermissionPrompt(tt.output); prompt != nil {
+			got = prompt.Name
+		}
+		if got != tt.want {
+			t.Errorf("%s: detected %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	config := &Config{Policies: map[string]Policy{
+		"default": {},
+		"ci":      {Action: PolicyApprove, Approve: []string{`npm (run )?test`, `(`}},
+	}}
+
+	name, policy := config.policyFor(Worker{})
+	if name != "default" || policy.Action != PolicyNotify {
+		t.Errorf("Expected the default policy to notify, got %q %+v", name, policy)
+	}
+	name, policy = config.policyFor(Worker{Policy: "ci"})
+	if name != "ci" || policy.Action != PolicyApprove {
+		t.Errorf("Expected the ci policy, got %q %+v", name, policy)
+	}
+	if !policy.approves("npm run test") {
+		t.Error("Expected npm run test to be approved")
+	}
+	for _, subject := range []string{"rm -rf node_modules", "npm test && rm -rf ~", "echo npm test", ""} {
+		if policy.approves(subject) {
+			t.Errorf("Expected %q not to be approved", subject)
+		}
+	}
+
+	if name, policy := (&Config{}).policyFor(Worker{}); name != "" || policy.Action != PolicyNotify {
+		t.Errorf("Expected notify without policies, got %q %+v", name, policy)
+	}
+}
+
+func TestPromptQuestion(t *testing.T) {
+	text := "│ Bash command\n│   npm test\n│ Do you want to proceed?\n│ ❯ 1. Yes\n│   2. No"
+	if got := promptQuestion(text); got != "Do you want to proceed?" {
+		t.Errorf("promptQuestion = %q", got)
+	}
+}
+
+func TestPromptSubject(t *testing.T) {
+	box := func(lines ...string) string {
+		text := "╭────╮\n"
+		for _, line := range lines {
+			text += "│ " + line + "\n"
+		}
+		return text + "│ Do you want to proceed?\n│ ❯ 1. Yes\n│   2. No\n╰────╯"
+	}
+	policy := Policy{Action: PolicyApprove, Approve: []string{`npm (run )?test`, `go test \./\.\.\.`}}
+
+	tests := []struct {
+		name    string
+		output  string
+		subject string
+		approve bool
+	}{
+		{"claude", box("Bash command", "", "  npm test", "  Run the test suite"), "npm test", true},
+		{"trailing destructive command", box("Bash command", "  npm test && rm -rf ~"), "npm test && rm -rf ~", false},
+		{"command wrapped by the box", box("Bash command", "  npm test", "  && rm -rf ~"), "npm test && rm -rf ~", false},
+		{"continued line", box("Bash command", "  npm test \\", "  ; rm -rf ~"), "npm test  ; rm -rf ~", false},
+		{"stale approved text above the prompt", "$ npm test\nok\n" + box("Bash command", "  rm -rf node_modules"), "rm -rf node_modules", false},
+		{"stale command box above a question", box("Bash command", "  npm test") + "\nOverwrite config.json? [y/N] ", "Overwrite config.json? [y/N]", false},
+		{"claude edit", "npm test\nDo you want to make this edit to main.go?\n❯ 1. Yes\n  2. No", "Do you want to make this edit to main.go?", false},
+		{"codex", "Allow command?\n  $ go test ./...\n▶ Yes (y)  No (n)", "go test ./...", true},
+		{"codex destructive", "npm test\nAllow command?\n  $ go test ./... && git push -f\n▶ Yes (y)  No (n)", "go test ./... && git push -f", false},
+	}
+	for _, tt := range tests {
+		prompt := detectPermissionPrompt(tt.output)
+		if prompt == nil {
+			t.Errorf("%s: no prompt detected", tt.name)
+			continue
+		}
+		subject := promptSubject(prompt, promptTail(tt.output))
+		if subject != tt.subject {
+			t.Errorf("%s: subject %q, want %q", tt.name, subject, tt.subject)
+		}
+		if got := policy.approves(subject); got != tt.approve {
+			t.Errorf("%s: approved %v, want %v", tt.name, got, tt.approve)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Preset is a ready-made init command for a common tool, applied with
+// `gtw config set --preset <name>`.
+type Preset struct {
+	Name         string
+	Description  string
+	InitCommand  string
+	ReadyPattern string // Matches the pane output once the tool accepts input
+}
+
+// builtinPresets are the presets shipped with gtw. The ready patterns match
+// what each tool prints once it waits for input (or has started serving).
+var builtinPresets = []Preset{
+	{
+		Name:         "claude",
+		Description:  "Claude Code",
+		InitCommand:  "claude",
+		ReadyPattern: `\? for shortcuts`,
+	},
+	{
+		Name:         "aider",
+		Description:  "Aider; gtw checkpoints replace its auto-commits",
+		InitCommand:  "aider --no-auto-commits --no-pretty",
+		ReadyPattern: `(?m)^[a-z-]*> ?$`,
+	},
+	{
+		Name:         "codex",
+		Description:  "OpenAI Codex CLI",
+		InitCommand:  "codex",
+		ReadyPattern: `\? for shortcuts|⏎ send`,
+	},
+	{
+		Name:         "npm-dev",
+		Description:  "Install dependencies and start the dev server",
+		InitCommand:  "npm install && npm run dev",
+		ReadyPattern: `(?i)ready in|listening on|https?://(localhost|127\.0\.0\.1):\d+`,
+	},
+	{
+		Name:         "pytest-watch",
+		Description:  "Re-run pytest on every change (pip install pytest-watch)",
+		InitCommand:  "ptw -- -q",
+		ReadyPattern: `\d+ (passed|failed|errors?)\b.* in [\d.]+s`,
+	},
+}
+
+// findPreset returns the built-in preset called name.
+func findPreset(name string) (Preset, bool) {
+	for _, preset := range builtinPresets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// presetNames lists the built-in presets for error messages.
+func presetNames() string {
+	names := make([]string, len(builtinPresets))
+	for i, preset := range builtinPresets {
+		names[i] = preset.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// configPresetsCommand is added to `gtw config` in main.go.
+func configPresetsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "presets",
+		Short: "List the built-in init command presets",
+		Long: `List the built-in init command presets for common agent CLIs and dev tools.
+Apply one with 'gtw config set --preset <name>' (or to a profile with
+--profile <name>). A command given after the preset name replaces the
+preset's command but keeps its ready pattern.`,
+		Args: cobra.NoArgs,
+		Run:  func(cmd *cobra.Command, args []string) { listPresets() },
+	}
+}
+
+func listPresets() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCOMMAND\tDESCRIPTION")
+	for _, preset := range builtinPresets {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", preset.Name, preset.InitCommand, preset.Description)
+	}
+	w.Flush()
+}
+
+// applyPreset sets the init command and ready pattern of a preset, on the
+// whole config or on one profile. A non-empty command replaces the preset's.
+func applyPreset(config *Config, name, command, profile string) (Preset, error) {
+	preset, ok := findPreset(name)
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset '%s' (available: %s)", name, presetNames())
+	}
+	if command != "" {
+		preset.InitCommand = command
+	}
+	if profile == "" {
+		config.InitCommand = preset.InitCommand
+		config.ReadyPattern = preset.ReadyPattern
+		return preset, nil
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]Profile{}
+	}
+	p := config.Profiles[profile]
+	p.InitCommand = preset.InitCommand
+	p.ReadyPattern = preset.ReadyPattern
+	config.Profiles[profile] = p
+	return preset, nil
+}
+
+func setPresetCommand(name, command, profile string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	preset, err := applyPreset(config, name, command, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		return false
+	}
+	target := "initialization command"
+	if profile != "" {
+		target = fmt.Sprintf("profile '%s'", profile)
+	}
+	fmt.Printf("✅ Set %s to preset '%s': %s\n", target, name, preset.InitCommand)
+	return true
+}
+
+// readyPatternFor returns the ready pattern of the worker's profile, or
+// ready_pattern when the worker has no (known) profile.
+func (c *Config) readyPatternFor(worker Worker) string {
+	if profile, ok := c.Profiles[worker.Profile]; ok && worker.Profile != "" {
+		return profile.ReadyPattern
+	}
+	return c.ReadyPattern
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestBuiltinPresets(t *testing.T) {
+	seen := map[string]bool{}
+	for _, preset := range builtinPresets {
+		if seen[preset.Name] {
+			t.Errorf("Duplicate preset %q", preset.Name)
+		}
+		seen[preset.Name] = true
+		if preset.InitCommand == "" {
+			t.Errorf("Preset %q has no init command", preset.Name)
+		}
+		if _, err := regexp.Compile(preset.ReadyPattern); err != nil {
+			t.Errorf("Preset %q has an invalid ready pattern: %v", preset.Name, err)
+		}
+	}
+}
+
+func TestPresetReadyPatterns(t *testing.T) {
+	tests := []struct {
+		preset string
+		output string
+		want   bool
+	}{
+		{"claude", "╭──────╮\n│ >    │\n╰──────╯\n  ? for shortcuts", true},
+		{"claude", "Welcome to Claude Code", false},
+		{"aider", "Aider v0.80.0\nRepo-map: using 1024 tokens\n> ", true},
+		{"aider", "architect> ", true},
+		{"aider", "Aider v0.80.0", false},
+		{"npm-dev", "  VITE v5.0.0  ready in 312 ms", true},
+		{"npm-dev", "- Local: http://localhost:3000", true},
+		{"npm-dev", "added 120 packages", false},
+		{"pytest-watch", "===== 12 passed in 0.53s =====", true},
+		{"pytest-watch", "1 failed, 3 passed in 1.20s", true},
+		{"pytest-watch", "collecting ...", false},
+	}
+	for _, tt := range tests {
+		preset, ok := findPreset(tt.preset)
+		if !ok {
+			t.Fatalf("Preset %q not found", tt.preset)
+		}
+		if got := regexp.MustCompile(preset.ReadyPattern).MatchString(tt.output); got != tt.want {
+			t.Errorf("%s pattern on %q = %v, want %v", tt.preset, tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	config := &Config{}
+	if _, err := applyPreset(config, "aider", "", ""); err != nil {
+		t.Fatalf("applyPreset returned error: %v", err)
+	}
+	aider, _ := findPreset("aider")
+	if config.InitCommand != aider.InitCommand || config.ReadyPattern != aider.ReadyPattern {
+		t.Errorf("Expected the aider preset, got %q / %q", config.InitCommand, config.ReadyPattern)
+	}
+
+	// A command replaces the preset's but keeps its ready pattern
+	if _, err := applyPreset(config, "claude", "claude --model opus", "review"); err != nil {
+		t.Fatalf("applyPreset returned error: %v", err)
+	}
+	claude, _ := findPreset("claude")
+	profile := config.Profiles["review"]
+	if profile.InitCommand != "claude --model opus" || profile.ReadyPattern != claude.ReadyPattern {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+	if config.InitCommand != aider.InitCommand {
+		t.Errorf("Applying to a profile changed init_command to %q", config.InitCommand)
+	}
+	if config.readyPatternFor(Worker{Profile: "review"}) != claude.ReadyPattern {
+		t.Error("Expected the profile's ready pattern for its workers")
+	}
+
+	if _, err := applyPreset(config, "unknown", "", ""); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "sort"
+
+// Profile is a named alternative to init_command, selected per worker with
+// `gtw add --profile` or in the manifest. Its dependency caches replace
+// dependency_caches and its env is added to worker_env.
+type Profile struct {
+	InitCommand      string            `json:"init_command"`
+	DependencyCaches []DependencyCache `json:"dependency_caches,omitempty"`
+	Env              map[string]string `json:"env,omitempty"`
+	ReadyPattern     string            `json:"ready_pattern,omitempty"`
+	UsageCommand     string            `json:"usage_command,omitempty"` // Prints the token/cost usage of the profile's agent for `gtw stats --costs`
+	Readiness        []ReadinessCheck  `json:"readiness,omitempty"`     // What has to hold before a worker counts as ready (see readiness.go)
+}
+
+// initCommandFor returns the command of the worker's agent, else the init
+// command of its profile, or init_command when it has no (known) profile.
+func (c *Config) initCommandFor(worker Worker) string {
+	if agent, err := findAgent(c, worker.Agent); err == nil && agent.Command() != "" {
+		return agent.Command()
+	}
+	if profile, ok := c.Profiles[worker.Profile]; ok && worker.Profile != "" {
+		return profile.InitCommand
+	}
+	return c.InitCommand
+}
+
+// profileNames returns the configured profile names in sorted order.
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Project is an initialized project recorded in the user's registry so it
+// can be targeted from any directory.
+type Project struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// ProjectRegistry is stored in the user config directory
+// (e.g. ~/.config/gtw/projects.json).
+type ProjectRegistry struct {
+	Projects []Project `json:"projects"`
+}
+
+func init() {
+	projectsCmd := &cobra.Command{
+		Use:   "projects",
+		Short: "List and switch between initialized projects",
+		Run:   func(cmd *cobra.Command, args []string) { listProjects() },
+	}
+
+	projectsListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List initialized projects",
+		Run:   func(cmd *cobra.Command, args []string) { listProjects() },
+	}
+
+	projectsSwitchCmd := &cobra.Command{
+		Use:   "switch <name|path>",
+		Short: "Switch the tmux client to a project's session (or attach to it)",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { switchProject(args[0]) },
+	}
+
+	projectsRemoveCmd := &cobra.Command{
+		Use:   "remove <name|path>",
+		Short: "Forget a project (its session and worktrees are left untouched)",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { forgetProject(args[0]) },
+	}
+
+	projectsCmd.AddCommand(projectsListCmd, projectsSwitchCmd, projectsRemoveCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
+
+func registryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gtw", "projects.json"), nil
+}
+
+func loadRegistry() (*ProjectRegistry, error) {
+	registry := &ProjectRegistry{}
+	path, err := registryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+func saveRegistry(registry *ProjectRegistry) error {
+	path, err := registryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// add records the project at dir, refreshing its last use if it is known.
+func (r *ProjectRegistry) add(dir string, now time.Time) {
+	for i := range r.Projects {
+		if r.Projects[i].Path == dir {
+			r.Projects[i].LastUsed = now
+			return
+		}
+	}
+	r.Projects = append(r.Projects, Project{Name: filepath.Base(dir), Path: dir, LastUsed: now})
+}
+
+// find looks a project up by name or path. Names are only accepted when
+// they are unambiguous.
+func (r *ProjectRegistry) find(nameOrPath string) (int, error) {
+	if abs, err := filepath.Abs(nameOrPath); err == nil {
+		for i, project := range r.Projects {
+			if project.Path == abs {
+				return i, nil
+			}
+		}
+	}
+
+	found := -1
+	for i, project := range r.Projects {
+		if project.Name != nameOrPath {
+			continue
+		}
+		if found != -1 {
+			return -1, fmt.Errorf("more than one project is named '%s'; use its path", nameOrPath)
+		}
+		found = i
+	}
+	if found == -1 {
+		return -1, fmt.Errorf("project '%s' not found (see 'gtw projects list')", nameOrPath)
+	}
+	return found, nil
+}
+
+// registerProject adds dir to the registry, warning instead of failing
+// because the registry is only a convenience.
+func registerProject(dir string) {
+	registry, err := loadRegistry()
+	if err == nil {
+		registry.add(dir, time.Now())
+		err = saveRegistry(registry)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Could not record project in registry: %v\n", err)
+	}
+}
+
+// resolveProjectDir returns the directory of a registered project name or
+// of a path that contains a gtw config.
+func resolveProjectDir(nameOrPath string) (string, error) {
+	registry, err := loadRegistry()
+	if err != nil {
+		return "", err
+	}
+	if i, findErr := registry.find(nameOrPath); findErr == nil {
+		return registry.Projects[i].Path, nil
+	} else if _, statErr := os.Stat(nameOrPath); statErr != nil {
+		return "", findErr
+	}
+
+	dir, err := filepath.Abs(nameOrPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(dir, configFile)); err != nil {
+		return "", fmt.Errorf("%s is not a gtw project (no %s)", dir, configFile)
+	}
+	return dir, nil
+}
+
+// enterProject makes dir the working directory so that the config, session
+// name and worktree paths all resolve against that project.
+func enterProject(nameOrPath string) error {
+	dir, err := resolveProjectDir(nameOrPath)
+	if err != nil {
+		return err
+	}
+	return os.Chdir(dir)
+}
+
+// invocationDir is the directory gtw was started in, before enterProjectRoot
+// moved to the project root.
+var invocationDir string
+
+// findProjectRoot walks up from dir to the nearest directory that holds the
+// gtw config or the .gtw directory.
+func findProjectRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, configFile)); err == nil {
+			return dir, true
+		}
+		if info, err := os.Stat(filepath.Join(dir, ".gtw")); err == nil && info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// mainRepositoryRoot returns the project root of the repository dir belongs
+// to, found next to the repository's common git directory. This is how a
+// worktree outside the project (worktree_prefix elsewhere) finds its way
+// back.
+func mainRepositoryRoot(dir string) (string, bool) {
+	output, err := gitCommand("-C", dir, "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", false
+	}
+	common := filepath.FromSlash(strings.TrimSpace(string(output)))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return findProjectRoot(filepath.Dir(common))
+}
+
+// enterProjectRoot moves to the project root when gtw is run from one of its
+// subdirectories, like git does: the nearest directory with the gtw config,
+// the project of the repository when in a worktree outside it, or else the
+// top of the git repository.
+func enterProjectRoot() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	invocationDir = cwd
+
+	root, found := findProjectRoot(cwd)
+	if !found {
+		root, found = mainRepositoryRoot(cwd)
+	}
+	if !found {
+		output, err := gitCommand("rev-parse", "--show-toplevel").Output()
+		if err != nil {
+			return nil // Not in a repository; leave it to the command
+		}
+		root = filepath.FromSlash(strings.TrimSpace(string(output)))
+	}
+	if root == cwd {
+		return nil
+	}
+	return os.Chdir(root)
+}
+
+// userPath resolves a path given on the command line against the directory
+// gtw was started in rather than the project root.
+func userPath(path string) string {
+	if path == "" || filepath.IsAbs(path) || invocationDir == "" {
+		return path
+	}
+	return filepath.Join(invocationDir, path)
+}
+
+func listProjects() {
+	registry, err := loadRegistry()
+	if err != nil {
+		fmt.Printf("Error loading project registry: %v\n", err)
+		return
+	}
+	if len(registry.Projects) == 0 {
+		fmt.Println("No projects registered. Run 'gtw init' in a repository to add one.")
+		return
+	}
+
+	fmt.Printf("%-20s %-10s %-8s %-17s %s\n", "NAME", "SESSION", "WORKERS", "LAST USED", "PATH")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, project := range registry.Projects {
+		workers := "-"
+		session := "missing"
+		if data, err := os.ReadFile(filepath.Join(project.Path, configFile)); err == nil {
+			var config Config
+			if json.Unmarshal(data, &config) == nil {
+				workers = fmt.Sprintf("%d", len(config.Workers))
+				if config.Store == StoreSQLite {
+					workers = "-"
+					if _, err := os.Stat(filepath.Join(project.Path, sqliteStoreFile)); err == nil {
+						if store, err := openSQLiteStore(filepath.Join(project.Path, sqliteStoreFile)); err == nil {
+							if count, err := store.storedWorkerCount(); err == nil {
+								workers = fmt.Sprintf("%d", count)
+							}
+						}
+					}
+				}
+			}
+			// Projects may use different multiplexers
+			session = "stopped"
+			if resolveMultiplexer(config.Multiplexer).HasSession(project.Name) {
+				session = "running"
+			}
+		}
+
+		fmt.Printf("%-20s %-10s %-8s %-17s %s\n",
+			project.Name,
+			session,
+			workers,
+			project.LastUsed.Format("2006-01-02 15:04"),
+			project.Path)
+	}
+}
+
+func switchProject(nameOrPath string) {
+	if err := enterProject(nameOrPath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// The target project may use another multiplexer
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	mux = resolveMultiplexer(config.Multiplexer)
+	paneLayout = resolvePaneLayout(config)
+
+	if cwd, err := os.Getwd(); err == nil {
+		registerProject(cwd)
+	}
+
+	sessionName := getSessionName()
+	if mux.Inside() && mux.Name() == "tmux" {
+		if !mux.HasSession(sessionName) && !recreateSession(sessionName, false) {
+			return
+		}
+		if err := switchClient(sessionName); err != nil {
+			fmt.Printf("Error switching to session '%s': %v\n", sessionName, err)
+			return
+		}
+		fmt.Printf("Switched to session '%s'\n", sessionName)
+		return
+	}
+	attachSession(false)
+}
+
+func forgetProject(nameOrPath string) {
+	registry, err := loadRegistry()
+	if err != nil {
+		fmt.Printf("Error loading project registry: %v\n", err)
+		return
+	}
+	i, err := registry.find(nameOrPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	project := registry.Projects[i]
+	registry.Projects = append(registry.Projects[:i], registry.Projects[i+1:]...)
+	if err := saveRegistry(registry); err != nil {
+		fmt.Printf("Error saving project registry: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Removed project '%s' (%s) from the registry\n", project.Name, project.Path)
+}
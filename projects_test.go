@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProjectRegistryAdd(t *testing.T) {
+	registry := &ProjectRegistry{}
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry.add("/src/app", first)
+	registry.add("/src/api", first)
+	registry.add("/src/app", first.Add(time.Hour))
+
+	if len(registry.Projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %+v", registry.Projects)
+	}
+	if registry.Projects[0].Name != "app" || !registry.Projects[0].LastUsed.Equal(first.Add(time.Hour)) {
+		t.Errorf("Expected 'app' to be refreshed, got %+v", registry.Projects[0])
+	}
+}
+
+func TestProjectRegistryFind(t *testing.T) {
+	registry := &ProjectRegistry{Projects: []Project{
+		{Name: "app", Path: "/src/app"},
+		{Name: "web", Path: "/src/a/web"},
+		{Name: "web", Path: "/src/b/web"},
+	}}
+
+	if i, err := registry.find("app"); err != nil || i != 0 {
+		t.Errorf("Expected 'app' at 0, got %d (%v)", i, err)
+	}
+	if i, err := registry.find("/src/b/web"); err != nil || i != 2 {
+		t.Errorf("Expected path lookup to return 2, got %d (%v)", i, err)
+	}
+	if _, err := registry.find("web"); err == nil {
+		t.Error("Expected an ambiguous name to fail")
+	}
+	if _, err := registry.find("missing"); err == nil {
+		t.Error("Expected an unknown project to fail")
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, configFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{root, sub} {
+		if got, found := findProjectRoot(dir); !found || got != root {
+			t.Errorf("findProjectRoot(%s) = %s, %v; want %s", dir, got, found, root)
+		}
+	}
+
+	// A .gtw directory also marks the root
+	other := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(other, ".gtw", "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, found := findProjectRoot(filepath.Join(other, ".gtw", "logs")); !found || got != other {
+		t.Errorf("Expected %s to be found through .gtw, got %s, %v", other, got, found)
+	}
+}
+
+func TestEnterProjectRootFromOutsideWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	worktree := filepath.Join(t.TempDir(), "issue-1") // worktree_prefix outside the repository
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"worktree", "add", "-q", "-b", "issue-1", worktree},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, configFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(worktree, "src")
+	os.Mkdir(sub, 0755)
+
+	old := invocationDir
+	defer func() { invocationDir = old }()
+	for _, dir := range []string{worktree, sub} {
+		t.Chdir(dir)
+		if err := enterProjectRoot(); err != nil {
+			t.Fatal(err)
+		}
+		if cwd, _ := os.Getwd(); cwd != root {
+			t.Errorf("From %s: expected to enter %s, got %s", dir, root, cwd)
+		}
+		config := &Config{ProjectPath: root, Workers: []Worker{{ID: "issue-1", WorktreePath: worktree}}}
+		if findCurrentWorker(config, "", "", invocationDir) != 0 {
+			t.Errorf("From %s: expected the worker to be found", dir)
+		}
+	}
+
+	// A repository without a gtw config still falls back to its top level
+	plain := t.TempDir()
+	if output, err := exec.Command("git", "-C", plain, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	os.Mkdir(filepath.Join(plain, "src"), 0755)
+	t.Chdir(filepath.Join(plain, "src"))
+	if err := enterProjectRoot(); err != nil {
+		t.Fatal(err)
+	}
+	if cwd, _ := os.Getwd(); cwd != plain {
+		t.Errorf("Expected to enter %s, got %s", plain, cwd)
+	}
+}
+
+func TestUserPath(t *testing.T) {
+	old := invocationDir
+	defer func() { invocationDir = old }()
+
+	invocationDir = filepath.FromSlash("/repo/src")
+	if got := userPath("tasks.txt"); got != filepath.FromSlash("/repo/src/tasks.txt") {
+		t.Errorf("Expected a path relative to the invocation directory, got %s", got)
+	}
+	abs := filepath.FromSlash("/tmp/tasks.txt")
+	if got := userPath(abs); got != abs {
+		t.Errorf("Expected absolute paths unchanged, got %s", got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// pasteSubmitDelay separates the paste from the Enter that submits it;
+// otherwise some TUIs take the Enter as part of the pasted text.
+const pasteSubmitDelay = 200 * time.Millisecond
+
+// PromptOptions holds the settings given to `gtw prompt`.
+type PromptOptions struct {
+	File    string // Read the prompt from this file ("-" for stdin)
+	NoEnter bool   // Paste without submitting
+	Wait    bool   // Wait for the worker's agent to be ready first
+}
+
+func init() {
+	var opts PromptOptions
+	promptCmd := &cobra.Command{
+		Use:   "prompt <worker-id> [text]",
+		Short: "Paste a (multi-line) prompt into a worker's pane and submit it",
+		Long: `Paste a prompt into the worker's pane as one block and press Enter. The text
+is loaded into a tmux buffer and pasted with bracketed paste, so newlines in
+it do not submit the lines one by one the way 'send-keys' does.
+
+The prompt is the text argument, the --file, or standard input when neither
+is given. With --wait, gtw first waits until the worker's agent (or
+ready_pattern) is ready.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			text := ""
+			if len(args) == 2 {
+				text = args[1]
+			}
+			if !promptWorker(args[0], text, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	promptCmd.Flags().StringVarP(&opts.File, "file", "f", "", "Read the prompt from this file (- for standard input)")
+	promptCmd.Flags().BoolVar(&opts.NoEnter, "no-enter", false, "Paste the prompt without pressing Enter")
+	promptCmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait until the worker's agent is ready before pasting")
+	rootCmd.AddCommand(promptCmd)
+}
+
+// readPrompt returns the prompt from the argument, the file or stdin.
+func readPrompt(text, file string, stdin io.Reader) (string, error) {
+	if text != "" && file != "" {
+		return "", errors.New("give the prompt either as an argument or with --file, not both")
+	}
+	if text == "" {
+		var data []byte
+		var err error
+		if file == "" || file == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return "", err
+		}
+		text = string(data)
+	}
+	// Files end with a newline, which would submit the prompt early
+	text = strings.TrimRight(text, "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("the prompt is empty")
+	}
+	return text, nil
+}
+
+// pastePrompt pastes text into the pane and, with enter, submits it.
+// Backends without Paster can only type single-line prompts.
+func pastePrompt(paneID, text string, enter bool) error {
+	paster, ok := mux.(Paster)
+	if !ok {
+		if strings.Contains(text, "\n") {
+			return fmt.Errorf("multi-line prompts need tmux (current: %s)", mux.Name())
+		}
+		if !enter {
+			return fmt.Errorf("--no-enter needs tmux (current: %s)", mux.Name())
+		}
+		return sendWithRetry(paneID, text)
+	}
+	if err := paster.Paste(paneID, text); err != nil {
+		return err
+	}
+	if !enter {
+		return nil
+	}
+	time.Sleep(pasteSubmitDelay)
+	return tmuxCommand("send-keys", "-t", paneID, "Enter").Run()
+}
+
+func promptWorker(id, text string, opts PromptOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Error: Worker '%s' not found\n", id)
+		return false
+	}
+	worker := config.Workers[index]
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: The pane of worker '%s' is gone (see 'gtw repair')\n", id)
+		return false
+	}
+
+	prompt, err := readPrompt(text, opts.File, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	if opts.Wait {
+		agent, err := workerAgent(config, worker)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if agent == nil {
+			agent = replAgent{}
+		}
+		if err := waitForAgent(worker.PaneID, agent, promptReadyTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	}
+
+	if err := pastePrompt(worker.PaneID, prompt, !opts.NoEnter); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not send the prompt: %v\n", err)
+		return false
+	}
+	lines := strings.Count(prompt, "\n") + 1
+	detail, _, _ := strings.Cut(prompt, "\n")
+	if lines > 1 {
+		detail += fmt.Sprintf(" (+%d lines)", lines-1)
+	}
+	recordEvent(EventPromptSent, id, detail)
+	fmt.Printf("✅ Sent a %d-line prompt to worker '%s'\n", lines, id)
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPrompt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prompt.md")
+	if err := os.WriteFile(file, []byte("Fix the bug.\n\n- add a test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		file  string
+		stdin string
+		want  string
+	}{
+		{"argument", "hello", "", "", "hello"},
+		{"file without trailing newline", "", file, "", "Fix the bug.\n\n- add a test"},
+		{"stdin", "", "", "line 1\nline 2\n\n", "line 1\nline 2"},
+		{"stdin dash", "", "-", "from stdin\r\n", "from stdin"},
+	}
+	for _, tt := range tests {
+		got, err := readPrompt(tt.text, tt.file, strings.NewReader(tt.stdin))
+		if err != nil {
+			t.Errorf("%s: readPrompt returned error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: readPrompt = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := readPrompt("", "", strings.NewReader(" \n\n")); err == nil {
+		t.Error("Expected an error for an empty prompt")
+	}
+	if _, err := readPrompt("text", file, strings.NewReader("")); err == nil {
+		t.Error("Expected an error for both an argument and --file")
+	}
+	if _, err := readPrompt("", filepath.Join(t.TempDir(), "missing"), strings.NewReader("")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	readinessDialTimeout = 500 * time.Millisecond
+	readinessLines       = 200 // Pane lines searched by output checks
+)
+
+// ReadinessCheck is a condition a worker of a profile meets once it is
+// actually usable, e.g. its dev server accepts connections. Exactly one
+// field is set; a worker is ready when all checks of its profile pass.
+type ReadinessCheck struct {
+	Port   int    `json:"port,omitempty"`   // TCP port on localhost accepts connections
+	File   string `json:"file,omitempty"`   // File exists, relative to the worktree
+	Output string `json:"output,omitempty"` // Regexp matching the pane's recent output
+}
+
+func (c ReadinessCheck) String() string {
+	switch {
+	case c.Port != 0:
+		return fmt.Sprintf("port %d", c.Port)
+	case c.File != "":
+		return "file " + c.File
+	default:
+		return fmt.Sprintf("output %q", c.Output)
+	}
+}
+
+func (c ReadinessCheck) validate() error {
+	set := 0
+	for _, ok := range []bool{c.Port != 0, c.File != "", c.Output != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("a readiness check needs exactly one of port, file or output")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+	if _, err := regexp.Compile(c.Output); err != nil {
+		return fmt.Errorf("invalid output regexp: %v", err)
+	}
+	return nil
+}
+
+// readinessFor returns the readiness checks of the worker's profile.
+func (c *Config) readinessFor(worker Worker) []ReadinessCheck {
+	if profile, ok := c.Profiles[worker.Profile]; ok && worker.Profile != "" {
+		return profile.Readiness
+	}
+	return nil
+}
+
+// passes runs the check against the worker's worktree and pane.
+func (c ReadinessCheck) passes(worker Worker) bool {
+	switch {
+	case c.Port != 0:
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(c.Port)), readinessDialTimeout)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	case c.File != "":
+		_, err := os.Stat(filepath.Join(worker.WorktreePath, c.File))
+		return err == nil
+	default:
+		pattern, err := regexp.Compile(c.Output)
+		if err != nil {
+			return false
+		}
+		output, err := capturePane(worker.PaneID, readinessLines)
+		return err == nil && pattern.MatchString(output)
+	}
+}
+
+// checkReadiness reports whether every check passes, and otherwise the
+// first one that does not.
+func checkReadiness(worker Worker, checks []ReadinessCheck) (bool, *ReadinessCheck) {
+	for i := range checks {
+		if !checks[i].passes(worker) {
+			return false, &checks[i]
+		}
+	}
+	return true, nil
+}
+
+// readinessLabel describes the readiness of a worker for list: "-" without
+// checks, else "yes" or the check it waits for.
+func readinessLabel(config *Config, worker Worker) string {
+	checks := config.readinessFor(worker)
+	if len(checks) == 0 {
+		return "-"
+	}
+	if ok, pending := checkReadiness(worker, checks); !ok {
+		return "waiting: " + pending.String()
+	}
+	return "yes"
+}
+
+// waitForReadiness polls the worker's readiness checks until they all pass
+// or the timeout expires (0 means no limit).
+func waitForReadiness(config *Config, worker Worker, timeout, interval time.Duration) error {
+	checks := config.readinessFor(worker)
+	started := time.Now()
+	for {
+		ok, pending := checkReadiness(worker, checks)
+		if ok {
+			return nil
+		}
+		if !mux.PaneExists(worker.PaneID) {
+			return fmt.Errorf("pane %s of worker '%s' is gone", worker.PaneID, worker.ID)
+		}
+		if timeout > 0 && time.Since(started) >= timeout {
+			return fmt.Errorf("worker '%s' was not ready after %s (waiting for %s)", worker.ID, timeout, pending)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadinessCheckValidate(t *testing.T) {
+	tests := []struct {
+		check ReadinessCheck
+		valid bool
+	}{
+		{ReadinessCheck{Port: 3000}, true},
+		{ReadinessCheck{File: "dist/index.html"}, true},
+		{ReadinessCheck{Output: `listening on :\d+`}, true},
+		{ReadinessCheck{}, false},
+		{ReadinessCheck{Port: 3000, File: "x"}, false},
+		{ReadinessCheck{Port: 70000}, false},
+		{ReadinessCheck{Output: "("}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.check.validate(); (err == nil) != tt.valid {
+			t.Errorf("validate(%+v) = %v, want valid %v", tt.check, err, tt.valid)
+		}
+	}
+}
+
+func TestCheckReadiness(t *testing.T) {
+	fake := &captureMultiplexer{output: map[string]string{"%1": "compiling..."}}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	dir := t.TempDir()
+	worker := Worker{ID: "web", PaneID: "%1", WorktreePath: dir, Profile: "dev"}
+	config := &Config{Profiles: map[string]Profile{"dev": {Readiness: []ReadinessCheck{
+		{Port: port},
+		{File: ".next/BUILD_ID"},
+		{Output: `ready in \d+ms`},
+	}}}}
+	checks := config.readinessFor(worker)
+
+	if ok, pending := checkReadiness(worker, checks); ok || pending.File == "" {
+		t.Errorf("Expected to wait for the file, got %v %v", ok, pending)
+	}
+	os.MkdirAll(filepath.Join(dir, ".next"), 0755)
+	os.WriteFile(filepath.Join(dir, ".next", "BUILD_ID"), []byte("1"), 0644)
+	if got := readinessLabel(config, worker); got != `waiting: output "ready in \\d+ms"` {
+		t.Errorf("Unexpected label %q", got)
+	}
+	fake.output["%1"] = "compiling...\nready in 420ms"
+	if got := readinessLabel(config, worker); got != "yes" {
+		t.Errorf("Expected ready, got %q", got)
+	}
+
+	listener.Close()
+	if ok, pending := checkReadiness(worker, checks); ok || pending.Port != port {
+		t.Errorf("Expected a closed port to fail, got %v %v", ok, pending)
+	}
+
+	if got := readinessLabel(config, Worker{ID: "plain"}); got != "-" {
+		t.Errorf("Expected - without checks, got %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	readyTimeout      = 15 * time.Second
+	readyPollInterval = 200 * time.Millisecond
+	readyQuiet        = time.Second // Shell rc files may pause while printing
+	sendRetryDelay    = 500 * time.Millisecond
+)
+
+// paneReady reports whether a new pane's shell has started and drawn its
+// prompt: the foreground command is a shell (backends that cannot tell
+// report "") and the output has not changed for readyQuiet.
+func paneReady(command, output string, unchangedFor time.Duration) bool {
+	if command != "" && !isShellCommand(command) {
+		return false
+	}
+	return strings.TrimSpace(output) != "" && unchangedFor >= readyQuiet
+}
+
+// worktreeReady reports whether the checkout of dir has completed, i.e. the
+// worktree is registered and git no longer holds its index lock.
+func worktreeReady(dir string) bool {
+	output, err := gitCommand("-C", dir, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(strings.TrimSpace(string(output)), "index.lock"))
+	return os.IsNotExist(err)
+}
+
+// waitForReady polls until the pane's shell and the worktree are ready or
+// the timeout expires.
+func waitForReady(paneID, worktreePath string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	previous := ""
+	changedAt := time.Now()
+	for {
+		command, err := paneCurrentCommand(paneID)
+		if err != nil {
+			return err
+		}
+		output, err := capturePane(paneID, 50)
+		if err != nil {
+			return err
+		}
+		if output != previous {
+			previous = output
+			changedAt = time.Now()
+		}
+		if paneReady(command, output, time.Since(changedAt)) && worktreeReady(worktreePath) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pane %s was not ready after %s", paneID, timeout)
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// sendWithRetry sends text to the pane, retrying once after a short delay.
+func sendWithRetry(paneID, text string) error {
+	err := mux.SendKeys(paneID, text)
+	if err == nil {
+		return nil
+	}
+	time.Sleep(sendRetryDelay)
+	return mux.SendKeys(paneID, text)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaneReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		output    string
+		unchanged time.Duration
+		want      bool
+	}{
+		{"prompt drawn and stable", "bash", "user@host:~$ ", readyQuiet, true},
+		{"still drawing", "zsh", "user@host:~$ ", readyQuiet / 2, false},
+		{"blank pane", "bash", "\n\n", readyQuiet, false},
+		{"rc file running a program", "direnv", "loading", readyQuiet, false},
+		{"backend cannot tell the command", "", "$ ", readyQuiet, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paneReady(tt.command, tt.output, tt.unchanged); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RebaseOptions holds the settings given to `gtw rebase`.
+type RebaseOptions struct {
+	All       bool
+	Tags      []string
+	Onto      string // Rebase onto this ref instead of each worker's base
+	Autostash bool   // Stash uncommitted changes around the rebase instead of skipping
+	NoFetch   bool
+}
+
+// Outcomes of rebasing a worker
+const (
+	RebaseDone     = "rebased"
+	RebaseUpToDate = "up to date"
+	RebaseConflict = "conflict"
+	RebaseSkipped  = "skipped"
+	RebaseFailed   = "failed"
+)
+
+// RebaseResult is the outcome of rebasing one worker.
+type RebaseResult struct {
+	Worker  string
+	Onto    string
+	Outcome string
+	Detail  string
+}
+
+func init() {
+	var opts RebaseOptions
+	rebaseCmd := &cobra.Command{
+		Use:   "rebase [worker-id...]",
+		Short: "Fetch and rebase workers' branches onto their base",
+		Long: `Fetch from the remotes, then rebase the branch of each selected worker in its
+worktree onto --onto, or else the worker's base (--base when it was added,
+otherwise the project's current branch).
+
+Workers with uncommitted changes are skipped unless --autostash is given. A
+rebase that conflicts is aborted, leaving the worker as it was, and
+reported; gtw exits with status 1 if any worker conflicted or failed.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !rebaseWorkers(args, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	rebaseCmd.Flags().BoolVar(&opts.All, "all", false, "Rebase every worker")
+	rebaseCmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Rebase workers with this tag (repeatable, all must match)")
+	rebaseCmd.Flags().StringVar(&opts.Onto, "onto", "", "Rebase onto this ref (e.g. origin/main) instead of each worker's base")
+	rebaseCmd.Flags().BoolVar(&opts.Autostash, "autostash", false, "Stash uncommitted changes before rebasing and restore them after")
+	rebaseCmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "Do not fetch before rebasing")
+	rootCmd.AddCommand(rebaseCmd)
+}
+
+// isDirty reports whether the worktree has uncommitted changes.
+func isDirty(worktreePath string) (bool, error) {
+	output, err := gitCommand("-C", worktreePath, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// rebaseWorker rebases the worker's branch onto onto in its worktree.
+func rebaseWorker(worker Worker, onto string, autostash bool) RebaseResult {
+	result := RebaseResult{Worker: worker.ID, Onto: onto}
+	if _, err := os.Stat(worker.WorktreePath); err != nil {
+		result.Outcome, result.Detail = RebaseSkipped, "worktree is missing"
+		return result
+	}
+	if op := operationInProgress(worker.WorktreePath); op != "" {
+		result.Outcome, result.Detail = RebaseSkipped, op+" in progress"
+		return result
+	}
+	dirty, err := isDirty(worker.WorktreePath)
+	if err != nil {
+		result.Outcome, result.Detail = RebaseFailed, fmt.Sprintf("git status failed: %v", err)
+		return result
+	}
+	if dirty && !autostash {
+		result.Outcome, result.Detail = RebaseSkipped, "uncommitted changes (use --autostash)"
+		return result
+	}
+
+	before, _ := gitCommand("-C", worker.WorktreePath, "rev-parse", "HEAD").Output()
+	args := []string{"-C", worker.WorktreePath, "rebase"}
+	if autostash {
+		args = append(args, "--autostash")
+	}
+	cmd := gitCommand(append(args, onto)...)
+	cmd.Timeout = 0 // Hooks and long histories take a while
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if operationInProgress(worker.WorktreePath) != "" {
+			// Leave the worker as it was; the conflicting files are listed
+			files, _ := gitCommand("-C", worker.WorktreePath, "diff", "--name-only", "--diff-filter=U").Output()
+			gitCommand("-C", worker.WorktreePath, "rebase", "--abort").Run()
+			result.Outcome, result.Detail = RebaseConflict, strings.Join(strings.Fields(string(files)), ", ")
+			return result
+		}
+		result.Outcome, result.Detail = RebaseFailed, strings.TrimSpace(lastLines(string(output), 1))
+		return result
+	}
+
+	after, _ := gitCommand("-C", worker.WorktreePath, "rev-parse", "HEAD").Output()
+	if string(before) == string(after) {
+		result.Outcome = RebaseUpToDate
+	} else {
+		result.Outcome = RebaseDone
+	}
+	return result
+}
+
+func rebaseWorkers(ids []string, opts RebaseOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	workers, err := selectWorkers(config, ids, opts.All, opts.Tags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if len(workers) == 0 {
+		fmt.Fprintln(os.Stderr, "No matching workers found")
+		return false
+	}
+
+	if !opts.NoFetch {
+		fmt.Println("Fetching...")
+		fetch := gitCommand("fetch", "--all", "--quiet")
+		fetch.Stdout, fetch.Stderr = os.Stdout, os.Stderr
+		fetch.Timeout = 0
+		if err := fetch.Run(); err != nil {
+			fmt.Printf("Warning: git fetch failed: %v\n", err)
+		}
+	}
+
+	ok := true
+	var results []RebaseResult
+	for _, worker := range workers {
+		onto := opts.Onto
+		if onto == "" {
+			onto = workerBase(worker)
+		}
+		fmt.Printf("🔧 Rebasing '%s' onto %s...\n", worker.ID, onto)
+		result := rebaseWorker(worker, onto, opts.Autostash)
+		if result.Outcome == RebaseConflict || result.Outcome == RebaseFailed {
+			ok = false
+		}
+		results = append(results, result)
+	}
+
+	fmt.Println()
+	fmt.Println(colorize(fmt.Sprintf("%-20s %-25s %-12s %s", "WORKER", "ONTO", "RESULT", "DETAIL"), "header"))
+	fmt.Println(colorize(strings.Repeat("-", 80), "separator"))
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Outcome]++
+		detail := result.Detail
+		if result.Outcome == RebaseConflict {
+			detail = "aborted; conflicts in " + detail
+		}
+		fmt.Printf("%-20s %-25s %s %s\n", result.Worker, result.Onto, colorize(fmt.Sprintf("%-12s", result.Outcome), rebaseColorKey(result.Outcome)), detail)
+	}
+	fmt.Println()
+	fmt.Printf("%d rebased, %d up to date, %d conflict(s), %d skipped, %d failed\n",
+		counts[RebaseDone], counts[RebaseUpToDate], counts[RebaseConflict], counts[RebaseSkipped], counts[RebaseFailed])
+	return ok
+}
+
+// rebaseColorKey maps an outcome to a theme key.
+func rebaseColorKey(outcome string) string {
+	switch outcome {
+	case RebaseDone, RebaseUpToDate:
+		return "pass"
+	case RebaseConflict, RebaseFailed:
+		return "fail"
+	}
+	return "error"
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRebaseWorker(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(wt, file, content string) {
+		os.WriteFile(filepath.Join(wt, file), []byte(content), 0644)
+		git("-C", wt, "add", file)
+		git("-C", wt, "commit", "-q", "-m", file)
+	}
+	git("init", "-q", "-b", "main")
+	commit(".", "shared", "base")
+	for _, id := range []string{"clean", "dirty", "conflict", "current"} {
+		git("worktree", "add", "-q", "-b", id, filepath.Join(dir, "wt-"+id))
+	}
+	commit(filepath.Join(dir, "wt-clean"), "clean", "work")
+	commit(filepath.Join(dir, "wt-dirty"), "dirty", "work")
+	commit(filepath.Join(dir, "wt-conflict"), "shared", "theirs")
+	commit(".", "shared", "ours")
+	os.WriteFile(filepath.Join(dir, "wt-dirty", "dirty"), []byte("changed"), 0644)
+
+	worker := func(id string) Worker {
+		return Worker{ID: id, WorktreePath: filepath.Join(dir, "wt-"+id)}
+	}
+	tests := []struct {
+		id        string
+		autostash bool
+		want      string
+	}{
+		{"clean", false, RebaseDone},
+		{"dirty", false, RebaseSkipped},
+		{"dirty", true, RebaseDone},
+		{"conflict", false, RebaseConflict},
+		{"clean", false, RebaseUpToDate},
+		{"missing", false, RebaseSkipped},
+	}
+	for _, tt := range tests {
+		result := rebaseWorker(worker(tt.id), "main", tt.autostash)
+		if result.Outcome != tt.want {
+			t.Errorf("rebaseWorker(%s, autostash=%v) = %s (%s), want %s", tt.id, tt.autostash, result.Outcome, result.Detail, tt.want)
+		}
+	}
+
+	// The conflicting rebase was aborted and the stashed changes restored
+	if op := operationInProgress(filepath.Join(dir, "wt-conflict")); op != "" {
+		t.Errorf("Expected the conflicting rebase to be aborted, found %s", op)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "wt-dirty", "dirty")); string(data) != "changed" {
+		t.Errorf("Expected uncommitted changes to survive --autostash, got %q", data)
+	}
+}
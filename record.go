@@ -0,0 +1,623 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	recordingDir      = ".gtw/recordings"
+	castVersion       = 2
+	castTimeFormat    = "20060102-150405"
+	recordLogOption   = "@gtw-record-log" // Pane option set while recording; its value is the worker log also written, or "-"
+	defaultCastWidth  = 80
+	defaultCastHeight = 24
+	defaultIdleLimit  = 2 * time.Second
+)
+
+// CastHeader is the first line of an asciicast v2 file.
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastEvent is one line after the header: [seconds, "o", data].
+type CastEvent struct {
+	Time float64
+	Type string
+	Data string
+}
+
+func (e CastEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+func (e *CastEvent) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, expected 3", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// ExportCastOptions holds the settings given to `gtw export-cast`.
+type ExportCastOptions struct {
+	Recording string        // File name of the recording (default: the latest)
+	Output    string        // Output file, "-" for stdout
+	IdleLimit time.Duration // Longest pause kept in the export (0 keeps them all)
+}
+
+func init() {
+	recordCmd := &cobra.Command{
+		Use:   "record <worker-id>",
+		Short: "Record a worker's pane as an asciicast for replay",
+		Long: `Start recording a worker's pane with its timing, as an asciicast v2 file
+in .gtw/recordings/<worker-id>/ that asciinema can play. Recording uses tmux
+pipe-pane; if the worker's output is being logged, the log keeps being
+written while recording. Stop with 'gtw record stop <worker-id>'; recordings are kept when the worker
+is removed. Use 'gtw export-cast' to share one.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !startRecording(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop <worker-id>",
+		Short: "Stop recording a worker",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !stopRecording(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list [worker-id]",
+		Short: "List the recordings, of all workers or one",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) == 1 {
+				id = args[0]
+			}
+			listRecordings(id)
+		},
+	}
+
+	// Runs inside tmux pipe-pane with the pane output on stdin
+	var width, height int
+	var title, logFile string
+	pipeCmd := &cobra.Command{
+		Use:              "pipe <file>",
+		Hidden:           true,
+		Args:             cobra.ExactArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			var input io.Reader = os.Stdin
+			if logFile != "" {
+				log := &rotatingWriter{path: logFile, maxSize: logMaxSize, keep: logKeep}
+				defer log.Close()
+				input = io.TeeReader(input, log)
+			}
+			if err := recordPipe(args[0], input, CastHeader{Width: width, Height: height, Title: title}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	pipeCmd.Flags().IntVar(&width, "width", defaultCastWidth, "Terminal width")
+	pipeCmd.Flags().IntVar(&height, "height", defaultCastHeight, "Terminal height")
+	pipeCmd.Flags().StringVar(&title, "title", "", "Title of the recording")
+	pipeCmd.Flags().StringVar(&logFile, "log", "", "Also append the output to this worker log")
+
+	recordCmd.AddCommand(stopCmd, listCmd, pipeCmd)
+	rootCmd.AddCommand(recordCmd)
+
+	var opts ExportCastOptions
+	exportCmd := &cobra.Command{
+		Use:   "export-cast <worker-id>",
+		Short: "Export a finished recording of a worker as a shareable asciicast",
+		Long: `Write a recording made with 'gtw record' (the latest one unless --recording
+is given) to a standalone asciicast file, shortening pauses longer than
+--idle-limit so that hours of agent work replay quickly. Works for workers
+that have been removed. Play it with 'asciinema play <file>'.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !exportCast(args[0], opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	exportCmd.Flags().StringVar(&opts.Recording, "recording", "", "Recording to export, as listed by 'gtw record list' (default: the latest)")
+	exportCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Output file, '-' for stdout (default: <worker-id>-<time>.cast)")
+	exportCmd.Flags().DurationVar(&opts.IdleLimit, "idle-limit", defaultIdleLimit, "Shorten pauses to at most this long (0 keeps them)")
+	rootCmd.AddCommand(exportCmd)
+}
+
+// castWriter turns a byte stream into asciicast output events, timed from
+// when it was created. Multi-byte characters split across writes are held
+// back until they are complete.
+type castWriter struct {
+	w       io.Writer
+	start   time.Time
+	now     func() time.Time
+	pending []byte
+}
+
+func newCastWriter(w io.Writer, header CastHeader, now func() time.Time) (*castWriter, error) {
+	start := now()
+	header.Version = castVersion
+	header.Timestamp = start.Unix()
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return &castWriter{w: w, start: start, now: now}, nil
+}
+
+func (c *castWriter) Write(p []byte) (int, error) {
+	data := append(c.pending, p...)
+	complete := len(data) - incompleteRuneSuffix(data)
+	c.pending = append([]byte{}, data[complete:]...)
+	if complete == 0 {
+		return len(p), nil
+	}
+	if err := c.emit(string(data[:complete])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *castWriter) emit(text string) error {
+	elapsed := c.now().Sub(c.start).Seconds()
+	line, err := json.Marshal(CastEvent{Time: float64(int64(elapsed*1e6)) / 1e6, Type: "o", Data: text})
+	if err != nil {
+		return err
+	}
+	_, err = c.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close writes what is left of an incomplete character.
+func (c *castWriter) Close() error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	text := string(c.pending)
+	c.pending = nil
+	return c.emit(text)
+}
+
+// incompleteRuneSuffix returns how many bytes at the end of data start a
+// UTF-8 character that is not complete yet.
+func incompleteRuneSuffix(data []byte) int {
+	for n := 1; n <= utf8.UTFMax-1 && n <= len(data); n++ {
+		b := data[len(data)-n]
+		if b < utf8.RuneSelf {
+			return 0
+		}
+		if utf8.RuneStart(b) {
+			if !utf8.FullRune(data[len(data)-n:]) {
+				return n
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// recordPipe writes the stream on r to a new asciicast file.
+func recordPipe(path string, r io.Reader, header CastHeader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	cast, err := newCastWriter(file, header, time.Now)
+	if err != nil {
+		return err
+	}
+	defer cast.Close()
+	_, err = io.Copy(cast, r)
+	return err
+}
+
+// workerRecordingDir returns where the recordings of a worker are kept.
+func workerRecordingDir(config *Config, id string) string {
+	dir := config.ProjectPath
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return filepath.Join(dir, recordingDir, id)
+}
+
+// paneSize returns the width and height of a tmux pane.
+func paneSize(paneID string) (int, int, error) {
+	output, err := tmuxCommand("display-message", "-t", paneID, "-p", "#{pane_width} #{pane_height}").Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected pane size %q", strings.TrimSpace(string(output)))
+	}
+	width, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	height, err := strconv.Atoi(fields[1])
+	return width, height, err
+}
+
+func startRecording(id string) bool {
+	if !requireTmux("record") {
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Worker '%s' not found\n", id)
+		return false
+	}
+	worker := config.Workers[index]
+	if !mux.PaneExists(worker.PaneID) {
+		fmt.Fprintf(os.Stderr, "Error: Pane %s of worker '%s' not found\n", worker.PaneID, id)
+		return false
+	}
+	if recordingLog(worker.PaneID) != "" {
+		fmt.Fprintf(os.Stderr, "Worker '%s' is already being recorded\n", id)
+		return false
+	}
+
+	// A pane has a single pipe: take over the worker log if there is one
+	logFile := ""
+	if paneLogging(worker.PaneID) {
+		logFile = workerLogPath(config, id)
+		if err := stopPaneLog(worker.PaneID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping the log pipe: %v\n", err)
+			return false
+		}
+	}
+
+	width, height, err := paneSize(worker.PaneID)
+	if err != nil {
+		width, height = defaultCastWidth, defaultCastHeight
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	path := filepath.Join(workerRecordingDir(config, id), time.Now().Format(castTimeFormat)+".cast")
+	title := fmt.Sprintf("gtw %s (%s)", id, worker.branchName())
+	command := fmt.Sprintf("exec %s record pipe --width %d --height %d --title %s",
+		shellQuote(exe), width, height, shellQuote(title))
+	marker := "-"
+	if logFile != "" {
+		command += " --log " + shellQuote(logFile)
+		marker = logFile
+	}
+	command += " " + shellQuote(path)
+	if err := tmuxCommand("pipe-pane", "-o", "-t", worker.PaneID, command).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting the recording: %v\n", err)
+		if logFile != "" {
+			startPaneLog(config, id, worker.PaneID)
+		}
+		return false
+	}
+	tmuxCommand("set-option", "-p", "-t", worker.PaneID, recordLogOption, marker).Run()
+	fmt.Printf("✅ Recording worker '%s' to %s\n", id, path)
+	fmt.Printf("Stop with 'gtw record stop %s'\n", id)
+	return true
+}
+
+func stopRecording(id string) bool {
+	if !requireTmux("record") {
+		return false
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Worker '%s' not found\n", id)
+		return false
+	}
+	paneID := config.Workers[index].PaneID
+	logFile := recordingLog(paneID)
+	if logFile == "" {
+		fmt.Fprintf(os.Stderr, "Worker '%s' is not being recorded\n", id)
+		return false
+	}
+	if err := stopPaneLog(paneID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error stopping the recording: %v\n", err)
+		return false
+	}
+	tmuxCommand("set-option", "-p", "-u", "-t", paneID, recordLogOption).Run()
+	fmt.Printf("✅ Stopped recording worker '%s'\n", id)
+
+	// Hand the pane back to the worker log the recording was writing
+	if logFile != "-" {
+		if err := startPaneLog(config, id, paneID); err != nil {
+			fmt.Printf("Warning: Could not resume the worker log: %v\n", err)
+		}
+	}
+	return true
+}
+
+// recordingLog returns "" when the pane is not being recorded, else the log
+// file the recording also writes, or "-" for none.
+func recordingLog(paneID string) string {
+	if !paneLogging(paneID) {
+		return ""
+	}
+	output, err := tmuxCommand("show-options", "-p", "-v", "-t", paneID, recordLogOption).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Recording is a cast file of a worker.
+type Recording struct {
+	Worker string
+	Name   string // File name
+	Path   string
+	Size   int64
+}
+
+// findRecordings returns the recordings of the worker id, or of every
+// worker when id is "", oldest first.
+func findRecordings(config *Config, id string) ([]Recording, error) {
+	root := filepath.Dir(workerRecordingDir(config, "x"))
+	var workers []string
+	if id != "" {
+		workers = []string{id}
+	} else {
+		entries, err := os.ReadDir(root)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				workers = append(workers, entry.Name())
+			}
+		}
+	}
+
+	var recordings []Recording
+	for _, worker := range workers {
+		entries, err := os.ReadDir(filepath.Join(root, worker))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".cast") {
+				continue
+			}
+			recording := Recording{Worker: worker, Name: entry.Name(), Path: filepath.Join(root, worker, entry.Name())}
+			if info, err := entry.Info(); err == nil {
+				recording.Size = info.Size()
+			}
+			recordings = append(recordings, recording)
+		}
+	}
+	// Names are timestamps
+	sort.SliceStable(recordings, func(i, j int) bool {
+		if recordings[i].Worker != recordings[j].Worker {
+			return recordings[i].Worker < recordings[j].Worker
+		}
+		return recordings[i].Name < recordings[j].Name
+	})
+	return recordings, nil
+}
+
+func listRecordings(id string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	recordings, err := findRecordings(config, id)
+	if err != nil {
+		fmt.Printf("Error listing recordings: %v\n", err)
+		return
+	}
+	if len(recordings) == 0 {
+		fmt.Println("No recordings found")
+		return
+	}
+	fmt.Printf("%-20s %-22s %s\n", "WORKER", "RECORDING", "SIZE")
+	for _, recording := range recordings {
+		fmt.Printf("%-20s %-22s %s\n", recording.Worker, recording.Name, formatBytes(recording.Size))
+	}
+}
+
+// readCast parses an asciicast v2 file. A last line cut short by a
+// recording that was killed is dropped.
+func readCast(r io.Reader) (CastHeader, []CastEvent, error) {
+	var header CastHeader
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, nil, err
+		}
+		return header, nil, fmt.Errorf("empty recording")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, fmt.Errorf("invalid header: %v", err)
+	}
+	if header.Version != castVersion {
+		return header, nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var events []CastEvent
+	var broken error
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		if broken != nil {
+			return header, nil, broken // Only the last line may be cut short
+		}
+		var event CastEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			broken = fmt.Errorf("invalid event: %v", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return header, events, scanner.Err()
+}
+
+// limitIdle shortens the pauses between events to at most limit.
+func limitIdle(events []CastEvent, limit time.Duration) []CastEvent {
+	if limit <= 0 {
+		return events
+	}
+	max := limit.Seconds()
+	limited := make([]CastEvent, len(events))
+	previous, shift := 0.0, 0.0
+	for i, event := range events {
+		if gap := event.Time - previous; gap > max {
+			shift += gap - max
+		}
+		previous = event.Time
+		event.Time = float64(int64((event.Time-shift)*1e6)) / 1e6
+		limited[i] = event
+	}
+	return limited
+}
+
+func writeCast(w io.Writer, header CastHeader, events []CastEvent) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportCast(id string, opts ExportCastOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	recordings, err := findRecordings(config, id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing recordings: %v\n", err)
+		return false
+	}
+	if len(recordings) == 0 {
+		fmt.Fprintf(os.Stderr, "No recordings of worker '%s'; start one with 'gtw record %s'\n", id, id)
+		return false
+	}
+	recording := recordings[len(recordings)-1]
+	if opts.Recording != "" {
+		found := false
+		for _, r := range recordings {
+			if r.Name == opts.Recording || strings.TrimSuffix(r.Name, ".cast") == opts.Recording {
+				recording, found = r, true
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "No recording '%s' of worker '%s' (see 'gtw record list %s')\n", opts.Recording, id, id)
+			return false
+		}
+	}
+
+	file, err := os.Open(recording.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	header, events, err := readCast(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", recording.Path, err)
+		return false
+	}
+	events = limitIdle(events, opts.IdleLimit)
+
+	if opts.Output == "-" {
+		if err := writeCast(os.Stdout, header, events); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		return true
+	}
+	output := userPath(opts.Output)
+	if output == "" {
+		output = id + "-" + recording.Name
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if err := writeCast(out, header, events); err != nil {
+		out.Close()
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return false
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return false
+	}
+	duration := 0.0
+	if len(events) > 0 {
+		duration = events[len(events)-1].Time
+	}
+	fmt.Printf("✅ Exported %s (%s) to %s; play it with 'asciinema play %s'\n",
+		recording.Name, (time.Duration(duration * float64(time.Second))).Round(time.Second), output, output)
+	return true
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCastWriter(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start
+	var buf bytes.Buffer
+	cast, err := newCastWriter(&buf, CastHeader{Width: 120, Height: 40, Title: "api"}, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = start.Add(1500 * time.Millisecond)
+	cast.Write([]byte("hello\r\n"))
+	// "é" split across two writes is emitted once it is complete
+	now = start.Add(2 * time.Second)
+	cast.Write([]byte{'c', 'a', 'f', 0xc3})
+	now = start.Add(3 * time.Second)
+	cast.Write([]byte{0xa9})
+	cast.Close()
+
+	header, events, err := readCast(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 120 || header.Height != 40 || header.Timestamp != start.Unix() || header.Title != "api" {
+		t.Errorf("Unexpected header %+v", header)
+	}
+	want := []CastEvent{{1.5, "o", "hello\r\n"}, {2, "o", "caf"}, {3, "o", "é"}}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want[i], events[i])
+		}
+	}
+}
+
+func TestIncompleteRuneSuffix(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want int
+	}{
+		{[]byte("abc"), 0},
+		{[]byte("é"), 0},
+		{[]byte{'a', 0xc3}, 1},
+		{[]byte{'a', 0xe2, 0x9c}, 2},
+		{[]byte("✅"), 0},
+		{[]byte{0xf0, 0x9f, 0x98}, 3},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := incompleteRuneSuffix(tt.data); got != tt.want {
+			t.Errorf("incompleteRuneSuffix(%v) = %d, expected %d", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestReadCastTruncated(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24}
+[0.5,"o","a"]
+[1.25,"o","b`
+	_, events, err := readCast(strings.NewReader(cast))
+	if err != nil || len(events) != 1 || events[0].Data != "a" {
+		t.Errorf("Expected the cut-short last event to be dropped, got %+v (%v)", events, err)
+	}
+
+	broken := `{"version":2,"width":80,"height":24}
+[0.5,"o"
+[1.25,"o","b"]`
+	if _, _, err := readCast(strings.NewReader(broken)); err == nil {
+		t.Error("Expected an error for a broken event before the last line")
+	}
+	if _, _, err := readCast(strings.NewReader(`{"version":1}`)); err == nil {
+		t.Error("Expected an error for asciicast v1")
+	}
+}
+
+func TestLimitIdle(t *testing.T) {
+	events := []CastEvent{{1, "o", "a"}, {1.5, "o", "b"}, {61.5, "o", "c"}, {62, "o", "d"}}
+	limited := limitIdle(events, 2*time.Second)
+	want := []float64{1, 1.5, 3.5, 4}
+	for i := range want {
+		if limited[i].Time != want[i] || limited[i].Data != events[i].Data {
+			t.Errorf("Event %d: expected time %v, got %+v", i, want[i], limited[i])
+		}
+	}
+	if events[2].Time != 61.5 {
+		t.Error("Expected the original events to be left alone")
+	}
+	if kept := limitIdle(events, 0); kept[2].Time != 61.5 {
+		t.Errorf("Expected a zero limit to keep pauses, got %+v", kept)
+	}
+}
+
+func TestExportCast(t *testing.T) {
+	project := t.TempDir()
+	t.Chdir(project)
+	config := &Config{ProjectPath: project}
+	dir := workerRecordingDir(config, "api")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	older := `{"version":2,"width":80,"height":24}
+[1,"o","old"]
+`
+	latest := `{"version":2,"width":80,"height":24,"title":"api"}
+[1,"o","new"]
+[100,"o","done"]
+`
+	os.WriteFile(filepath.Join(dir, "20260101-090000.cast"), []byte(older), 0644)
+	os.WriteFile(filepath.Join(dir, "20260102-090000.cast"), []byte(latest), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+
+	recordings, err := findRecordings(config, "")
+	if err != nil || len(recordings) != 2 || recordings[1].Name != "20260102-090000.cast" {
+		t.Fatalf("Expected two recordings, oldest first, got %+v (%v)", recordings, err)
+	}
+
+	// Exports work without the worker in the config, e.g. after gtw remove
+	output := filepath.Join(project, "out.cast")
+	if !exportCast("api", ExportCastOptions{Output: output, IdleLimit: 2 * time.Second}) {
+		t.Fatal("Expected the export to succeed")
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, events, err := readCast(bytes.NewReader(data))
+	if err != nil || header.Title != "api" || len(events) != 2 || events[1].Time != 3 {
+		t.Errorf("Expected the latest recording with pauses shortened, got %s (%v)", data, err)
+	}
+
+	if !exportCast("api", ExportCastOptions{Recording: "20260101-090000", Output: output}) {
+		t.Fatal("Expected the export of a named recording to succeed")
+	}
+	if data, _ := os.ReadFile(output); !strings.Contains(string(data), "old") {
+		t.Errorf("Expected the named recording, got %s", data)
+	}
+	if exportCast("api", ExportCastOptions{Recording: "missing", Output: output}) {
+		t.Error("Expected an unknown recording to fail")
+	}
+	if exportCast("web", ExportCastOptions{Output: output}) {
+		t.Error("Expected a worker without recordings to fail")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// agentMultiplexer runs an agent in every live pane.
+type agentMultiplexer struct {
+	*sessionMultiplexer
+}
+
+func (a agentMultiplexer) CurrentCommand(paneID string) (string, error) {
+	if _, err := a.sessionMultiplexer.CurrentCommand(paneID); err != nil {
+		return "", err
+	}
+	return "claude", nil
+}
+
+func TestRemoveWorkerWithRunningAgent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+
+	tests := []struct {
+		name        string
+		backup      string // backup_on_remove
+		opts        RemoveOptions
+		wantRemoved bool
+		wantBackup  bool
+	}{
+		{"refused", "", RemoveOptions{Yes: true}, false, false},
+		{"kill running still asks about changes", "", RemoveOptions{KillRunning: true}, false, false},
+		{"kill running backs up", "", RemoveOptions{KillRunning: true, Yes: true}, true, true},
+		{"force without backup", "", RemoveOptions{Force: true}, true, false},
+		{"force honors backup always", BackupAlways, RemoveOptions{Force: true}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			git := func(args ...string) string {
+				output, err := exec.Command("git", args...).CombinedOutput()
+				if err != nil {
+					t.Fatalf("git %v: %v\n%s", args, err, output)
+				}
+				return strings.TrimSpace(string(output))
+			}
+			git("init", "-q", "-b", "main")
+			git("commit", "-q", "--allow-empty", "-m", "init")
+			git("worktree", "add", "-q", "-b", "issue-1", "worktree/issue-1")
+			worktree := filepath.Join("worktree", "issue-1")
+			os.WriteFile(filepath.Join(worktree, "draft.txt"), []byte("agent output"), 0644)
+
+			config := &Config{ProjectPath: dir, BackupOnRemove: tt.backup, Workers: []Worker{{ID: "issue-1", PaneID: "%1", WorktreePath: worktree}}}
+			if err := saveConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			useMultiplexer(t, agentMultiplexer{newSessionMultiplexer("%1")})
+			previous := nonInteractive
+			nonInteractive = true // Nobody answers the prompts
+			t.Cleanup(func() { nonInteractive = previous })
+
+			removeWorker("issue-1", tt.opts)
+
+			saved, err := loadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed := len(saved.Workers) == 0; removed != tt.wantRemoved {
+				t.Errorf("Expected removed %v, got workers %+v", tt.wantRemoved, saved.Workers)
+			}
+			if backups := git("branch", "--list", "gtw/backup/issue-1/*"); (backups != "") != tt.wantBackup {
+				t.Errorf("Expected a backup branch %v, got %q", tt.wantBackup, backups)
+			}
+		})
+	}
+}
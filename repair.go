@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RepairOptions holds the settings given to `gtw repair`.
+type RepairOptions struct {
+	Only        []string
+	Worker      string
+	Interactive bool
+}
+
+// Categories accepted by `gtw repair --only`
+const (
+	RepairMissingPanes     = "missing-panes"
+	RepairMissingWorktrees = "missing-worktrees"
+	RepairOrphans          = "orphans"
+	RepairStuckStates      = "stuck-states"
+	RepairTrackedWorktrees = "tracked-worktrees"
+)
+
+// Answers of the interactive repair prompt
+const (
+	RepairFix    = "fix"
+	RepairIgnore = "ignore"
+	RepairDelete = "delete"
+)
+
+func init() {
+	var opts RepairOptions
+	repairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Repair worktree/pane inconsistencies",
+		Run:   func(cmd *cobra.Command, args []string) { repairInconsistencies(opts) },
+	}
+	repairCmd.Flags().StringSliceVar(&opts.Only, "only", nil, "Only repair these kinds: missing-panes, missing-worktrees, orphans, stuck-states, tracked-worktrees")
+	repairCmd.Flags().StringVar(&opts.Worker, "worker", "", "Only repair inconsistencies of this worker")
+	repairCmd.Flags().BoolVarP(&opts.Interactive, "interactive", "i", false, "Ask whether to fix, ignore or delete each inconsistency")
+	rootCmd.AddCommand(repairCmd)
+}
+
+// repairCategory returns the --only category of an inconsistency type.
+func repairCategory(t InconsistencyType) string {
+	switch t {
+	case MissingPane:
+		return RepairMissingPanes
+	case MissingWorktree:
+		return RepairMissingWorktrees
+	case StuckState:
+		return RepairStuckStates
+	case TrackedWorktree:
+		return RepairTrackedWorktrees
+	default:
+		return RepairOrphans
+	}
+}
+
+// filterInconsistencies keeps the inconsistencies selected by --only and
+// --worker, ordered so that worktrees exist before panes are created in them.
+func filterInconsistencies(inconsistencies []Inconsistency, only []string, worker string) ([]Inconsistency, error) {
+	categories := make(map[string]bool)
+	for _, category := range only {
+		switch category {
+		case RepairMissingPanes, RepairMissingWorktrees, RepairOrphans, RepairStuckStates, RepairTrackedWorktrees:
+			categories[category] = true
+		default:
+			return nil, fmt.Errorf("unknown kind '%s' (use %s, %s, %s, %s or %s)", category, RepairMissingPanes, RepairMissingWorktrees, RepairOrphans, RepairStuckStates, RepairTrackedWorktrees)
+		}
+	}
+
+	var selected []Inconsistency
+	for _, inc := range inconsistencies {
+		if len(categories) > 0 && !categories[repairCategory(inc.Type)] {
+			continue
+		}
+		if worker != "" && inc.WorkerID != worker {
+			continue
+		}
+		selected = append(selected, inc)
+	}
+
+	order := map[InconsistencyType]int{MissingWorktree: 0, MissingPane: 1, StuckState: 2, OrphanedPane: 3, OrphanedWorktree: 4, PrunableWorktree: 5, TrackedWorktree: 6}
+	sort.SliceStable(selected, func(i, j int) bool { return order[selected[i].Type] < order[selected[j].Type] })
+	return selected, nil
+}
+
+// repairChoices describes what fixing and deleting an inconsistency does.
+func repairChoices(inc Inconsistency) (fix, del string) {
+	switch inc.Type {
+	case MissingPane:
+		return "create a new pane", "remove the worker and its worktree"
+	case MissingWorktree:
+		return "recreate the worktree", "remove the worker and its pane"
+	case StuckState:
+		return "reset its state", "forget the worker, keeping its pane and worktree"
+	case TrackedWorktree:
+		return "untrack the files and exclude the directory", "same as fix; the files are kept"
+	case PrunableWorktree:
+		return "prune the stale entry with 'git worktree prune'", "same as fix"
+	case OrphanedPane:
+		return "add it as a worker", "kill the pane"
+	default:
+		return "add it as a worker with a new pane", "remove the worktree"
+	}
+}
+
+// askRepairAction asks what to do about an inconsistency and defaults to
+// ignoring it.
+func askRepairAction(inc Inconsistency) string {
+	fix, del := repairChoices(inc)
+	fmt.Printf("%s\n  [f]ix (%s), [i]gnore, [d]elete (%s)? [f/I/d]: ", inc.Description, fix, del)
+	var answer string
+	fmt.Scanln(&answer)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "f", "fix":
+		return RepairFix
+	case "d", "delete":
+		return RepairDelete
+	default:
+		return RepairIgnore
+	}
+}
+
+func repairInconsistencies(opts RepairOptions) {
+	if !requireTmux("repair") {
+		return
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return
+	}
+
+	// Check if session exists
+	if !mux.HasSession(sessionName) {
+		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw init' first.\n", sessionName)
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	fmt.Println("Repairing worktree/pane inconsistencies...")
+
+	// Entries of deleted worktrees would block recreating them; locked ones are kept
+	gitCommand("worktree", "prune").Run()
+
+	inconsistencies, err := findInconsistencies(sessionName, config)
+	if err != nil {
+		fmt.Printf("Error listing panes: %v\n", err)
+		return
+	}
+	inconsistencies, err = filterInconsistencies(inconsistencies, opts.Only, opts.Worker)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	repairCount := 0
+	adopted := make(map[string]bool) // Orphaned panes added as workers
+	for _, inc := range inconsistencies {
+		if inc.Type == OrphanedWorktree && adopted[inc.WorkerID] {
+			continue
+		}
+		// The worker may have been deleted by an earlier answer
+		if (inc.Type == MissingPane || inc.Type == MissingWorktree || inc.Type == StuckState) && findWorkerIndex(config, inc.WorkerID) == -1 {
+			continue
+		}
+
+		action := RepairFix
+		if opts.Interactive {
+			action = askRepairAction(inc)
+		}
+		if action == RepairIgnore {
+			continue
+		}
+
+		var ok bool
+		if action == RepairDelete {
+			ok = deleteInconsistency(config, inc)
+		} else {
+			ok = fixInconsistency(config, sessionName, inc)
+		}
+		if ok {
+			recordEvent(EventRepair, inc.WorkerID, action+": "+inc.Description)
+			if inc.Type == OrphanedPane && action == RepairFix {
+				adopted[inc.WorkerID] = true
+			}
+			repairCount++
+		}
+	}
+
+	// Save updated config
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("❌ Error saving config: %v\n", err)
+		return
+	}
+
+	if repairCount == 0 {
+		fmt.Println("✅ No repairs made.")
+	} else {
+		fmt.Printf("✅ Repaired %d inconsistency(ies).\n", repairCount)
+	}
+}
+
+// fixInconsistency brings the worker, pane and worktree of inc back in sync.
+func fixInconsistency(config *Config, sessionName string, inc Inconsistency) bool {
+	switch inc.Type {
+	case MissingWorktree:
+		worker := config.Workers[findWorkerIndex(config, inc.WorkerID)]
+		if inc.Locked {
+			// Most likely on a mount that is not available right now
+			fmt.Printf("⚠️  Not recreating the locked worktree of worker '%s'; make it available again or run 'gtw unlock %s'\n", worker.ID, worker.ID)
+			return false
+		}
+		if inc.Path != "" {
+			// The directory is there but git lost track of it, e.g. after a move
+			fmt.Printf("🔧 Registering the worktree of worker '%s' again...\n", worker.ID)
+			if output, err := gitCommand("worktree", "repair", worker.WorktreePath).CombinedOutput(); err != nil {
+				fmt.Printf("❌ Error repairing worktree: %s\n", strings.TrimSpace(string(output)))
+				fmt.Printf("Move %s away to recreate it, or delete worker '%s'\n", worker.WorktreePath, worker.ID)
+				return false
+			}
+			break
+		}
+		branch := worker.branchName()
+		fmt.Printf("🔧 Adding missing worktree for worker '%s'...\n", worker.ID)
+		if output, err := createWorktree(branch, worker.WorktreePath, worker.Base); err != nil {
+			fmt.Printf("❌ Error creating worktree: %v\n", err)
+			fmt.Printf("Git output: %s\n", string(output))
+			return false
+		}
+		prepareWorktree(config, worker.WorktreePath)
+		applyDependencyCaches(config, worker.Profile, worker.WorktreePath)
+
+	case MissingPane:
+		worker := &config.Workers[findWorkerIndex(config, inc.WorkerID)]
+		fmt.Printf("🔧 Adding missing pane for worker '%s'...\n", worker.ID)
+		paneIndex, paneID, err := mux.NewPane(sessionName, worker.WorktreePath, worker.ID)
+		if err != nil {
+			fmt.Printf("❌ Error creating pane: %v\n", err)
+			return false
+		}
+		worker.TmuxSession = sessionName
+		worker.WindowIndex = paneWindowIndex(paneID)
+		worker.PaneID = paneID
+		worker.PaneIndex = paneIndex
+		worker.setState(StateReady, "pane recreated by gtw repair")
+		labelWorkerPane(config, *worker)
+
+	case StuckState:
+		worker := &config.Workers[findWorkerIndex(config, inc.WorkerID)]
+		state, reason := StateFailed, "stuck "+string(worker.state())+" reset by gtw repair"
+		if mux.PaneExists(worker.PaneID) {
+			state = StateReady
+		}
+		fmt.Printf("🔧 Resetting worker '%s' from %s to %s...\n", worker.ID, worker.state(), state)
+		if err := worker.transition(state, reason); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return false
+		}
+
+	case TrackedWorktree:
+		fmt.Printf("🔧 Untracking '%s/' (the files are kept)...\n", inc.Path)
+		if err := untrackWorktrees(config, inc.Path); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return false
+		}
+		fmt.Println("Commit the removal to take the files out of the project history from now on")
+
+	case PrunableWorktree:
+		if inc.Locked {
+			fmt.Printf("⚠️  Not pruning the locked entry of %s; run 'git worktree unlock %s' first\n", inc.Path, inc.Path)
+			return false
+		}
+		fmt.Printf("🔧 Pruning the stale entry of %s...\n", inc.Path)
+		if output, err := gitCommand("worktree", "prune").CombinedOutput(); err != nil {
+			fmt.Printf("❌ Error pruning worktrees: %s\n", strings.TrimSpace(string(output)))
+			return false
+		}
+
+	case OrphanedPane:
+		fmt.Printf("🔧 Adding orphaned pane %s as worker '%s'...\n", inc.PaneID, inc.WorkerID)
+		paneIndex := 0
+		if output, err := tmuxCommand("display-message", "-t", inc.PaneID, "-p", "#{pane_index}").Output(); err == nil {
+			fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &paneIndex)
+		}
+		config.Workers = append(config.Workers, Worker{
+			ID:           inc.WorkerID,
+			WorktreePath: inc.Path,
+			TmuxSession:  sessionName,
+			WindowIndex:  paneWindowIndex(inc.PaneID),
+			PaneID:       inc.PaneID,
+			PaneIndex:    paneIndex,
+			CreatedAt:    time.Now(),
+			Status:       StateCreating,
+		})
+		config.Workers[len(config.Workers)-1].setState(StateReady, "adopted by gtw repair")
+		labelWorkerPane(config, config.Workers[len(config.Workers)-1])
+
+	case OrphanedWorktree:
+		fmt.Printf("🔧 Adding orphaned worktree '%s' as a worker...\n", inc.WorkerID)
+		paneIndex, paneID, err := mux.NewPane(sessionName, inc.Path, inc.WorkerID)
+		if err != nil {
+			fmt.Printf("❌ Error creating pane: %v\n", err)
+			return false
+		}
+		config.Workers = append(config.Workers, Worker{
+			ID:           inc.WorkerID,
+			WorktreePath: inc.Path,
+			TmuxSession:  sessionName,
+			WindowIndex:  paneWindowIndex(paneID),
+			PaneID:       paneID,
+			PaneIndex:    paneIndex,
+			CreatedAt:    time.Now(),
+			Status:       StateCreating,
+		})
+		config.Workers[len(config.Workers)-1].setState(StateReady, "adopted by gtw repair")
+		labelWorkerPane(config, config.Workers[len(config.Workers)-1])
+	}
+	return true
+}
+
+// deleteInconsistency resolves inc by removing whatever is left over.
+// Worktrees are never force-removed so uncommitted work is kept.
+func deleteInconsistency(config *Config, inc Inconsistency) bool {
+	switch inc.Type {
+	case MissingPane, MissingWorktree:
+		index := findWorkerIndex(config, inc.WorkerID)
+		worker := config.Workers[index]
+		if refuseLocked(worker.ID, worker.WorktreePath) {
+			return false
+		}
+		if inc.Type == MissingPane {
+			fmt.Printf("🔧 Removing worker '%s' and its worktree...\n", worker.ID)
+			if output, err := gitCommand("worktree", "remove", worker.WorktreePath).CombinedOutput(); err != nil {
+				fmt.Printf("❌ Error removing worktree: %s\n", strings.TrimSpace(string(output)))
+				fmt.Printf("Use 'gtw remove %s --force' to discard its changes\n", worker.ID)
+				return false
+			}
+		} else {
+			fmt.Printf("🔧 Removing worker '%s' and its pane...\n", worker.ID)
+			if mux.PaneExists(worker.PaneID) {
+				mux.KillPane(worker.PaneID)
+			}
+		}
+		config.Workers = append(config.Workers[:index], config.Workers[index+1:]...)
+
+	case TrackedWorktree, PrunableWorktree:
+		// Nothing to delete: the worktrees themselves are fine or already gone
+		return fixInconsistency(config, "", inc)
+
+	case StuckState:
+		fmt.Printf("🔧 Forgetting worker '%s'...\n", inc.WorkerID)
+		index := findWorkerIndex(config, inc.WorkerID)
+		config.Workers = append(config.Workers[:index], config.Workers[index+1:]...)
+
+	case OrphanedPane:
+		fmt.Printf("🔧 Killing orphaned pane %s...\n", inc.PaneID)
+		if err := mux.KillPane(inc.PaneID); err != nil {
+			fmt.Printf("❌ Error killing pane: %v\n", err)
+			return false
+		}
+
+	case OrphanedWorktree:
+		if inc.Locked {
+			fmt.Printf("❌ Worktree '%s' is locked; run 'git worktree unlock %s' first\n", inc.WorkerID, inc.Path)
+			return false
+		}
+		fmt.Printf("🔧 Removing orphaned worktree '%s'...\n", inc.WorkerID)
+		if output, err := gitCommand("worktree", "remove", inc.Path).CombinedOutput(); err != nil {
+			fmt.Printf("❌ Error removing worktree: %s\n", strings.TrimSpace(string(output)))
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterInconsistencies(t *testing.T) {
+	inconsistencies := []Inconsistency{
+		{Type: OrphanedWorktree, WorkerID: "keep"},
+		{Type: MissingPane, WorkerID: "w1"},
+		{Type: OrphanedPane, WorkerID: "stray"},
+		{Type: MissingWorktree, WorkerID: "w1"},
+		{Type: MissingPane, WorkerID: "w2"},
+	}
+
+	all, err := filterInconsistencies(inconsistencies, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []InconsistencyType{MissingWorktree, MissingPane, MissingPane, OrphanedPane, OrphanedWorktree}
+	for i, inc := range all {
+		if inc.Type != want[i] {
+			t.Errorf("Expected %v at %d, got %v", want[i], i, inc.Type)
+		}
+	}
+
+	panes, _ := filterInconsistencies(inconsistencies, []string{RepairMissingPanes}, "")
+	if len(panes) != 2 {
+		t.Errorf("Expected 2 missing panes, got %v", panes)
+	}
+
+	orphans, _ := filterInconsistencies(inconsistencies, []string{RepairOrphans}, "")
+	if len(orphans) != 2 {
+		t.Errorf("Expected 2 orphans, got %v", orphans)
+	}
+
+	worker, _ := filterInconsistencies(inconsistencies, nil, "w1")
+	if len(worker) != 2 || worker[0].Type != MissingWorktree {
+		t.Errorf("Expected the worktree of w1 to be repaired first, got %v", worker)
+	}
+
+	tracked, _ := filterInconsistencies(append(inconsistencies, Inconsistency{Type: TrackedWorktree, Path: "worktree"}), []string{RepairTrackedWorktrees}, "")
+	if len(tracked) != 1 || tracked[0].Type.String() != "tracked_worktree" {
+		t.Errorf("Expected the tracked worktree directory, got %v", tracked)
+	}
+
+	if _, err := filterInconsistencies(inconsistencies, []string{"everything"}, ""); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+}
+
+func TestInconsistencyJSON(t *testing.T) {
+	inc := Inconsistency{Type: OrphanedPane, Severity: OrphanedPane.Severity(), WorkerID: "stray", PaneID: "%3"}
+	data, err := json.Marshal(inc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `{"type":"orphaned_pane","severity":"warning","worker_id":"stray","pane_id":"%3","description":""}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+	if MissingPane.Severity() != "error" {
+		t.Errorf("Expected a missing pane to be an error")
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffStats summarizes a worker branch's changes against its base.
+type DiffStats struct {
+	Base         string `json:"base"`
+	Commits      int    `json:"commits"`
+	FilesChanged int    `json:"files_changed"`
+	Insertions   int    `json:"insertions"`
+	Deletions    int    `json:"deletions"`
+}
+
+var shortstatPattern = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// parseShortstat parses the output of `git diff --shortstat`.
+func parseShortstat(output string, stats *DiffStats) {
+	for _, m := range shortstatPattern.FindAllStringSubmatch(output, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			stats.FilesChanged = n
+		case "insertion":
+			stats.Insertions = n
+		case "deletion":
+			stats.Deletions = n
+		}
+	}
+}
+
+// workerDiffStats compares the worker's branch with its base, or with the
+// project's HEAD for workers created without --base.
+func workerDiffStats(worker Worker) *DiffStats {
+	base := workerBase(worker)
+	if base == "" {
+		return nil
+	}
+	stats := &DiffStats{Base: base}
+
+	output, err := gitCommand("-C", worker.WorktreePath, "rev-list", "--count", base+"..HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	stats.Commits, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+
+	output, err = gitCommand("-C", worker.WorktreePath, "diff", "--shortstat", base+"...HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	parseShortstat(string(output), stats)
+	return stats
+}
+
+func (s *DiffStats) String() string {
+	if s == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d commit(s), %d file(s), +%d -%d", s.Commits, s.FilesChanged, s.Insertions, s.Deletions)
+}
+
+// JUnit XML as read by CI systems (Jenkins, GitLab, GitHub actions)
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitMessage    `xml:"failure,omitempty"`
+	Error      *junitMessage    `xml:"error,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes the report as JUnit XML with one test case per
+// worker.
+func writeJUnitReport(w io.Writer, report *TestReport) error {
+	suite := junitTestSuite{Name: "gtw test: " + report.Command, Tests: len(report.Results)}
+	total := 0.0
+	for _, result := range report.Results {
+		testCase := junitTestCase{
+			Name:      result.Worker,
+			ClassName: "gtw." + result.Branch,
+			Time:      fmt.Sprintf("%.3f", result.Duration),
+		}
+		testCase.Properties = &junitProperties{Properties: []junitProperty{{Name: "branch", Value: result.Branch}}}
+		if result.Diff != nil {
+			testCase.Properties.Properties = append(testCase.Properties.Properties,
+				junitProperty{Name: "diff", Value: result.Diff.String()})
+		}
+		switch {
+		case result.Error != "":
+			suite.Errors++
+			testCase.Error = &junitMessage{Message: result.Error, Body: result.Output}
+		case !result.Passed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("exit status %d", result.ExitCode), Body: result.Output}
+		default:
+			testCase.SystemOut = result.Output
+		}
+		total += result.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// writeMarkdownReport writes the report as a Markdown table, with the output
+// of failed workers in collapsed sections.
+func writeMarkdownReport(w io.Writer, report *TestReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## gtw test: `%s`\n\n", strings.ReplaceAll(report.Command, "`", "'"))
+	if report.Failed > 0 {
+		fmt.Fprintf(&b, "❌ %d passed, %d failed\n\n", report.Passed, report.Failed)
+	} else {
+		fmt.Fprintf(&b, "✅ %d passed\n\n", report.Passed)
+	}
+
+	b.WriteString("| Worker | Branch | Result | Commits | Changes | Duration |\n")
+	b.WriteString("|---|---|---|---:|---|---:|\n")
+	for _, result := range report.Results {
+		outcome := "✅ pass"
+		switch {
+		case result.Error != "":
+			outcome = "⚠️ error"
+		case !result.Passed:
+			outcome = fmt.Sprintf("❌ fail (%d)", result.ExitCode)
+		}
+		commits, changes := "-", "-"
+		if result.Diff != nil {
+			commits = strconv.Itoa(result.Diff.Commits)
+			changes = fmt.Sprintf("%d file(s), +%d −%d", result.Diff.FilesChanged, result.Diff.Insertions, result.Diff.Deletions)
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %s | %.1fs |\n",
+			markdownCell(result.Worker), markdownCell(result.Branch), outcome, commits, changes, result.Duration)
+	}
+
+	for _, result := range report.Results {
+		detail := strings.TrimSpace(result.Output)
+		if result.Error != "" {
+			detail = strings.TrimSpace(result.Error + "\n" + detail)
+		}
+		if result.Passed || detail == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary>%s output</summary>\n\n```\n%s\n```\n\n</details>\n",
+			markdownCell(result.Worker), strings.TrimRight(strings.ReplaceAll(detail, "```", "'''"), "\n"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// reportTarget returns the format and file of a --report value: a file
+// ending in .xml (JUnit) or .md (Markdown), or "junit" or "markdown" for
+// stdout (empty path).
+func reportTarget(spec string) (string, string, error) {
+	switch {
+	case spec == "junit" || spec == "markdown":
+		return spec, "", nil
+	case strings.HasSuffix(spec, ".xml"):
+		return "junit", userPath(spec), nil
+	case strings.HasSuffix(spec, ".md"):
+		return "markdown", userPath(spec), nil
+	}
+	return "", "", fmt.Errorf("unknown report '%s' (use a .xml or .md file, junit or markdown)", spec)
+}
+
+// writeReport writes the report as described by spec (see reportTarget).
+func writeReport(spec string, report *TestReport) error {
+	format, path, err := reportTarget(spec)
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if format == "junit" {
+		return writeJUnitReport(out, report)
+	}
+	return writeMarkdownReport(out, report)
+}
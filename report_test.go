@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *TestReport {
+	return newTestReport("go test ./...", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), []TestResult{
+		{Worker: "api", Branch: "feature/api", Passed: true, Duration: 1.5, Output: "ok",
+			Diff: &DiffStats{Base: "main", Commits: 2, FilesChanged: 3, Insertions: 10, Deletions: 4}},
+		{Worker: "ui", Branch: "ui", ExitCode: 1, Duration: 2, Output: "FAIL: x | y"},
+		{Worker: "gone", Branch: "gone", ExitCode: -1, Error: "worktree is missing"},
+	})
+}
+
+func TestParseShortstat(t *testing.T) {
+	var stats DiffStats
+	parseShortstat(" 3 files changed, 10 insertions(+), 4 deletions(-)\n", &stats)
+	if stats.FilesChanged != 3 || stats.Insertions != 10 || stats.Deletions != 4 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	stats = DiffStats{}
+	parseShortstat(" 1 file changed, 1 deletion(-)\n", &stats)
+	if stats.FilesChanged != 1 || stats.Insertions != 0 || stats.Deletions != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJUnitReport(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, buf.String())
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 {
+		t.Errorf("Unexpected counts: %+v", suite)
+	}
+	if suite.Cases[1].Failure == nil || suite.Cases[1].Failure.Body != "FAIL: x | y" {
+		t.Errorf("Expected the failure output, got %+v", suite.Cases[1])
+	}
+	if suite.Cases[0].Properties.Properties[1].Value != "2 commit(s), 3 file(s), +10 -4" {
+		t.Errorf("Unexpected diff property %+v", suite.Cases[0].Properties)
+	}
+}
+
+func TestWriteMarkdownReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMarkdownReport(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"❌ 1 passed, 2 failed",
+		"| api | `feature/api` | ✅ pass | 2 | 3 file(s), +10 −4 | 1.5s |",
+		"| ui | `ui` | ❌ fail (1) | - | - | 2.0s |",
+		"<details><summary>gone output</summary>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Missing %q in:\n%s", want, output)
+		}
+	}
+}
+
+func TestReportTarget(t *testing.T) {
+	if format, path, err := reportTarget("markdown"); err != nil || format != "markdown" || path != "" {
+		t.Errorf("reportTarget(markdown) = %q, %q, %v", format, path, err)
+	}
+	if format, path, _ := reportTarget("out/junit.xml"); format != "junit" || !strings.HasSuffix(path, "junit.xml") {
+		t.Errorf("reportTarget(junit.xml) = %q, %q", format, path)
+	}
+	if _, _, err := reportTarget("report.html"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// processInfo is one row of the process table.
+type processInfo struct {
+	PID   int
+	PPID  int
+	CPU   float64 // Percent of one core
+	RSSKB int64
+}
+
+// ResourceUsage is the combined usage of a pane's process tree.
+type ResourceUsage struct {
+	CPU       float64
+	RSSKB     int64
+	Processes int
+}
+
+// parseProcessTable parses `ps -A -o pid=,ppid=,pcpu=,rss=` output,
+// skipping malformed lines.
+func parseProcessTable(output string) []processInfo {
+	var table []processInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		table = append(table, processInfo{PID: pid, PPID: ppid, CPU: cpu, RSSKB: rss})
+	}
+	return table
+}
+
+// processTree returns rootPID and all of its descendants, parents first.
+func processTree(table []processInfo, rootPID int) []processInfo {
+	children := make(map[int][]processInfo)
+	var root *processInfo
+	for i, p := range table {
+		children[p.PPID] = append(children[p.PPID], p)
+		if p.PID == rootPID {
+			root = &table[i]
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var tree []processInfo
+	queue := []processInfo{*root}
+	seen := make(map[int]bool)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p.PID] {
+			continue
+		}
+		seen[p.PID] = true
+		tree = append(tree, p)
+		queue = append(queue, children[p.PID]...)
+	}
+	return tree
+}
+
+// processTreeUsage sums the usage of rootPID and all of its descendants.
+func processTreeUsage(table []processInfo, rootPID int) ResourceUsage {
+	usage := ResourceUsage{}
+	for _, p := range processTree(table, rootPID) {
+		usage.CPU += p.CPU
+		usage.RSSKB += p.RSSKB
+		usage.Processes++
+	}
+	return usage
+}
+
+// readProcessTable lists every process on the machine.
+func readProcessTable() ([]processInfo, error) {
+	output, err := newCommand("ps", "-A", "-o", "pid=,ppid=,pcpu=,rss=").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseProcessTable(string(output)), nil
+}
+
+// panePID returns the PID of the process started in the pane (its shell).
+// Only tmux exposes it.
+func panePID(paneID string) (int, error) {
+	if mux.Name() != "tmux" {
+		return 0, fmt.Errorf("pane PIDs are not available with %s", mux.Name())
+	}
+	output, err := tmuxCommand("display-message", "-p", "-t", paneID, "#{pane_pid}").Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// paneResourceUsage returns the usage of the pane's process tree, using
+// table when given to avoid listing processes once per worker.
+func paneResourceUsage(paneID string, table []processInfo) (ResourceUsage, error) {
+	pid, err := panePID(paneID)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+	if table == nil {
+		if table, err = readProcessTable(); err != nil {
+			return ResourceUsage{}, err
+		}
+	}
+	return processTreeUsage(table, pid), nil
+}
+
+// dirSize returns the total size in bytes of the regular files under path.
+// Unreadable entries are skipped.
+func dirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d == nil {
+				return err
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// formatBytes renders a byte count with a binary unit (e.g. "1.5G").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProcessTreeUsage(t *testing.T) {
+	table := parseProcessTable(`
+    1     0  0.0  1000
+  100     1  1.5  2000
+  101   100 50.0 30000
+  102   101 10.0  4000
+  200     1 99.0 99999
+garbage line
+`)
+	if len(table) != 5 {
+		t.Fatalf("Expected 5 processes, got %d", len(table))
+	}
+
+	usage := processTreeUsage(table, 100)
+	if usage.Processes != 3 || usage.CPU != 61.5 || usage.RSSKB != 36000 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	if usage := processTreeUsage(table, 999); usage.Processes != 0 {
+		t.Errorf("Expected no usage for unknown PID, got %+v", usage)
+	}
+}
+
+func TestProcessTree(t *testing.T) {
+	table := []processInfo{
+		{PID: 1, PPID: 0},
+		{PID: 100, PPID: 1},
+		{PID: 101, PPID: 100},
+		{PID: 102, PPID: 101},
+		{PID: 103, PPID: 100},
+		{PID: 200, PPID: 1},
+	}
+
+	var pids []int
+	for _, p := range processTree(table, 100) {
+		pids = append(pids, p.PID)
+	}
+	if !reflect.DeepEqual(pids, []int{100, 101, 103, 102}) {
+		t.Errorf("processTree() = %v", pids)
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 50), 0644)
+
+	size, err := dirSize(dir)
+	if err != nil || size != 150 {
+		t.Errorf("dirSize() = %d, %v", size, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                      "0B",
+		1023:                   "1023B",
+		1536:                   "1.5K",
+		5 * 1024 * 1024:        "5.0M",
+		3 * 1024 * 1024 * 1024: "3.0G",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
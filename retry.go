@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = 200 * time.Millisecond
+)
+
+// RetryPolicy says how git and tmux commands that failed for a transient
+// reason are retried.
+type RetryPolicy struct {
+	Attempts int           // Total attempts; 1 disables retries
+	Backoff  time.Duration // Wait before the first retry, doubled for each further one
+}
+
+// retryPolicy is set from the retry_attempts and retry_backoff config
+// values.
+var retryPolicy = RetryPolicy{Attempts: defaultRetryAttempts, Backoff: defaultRetryBackoff}
+
+// transientErrors are failures that go away by themselves, by the reason
+// shown when retrying. Parallel `gtw add` runs contend for git's lock files
+// (index, refs and worktree metadata).
+var transientErrors = []struct {
+	Pattern *regexp.Regexp
+	Reason  string
+}{
+	{regexp.MustCompile(`(?i)unable to create '[^']*\.lock': file exists`), "git lock file in use"},
+	{regexp.MustCompile(`(?i)could not lock config file`), "git config locked"},
+	{regexp.MustCompile(`(?i)resource temporarily unavailable`), "tmux server busy"},
+	{regexp.MustCompile(`(?i)server exited unexpectedly|lost server`), "tmux server restarting"},
+}
+
+// transientReason returns why the error output of a command looks transient,
+// or "" when retrying will not help.
+func transientReason(output string) string {
+	for _, transient := range transientErrors {
+		if transient.Pattern.MatchString(output) {
+			return transient.Reason
+		}
+	}
+	return ""
+}
+
+// delay returns the wait before the given retry (1 for the first), with up
+// to 50% jitter so that parallel gtw runs do not retry in lockstep.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	wait := p.Backoff << (retry - 1)
+	if wait <= 0 {
+		return 0
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/2+1))
+}
+
+// resolveRetryPolicy reads the retry settings from the config, falling back
+// to the defaults for unset or invalid values.
+func resolveRetryPolicy(config *Config) RetryPolicy {
+	policy := RetryPolicy{Attempts: defaultRetryAttempts, Backoff: defaultRetryBackoff}
+	if config.RetryAttempts > 0 {
+		policy.Attempts = config.RetryAttempts
+	}
+	if config.RetryBackoff != "" {
+		backoff, err := time.ParseDuration(config.RetryBackoff)
+		if err != nil || backoff < 0 {
+			fmt.Printf("Warning: Invalid retry_backoff %q in config, using %s\n", config.RetryBackoff, defaultRetryBackoff)
+		} else {
+			policy.Backoff = backoff
+		}
+	}
+	return policy
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransientReason(t *testing.T) {
+	tests := map[string]string{
+		"fatal: Unable to create '/repo/.git/index.lock': File exists.":                                          "git lock file in use",
+		"error: cannot lock ref 'refs/heads/w1': Unable to create '/repo/.git/refs/heads/w1.lock': File exists.": "git lock file in use",
+		"error: cannot lock ref 'refs/heads/w1': reference already exists":                                       "",
+		"error: could not lock config file .git/config: File exists":                                             "git config locked",
+		"error connecting to /tmp/tmux-0/default (Resource temporarily unavailable)":                             "tmux server busy",
+		"server exited unexpectedly":             "tmux server restarting",
+		"fatal: invalid reference: no-such-base": "",
+		"can't find session: proj":               "",
+	}
+	for output, want := range tests {
+		if got := transientReason(output); got != want {
+			t.Errorf("transientReason(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: 100 * time.Millisecond}
+	for retry, base := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond} {
+		if wait := policy.delay(retry); wait < base || wait > base*3/2 {
+			t.Errorf("delay(%d) = %v, want between %v and %v", retry, wait, base, base*3/2)
+		}
+	}
+	if wait := (RetryPolicy{Attempts: 3}).delay(1); wait != 0 {
+		t.Errorf("Expected no wait without backoff, got %v", wait)
+	}
+}
+
+func TestResolveRetryPolicy(t *testing.T) {
+	if policy := resolveRetryPolicy(&Config{}); policy.Attempts != defaultRetryAttempts || policy.Backoff != defaultRetryBackoff {
+		t.Errorf("Expected the defaults, got %+v", policy)
+	}
+	if policy := resolveRetryPolicy(&Config{RetryAttempts: 5, RetryBackoff: "1s"}); policy.Attempts != 5 || policy.Backoff != time.Second {
+		t.Errorf("Expected the configured policy, got %+v", policy)
+	}
+}
+
+func TestCmdRetriesTransientFailure(t *testing.T) {
+	previous := retryPolicy
+	retryPolicy = RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	t.Cleanup(func() { retryPolicy = previous })
+
+	// Fails with lock contention the first time only
+	marker := filepath.Join(t.TempDir(), "tried")
+	script := `if [ ! -e "$1" ]; then touch "$1"; echo "fatal: Unable to create '.git/index.lock': File exists." >&2; exit 128; fi; echo ok`
+	cmd := newCommand("sh", "-c", script, "sh", marker)
+	cmd.Retry = true
+	output, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(output)) != "ok" {
+		t.Errorf("Expected the retry to succeed, got %q, %v", output, err)
+	}
+
+	// Other failures and commands without Retry are not retried
+	cmd = newCommand("sh", "-c", `echo "fatal: not a git repository" >&2; exit 128`)
+	cmd.Retry = true
+	started := time.Now()
+	if err := cmd.Run(); err == nil {
+		t.Error("Expected the failure to be returned")
+	}
+	marker = filepath.Join(t.TempDir(), "tried")
+	if _, err := newCommand("sh", "-c", script, "sh", marker).CombinedOutput(); err == nil {
+		t.Error("Expected a command without Retry to fail")
+	}
+	if time.Since(started) > 2*time.Second {
+		t.Error("Expected failures to return without waiting")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// paneInfo describes a live tmux pane.
+type paneInfo struct {
+	ID       string
+	Index    int
+	WorkerID string // @gtw_worker_id, set by gtw on worker panes
+	Title    string
+	Path     string // pane_current_path
+}
+
+// listSessionPanes returns every pane in the session.
+func listSessionPanes(sessionName string) ([]paneInfo, error) {
+	// tmux escapes tabs in formats; the title goes last as it may contain "|"
+	output, err := tmuxCommand("list-panes", "-s", "-t", sessionName, "-F", "#{pane_id}|#{pane_index}|#{"+paneWorkerIDOption+"}|#{pane_current_path}|#{pane_title}").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePaneList(string(output)), nil
+}
+
+func parsePaneList(output string) []paneInfo {
+	var panes []paneInfo
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) != 5 {
+			continue
+		}
+		index, _ := strconv.Atoi(parts[1])
+		panes = append(panes, paneInfo{ID: parts[0], Index: index, WorkerID: parts[2], Path: parts[3], Title: parts[4]})
+	}
+	return panes
+}
+
+// matchStalePanes finds a live pane for every worker whose stored PaneID no
+// longer exists, first by its @gtw_worker_id pane option, then by pane title
+// and then by the pane's working directory. Panes that already belong to a
+// worker are never reassigned. The result maps worker index to its new pane.
+func matchStalePanes(workers []Worker, panes []paneInfo, worktreeDir func(Worker) string) map[int]paneInfo {
+	live := make(map[string]bool)
+	for _, pane := range panes {
+		live[pane.ID] = true
+	}
+
+	claimed := make(map[string]bool)
+	var stale []int
+	for i, worker := range workers {
+		if live[worker.PaneID] {
+			claimed[worker.PaneID] = true
+		} else {
+			stale = append(stale, i)
+		}
+	}
+
+	matches := make(map[int]paneInfo)
+	match := func(i int, ok func(paneInfo) bool) {
+		if _, done := matches[i]; done {
+			return
+		}
+		for _, pane := range panes {
+			if !claimed[pane.ID] && ok(pane) {
+				matches[i] = pane
+				claimed[pane.ID] = true
+				return
+			}
+		}
+	}
+
+	// The worker ID option is exact and titles are more specific than
+	// paths, so match in that order
+	for _, i := range stale {
+		match(i, func(p paneInfo) bool { return p.WorkerID == workers[i].ID })
+	}
+	for _, i := range stale {
+		match(i, func(p paneInfo) bool { return p.Title == workers[i].ID })
+	}
+	for _, i := range stale {
+		dir := worktreeDir(workers[i])
+		match(i, func(p paneInfo) bool { return dir != "" && samePath(p.Path, dir) })
+	}
+	return matches
+}
+
+// samePath compares two directories after resolving symlinks.
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, err1 := filepath.EvalSymlinks(a)
+	rb, err2 := filepath.EvalSymlinks(b)
+	return err1 == nil && err2 == nil && ra == rb
+}
+
+// revalidatePanes re-resolves stale pane IDs (e.g. after a tmux server
+// restart) in config. It reports whether any worker was updated.
+func revalidatePanes(config *Config) bool {
+	if mux.Name() != "tmux" || len(config.Workers) == 0 {
+		return false
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return false
+	}
+	panes, err := listSessionPanes(sessionName)
+	if err != nil {
+		return false
+	}
+
+	matches := matchStalePanes(config.Workers, panes, func(w Worker) string { return muxPath(w.WorktreePath) })
+	for i, pane := range matches {
+		worker := &config.Workers[i]
+		fmt.Printf("Re-linked worker '%s' to pane %s (was %s)\n", worker.ID, pane.ID, worker.PaneID)
+		worker.PaneID = pane.ID
+		worker.PaneIndex = pane.Index
+		worker.TmuxSession = sessionName
+	}
+	return len(matches) > 0
+}
+
+// worktreeRoot returns the directory that holds the worker worktrees.
+func worktreeRoot(config *Config) string {
+	prefix := resolvedWorktreePrefix(config)
+	if filepath.IsAbs(prefix) || config.ProjectPath == "" {
+		return prefix
+	}
+	return filepath.Join(config.ProjectPath, prefix)
+}
+
+// nameIgnored reports whether name matches one of the glob patterns in ignore.
+func nameIgnored(name string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// paneIgnored reports whether the pane title or worktree name is ignored.
+func paneIgnored(pane paneInfo, name string, ignore []string) bool {
+	return nameIgnored(pane.Title, ignore) || nameIgnored(name, ignore)
+}
+
+// orphanedPanes returns the panes not claimed by any worker that are working
+// inside a worktree under root, keyed by the worktree directory name. Panes
+// elsewhere (e.g. the project pane) are not considered workers.
+func orphanedPanes(panes []paneInfo, claimed map[string]bool, root string, ignore []string) map[string]paneInfo {
+	orphans := make(map[string]paneInfo)
+	for _, pane := range panes {
+		if claimed[pane.ID] {
+			continue
+		}
+		rel, err := filepath.Rel(root, pane.Path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		name := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		if paneIgnored(pane, name, ignore) {
+			continue
+		}
+		if _, exists := orphans[name]; !exists {
+			orphans[name] = pane
+		}
+	}
+	return orphans
+}
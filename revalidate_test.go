@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestParsePaneList(t *testing.T) {
+	panes := parsePaneList("%1|0||/src/proj|proj\n%5|1|issue-1|/src/proj/worktree/issue-1|issue|1\nbad\n")
+	if len(panes) != 2 {
+		t.Fatalf("Expected 2 panes, got %d", len(panes))
+	}
+	if panes[1] != (paneInfo{ID: "%5", Index: 1, WorkerID: "issue-1", Title: "issue|1", Path: "/src/proj/worktree/issue-1"}) {
+		t.Errorf("Unexpected pane: %+v", panes[1])
+	}
+}
+
+func TestMatchStalePanes(t *testing.T) {
+	workers := []Worker{
+		{ID: "live", PaneID: "%1", WorktreePath: "worktree/live"},
+		{ID: "by-title", PaneID: "%90", WorktreePath: "worktree/by-title"},
+		{ID: "by-path", PaneID: "%91", WorktreePath: "worktree/by-path"},
+		{ID: "gone", PaneID: "%92", WorktreePath: "worktree/gone"},
+		{ID: "by-option", PaneID: "%93", WorktreePath: "worktree/by-option"},
+	}
+	panes := []paneInfo{
+		{ID: "%1", Index: 0, Title: "by-path", Path: "/p/worktree/live"},
+		{ID: "%2", Index: 1, Title: "by-title", Path: "/p/worktree/by-title"},
+		{ID: "%3", Index: 2, Title: "bash", Path: "/p/worktree/by-path"},
+		{ID: "%4", Index: 3, WorkerID: "by-option", Title: "by-option [main]", Path: "/p"},
+	}
+	dir := func(w Worker) string { return "/p/" + w.WorktreePath }
+
+	matches := matchStalePanes(workers, panes, dir)
+
+	if len(matches) != 3 {
+		t.Fatalf("Expected 3 matches, got %v", matches)
+	}
+	if matches[4].ID != "%4" {
+		t.Errorf("Expected 'by-option' to match %%4 by @gtw_worker_id, got %+v", matches[4])
+	}
+	if matches[1].ID != "%2" {
+		t.Errorf("Expected 'by-title' to match %%2 by title, got %+v", matches[1])
+	}
+	// %1 carries the title "by-path" but belongs to a live worker
+	if matches[2].ID != "%3" {
+		t.Errorf("Expected 'by-path' to match %%3 by path, got %+v", matches[2])
+	}
+	if _, ok := matches[3]; ok {
+		t.Errorf("Expected no match for 'gone'")
+	}
+}
+
+func TestOrphanedPanes(t *testing.T) {
+	panes := []paneInfo{
+		{ID: "%0", Title: "myhost.local", Path: "/p"},
+		{ID: "%1", Title: "worker-1", Path: "/p/worktree/worker-1"},
+		{ID: "%2", Title: "bash", Path: "/p/worktree/stray/src"},
+		{ID: "%3", Title: "scratch", Path: "/p/worktree/scratch"},
+		{ID: "%4", Title: "bash", Path: "/p/worktree"},
+	}
+	claimed := map[string]bool{"%1": true}
+
+	orphans := orphanedPanes(panes, claimed, "/p/worktree", []string{"scr*"})
+
+	if len(orphans) != 1 {
+		t.Fatalf("Expected 1 orphaned pane, got %v", orphans)
+	}
+	if orphans["stray"].ID != "%2" {
+		t.Errorf("Expected pane %%2 to be orphaned as 'stray', got %v", orphans)
+	}
+}
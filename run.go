@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RunOptions configures a fan-out run across ephemeral workers.
+type RunOptions struct {
+	Workers  int
+	TaskFile string
+	Prefix   string
+	Pattern  string
+	Quiet    time.Duration
+	Interval time.Duration
+	Timeout  time.Duration
+	Keep     bool
+}
+
+// runTask is one entry of a task file. Plain lines become Command; JSON
+// lines may carry extra fields.
+type runTask struct {
+	Command string `json:"command"`
+}
+
+// runSlot tracks the task currently executing on one ephemeral worker.
+type runSlot struct {
+	workerID string
+	paneID   string
+	tasks    []runTask
+	current  *runTask
+	output   string // Pane output at the last check, for new pattern matches
+	done     int
+}
+
+func init() {
+	var opts RunOptions
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Fan tasks out across ephemeral workers and tear them down when done",
+		Long: `Create ephemeral workers, hand them the tasks of the task file round-robin
+and remove the workers once every task completed; uncommitted work is saved
+to gtw/backup/<id>/<timestamp> branches first. When the run times out or a
+task fails, the workers are kept for inspection. Exits with status 1 unless
+every task completed.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !runFanOut(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	runCmd.Flags().IntVar(&opts.Workers, "workers", 2, "Number of ephemeral workers to create")
+	runCmd.Flags().StringVar(&opts.TaskFile, "task-file", "", "File with one task per line (plain text or JSON object with a \"command\" field)")
+	runCmd.Flags().StringVar(&opts.Prefix, "prefix", "run", "Prefix for ephemeral worker IDs")
+	runCmd.Flags().StringVar(&opts.Pattern, "pattern", "", "Regexp printed by a worker when a task is complete (default: wait for idle)")
+	runCmd.Flags().DurationVar(&opts.Quiet, "quiet", defaultIdleQuiet, "How long pane output must stay unchanged to count as idle")
+	runCmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second, "How often workers are polled")
+	runCmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Give up after this long (0 means no limit)")
+	runCmd.Flags().BoolVar(&opts.Keep, "keep", false, "Keep the workers after all tasks complete")
+	runCmd.MarkFlagRequired("task-file")
+	rootCmd.AddCommand(runCmd)
+}
+
+// parseTaskFile reads tasks, one per line. Blank lines and lines starting
+// with '#' are ignored; lines starting with '{' are decoded as JSON.
+func parseTaskFile(file string) ([]runTask, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tasks []runTask
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		task := runTask{Command: line}
+		if strings.HasPrefix(line, "{") {
+			task = runTask{}
+			if err := json.Unmarshal([]byte(line), &task); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNum, err)
+			}
+			if task.Command == "" {
+				return nil, fmt.Errorf("line %d: missing \"command\"", lineNum)
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, scanner.Err()
+}
+
+// distributeTasks assigns tasks to n slots round-robin.
+func distributeTasks(tasks []runTask, n int) [][]runTask {
+	buckets := make([][]runTask, n)
+	for i, task := range tasks {
+		buckets[i%n] = append(buckets[i%n], task)
+	}
+	return buckets
+}
+
+func runFanOut(opts RunOptions) bool {
+	if opts.Workers < 1 {
+		fmt.Println("Error: --workers must be at least 1")
+		return false
+	}
+
+	tasks, err := parseTaskFile(userPath(opts.TaskFile))
+	if err != nil {
+		fmt.Printf("Error reading task file: %v\n", err)
+		return false
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No tasks found in task file")
+		return false
+	}
+
+	var pattern *regexp.Regexp
+	if opts.Pattern != "" {
+		if pattern, err = regexp.Compile(opts.Pattern); err != nil {
+			fmt.Printf("Error: invalid --pattern: %v\n", err)
+			return false
+		}
+	}
+
+	// Never create more workers than there are tasks
+	if opts.Workers > len(tasks) {
+		opts.Workers = len(tasks)
+	}
+
+	stamp := time.Now().Format("20060102150405")
+	buckets := distributeTasks(tasks, opts.Workers)
+	var slots []*runSlot
+
+	for i := 0; i < opts.Workers; i++ {
+		id := fmt.Sprintf("%s-%s-%d", opts.Prefix, stamp, i+1)
+		addWorker(id, AddOptions{Tags: []string{"run"}, Note: fmt.Sprintf("ephemeral worker for %s", opts.TaskFile)})
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			break
+		}
+		index := findWorkerIndex(config, id)
+		if index == -1 {
+			fmt.Printf("Error: Could not create worker '%s'\n", id)
+			break
+		}
+		slots = append(slots, &runSlot{workerID: id, paneID: config.Workers[index].PaneID, tasks: buckets[i]})
+	}
+
+	started := len(slots) == opts.Workers
+	completed := false
+	if started {
+		fmt.Printf("Running %d task(s) on %d worker(s)...\n", len(tasks), len(slots))
+		if completed = driveRun(slots, pattern, opts); !completed {
+			fmt.Println("⚠️  Run did not complete")
+		}
+	}
+
+	// Workers that got tasks but did not finish them may still be at work
+	if opts.Keep || (started && !completed) {
+		fmt.Println("Keeping workers for inspection:")
+		for _, slot := range slots {
+			fmt.Printf("  %s\n", slot.workerID)
+		}
+		return completed
+	}
+
+	for _, slot := range slots {
+		// Ephemeral worktrees are scratch space, but what the tasks left
+		// uncommitted goes to a backup branch
+		removeWorker(slot.workerID, RemoveOptions{Yes: true, KillRunning: true, Backup: true})
+	}
+	if started {
+		fmt.Println("Branches of the ephemeral workers were kept for inspection.")
+	}
+	return completed
+}
+
+// driveRun feeds tasks into the slots until all of them are done. It
+// reports false when the timeout expired first or a task could not be run.
+func driveRun(slots []*runSlot, pattern *regexp.Regexp, opts RunOptions) bool {
+	tracker := NewIdleTracker(opts.Quiet)
+	started := time.Now()
+	failed := 0
+
+	for {
+		remaining := 0
+		for _, slot := range slots {
+			if slot.current != nil {
+				finished, err := taskFinished(slot, tracker, pattern)
+				if err != nil {
+					fmt.Printf("❌ Worker '%s': %v\n", slot.workerID, err)
+					failed += 1 + len(slot.tasks) // The worker's remaining tasks are dropped
+					slot.current = nil
+					slot.tasks = nil
+					continue
+				}
+				if !finished {
+					remaining++
+					continue
+				}
+				slot.done++
+				fmt.Printf("✅ [%s] finished: %s\n", slot.workerID, slot.current.Command)
+				slot.current = nil
+			}
+
+			if len(slot.tasks) == 0 {
+				continue
+			}
+
+			// Workers must be idle before the next task is typed in
+			idle, err := tracker.Observe(slot.paneID)
+			if err != nil {
+				fmt.Printf("❌ Worker '%s': %v\n", slot.workerID, err)
+				failed += len(slot.tasks)
+				slot.tasks = nil
+				continue
+			}
+			if !idle {
+				remaining++
+				continue
+			}
+
+			next := slot.tasks[0]
+			slot.tasks = slot.tasks[1:]
+			if pattern != nil {
+				slot.output, _ = capturePane(slot.paneID, waitCaptureLines)
+			}
+			fmt.Printf("▶ [%s] %s\n", slot.workerID, next.Command)
+			if err := sendToPane(slot.paneID, next.Command); err != nil {
+				fmt.Printf("❌ Worker '%s': could not send task: %v\n", slot.workerID, err)
+				failed++
+				continue
+			}
+			tracker.Touch(slot.paneID)
+			slot.current = &next
+			remaining++
+		}
+
+		if remaining == 0 {
+			total := 0
+			for _, slot := range slots {
+				total += slot.done
+			}
+			if failed > 0 {
+				fmt.Printf("❌ Completed %d task(s), %d failed\n", total, failed)
+				return false
+			}
+			fmt.Printf("✅ Completed %d task(s)\n", total)
+			return true
+		}
+
+		if opts.Timeout > 0 && time.Since(started) > opts.Timeout {
+			fmt.Printf("❌ Timed out after %s\n", opts.Timeout)
+			return false
+		}
+		time.Sleep(opts.Interval)
+	}
+}
+
+// taskFinished reports whether the slot's current task is complete: either
+// the pattern appeared in new output, or (without a pattern) the pane went
+// idle.
+func taskFinished(slot *runSlot, tracker *IdleTracker, pattern *regexp.Regexp) (bool, error) {
+	if pattern != nil {
+		current, err := capturePane(slot.paneID, waitCaptureLines)
+		if err != nil {
+			return false, err
+		}
+		fresh := newOutput(slot.output, current)
+		slot.output = current
+		return pattern.MatchString(fresh), nil
+	}
+	return tracker.Observe(slot.paneID)
+}
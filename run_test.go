@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseTaskFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+	content := "# comment\nmake test\n\n{\"command\": \"fix the login bug\"}\n  go vet ./...  \n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := parseTaskFile(file)
+	if err != nil {
+		t.Fatalf("parseTaskFile failed: %v", err)
+	}
+
+	want := []string{"make test", "fix the login bug", "go vet ./..."}
+	if len(tasks) != len(want) {
+		t.Fatalf("Expected %d tasks, got %d: %+v", len(want), len(tasks), tasks)
+	}
+	for i, task := range tasks {
+		if task.Command != want[i] {
+			t.Errorf("Task %d = %q, want %q", i, task.Command, want[i])
+		}
+	}
+}
+
+func TestParseTaskFileRejectsBadJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+	if err := os.WriteFile(file, []byte("{\"prompt\": \"no command\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parseTaskFile(file); err == nil {
+		t.Error("Expected error for JSON task without command")
+	}
+}
+
+func TestDistributeTasks(t *testing.T) {
+	tasks := []runTask{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}
+	buckets := distributeTasks(tasks, 2)
+
+	if len(buckets) != 2 || len(buckets[0]) != 3 || len(buckets[1]) != 2 {
+		t.Fatalf("Unexpected distribution: %+v", buckets)
+	}
+	if buckets[0][1].Command != "c" || buckets[1][1].Command != "d" {
+		t.Errorf("Tasks not assigned round-robin: %+v", buckets)
+	}
+}
+
+func TestDriveRun(t *testing.T) {
+	opts := RunOptions{Quiet: time.Hour, Interval: time.Millisecond, Timeout: 200 * time.Millisecond}
+	slots := func() []*runSlot {
+		return []*runSlot{{workerID: "run-1", paneID: "%1", tasks: []runTask{{Command: "make a"}, {Command: "make b"}}}}
+	}
+
+	// A shell prompt is idle, so every task is sent and finished at once
+	fake := &paneMultiplexer{commands: map[string]string{"%1": "zsh"}, output: map[string]string{"%1": "$ "}}
+	useMultiplexer(t, fake)
+	if !driveRun(slots(), nil, opts) {
+		t.Error("Expected the run to complete")
+	}
+	if len(fake.sent) != 2 {
+		t.Errorf("Expected both tasks sent, got %v", fake.sent)
+	}
+
+	// An agent that never goes quiet runs into the timeout
+	fake = &paneMultiplexer{commands: map[string]string{"%1": "claude"}, output: map[string]string{"%1": "thinking"}}
+	useMultiplexer(t, fake)
+	if driveRun(slots(), regexp.MustCompile("DONE"), opts) {
+		t.Error("Expected the run to time out")
+	}
+
+	// A pane that is gone fails its tasks instead of waiting for the timeout
+	fake = &paneMultiplexer{commands: map[string]string{}}
+	useMultiplexer(t, fake)
+	started := time.Now()
+	if driveRun(slots(), nil, RunOptions{Quiet: time.Hour, Interval: time.Millisecond, Timeout: time.Hour}) {
+		t.Error("Expected the run to fail")
+	}
+	if time.Since(started) > time.Minute {
+		t.Error("Expected the run to stop without waiting for the timeout")
+	}
+}
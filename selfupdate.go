@@ -0,0 +1,327 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// releaseRepo is the GitHub repository whose releases `gtw self-update`
+// installs. Each release has an archive per platform (see releaseArchiveName)
+// and a checksums.txt with their SHA-256 sums, as built by .goreleaser.yaml.
+const releaseRepo = "nakamasato/git-tmux-workspace"
+
+const (
+	releaseChecksumsFile   = "checksums.txt"
+	releaseDownloadTimeout = 5 * time.Minute
+)
+
+// releaseAPIURL is the GitHub API that releases are looked up in.
+var releaseAPIURL = "https://api.github.com"
+
+// SelfUpdateOptions holds the settings given to `gtw self-update`.
+type SelfUpdateOptions struct {
+	Check   bool   // Only report whether an update is available
+	Version string // Install this release instead of the latest
+	Yes     bool
+	Force   bool // Reinstall even when the version is not newer
+}
+
+// githubRelease is the part of a GitHub release used by self-update.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func init() {
+	var opts SelfUpdateOptions
+	selfUpdateCmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update gtw to the latest release",
+		Long: `Check the GitHub releases of gtw and replace the running binary with the
+latest one (or --version). The downloaded archive is verified against the
+release's SHA-256 checksums before anything is replaced.
+
+Binaries installed with Homebrew are not touched; use 'brew upgrade gtw'.
+GITHUB_TOKEN (or GH_TOKEN) is used for the GitHub API when set.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !selfUpdate(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	selfUpdateCmd.Flags().BoolVar(&opts.Check, "check", false, "Only check whether a newer release is available")
+	selfUpdateCmd.Flags().StringVar(&opts.Version, "version", "", "Install this release (e.g. v1.2.3) instead of the latest")
+	selfUpdateCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Do not ask for confirmation")
+	selfUpdateCmd.Flags().BoolVar(&opts.Force, "force", false, "Install even if the release is not newer than the running version")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// fetchRelease looks up the latest release, or the one tagged tag.
+func fetchRelease(tag string) (*githubRelease, error) {
+	client := forgeClient{
+		http:       &http.Client{Timeout: forgeRequestTimeout},
+		baseURL:    releaseAPIURL,
+		token:      forgeToken("", "GITHUB_TOKEN", "GH_TOKEN"),
+		authHeader: "Authorization",
+		authPrefix: "Bearer ",
+	}
+	path := "/repos/" + releaseRepo + "/releases/latest"
+	if tag != "" {
+		if !strings.HasPrefix(tag, "v") {
+			tag = "v" + tag
+		}
+		path = "/repos/" + releaseRepo + "/releases/tags/" + tag
+	}
+	var release githubRelease
+	if err := client.do("GET", path, nil, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// assetURL returns the download URL of the release's asset called name.
+func (r *githubRelease) assetURL(name string) (string, error) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no %s", r.TagName, name)
+}
+
+// releaseArchiveName returns the archive of a release for a platform, e.g.
+// gtw_1.2.3_linux_amd64.tar.gz (a .zip on Windows).
+func releaseArchiveName(tag, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("gtw_%s_%s_%s%s", strings.TrimPrefix(tag, "v"), goos, goarch, ext)
+}
+
+// parseChecksums reads a sha256sum-style file: "<hex digest>  <file name>".
+func parseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+		}
+	}
+	return sums
+}
+
+// download fetches url into memory.
+func download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: releaseDownloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyChecksum checks data against the checksum listed for name.
+func verifyChecksum(data []byte, name string, sums map[string]string) error {
+	want, ok := sums[name]
+	if !ok {
+		return fmt.Errorf("%s is not listed in %s", name, releaseChecksumsFile)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return nil
+}
+
+// extractBinary returns the gtw executable from a release archive.
+func extractBinary(archive []byte, name string) ([]byte, error) {
+	binary := "gtw"
+	if strings.HasSuffix(name, ".zip") {
+		binary = "gtw.exe"
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) == binary {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("%s has no %s", name, binary)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s has no %s", name, binary)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binary {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+// installedWithHomebrew reports whether the executable lives in a Homebrew
+// Cellar, which brew has to keep managing.
+func installedWithHomebrew(exe string) bool {
+	return strings.Contains(filepath.ToSlash(exe), "/Cellar/")
+}
+
+// replaceExecutable writes binary next to exe and renames it over exe, so
+// that a failed write leaves the old binary in place.
+func replaceExecutable(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".gtw-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0111); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// A running executable cannot be replaced, but it can be renamed
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exe)
+}
+
+func selfUpdate(opts SelfUpdateOptions) bool {
+	current := buildInfo().Version
+	release, err := fetchRelease(opts.Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not look up the release: %v\n", err)
+		return false
+	}
+
+	newer := compareVersions(release.TagName, current) > 0
+	if opts.Check {
+		if newer {
+			fmt.Printf("Update available: %s → %s (%s)\n", current, release.TagName, release.HTMLURL)
+		} else {
+			fmt.Printf("gtw %s is up to date (latest: %s)\n", current, release.TagName)
+		}
+		return true
+	}
+	if !newer && opts.Version == "" && !opts.Force {
+		fmt.Printf("gtw %s is up to date (latest: %s)\n", current, release.TagName)
+		return true
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot find the gtw executable: %v\n", err)
+		return false
+	}
+	if installedWithHomebrew(exe) {
+		fmt.Fprintln(os.Stderr, "gtw was installed with Homebrew; run 'brew upgrade gtw' instead")
+		return false
+	}
+
+	name := releaseArchiveName(release.TagName, runtime.GOOS, runtime.GOARCH)
+	archiveURL, err := release.assetURL(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (no build for %s/%s?)\n", err, runtime.GOOS, runtime.GOARCH)
+		return false
+	}
+	checksumsURL, err := release.assetURL(releaseChecksumsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v; refusing to install an unverified binary\n", err)
+		return false
+	}
+
+	if !opts.Yes && !confirm(fmt.Sprintf("Replace %s (%s) with %s?", exe, current, release.TagName)) {
+		fmt.Println("Cancelled")
+		return false
+	}
+
+	fmt.Printf("Downloading %s...\n", name)
+	binary, err := downloadVerified(archiveURL, checksumsURL, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if err := replaceExecutable(exe, binary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not replace %s: %v\n", exe, err)
+		if errors.Is(err, os.ErrPermission) {
+			fmt.Fprintln(os.Stderr, "Run it again with permission to write there (e.g. with sudo)")
+		}
+		return false
+	}
+	fmt.Printf("✅ Updated gtw %s → %s\n", current, release.TagName)
+	return true
+}
+
+// downloadVerified downloads the archive and the checksums, checks the
+// archive and returns the binary in it.
+func downloadVerified(archiveURL, checksumsURL, name string) ([]byte, error) {
+	checksums, err := download(checksumsURL)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := download(archiveURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(archive, name, parseChecksums(checksums)); err != nil {
+		return nil, err
+	}
+	return extractBinary(archive, name)
+}
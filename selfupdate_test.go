@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// tarGz builds a release archive holding files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestReleaseArchiveName(t *testing.T) {
+	if got := releaseArchiveName("v1.2.3", "linux", "arm64"); got != "gtw_1.2.3_linux_arm64.tar.gz" {
+		t.Errorf("Unexpected archive name %q", got)
+	}
+	if got := releaseArchiveName("v1.2.3", "windows", "amd64"); got != "gtw_1.2.3_windows_amd64.zip" {
+		t.Errorf("Unexpected archive name %q", got)
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	name := "gtw_1.2.3_linux_amd64.tar.gz"
+	archive := tarGz(t, map[string]string{"README.md": "docs", "gtw": "new binary"})
+	sum := sha256.Sum256(archive)
+	checksums := fmt.Sprintf("%s  %s\n0000  gtw_1.2.3_darwin_arm64.tar.gz\n", hex.EncodeToString(sum[:]), name)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/" + releaseRepo + "/releases/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.2.3", "assets": [{"name": %q, "browser_download_url": "http://%s/archive"}, {"name": "checksums.txt", "browser_download_url": "http://%s/checksums"}]}`, name, r.Host, r.Host)
+		case "/archive":
+			w.Write(archive)
+		case "/tampered":
+			w.Write(append(archive, 0))
+		case "/checksums":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(url string) { releaseAPIURL = url }(releaseAPIURL)
+	releaseAPIURL = server.URL
+
+	release, err := fetchRelease("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archiveURL, err := release.assetURL(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, err := downloadVerified(archiveURL, server.URL+"/checksums", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(binary) != "new binary" {
+		t.Errorf("Expected the gtw binary from the archive, got %q", binary)
+	}
+
+	if _, err := downloadVerified(server.URL+"/tampered", server.URL+"/checksums", name); err == nil {
+		t.Error("Expected a checksum mismatch for a tampered archive")
+	}
+	if _, err := downloadVerified(archiveURL, server.URL+"/checksums", "gtw_1.2.3_linux_386.tar.gz"); err == nil {
+		t.Error("Expected an error for an archive without a checksum")
+	}
+}
+
+func TestInstalledWithHomebrew(t *testing.T) {
+	if !installedWithHomebrew("/opt/homebrew/Cellar/gtw/1.2.3/bin/gtw") {
+		t.Error("Expected a Cellar path to be detected as Homebrew")
+	}
+	if installedWithHomebrew("/usr/local/bin/gtw") {
+		t.Error("Expected /usr/local/bin/gtw not to be detected as Homebrew")
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultTokenFile = ".gtw/api-token"
+	legacyTokenFile  = ".gtw-token" // Older versions kept the token in the project root
+)
+
+// ServeOptions holds the settings given to `gtw serve`.
+type ServeOptions struct {
+	Addr      string
+	Socket    string
+	TokenFile string
+}
+
+// WorkerResponse is the JSON representation of a worker in the API.
+type WorkerResponse struct {
+	Worker
+	Active bool `json:"active"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+	Log   string `json:"log,omitempty"`
+}
+
+func init() {
+	var opts ServeOptions
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local HTTP API for controlling workers",
+		Long: `Serve a local JSON API so editors and dashboards can control workers
+without shelling out to gtw. Every request must carry the token from the token
+file in an "Authorization: Bearer <token>" header; the file is created with a
+random token on first start.
+
+Endpoints:
+  GET    /v1/workers                 List workers
+  POST   /v1/workers                 Create a worker {"id", "tags", "note"}
+  GET    /v1/workers/{id}            Show a worker
+  PATCH  /v1/workers/{id}            Update a worker's {"note", "tags"}
+  DELETE /v1/workers/{id}            Remove a worker (?force=true if dirty)
+  GET    /v1/workers/{id}/capture    Capture pane output (?lines=N)
+  POST   /v1/workers/{id}/send       Type {"text"} into the pane`,
+		Run: func(cmd *cobra.Command, args []string) { serveAPI(opts) },
+	}
+	serveCmd.Flags().StringVar(&opts.Addr, "addr", "127.0.0.1:7420", "TCP address to listen on")
+	serveCmd.Flags().StringVar(&opts.Socket, "socket", "", "Listen on this unix socket instead of --addr")
+	serveCmd.Flags().StringVar(&opts.TokenFile, "token-file", defaultTokenFile, "File holding the API token")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// loadOrCreateToken reads the API token from file, generating a new one
+// readable only by the current user when the file does not exist.
+func loadOrCreateToken(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err == nil {
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", file)
+		}
+		return token, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// moveLegacyToken moves the token of an older version out of the project
+// root, where git would pick it up, so that clients keep working.
+func moveLegacyToken() error {
+	if _, err := os.Stat(defaultTokenFile); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(legacyTokenFile); err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(defaultTokenFile), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyTokenFile, defaultTokenFile); err != nil {
+		return err
+	}
+	fmt.Printf("Moved the API token from %s to %s\n", legacyTokenFile, defaultTokenFile)
+	return nil
+}
+
+func serveAPI(opts ServeOptions) {
+	if opts.TokenFile == defaultTokenFile {
+		if err := moveLegacyToken(); err != nil {
+			fmt.Printf("Warning: Could not move %s: %v\n", legacyTokenFile, err)
+		}
+	}
+	token, err := loadOrCreateToken(opts.TokenFile)
+	if err != nil {
+		fmt.Printf("Error loading API token: %v\n", err)
+		return
+	}
+
+	var listener net.Listener
+	if opts.Socket != "" {
+		os.Remove(opts.Socket)
+		listener, err = net.Listen("unix", opts.Socket)
+		if err == nil {
+			os.Chmod(opts.Socket, 0600)
+		}
+	} else {
+		listener, err = net.Listen("tcp", opts.Addr)
+	}
+	if err != nil {
+		fmt.Printf("Error listening: %v\n", err)
+		return
+	}
+
+	fmt.Printf("gtw API listening on %s (token: %s). Press Ctrl-C to stop.\n", listener.Addr(), opts.TokenFile)
+	if err := http.Serve(listener, newAPIHandler(token)); err != nil {
+		fmt.Printf("Error serving API: %v\n", err)
+	}
+}
+
+// apiServer serializes every request: handlers read-modify-write the config
+// file and share stdout with the commands they call.
+type apiServer struct {
+	mu sync.Mutex
+}
+
+func newAPIHandler(token string) http.Handler {
+	s := &apiServer{}
+	handler := http.NewServeMux()
+	handler.HandleFunc("GET /v1/workers", s.listWorkers)
+	handler.HandleFunc("POST /v1/workers", s.createWorker)
+	handler.HandleFunc("GET /v1/workers/{id}", s.getWorker)
+	handler.HandleFunc("PATCH /v1/workers/{id}", s.updateWorker)
+	handler.HandleFunc("DELETE /v1/workers/{id}", s.deleteWorker)
+	handler.HandleFunc("GET /v1/workers/{id}/capture", s.captureWorker)
+	handler.HandleFunc("POST /v1/workers/{id}/send", s.sendToWorker)
+	return requireToken(token, handler)
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "missing or invalid token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func workerResponse(worker Worker) WorkerResponse {
+	return WorkerResponse{Worker: worker, Active: mux.PaneExists(worker.PaneID)}
+}
+
+// lookupWorker loads the config and finds the worker named in the path,
+// writing an error response when either fails.
+func (s *apiServer) lookupWorker(w http.ResponseWriter, r *http.Request) (*Config, int, bool) {
+	config, err := loadConfig()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return nil, -1, false
+	}
+	index := findWorkerIndex(config, r.PathValue("id"))
+	if index == -1 {
+		writeJSON(w, http.StatusNotFound, apiError{Error: fmt.Sprintf("worker '%s' not found", r.PathValue("id"))})
+		return nil, -1, false
+	}
+	return config, index, true
+}
+
+func (s *apiServer) listWorkers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, err := loadConfig()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+
+	tags := r.URL.Query()["tag"]
+	workers := []WorkerResponse{}
+	for _, worker := range config.Workers {
+		if hasAllTags(worker, tags) {
+			workers = append(workers, workerResponse(worker))
+		}
+	}
+	writeJSON(w, http.StatusOK, workers)
+}
+
+func (s *apiServer) getWorker(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, index, ok := s.lookupWorker(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, workerResponse(config.Workers[index]))
+}
+
+func (s *apiServer) createWorker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID   string   `json:"id"`
+		Tags []string `json:"tags"`
+		Note string   `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: `expected a JSON body with a non-empty "id"`})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, err := loadConfig()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	if findWorkerIndex(config, req.ID) != -1 {
+		writeJSON(w, http.StatusConflict, apiError{Error: fmt.Sprintf("worker '%s' already exists", req.ID)})
+		return
+	}
+
+	log := captureStdout(func() { addWorker(req.ID, AddOptions{Tags: req.Tags, Note: req.Note}) })
+
+	config, err = loadConfig()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error(), Log: log})
+		return
+	}
+	index := findWorkerIndex(config, req.ID)
+	if index == -1 {
+		writeJSON(w, http.StatusUnprocessableEntity, apiError{Error: fmt.Sprintf("could not create worker '%s'", req.ID), Log: log})
+		return
+	}
+	writeJSON(w, http.StatusCreated, workerResponse(config.Workers[index]))
+}
+
+func (s *apiServer) updateWorker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Note *string   `json:"note"`
+		Tags *[]string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, index, ok := s.lookupWorker(w, r)
+	if !ok {
+		return
+	}
+	if req.Note != nil {
+		config.Workers[index].Note = strings.TrimSpace(*req.Note)
+	}
+	if req.Tags != nil {
+		config.Workers[index].Tags = normalizeTags(*req.Tags)
+	}
+	if err := saveConfig(config); err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, workerResponse(config.Workers[index]))
+}
+
+func (s *apiServer) deleteWorker(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, index, ok := s.lookupWorker(w, r)
+	if !ok {
+		return
+	}
+
+	// The server cannot prompt, so dirty worktrees need an explicit ?force=true
+	id := r.PathValue("id")
+	force := r.URL.Query().Get("force") == "true"
+	if !force {
+		if dirty, _ := worktreeDirty(config.Workers[index].WorktreePath); dirty {
+			writeJSON(w, http.StatusConflict, apiError{Error: fmt.Sprintf("worker '%s' has uncommitted changes; retry with ?force=true", id)})
+			return
+		}
+	}
+
+	log := captureStdout(func() { removeWorker(id, RemoveOptions{Yes: true, Force: force}) })
+
+	config, err := loadConfig()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error(), Log: log})
+		return
+	}
+	if findWorkerIndex(config, id) != -1 {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: fmt.Sprintf("could not remove worker '%s'", id), Log: log})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *apiServer) captureWorker(w http.ResponseWriter, r *http.Request) {
+	lines := 50
+	if value := r.URL.Query().Get("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, apiError{Error: "lines must be a positive integer"})
+			return
+		}
+		lines = n
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, index, ok := s.lookupWorker(w, r)
+	if !ok {
+		return
+	}
+	content, err := capturePane(config.Workers[index].PaneID, lines)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"content": content})
+}
+
+func (s *apiServer) sendToWorker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, index, ok := s.lookupWorker(w, r)
+	if !ok {
+		return
+	}
+	if err := sendToPane(config.Workers[index].PaneID, req.Text); err != nil {
+		writeJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// captureStdout runs fn and returns everything it printed, so the progress
+// messages of CLI commands can be passed back to API clients.
+func captureStdout(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	os.Stdout = stdout
+	output := <-done
+	r.Close()
+
+	// Keep the server log complete
+	fmt.Print(output)
+	return output
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeMultiplexer treats the panes in alive as running and records sent keys.
+type fakeMultiplexer struct {
+	TmuxMultiplexer
+	alive map[string]bool
+	sent  []string
+}
+
+func (f *fakeMultiplexer) PaneExists(paneID string) bool { return f.alive[paneID] }
+
+func (f *fakeMultiplexer) SendKeys(paneID, text string) error {
+	f.sent = append(f.sent, paneID+" "+text)
+	return nil
+}
+
+func setupAPITest(t *testing.T) (http.Handler, *fakeMultiplexer) {
+	t.Chdir(t.TempDir())
+
+	config := &Config{Workers: []Worker{
+		{ID: "a", PaneID: "%1", Tags: []string{"backend"}},
+		{ID: "b", PaneID: "%2"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeMultiplexer{alive: map[string]bool{"%1": true}}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	return newAPIHandler("secret"), fake
+}
+
+func apiRequest(handler http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	handler, _ := setupAPITest(t)
+
+	for _, token := range []string{"", "wrong"} {
+		if rec := apiRequest(handler, "GET", "/v1/workers", "", token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for token %q, got %d", token, rec.Code)
+		}
+	}
+}
+
+func TestAPIListAndGetWorkers(t *testing.T) {
+	handler, _ := setupAPITest(t)
+
+	rec := apiRequest(handler, "GET", "/v1/workers?tag=backend", "", "secret")
+	var workers []WorkerResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &workers); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(workers) != 1 || workers[0].ID != "a" || !workers[0].Active {
+		t.Errorf("Unexpected workers: %+v", workers)
+	}
+
+	rec = apiRequest(handler, "GET", "/v1/workers/b", "", "secret")
+	var worker WorkerResponse
+	json.Unmarshal(rec.Body.Bytes(), &worker)
+	if rec.Code != http.StatusOK || worker.ID != "b" || worker.Active {
+		t.Errorf("Unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := apiRequest(handler, "GET", "/v1/workers/missing", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
+
+func TestAPIUpdateWorker(t *testing.T) {
+	handler, _ := setupAPITest(t)
+
+	rec := apiRequest(handler, "PATCH", "/v1/workers/b", `{"note": "reviewing", "tags": ["urgent", "urgent"]}`, "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := config.Workers[findWorkerIndex(config, "b")]
+	if worker.Note != "reviewing" || len(worker.Tags) != 1 || worker.Tags[0] != "urgent" {
+		t.Errorf("Worker was not updated: %+v", worker)
+	}
+}
+
+func TestAPISendToWorker(t *testing.T) {
+	handler, fake := setupAPITest(t)
+
+	rec := apiRequest(handler, "POST", "/v1/workers/a/send", `{"text": "make test"}`, "secret")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(fake.sent) != 1 || fake.sent[0] != "%1 make test" {
+		t.Errorf("Unexpected keys sent: %v", fake.sent)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".gtw", "api-token") // Its directory is created
+
+	token, err := loadOrCreateToken(file)
+	if err != nil || len(token) != 64 {
+		t.Fatalf("loadOrCreateToken() = %q, %v", token, err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected token file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	again, err := loadOrCreateToken(file)
+	if err != nil || again != token {
+		t.Errorf("Expected the stored token to be reused, got %q, %v", again, err)
+	}
+}
+
+func TestMoveLegacyToken(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(legacyTokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := moveLegacyToken(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacyTokenFile); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be moved away, got %v", legacyTokenFile, err)
+	}
+	if token, err := loadOrCreateToken(defaultTokenFile); err != nil || token != "secret" {
+		t.Errorf("Expected the old token to be kept, got %q, %v", token, err)
+	}
+
+	// A token already in place wins over a stray old one
+	os.WriteFile(legacyTokenFile, []byte("stale\n"), 0600)
+	if err := moveLegacyToken(); err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := loadOrCreateToken(defaultTokenFile); token != "secret" {
+		t.Errorf("Expected the current token to stay, got %q", token)
+	}
+}
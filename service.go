@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Service managers `gtw daemon install` writes a service for
+const (
+	ServiceSystemd = "systemd" // User unit in ~/.config/systemd/user (Linux)
+	ServiceLaunchd = "launchd" // Launch agent in ~/Library/LaunchAgents (macOS)
+)
+
+const launchdLabelPrefix = "com.github.nakamasato.gtw."
+
+// daemonLogFile is where the launchd agent writes the daemon's output;
+// systemd sends it to the journal.
+const daemonLogFile = ".gtw/daemon.log"
+
+// ServiceOptions holds the settings given to `gtw daemon install` and
+// `gtw daemon uninstall`.
+type ServiceOptions struct {
+	Manager string // systemd or launchd (default: from the OS)
+	Session bool   // Recreate the session when the daemon starts
+	Print   bool   // Only print the service file
+	NoStart bool   // Write the file without enabling and starting it
+}
+
+// DaemonService is what a service file starts: gtw daemon in the project.
+type DaemonService struct {
+	Name       string // Unit name or launchd label
+	Executable string
+	Args       []string // Arguments after the executable
+	Dir        string   // Project directory
+	Path       string   // PATH the daemon finds tmux and git with
+	LogFile    string   // launchd only
+}
+
+// daemonInstallCommand and daemonUninstallCommand are added to `gtw daemon`
+// in daemon.go.
+func daemonInstallCommand() *cobra.Command {
+	var opts ServiceOptions
+	cmd := &cobra.Command{
+		Use:   "install [-- daemon flags...]",
+		Short: "Run the daemon of this project as a user service across logins and reboots",
+		Long: `Write a user-level service that keeps 'gtw daemon' running for this project:
+a systemd unit in ~/.config/systemd/user on Linux, or a launch agent in
+~/Library/LaunchAgents on macOS. It is enabled and started unless
+--no-start is given. Flags after -- are passed to the daemon, e.g.
+
+  gtw daemon install --session -- --interval 10s --gc-remove
+
+With --session the daemon recreates the session and worker panes when it
+starts and finds them gone, e.g. after a reboot.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !installDaemonService(opts, args) {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Manager, "manager", "", "Service manager: systemd or launchd (default: from the OS)")
+	cmd.Flags().BoolVar(&opts.Session, "session", false, "Recreate the session and worker panes when the daemon starts")
+	cmd.Flags().BoolVar(&opts.Print, "print", false, "Print the service file instead of installing it")
+	cmd.Flags().BoolVar(&opts.NoStart, "no-start", false, "Write the service file without enabling and starting it")
+	return cmd
+}
+
+func daemonUninstallCommand() *cobra.Command {
+	var opts ServiceOptions
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop and remove the daemon service of this project",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !uninstallDaemonService(opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Manager, "manager", "", "Service manager: systemd or launchd (default: from the OS)")
+	return cmd
+}
+
+// serviceManager returns the service manager to use on this OS.
+func serviceManager(name string) (string, error) {
+	switch name {
+	case ServiceSystemd, ServiceLaunchd:
+		return name, nil
+	case "":
+		switch runtime.GOOS {
+		case "linux":
+			return ServiceSystemd, nil
+		case "darwin":
+			return ServiceLaunchd, nil
+		}
+		return "", fmt.Errorf("no service manager is supported on %s", runtime.GOOS)
+	}
+	return "", fmt.Errorf("unknown service manager '%s' (use %s or %s)", name, ServiceSystemd, ServiceLaunchd)
+}
+
+var serviceNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// serviceName names the service of the project (and workspace), so that
+// every project gets its own daemon.
+func serviceName(manager, session string) string {
+	slug := strings.Trim(serviceNameUnsafe.ReplaceAllString(session, "-"), "-")
+	if slug == "" {
+		slug = "project"
+	}
+	if manager == ServiceLaunchd {
+		return launchdLabelPrefix + slug
+	}
+	return "gtw-daemon-" + slug + ".service"
+}
+
+// serviceFile returns where the service file of name is written.
+func serviceFile(manager, name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if manager == ServiceLaunchd {
+		return filepath.Join(home, "Library", "LaunchAgents", name+".plist"), nil
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "systemd", "user", name), nil
+}
+
+// systemdQuote quotes an argument of ExecStart when it needs it.
+func systemdQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;$%") {
+		return arg
+	}
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(arg)
+	return `"` + arg + `"`
+}
+
+// systemdSpecifiers escapes the '%' specifiers in a value that systemd
+// takes as it is, such as WorkingDirectory, which is never unquoted.
+func systemdSpecifiers(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// renderSystemdUnit returns the user unit running the daemon. KillMode
+// process stops only the daemon, so that a tmux server it started survives.
+func renderSystemdUnit(s DaemonService) string {
+	execStart := []string{systemdQuote(s.Executable)}
+	for _, arg := range s.Args {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=gtw daemon for %s\n", systemdSpecifiers(s.Dir))
+	fmt.Fprintf(&b, "\n[Service]\n")
+	fmt.Fprintf(&b, "Type=simple\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdSpecifiers(s.Dir))
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(execStart, " "))
+	fmt.Fprintf(&b, "Environment=%s\n", systemdQuote("PATH="+s.Path))
+	fmt.Fprintf(&b, "Environment=%s\n", nonInteractiveEnv+"=1")
+	fmt.Fprintf(&b, "Restart=on-failure\n")
+	fmt.Fprintf(&b, "RestartSec=10\n")
+	fmt.Fprintf(&b, "KillMode=process\n")
+	fmt.Fprintf(&b, "\n[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=default.target\n")
+	return b.String()
+}
+
+// xmlEscape escapes text for a plist string.
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// renderLaunchdPlist returns the launch agent running the daemon at login
+// and keeping it alive.
+func renderLaunchdPlist(s DaemonService) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n", xmlEscape(s.Name))
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	for _, arg := range append([]string{s.Executable}, s.Args...) {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("  </array>\n")
+	fmt.Fprintf(&b, "  <key>WorkingDirectory</key>\n  <string>%s</string>\n", xmlEscape(s.Dir))
+	b.WriteString("  <key>EnvironmentVariables</key>\n  <dict>\n")
+	fmt.Fprintf(&b, "    <key>PATH</key>\n    <string>%s</string>\n", xmlEscape(s.Path))
+	fmt.Fprintf(&b, "    <key>%s</key>\n    <string>1</string>\n", nonInteractiveEnv)
+	b.WriteString("  </dict>\n")
+	b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+	b.WriteString("  <key>KeepAlive</key>\n  <dict>\n    <key>SuccessfulExit</key>\n    <false/>\n  </dict>\n")
+	b.WriteString("  <key>AbandonProcessGroup</key>\n  <true/>\n")
+	fmt.Fprintf(&b, "  <key>StandardOutPath</key>\n  <string>%s</string>\n", xmlEscape(s.LogFile))
+	fmt.Fprintf(&b, "  <key>StandardErrorPath</key>\n  <string>%s</string>\n", xmlEscape(s.LogFile))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// newDaemonService describes the daemon of the project in the current
+// directory.
+func newDaemonService(manager string, opts ServiceOptions, daemonArgs []string) (DaemonService, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return DaemonService{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return DaemonService{}, err
+	}
+	if config, err := loadConfig(); err == nil && config.ProjectPath != "" {
+		dir = config.ProjectPath
+	}
+
+	args := []string{"daemon"}
+	if workspace != "" {
+		args = append(args, "--workspace", workspace)
+	}
+	if opts.Session {
+		args = append(args, "--restore-session")
+	}
+	args = append(args, daemonArgs...)
+
+	return DaemonService{
+		Name:       serviceName(manager, getSessionName()),
+		Executable: executable,
+		Args:       args,
+		Dir:        dir,
+		Path:       os.Getenv("PATH"),
+		LogFile:    filepath.Join(dir, daemonLogFile),
+	}, nil
+}
+
+func installDaemonService(opts ServiceOptions, daemonArgs []string) bool {
+	manager, err := serviceManager(opts.Manager)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	service, err := newDaemonService(manager, opts, daemonArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	content := renderSystemdUnit(service)
+	if manager == ServiceLaunchd {
+		content = renderLaunchdPlist(service)
+	}
+	if opts.Print {
+		fmt.Print(content)
+		return true
+	}
+
+	file, err := serviceFile(manager, service.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if manager == ServiceLaunchd {
+		os.MkdirAll(filepath.Dir(service.LogFile), 0755)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", file, err)
+		return false
+	}
+	fmt.Printf("✅ Wrote %s\n", file)
+	if opts.NoStart {
+		return true
+	}
+
+	var steps [][]string
+	if manager == ServiceLaunchd {
+		// Unloading first makes a reinstall pick up the new file
+		newCommand("launchctl", "unload", file).Run()
+		steps = [][]string{{"launchctl", "load", "-w", file}}
+	} else {
+		steps = [][]string{
+			{"systemctl", "--user", "daemon-reload"},
+			{"systemctl", "--user", "enable", service.Name},
+			{"systemctl", "--user", "restart", service.Name},
+		}
+	}
+	for _, step := range steps {
+		if output, err := newCommand(step[0], step[1:]...).CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n%s", strings.Join(step, " "), err, output)
+			return false
+		}
+	}
+
+	fmt.Printf("✅ Started the gtw daemon service %s\n", service.Name)
+	if manager == ServiceSystemd {
+		fmt.Printf("Logs: journalctl --user -u %s -f\n", service.Name)
+		fmt.Println("To keep it running while you are logged out: loginctl enable-linger $USER")
+	} else {
+		fmt.Printf("Logs: %s\n", service.LogFile)
+	}
+	return true
+}
+
+func uninstallDaemonService(opts ServiceOptions) bool {
+	manager, err := serviceManager(opts.Manager)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	name := serviceName(manager, getSessionName())
+	file, err := serviceFile(manager, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		fmt.Printf("No daemon service is installed for this project (%s)\n", file)
+		return true
+	}
+
+	if manager == ServiceLaunchd {
+		newCommand("launchctl", "unload", "-w", file).Run()
+	} else {
+		newCommand("systemctl", "--user", "disable", "--now", name).Run()
+	}
+	if err := os.Remove(file); err != nil {
+		fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", file, err)
+		return false
+	}
+	if manager == ServiceSystemd {
+		newCommand("systemctl", "--user", "daemon-reload").Run()
+	}
+	fmt.Printf("✅ Removed the gtw daemon service %s\n", name)
+	return true
+}
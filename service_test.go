@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestServiceName(t *testing.T) {
+	if got := serviceName(ServiceSystemd, "my app-review"); got != "gtw-daemon-my-app-review.service" {
+		t.Errorf("Unexpected unit name %q", got)
+	}
+	if got := serviceName(ServiceLaunchd, "api"); got != "com.github.nakamasato.gtw.api" {
+		t.Errorf("Unexpected label %q", got)
+	}
+	if _, err := serviceManager("upstart"); err == nil {
+		t.Error("Expected an error for an unknown service manager")
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"/usr/local/bin/gtw": "/usr/local/bin/gtw",
+		"/home/me/my repo":   `"/home/me/my repo"`,
+		`50%`:                `"50%%"`,
+		`say "hi" $HOME`:     `"say \"hi\" $$HOME"`,
+	}
+	for input, want := range tests {
+		if got := systemdQuote(input); got != want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestRenderDaemonService(t *testing.T) {
+	service := DaemonService{
+		Name:       "gtw-daemon-app.service",
+		Executable: "/usr/local/bin/gtw",
+		Args:       []string{"daemon", "--restore-session", "--interval", "10s"},
+		Dir:        "/home/me/src/R&D app 100%",
+		Path:       "/usr/bin:/bin",
+		LogFile:    "/home/me/src/R&D app/.gtw/daemon.log",
+	}
+
+	unit := renderSystemdUnit(service)
+	for _, want := range []string{
+		"WorkingDirectory=/home/me/src/R&D app 100%%\n",
+		"ExecStart=/usr/local/bin/gtw daemon --restore-session --interval 10s\n",
+		"Environment=PATH=/usr/bin:/bin\n",
+		"KillMode=process\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("Expected the unit to contain %q:\n%s", want, unit)
+		}
+	}
+
+	service.Name = "com.github.nakamasato.gtw.app"
+	plist := renderLaunchdPlist(service)
+	if err := xml.Unmarshal([]byte(plist), new(struct{})); err != nil {
+		t.Fatalf("Invalid plist XML: %v\n%s", err, plist)
+	}
+	for _, want := range []string{
+		"<string>com.github.nakamasato.gtw.app</string>",
+		"<string>--restore-session</string>",
+		"<string>/home/me/src/R&amp;D app 100%</string>",
+		"<key>RunAtLoad</key>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("Expected the plist to contain %q", want)
+		}
+	}
+}
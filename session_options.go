@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tmuxWindowOption is a window option gtw sets on every window of the
+// session.
+type tmuxWindowOption struct {
+	Name  string
+	Value string
+}
+
+// tmuxWindowOptions keep pane titles visible and stop programs running in
+// the panes from overwriting them; check and repair match panes by title.
+var tmuxWindowOptions = []tmuxWindowOption{
+	{"pane-border-status", "top"},
+	{"pane-border-format", " #{pane_index}: #{pane_title} "},
+	{"allow-rename", "off"},
+	{"automatic-rename", "off"},
+}
+
+// applyTmuxOptions sets tmuxWindowOptions on every window of the session.
+func applyTmuxOptions(sessionName string) error {
+	output, err := tmuxCommand("list-windows", "-t", sessionName, "-F", "#{window_id}").Output()
+	if err != nil {
+		return err
+	}
+
+	for _, windowID := range strings.Fields(string(output)) {
+		for _, option := range tmuxWindowOptions {
+			if err := tmuxCommand("set-option", "-w", "-t", windowID, option.Name, option.Value).Run(); err != nil {
+				return fmt.Errorf("setting %s: %v", option.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configureTmuxSession names the first window and, unless no_tmux_options
+// is set, applies the tmux options gtw relies on. It is a no-op for other
+// multiplexers.
+func configureTmuxSession(sessionName string, config *Config) {
+	if mux.Name() != "tmux" {
+		return
+	}
+
+	if config.WindowName != "" {
+		if err := tmuxCommand("rename-window", "-t", sessionName+":0", config.WindowName).Run(); err != nil {
+			fmt.Printf("Warning: Could not rename window: %v\n", err)
+		}
+	}
+
+	if config.NoTmuxOptions {
+		return
+	}
+	if err := applyTmuxOptions(sessionName); err != nil {
+		fmt.Printf("Warning: Could not apply tmux options: %v\n", err)
+	}
+}
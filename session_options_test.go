@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// attachRecorder is the tmux backend, except that attaching is only
+// recorded.
+type attachRecorder struct {
+	TmuxMultiplexer
+	attached []string
+}
+
+func (a *attachRecorder) Attach(session string) error {
+	a.attached = append(a.attached, session)
+	return nil
+}
+
+func TestInitSetsTmuxOptions(t *testing.T) {
+	for _, tool := range []string{"tmux", "git"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts InitOptions
+		want map[string]string // Window option values, "" when not set on the window
+	}{
+		{"options", InitOptions{Attach: true, WindowName: "agents"}, map[string]string{
+			"pane-border-status": "top",
+			"pane-border-format": " #{pane_index}: #{pane_title} ",
+			"allow-rename":       "off",
+			"automatic-rename":   "off",
+		}},
+		{"no tmux options", InitOptions{Attach: true, WindowName: "agents", NoTmuxOptions: true}, map[string]string{
+			"pane-border-status": "",
+			"allow-rename":       "",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A server of our own, without the user's tmux.conf
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("TMUX_TMPDIR", t.TempDir())
+			t.Setenv("TMUX", "")
+			tmux := func(args ...string) string {
+				output, err := exec.Command("tmux", args...).CombinedOutput()
+				if err != nil {
+					t.Fatalf("tmux %v: %v\n%s", args, err, output)
+				}
+				return strings.TrimSuffix(string(output), "\n")
+			}
+			t.Cleanup(func() { exec.Command("tmux", "kill-server").Run() })
+
+			dir := filepath.Join(t.TempDir(), "demo")
+			os.Mkdir(dir, 0755)
+			t.Chdir(dir)
+			for _, args := range [][]string{{"init", "-q", "-b", "main"}, {"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"}} {
+				if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %v\n%s", args, err, output)
+				}
+			}
+			recorder := &attachRecorder{}
+			useMultiplexer(t, recorder)
+
+			initSession(tt.opts)
+
+			if len(recorder.attached) != 1 || recorder.attached[0] != "demo" {
+				t.Errorf("Expected to attach to demo, got %v", recorder.attached)
+			}
+			if name := tmux("display-message", "-p", "-t", "demo:0", "#{window_name}"); name != "agents" {
+				t.Errorf("Expected the window to be named agents, got %q", name)
+			}
+			for option, want := range tt.want {
+				if got := tmux("show-options", "-w", "-v", "-t", "demo:0", option); got != want {
+					t.Errorf("Expected %s to be %q, got %q", option, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Actions `gtw daemon` takes when the session was idle for session_idle_ttl
+const (
+	SessionIdleDestroy = "destroy" // Kill the session, keeping the workers to recreate with `gtw attach --recreate` (default)
+	SessionIdleArchive = "archive" // Archive every worker without uncommitted changes, then destroy
+)
+
+// wakeThreshold is how much longer than the poll interval a tick may take
+// before the daemon assumes that the machine was asleep.
+const wakeThreshold = time.Minute
+
+// sessionIdleTTL returns session_idle_ttl; 0 means the session is kept.
+func sessionIdleTTL(config *Config) (time.Duration, error) {
+	if config.SessionIdleTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(config.SessionIdleTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid session_idle_ttl %q: %v", config.SessionIdleTTL, err)
+	}
+	return ttl, nil
+}
+
+// sleptFor returns how long the machine was suspended between two ticks of
+// the given interval, or 0. Both times must be wall clock readings: the
+// monotonic clock stops during suspend on Linux.
+func sleptFor(previous, now time.Time, interval time.Duration) time.Duration {
+	if previous.IsZero() {
+		return 0
+	}
+	gap := now.Sub(previous) - interval
+	if gap <= wakeThreshold {
+		return 0
+	}
+	return gap
+}
+
+// SessionWatcher notices from the daemon that the machine woke up, and that
+// no pane of the session printed anything for session_idle_ttl.
+type SessionWatcher struct {
+	contents     map[string]string
+	lastActivity time.Time
+	lastTick     time.Time
+}
+
+func NewSessionWatcher() *SessionWatcher {
+	return &SessionWatcher{contents: make(map[string]string)}
+}
+
+// Tick records a poll at now and returns how long the machine slept since
+// the previous one. Time asleep does not count towards session_idle_ttl.
+func (w *SessionWatcher) Tick(now time.Time, interval time.Duration) time.Duration {
+	now = now.Round(0)
+	slept := sleptFor(w.lastTick, now, interval)
+	w.lastTick = now
+	if slept > 0 && !w.lastActivity.IsZero() {
+		w.lastActivity = w.lastActivity.Add(slept)
+	}
+	return slept
+}
+
+// observe samples the workers' panes and returns how long none of them
+// changed.
+func (w *SessionWatcher) observe(config *Config, now time.Time) time.Duration {
+	now = now.Round(0)
+	if w.lastActivity.IsZero() {
+		w.lastActivity = now
+	}
+	for _, worker := range config.Workers {
+		if worker.Status == WorkerDetached || worker.PaneID == "" {
+			continue
+		}
+		output, err := capturePane(worker.PaneID, 50)
+		if err != nil {
+			continue
+		}
+		if previous, seen := w.contents[worker.ID]; !seen || previous != output {
+			w.contents[worker.ID] = output
+			w.lastActivity = now
+		}
+	}
+	return now.Sub(w.lastActivity)
+}
+
+// Run closes the session once it was idle for session_idle_ttl. It reports
+// whether the session was closed.
+func (w *SessionWatcher) Run(config *Config, now time.Time) bool {
+	ttl, err := sessionIdleTTL(config)
+	if err != nil || ttl <= 0 {
+		return false
+	}
+	idle := w.observe(config, now)
+	if idle < ttl {
+		return false
+	}
+	sessionName := getSessionName()
+	if sessionName == "" || !mux.HasSession(sessionName) {
+		return false
+	}
+
+	fmt.Printf("Session '%s' had no pane activity for %s\n", sessionName, idle.Round(time.Second))
+	if config.SessionIdleAction == SessionIdleArchive {
+		for _, worker := range config.Workers {
+			// Refuses workers with uncommitted changes; they stay detached
+			archiveWorker(worker.ID, false)
+		}
+	}
+	destroySession(RemoveOptions{Yes: true, Force: true, KeepWorktrees: true})
+	w.contents = make(map[string]string)
+	w.lastActivity = time.Time{}
+	return true
+}
+
+// repairAfterWake re-resolves every pane of the session after the machine
+// woke up, and recreates the panes of workers that lost theirs.
+func repairAfterWake() {
+	if mux.Name() != "tmux" {
+		return
+	}
+	sessionName := getSessionName()
+	if sessionName == "" || !mux.HasSession(sessionName) {
+		return
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return
+	}
+	if revalidatePanes(config) {
+		if err := saveConfig(config); err != nil {
+			fmt.Printf("Warning: Failed to save config: %v\n", err)
+			return
+		}
+	}
+	inconsistencies, err := findInconsistencies(sessionName, config)
+	if err != nil {
+		fmt.Printf("Warning: Could not list panes: %v\n", err)
+		return
+	}
+	if missing, _ := filterInconsistencies(inconsistencies, []string{RepairMissingPanes, RepairMissingWorktrees}, ""); len(missing) > 0 {
+		repairInconsistencies(RepairOptions{Only: []string{RepairMissingPanes, RepairMissingWorktrees}})
+	}
+}
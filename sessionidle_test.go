@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// captureMultiplexer returns the text in output as the content of each pane.
+type captureMultiplexer struct {
+	TmuxMultiplexer
+	output map[string]string
+}
+
+func (c *captureMultiplexer) Capture(paneID string, lines int) (string, error) {
+	return c.output[paneID], nil
+}
+
+func TestSleptFor(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		previous time.Time
+		now      time.Time
+		want     time.Duration
+	}{
+		{"first tick", time.Time{}, start, 0},
+		{"on time", start, start.Add(5 * time.Second), 0},
+		{"slow tick", start, start.Add(30 * time.Second), 0},
+		{"overnight", start, start.Add(8*time.Hour + 5*time.Second), 8 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := sleptFor(tt.previous, tt.now, 5*time.Second); got != tt.want {
+			t.Errorf("%s: sleptFor() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSessionIdleTTL(t *testing.T) {
+	if ttl, err := sessionIdleTTL(&Config{SessionIdleTTL: "8h"}); err != nil || ttl != 8*time.Hour {
+		t.Errorf("sessionIdleTTL() = %v, %v", ttl, err)
+	}
+	if ttl, err := sessionIdleTTL(&Config{}); err != nil || ttl != 0 {
+		t.Errorf("Expected no TTL, got %v, %v", ttl, err)
+	}
+	if _, err := sessionIdleTTL(&Config{SessionIdleTTL: "overnight"}); err == nil {
+		t.Error("Expected error for invalid session_idle_ttl")
+	}
+}
+
+func TestSessionWatcherIdle(t *testing.T) {
+	fake := &captureMultiplexer{output: map[string]string{"%1": "$ ", "%2": "> "}}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	config := &Config{Workers: []Worker{
+		{ID: "a", PaneID: "%1"},
+		{ID: "b", PaneID: "%2"},
+		{ID: "c", Status: WorkerDetached},
+	}}
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	w := NewSessionWatcher()
+
+	if idle := w.observe(config, start); idle != 0 {
+		t.Errorf("Expected a fresh session, idle for %v", idle)
+	}
+	if idle := w.observe(config, start.Add(time.Hour)); idle != time.Hour {
+		t.Errorf("Expected 1h idle, got %v", idle)
+	}
+
+	fake.output["%2"] = "> working"
+	if idle := w.observe(config, start.Add(2*time.Hour)); idle != 0 {
+		t.Errorf("Expected output to reset the idle time, got %v", idle)
+	}
+
+	// Sleeping overnight does not count as idle time
+	w.Tick(start.Add(2*time.Hour), 5*time.Second)
+	if slept := w.Tick(start.Add(10*time.Hour+5*time.Second), 5*time.Second); slept != 8*time.Hour {
+		t.Errorf("Expected 8h asleep, got %v", slept)
+	}
+	if idle := w.observe(config, start.Add(10*time.Hour+5*time.Second)); idle != 5*time.Second {
+		t.Errorf("Expected the sleep to be skipped, idle for %v", idle)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// gtwcd for bash and zsh. Without an argument the worker is picked; the
+// picker draws on stderr, so it stays interactive inside $(...).
+const posixShellInit = `# gtw shell integration
+gtwcd() {
+  local id dir
+  if [ $# -gt 0 ]; then
+    id=$1
+  else
+    id=$(GTW_NONINTERACTIVE=0 command gtw pick) || return
+  fi
+  dir=$(command gtw path "$id") || return
+  cd "$dir"
+}
+`
+
+const bashShellInit = posixShellInit + `_gtwcd() {
+  COMPREPLY=($(compgen -W "root $(command gtw list --format '{{.ID}}' 2>/dev/null)" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -F _gtwcd gtwcd
+`
+
+const zshShellInit = posixShellInit + `_gtwcd() {
+  compadd root ${(f)"$(command gtw list --format '{{.ID}}' 2>/dev/null)"}
+}
+(( $+functions[compdef] )) && compdef _gtwcd gtwcd
+`
+
+const fishShellInit = `# gtw shell integration
+function gtwcd --description 'cd into the worktree of a gtw worker'
+    set -l id $argv[1]
+    if test -z "$id"
+        set id (env GTW_NONINTERACTIVE=0 gtw pick); or return
+    end
+    set -l dir (command gtw path $id); or return
+    cd $dir
+end
+complete -c gtwcd -f -a 'root (command gtw list --format "{{.ID}}" 2>/dev/null)'
+`
+
+var shellInits = map[string]string{
+	"bash": bashShellInit,
+	"zsh":  zshShellInit,
+	"fish": fishShellInit,
+}
+
+func init() {
+	pathCmd := &cobra.Command{
+		Use:   "path <worker-id>",
+		Short: "Print the absolute path of a worker's worktree",
+		Long: `Print the absolute path of a worker's worktree, or of the project root for
+'root', e.g. for 'cd $(gtw path issue-123)'. See 'gtw shell-init' for a gtwcd
+function that does this for a picked worker.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !printWorkerPath(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+	rootCmd.AddCommand(pathCmd)
+
+	shellInitCmd := &cobra.Command{
+		Use:   "shell-init [bash|zsh|fish]",
+		Short: "Print shell functions for moving between worktrees",
+		Long: `Print a gtwcd function for your shell (default: from $SHELL) that cd's into
+the worktree of a worker, picked from a list when no ID is given, with
+completion of the worker IDs. Add it to your shell's startup file:
+
+  bash:  eval "$(gtw shell-init bash)"   # ~/.bashrc
+  zsh:   eval "$(gtw shell-init zsh)"    # ~/.zshrc
+  fish:  gtw shell-init fish | source    # ~/.config/fish/config.fish`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Run: func(cmd *cobra.Command, args []string) {
+			shell := filepath.Base(os.Getenv("SHELL"))
+			if len(args) == 1 {
+				shell = args[0]
+			}
+			script, ok := shellInits[shell]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: Unsupported shell '%s' (use bash, zsh or fish)\n", shell)
+				os.Exit(1)
+			}
+			fmt.Print(script)
+		},
+	}
+	rootCmd.AddCommand(shellInitCmd)
+}
+
+func printWorkerPath(id string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	dir, err := locationDir(config, id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	fmt.Println(dir)
+	return true
+}
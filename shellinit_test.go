@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocationDir(t *testing.T) {
+	project := t.TempDir()
+	worktree := filepath.Join(project, "worktree", "api")
+	os.MkdirAll(worktree, 0755)
+	config := &Config{ProjectPath: project, Workers: []Worker{
+		{ID: "api", WorktreePath: worktree},
+		{ID: "gone", WorktreePath: filepath.Join(project, "worktree", "gone")},
+	}}
+
+	if dir, err := locationDir(config, "api"); err != nil || dir != worktree {
+		t.Errorf("Expected %s, got %s (%v)", worktree, dir, err)
+	}
+	if dir, err := locationDir(config, rootLocation); err != nil || dir != project {
+		t.Errorf("Expected the project root %s, got %s (%v)", project, dir, err)
+	}
+	for _, id := range []string{"gone", "missing"} {
+		if _, err := locationDir(config, id); err == nil {
+			t.Errorf("Expected an error for worker '%s'", id)
+		}
+	}
+}
+
+func TestBashShellInit(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	// A stand-in gtw: pick chooses api, path prints a directory per worker
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.MkdirAll(bin, 0755)
+	fake := `#!/bin/sh
+case "$1" in
+pick) [ "$GTW_NONINTERACTIVE" = 0 ] && echo api ;;
+path) [ -d "` + dir + `/$2" ] && echo "` + dir + `/$2" || exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "gtw"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(script string) (string, error) {
+		cmd := exec.Command("bash", "-c", bashShellInit+script)
+		cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		output, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(output)), err
+	}
+	if output, err := run("gtwcd web && pwd"); err != nil || output != filepath.Join(dir, "web") {
+		t.Errorf("Expected gtwcd web to cd into web, got %q (%v)", output, err)
+	}
+	if output, err := run("gtwcd && pwd"); err != nil || output != filepath.Join(dir, "api") {
+		t.Errorf("Expected gtwcd to cd into the picked worker, got %q (%v)", output, err)
+	}
+	if output, err := run("cd / && gtwcd missing; echo $? $PWD"); err != nil || output != "1 /" {
+		t.Errorf("Expected gtwcd to fail and stay put for an unknown worker, got %q (%v)", output, err)
+	}
+}
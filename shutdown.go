@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultShutdownGrace = 10 * time.Second
+	termGrace            = 3 * time.Second // Wait after SIGTERM before killing the pane
+)
+
+// resolveShutdownGrace returns the shutdown_grace config value, falling back
+// to the default when it is unset or invalid.
+func resolveShutdownGrace(config *Config) time.Duration {
+	if config.ShutdownGrace == "" {
+		return defaultShutdownGrace
+	}
+	grace, err := time.ParseDuration(config.ShutdownGrace)
+	if err != nil {
+		fmt.Printf("Warning: Invalid shutdown_grace %q in config, using %s\n", config.ShutdownGrace, defaultShutdownGrace)
+		return defaultShutdownGrace
+	}
+	return grace
+}
+
+// signalScript returns the shell command that sends signal to the
+// foreground process group of the terminal owned by shellPID, unless the
+// shell itself is in the foreground. It is run with sh as tmux uses the
+// user's default-shell, and dash's kill does not accept "--".
+func signalScript(shellPID int, signal string) string {
+	script := fmt.Sprintf(`pgid=$(ps -o tpgid= -p %d | tr -d " "); [ "${pgid:-0}" -gt 0 ] && [ "$pgid" != %d ] && kill -%s -"$pgid" 2>/dev/null; true`,
+		shellPID, shellPID, signal)
+	return "sh -c " + shellQuote(script)
+}
+
+// signalForeground sends signal to the pane's foreground process group. It
+// runs through tmux so that it reaches the processes wherever the tmux
+// server runs (e.g. inside WSL).
+func signalForeground(paneID, signal string) error {
+	pid, err := panePID(paneID)
+	if err != nil {
+		return err
+	}
+	return tmuxCommand("run-shell", signalScript(pid, signal)).Run()
+}
+
+// waitForShell polls until the pane is back at a shell prompt or gone, and
+// reports whether that happened before the timeout.
+func waitForShell(paneID string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if !mux.PaneExists(paneID) {
+			return true
+		}
+		if command, err := paneCurrentCommand(paneID); err == nil && isShellCommand(command) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// stopPane kills the pane after giving its foreground program a chance to
+// exit: SIGINT, then SIGTERM once grace has passed, then kill-pane. Backends
+// other than tmux, and panes sitting at a shell prompt, are killed at once.
+func stopPane(paneID string, grace time.Duration) error {
+	command, err := paneCurrentCommand(paneID)
+	if mux.Name() != "tmux" || err != nil || command == "" || isShellCommand(command) || grace <= 0 {
+		return mux.KillPane(paneID)
+	}
+
+	fmt.Printf("Interrupting '%s' in pane %s (waiting up to %s)...\n", command, paneID, grace)
+	if err := signalForeground(paneID, "INT"); err != nil {
+		fmt.Printf("Warning: Could not send SIGINT: %v\n", err)
+	} else if waitForShell(paneID, grace) {
+		return killPaneIfExists(paneID)
+	}
+
+	fmt.Printf("'%s' is still running, sending SIGTERM...\n", command)
+	if err := signalForeground(paneID, "TERM"); err != nil {
+		fmt.Printf("Warning: Could not send SIGTERM: %v\n", err)
+	} else {
+		waitForShell(paneID, termGrace)
+	}
+	return killPaneIfExists(paneID)
+}
+
+// killPaneIfExists kills the pane unless it already closed on its own.
+func killPaneIfExists(paneID string) error {
+	if !mux.PaneExists(paneID) {
+		return nil
+	}
+	return mux.KillPane(paneID)
+}
+
+// runningProgram is a program other than a shell found in a worker pane.
+type runningProgram struct {
+	Worker    string
+	PaneID    string
+	Command   string
+	Processes int // Size of the pane's process tree, 0 when unknown
+}
+
+func (p runningProgram) String() string {
+	if p.Processes > 0 {
+		return fmt.Sprintf("worker '%s' (pane %s): %s, %d process(es)", p.Worker, p.PaneID, p.Command, p.Processes)
+	}
+	return fmt.Sprintf("worker '%s' (pane %s): %s", p.Worker, p.PaneID, p.Command)
+}
+
+// runningPrograms returns the workers whose pane is running something in the
+// foreground rather than sitting at a shell prompt.
+func runningPrograms(workers []Worker) []runningProgram {
+	var table []processInfo
+	if mux.Name() == "tmux" {
+		table, _ = readProcessTable()
+	}
+
+	var programs []runningProgram
+	for _, worker := range workers {
+		if !mux.PaneExists(worker.PaneID) {
+			continue
+		}
+		command, err := paneCurrentCommand(worker.PaneID)
+		if err != nil || command == "" || isShellCommand(command) {
+			continue
+		}
+		program := runningProgram{Worker: worker.ID, PaneID: worker.PaneID, Command: command}
+		if table != nil {
+			if usage, err := paneResourceUsage(worker.PaneID, table); err == nil {
+				program.Processes = usage.Processes
+			}
+		}
+		programs = append(programs, program)
+	}
+	return programs
+}
+
+// warnRunningPrograms lists the running programs and reports whether there
+// were any.
+func warnRunningPrograms(programs []runningProgram) bool {
+	if len(programs) == 0 {
+		return false
+	}
+	fmt.Printf("⚠️  %d worker(s) are still running a program:\n", len(programs))
+	for _, program := range programs {
+		fmt.Printf("  - %s\n", program)
+	}
+	return true
+}
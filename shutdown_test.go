@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveShutdownGrace(t *testing.T) {
+	if grace := resolveShutdownGrace(&Config{}); grace != defaultShutdownGrace {
+		t.Errorf("Expected default %s, got %s", defaultShutdownGrace, grace)
+	}
+	if grace := resolveShutdownGrace(&Config{ShutdownGrace: "30s"}); grace != 30*time.Second {
+		t.Errorf("Expected 30s, got %s", grace)
+	}
+	if grace := resolveShutdownGrace(&Config{ShutdownGrace: "soon"}); grace != defaultShutdownGrace {
+		t.Errorf("Expected default for an invalid value, got %s", grace)
+	}
+}
+
+func TestSignalScript(t *testing.T) {
+	script := signalScript(4242, "INT")
+	for _, want := range []string{"ps -o tpgid= -p 4242", `[ "$pgid" != 4242 ]`, `kill -INT -"$pgid"`} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got %s", want, script)
+		}
+	}
+	if !strings.HasPrefix(script, "sh -c '") || !strings.HasSuffix(script, "; true'") {
+		t.Errorf("Expected script to always succeed, got %s", script)
+	}
+}
+
+func TestRunningProgramString(t *testing.T) {
+	program := runningProgram{Worker: "w1", PaneID: "%3", Command: "claude", Processes: 4}
+	if got := program.String(); got != "worker 'w1' (pane %3): claude, 4 process(es)" {
+		t.Errorf("Unexpected description: %s", got)
+	}
+	program.Processes = 0
+	if got := program.String(); got != "worker 'w1' (pane %3): claude" {
+		t.Errorf("Unexpected description without a process count: %s", got)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Snapshot is a portable description of a workspace. It contains no
+// machine-specific state (pane IDs, absolute paths) so it can be restored
+// after a tmux server restart or on another machine.
+type Snapshot struct {
+	Version        int              `json:"version"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Project        string           `json:"project"`
+	InitCommand    string           `json:"init_command,omitempty"`
+	WorktreePrefix string           `json:"worktree_prefix,omitempty"`
+	Layout         string           `json:"layout,omitempty"` // tmux window_layout of window 0
+	Workers        []SnapshotWorker `json:"workers"`
+}
+
+type SnapshotWorker struct {
+	ID           string   `json:"id"`
+	Branch       string   `json:"branch"`
+	WorktreePath string   `json:"worktree_path"` // Relative to the project root
+	PaneIndex    int      `json:"pane_index"`
+	Note         string   `json:"note,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+const (
+	snapshotVersion     = 1
+	defaultSnapshotFile = "gtw-snapshot.json"
+)
+
+func init() {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot [file]",
+		Short: "Export the workspace to a portable snapshot file",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := defaultSnapshotFile
+			if len(args) == 1 {
+				file = userPath(args[0])
+			}
+			snapshotWorkspace(file)
+		},
+	}
+	rootCmd.AddCommand(snapshotCmd)
+
+	var restoreNoInit bool
+	restoreCmd := &cobra.Command{
+		Use:   "restore [file]",
+		Short: "Recreate the workspace from a snapshot file",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := defaultSnapshotFile
+			if len(args) == 1 {
+				file = userPath(args[0])
+			}
+			restoreWorkspace(file, !restoreNoInit)
+		},
+	}
+	restoreCmd.Flags().BoolVar(&restoreNoInit, "no-init", false, "Do not run the initialization command in restored panes")
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// getWorktreeBranch returns the branch checked out in worktreePath, falling
+// back to fallback when it cannot be determined (e.g. the worktree is gone).
+func getWorktreeBranch(worktreePath, fallback string) string {
+	output, err := gitCommand("-C", worktreePath, "branch", "--show-current").Output()
+	if err != nil {
+		return fallback
+	}
+	branch := strings.TrimSpace(string(output))
+	if branch == "" {
+		return fallback
+	}
+	return branch
+}
+
+func snapshotWorkspace(file string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	snapshot := Snapshot{
+		Version:        snapshotVersion,
+		CreatedAt:      time.Now(),
+		Project:        getCurrentProjectName(),
+		InitCommand:    config.InitCommand,
+		WorktreePrefix: config.WorktreePrefix,
+	}
+
+	// Record the current layout so panes can be arranged the same way on restore
+	sessionName := getSessionName()
+	if mux.Name() == "tmux" {
+		if output, err := tmuxCommand("display-message", "-t", sessionName+":0", "-p", "#{window_layout}").Output(); err == nil {
+			snapshot.Layout = strings.TrimSpace(string(output))
+		}
+	}
+
+	snapshot.Workers = snapshotWorkers(config.Workers)
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding snapshot: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		fmt.Printf("Error writing snapshot: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Saved snapshot of %d worker(s) to %s\n", len(snapshot.Workers), file)
+}
+
+func loadSnapshot(file string) (*Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version > snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+func restoreWorkspace(file string, runInit bool) {
+	snapshot, err := loadSnapshot(file)
+	if err != nil {
+		fmt.Printf("Error loading snapshot: %v\n", err)
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error getting current directory: %v\n", err)
+		return
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return
+	}
+
+	if err := ensureSession(sessionName, cwd); err != nil {
+		fmt.Printf("Error creating tmux session: %v\n", err)
+		return
+	}
+
+	config.ProjectPath = cwd
+	if snapshot.InitCommand != "" {
+		config.InitCommand = snapshot.InitCommand
+	}
+	if snapshot.WorktreePrefix != "" {
+		config.WorktreePrefix = snapshot.WorktreePrefix
+	}
+
+	windowTarget := fmt.Sprintf("%s:%d", sessionName, 0)
+	restored := restoreWorkers(config, sessionName, snapshot.Workers, runInit)
+
+	// Layouts only apply cleanly when the pane count matches the snapshot
+	if snapshot.Layout != "" && mux.Name() == "tmux" {
+		if err := tmuxCommand("select-layout", "-t", windowTarget, snapshot.Layout).Run(); err != nil {
+			tmuxCommand("select-layout", "-t", windowTarget, "tiled").Run()
+		}
+	}
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("❌ Error saving config: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Restored %d worker(s) from %s\n", restored, file)
+	fmt.Printf("To attach: tmux attach-session -t %s\n", sessionName)
+}
+
+// ensureSession creates the tmux session rooted at dir if the tmux server
+// does not know about it (e.g. after a reboot).
+func ensureSession(sessionName, dir string) error {
+	if mux.HasSession(sessionName) {
+		return nil
+	}
+
+	fmt.Printf("Creating %s session '%s'...\n", mux.Name(), sessionName)
+	if err := mux.NewSession(sessionName, dir, getCurrentProjectName()); err != nil {
+		return err
+	}
+	if config, err := loadConfig(); err == nil {
+		configureTmuxSession(sessionName, config)
+	}
+	return nil
+}
+
+// snapshotWorkers converts the configured workers into their portable form.
+func snapshotWorkers(workers []Worker) []SnapshotWorker {
+	result := []SnapshotWorker{}
+	for _, worker := range workers {
+		result = append(result, SnapshotWorker{
+			ID:           worker.ID,
+			Branch:       getWorktreeBranch(worker.WorktreePath, worker.branchName()),
+			WorktreePath: filepath.ToSlash(worker.WorktreePath),
+			PaneIndex:    worker.PaneIndex,
+			Note:         worker.Note,
+			Tags:         worker.Tags,
+		})
+	}
+	return result
+}
+
+// restoreWorkers recreates worktrees and panes for the given workers in
+// sessionName, updating config in place. Workers whose pane is
+// still alive are left untouched. It returns the number of restored workers.
+func restoreWorkers(config *Config, sessionName string, workers []SnapshotWorker, runInit bool) int {
+	restored := 0
+	created := make(map[string]bool) // Pane IDs are reused after a server restart
+
+	for _, sw := range workers {
+		worktreePath := filepath.FromSlash(sw.WorktreePath)
+		if worktreePath == "" {
+			worktreePath = workerWorktreePath(config, sw.ID)
+		}
+		branch := sw.Branch
+		if branch == "" {
+			branch = sw.ID
+		}
+
+		// Skip workers that are already alive in this session
+		existing := -1
+		for i, w := range config.Workers {
+			if w.ID == sw.ID {
+				existing = i
+				break
+			}
+		}
+		if existing >= 0 && !created[config.Workers[existing].PaneID] && mux.PaneExists(config.Workers[existing].PaneID) {
+			fmt.Printf("Worker '%s' is already running, skipping\n", sw.ID)
+			continue
+		}
+
+		fmt.Printf("🔧 Restoring worker '%s' (branch: %s)...\n", sw.ID, branch)
+
+		if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+			if output, err := createWorktree(branch, worktreePath, ""); err != nil {
+				fmt.Printf("❌ Error creating git worktree: %v\n", err)
+				fmt.Printf("Git output: %s\n", string(output))
+				continue
+			}
+			prepareWorktree(config, worktreePath)
+		}
+
+		paneIndex, paneID, err := mux.NewPane(sessionName, worktreePath, sw.ID)
+		if err != nil {
+			fmt.Printf("❌ Error creating pane: %v\n", err)
+			continue
+		}
+		created[paneID] = true
+
+		worker := Worker{
+			ID:        sw.ID,
+			CreatedAt: time.Now(),
+			Status:    StateCreating,
+			Note:      sw.Note,
+			Tags:      sw.Tags,
+		}
+		if existing >= 0 {
+			// Keep metadata (notes, tags, tasks) and only rewire pane/worktree
+			worker = config.Workers[existing]
+		}
+		if sw.Branch != "" {
+			worker.Branch = sw.Branch
+		}
+		worker.WorktreePath = worktreePath
+		worker.TmuxSession = sessionName
+		worker.WindowIndex = paneWindowIndex(paneID)
+		worker.PaneID = paneID
+		worker.PaneIndex = paneIndex
+		worker.setState(StateReady, "pane restored")
+		if existing >= 0 {
+			config.Workers[existing] = worker
+		} else {
+			config.Workers = append(config.Workers, worker)
+		}
+		labelWorkerPane(config, worker)
+
+		if runInit {
+			executeInitCommand(config, worktreePath, paneID, false)
+		}
+		restored++
+	}
+
+	return restored
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "snapshot.json")
+	data := `{"version": 1, "project": "demo", "workers": [{"id": "issue-1", "branch": "feature/issue-1", "worktree_path": "worktree/issue-1"}]}`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := loadSnapshot(file)
+	if err != nil {
+		t.Fatalf("loadSnapshot failed: %v", err)
+	}
+	if len(snapshot.Workers) != 1 || snapshot.Workers[0].Branch != "feature/issue-1" {
+		t.Errorf("Unexpected workers: %+v", snapshot.Workers)
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version": 99, "workers": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshot(future); err == nil {
+		t.Error("Expected error for unsupported snapshot version")
+	}
+}
+
+func TestRestoreWorkers(t *testing.T) {
+	project := t.TempDir()
+	for _, id := range []string{"issue-1", "issue-2", "issue-3"} {
+		os.MkdirAll(filepath.Join(project, "worktree", id), 0755)
+	}
+	fake := newSessionMultiplexer("%1")
+	fake.sessions["demo"] = true
+	useMultiplexer(t, fake)
+
+	config := &Config{ProjectPath: project, Workers: []Worker{
+		{ID: "issue-1", PaneID: "%1", Branch: "issue-1", Note: "alive"},
+		{ID: "issue-2", PaneID: "%2", Note: "pane gone"},
+	}}
+	workers := []SnapshotWorker{
+		{ID: "issue-1", Branch: "feature/one", WorktreePath: filepath.Join(project, "worktree", "issue-1")},
+		{ID: "issue-2", Branch: "feature/two", WorktreePath: filepath.Join(project, "worktree", "issue-2")},
+		{ID: "issue-3", Branch: "fix/three", WorktreePath: filepath.Join(project, "worktree", "issue-3"), Tags: []string{"new"}},
+	}
+
+	if restored := restoreWorkers(config, "demo", workers, false); restored != 2 {
+		t.Fatalf("Expected 2 restored workers, got %d", restored)
+	}
+	if len(config.Workers) != 3 {
+		t.Fatalf("Expected 3 workers, got %+v", config.Workers)
+	}
+	want := []struct{ paneID, branch, note string }{
+		{"%1", "issue-1", "alive"}, // Still running, left alone
+		{"%100", "feature/two", "pane gone"},
+		{"%101", "fix/three", ""},
+	}
+	for i, w := range want {
+		worker := config.Workers[i]
+		if worker.PaneID != w.paneID || worker.Branch != w.branch || worker.Note != w.note {
+			t.Errorf("Worker %s: expected pane %s, branch %s and note %q, got %+v", worker.ID, w.paneID, w.branch, w.note, worker)
+		}
+	}
+	if branch := config.Workers[2].branchName(); branch != "fix/three" {
+		t.Errorf("Expected the restored worker to keep its branch, got %s", branch)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkerState is where a worker is in its lifecycle. It is stored in the
+// worker's "status" field.
+type WorkerState string
+
+const (
+	StateCreating       WorkerState = "creating"        // Worktree and pane are being set up
+	StateInitializing   WorkerState = "initializing"    // Init command sent, not idle yet
+	StateReady          WorkerState = "ready"           // Idle, waiting for a task
+	StateBusy           WorkerState = "busy"            // Output is changing
+	StateNeedsAttention WorkerState = "needs-attention" // Stopped at a permission prompt
+	StateFailed         WorkerState = "failed"          // Init failed or the pane is gone
+	StateRemoving       WorkerState = "removing"        // `gtw remove` in progress
+	StateArchived       WorkerState = "archived"
+)
+
+// workerStates is the order in which states are listed.
+var workerStates = []WorkerState{
+	StateCreating, StateInitializing, StateReady, StateBusy, StateNeedsAttention,
+	StateFailed, WorkerPaused, WorkerDetached, StateRemoving, StateArchived,
+}
+
+// maxStateHistory is how many transitions are kept per worker.
+const maxStateHistory = 20
+
+// reasonPaneGone is the reason recorded when the watcher finds a worker's
+// pane gone. Only such failures end by themselves once the pane is back.
+const reasonPaneGone = "pane is gone"
+
+// stuckStateAfter is how long a worker may stay creating or removing before
+// `gtw check` reports it.
+const stuckStateAfter = 10 * time.Minute
+
+// StateChange is one transition in a worker's state history.
+type StateChange struct {
+	From   WorkerState `json:"from"`
+	To     WorkerState `json:"to"`
+	At     time.Time   `json:"at"`
+	Reason string      `json:"reason,omitempty"`
+}
+
+// stateTransitions lists the states each state may move to. Paused and
+// detached are entered and left by `gtw pause`/`resume` and by destroying
+// and recreating the session.
+var stateTransitions = map[WorkerState][]WorkerState{
+	StateCreating:       {StateInitializing, StateReady, StateFailed, StateRemoving},
+	StateInitializing:   {StateReady, StateBusy, StateNeedsAttention, StateFailed, StateRemoving, WorkerPaused, WorkerDetached, StateArchived},
+	StateReady:          {StateInitializing, StateBusy, StateNeedsAttention, StateFailed, StateRemoving, WorkerPaused, WorkerDetached, StateArchived},
+	StateBusy:           {StateReady, StateNeedsAttention, StateFailed, StateRemoving, WorkerPaused, WorkerDetached, StateArchived},
+	StateNeedsAttention: {StateReady, StateBusy, StateFailed, StateRemoving, WorkerPaused, WorkerDetached, StateArchived},
+	StateFailed:         {StateInitializing, StateReady, StateRemoving, WorkerDetached, StateArchived},
+	StateRemoving:       {StateReady, StateFailed},
+	WorkerPaused:        {StateInitializing, StateReady, StateBusy, StateNeedsAttention, StateFailed, StateRemoving, WorkerDetached, StateArchived},
+	WorkerDetached:      {StateInitializing, StateReady, StateFailed, StateRemoving, StateArchived},
+	StateArchived:       {StateInitializing, StateReady},
+}
+
+// canTransition reports whether a worker may move from one state to another.
+func canTransition(from, to WorkerState) bool {
+	for _, state := range stateTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// state returns the worker's state, reading the "active" status of older
+// configs as ready.
+func (w *Worker) state() WorkerState {
+	switch w.Status {
+	case "", "active", "inactive":
+		return StateReady
+	}
+	return w.Status
+}
+
+// transition moves the worker to a new state and records it in its history.
+// Moving to the current state does nothing.
+func (w *Worker) transition(to WorkerState, reason string) error {
+	from := w.state()
+	if from == to {
+		return nil
+	}
+	if !canTransition(from, to) {
+		return fmt.Errorf("worker '%s' cannot go from %s to %s", w.ID, from, to)
+	}
+	if from == WorkerPaused {
+		w.PausedFrom = ""
+	}
+	now := time.Now()
+	w.Status = to
+	w.StateSince = &now
+	w.StateHistory = append(w.StateHistory, StateChange{From: from, To: to, At: now, Reason: reason})
+	if len(w.StateHistory) > maxStateHistory {
+		w.StateHistory = w.StateHistory[len(w.StateHistory)-maxStateHistory:]
+	}
+	return nil
+}
+
+// setState is transition for code paths that go on either way: an invalid
+// transition is only reported.
+func (w *Worker) setState(to WorkerState, reason string) {
+	if err := w.transition(to, reason); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// migrateStates rewrites the free-text status of older configs.
+func (c *Config) migrateStates() {
+	for i := range c.Workers {
+		c.Workers[i].Status = c.Workers[i].state()
+	}
+}
+
+// liveState is the state shown by list and status: a worker whose pane is
+// gone is failed, whatever its last recorded state.
+func liveState(worker Worker, alive func(paneID string) bool) WorkerState {
+	state := worker.state()
+	switch state {
+	case WorkerPaused, WorkerDetached, StateArchived, StateCreating, StateRemoving:
+		return state
+	}
+	if !alive(worker.PaneID) {
+		return StateFailed
+	}
+	return state
+}
+
+// isStuck reports whether the worker stayed creating or removing for so
+// long that the gtw command doing it must have died.
+func isStuck(worker Worker, now time.Time) bool {
+	state := worker.state()
+	if state != StateCreating && state != StateRemoving {
+		return false
+	}
+	return worker.StateSince == nil || now.Sub(*worker.StateSince) >= stuckStateAfter
+}
+
+// StateWatcher moves live workers between ready, busy, needs-attention and
+// failed from what their panes show.
+type StateWatcher struct {
+	tracker *IdleTracker
+}
+
+func NewStateWatcher(quiet time.Duration) *StateWatcher {
+	return &StateWatcher{tracker: NewIdleTracker(quiet)}
+}
+
+// Run samples every worker and reports whether any changed state.
+func (w *StateWatcher) Run(config *Config) bool {
+	changed := false
+	paneAlive := livePaneCheck()
+	for i := range config.Workers {
+		worker := &config.Workers[i]
+		state := worker.state()
+		switch state {
+		case WorkerPaused, WorkerDetached, StateArchived, StateCreating, StateRemoving:
+			continue
+		}
+
+		next, reason := w.observe(worker, state, paneAlive(worker.PaneID), config.readinessFor(*worker))
+		if next != state && worker.transition(next, reason) == nil {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// observe decides the next state of a worker from its pane. An idle worker
+// only becomes ready once its readiness checks pass.
+func (w *StateWatcher) observe(worker *Worker, state WorkerState, alive bool, checks []ReadinessCheck) (WorkerState, string) {
+	if !alive {
+		return StateFailed, reasonPaneGone
+	}
+	if state == StateFailed {
+		if history := worker.StateHistory; len(history) > 0 && history[len(history)-1].Reason == reasonPaneGone {
+			return StateReady, "pane is back"
+		}
+		return state, "" // Failed init: left for the user to look into
+	}
+	idle, err := w.tracker.Observe(worker.PaneID)
+	if err != nil {
+		return state, ""
+	}
+	if output, err := capturePane(worker.PaneID, 50); err == nil && detectPermissionPrompt(output) != nil {
+		return StateNeedsAttention, "waiting at a permission prompt"
+	}
+	switch {
+	case idle:
+		if ok, _ := checkReadiness(*worker, checks); !ok {
+			return state, "" // Idle, but not serving yet
+		}
+		return StateReady, "idle"
+	case state == StateInitializing:
+		return state, "" // Still running the init command
+	default:
+		return StateBusy, "output is changing"
+	}
+}
+
+// recentStateChanges returns the last n transitions, newest first.
+func recentStateChanges(history []StateChange, n int) []StateChange {
+	var recent []StateChange
+	for i := len(history) - 1; i >= 0 && len(recent) < n; i-- {
+		recent = append(recent, history[i])
+	}
+	return recent
+}
+
+// describeState shows a state with how long the worker has been in it, e.g.
+// "busy for 5m". The time is left out when the state is not the recorded
+// one (a failed pane) or when it was never recorded.
+func describeState(worker Worker, state WorkerState, now time.Time) string {
+	if state != worker.state() || worker.StateSince == nil {
+		return string(state)
+	}
+	return fmt.Sprintf("%s for %s", state, formatLifetime(now.Sub(*worker.StateSince)))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// stateMultiplexer reports the foreground command of each pane and which
+// panes are alive.
+type stateMultiplexer struct {
+	captureMultiplexer
+	commands map[string]string
+	alive    map[string]bool
+}
+
+func (s *stateMultiplexer) CurrentCommand(paneID string) (string, error) {
+	return s.commands[paneID], nil
+}
+
+func (s *stateMultiplexer) ListPanes() (map[string]bool, error) {
+	return s.alive, nil
+}
+
+func TestWorkerTransition(t *testing.T) {
+	worker := Worker{ID: "a", Status: StateCreating}
+	for _, state := range []WorkerState{StateInitializing, StateBusy, StateReady} {
+		if err := worker.transition(state, "test"); err != nil {
+			t.Fatalf("transition to %s: %v", state, err)
+		}
+	}
+	if worker.Status != StateReady || worker.StateSince == nil {
+		t.Errorf("Expected ready with a timestamp, got %+v", worker)
+	}
+	if len(worker.StateHistory) != 3 || worker.StateHistory[0].From != StateCreating || worker.StateHistory[2].To != StateReady {
+		t.Errorf("Unexpected history: %+v", worker.StateHistory)
+	}
+
+	// Staying in the same state records nothing
+	worker.transition(StateReady, "again")
+	if len(worker.StateHistory) != 3 {
+		t.Errorf("Expected no new entry, got %d", len(worker.StateHistory))
+	}
+
+	if err := worker.transition(StateCreating, "test"); err == nil {
+		t.Error("Expected ready -> creating to be rejected")
+	}
+	if worker.Status != StateReady {
+		t.Errorf("Expected a rejected transition to keep the state, got %s", worker.Status)
+	}
+}
+
+func TestStateHistoryCap(t *testing.T) {
+	worker := Worker{ID: "a", Status: StateReady}
+	for i := 0; i < maxStateHistory; i++ {
+		worker.transition(StateBusy, "")
+		worker.transition(StateReady, "")
+	}
+	if len(worker.StateHistory) != maxStateHistory {
+		t.Errorf("Expected %d entries, got %d", maxStateHistory, len(worker.StateHistory))
+	}
+	if recent := recentStateChanges(worker.StateHistory, 2); len(recent) != 2 || recent[0].To != StateReady || recent[1].To != StateBusy {
+		t.Errorf("Expected the newest changes first, got %+v", recent)
+	}
+}
+
+func TestMigrateStates(t *testing.T) {
+	config := &Config{Workers: []Worker{{ID: "a", Status: "active"}, {ID: "b"}, {ID: "c", Status: WorkerPaused}}}
+	config.migrateStates()
+	want := []WorkerState{StateReady, StateReady, WorkerPaused}
+	for i, worker := range config.Workers {
+		if worker.Status != want[i] {
+			t.Errorf("Worker %s: expected %s, got %s", worker.ID, want[i], worker.Status)
+		}
+	}
+}
+
+func TestLiveState(t *testing.T) {
+	alive := func(paneID string) bool { return paneID == "%1" }
+	tests := []struct {
+		worker Worker
+		want   WorkerState
+	}{
+		{Worker{PaneID: "%1", Status: StateBusy}, StateBusy},
+		{Worker{PaneID: "%2", Status: StateBusy}, StateFailed},
+		{Worker{PaneID: "%2", Status: "active"}, StateFailed},
+		{Worker{Status: WorkerDetached}, WorkerDetached},
+		{Worker{PaneID: "%2", Status: StateRemoving}, StateRemoving},
+	}
+	for _, tt := range tests {
+		if got := liveState(tt.worker, alive); got != tt.want {
+			t.Errorf("liveState(%s) = %s, want %s", tt.worker.Status, got, tt.want)
+		}
+	}
+}
+
+func TestIsStuck(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	recent, old := now.Add(-time.Minute), now.Add(-time.Hour)
+	tests := []struct {
+		worker Worker
+		want   bool
+	}{
+		{Worker{Status: StateRemoving, StateSince: &old}, true},
+		{Worker{Status: StateRemoving, StateSince: &recent}, false},
+		{Worker{Status: StateCreating}, true},
+		{Worker{Status: StateBusy, StateSince: &old}, false},
+	}
+	for _, tt := range tests {
+		if got := isStuck(tt.worker, now); got != tt.want {
+			t.Errorf("isStuck(%s) = %v, want %v", tt.worker.Status, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeState(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-5 * time.Minute)
+	worker := Worker{Status: StateBusy, StateSince: &since}
+	if got := describeState(worker, StateBusy, now); got != "busy for 5m" {
+		t.Errorf("Unexpected description: %q", got)
+	}
+	if got := describeState(worker, StateFailed, now); got != "failed" {
+		t.Errorf("Expected no time for a state that was not recorded, got %q", got)
+	}
+}
+
+func TestStateWatcher(t *testing.T) {
+	fake := &stateMultiplexer{
+		captureMultiplexer: captureMultiplexer{output: map[string]string{"%1": "building..."}},
+		commands:           map[string]string{"%1": "node"},
+		alive:              map[string]bool{"%1": true},
+	}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	config := &Config{Workers: []Worker{
+		{ID: "a", PaneID: "%1", Status: StateInitializing},
+		{ID: "b", PaneID: "%1", Status: WorkerPaused},
+	}}
+	w := NewStateWatcher(0)
+	worker := &config.Workers[0]
+
+	// New output while initializing keeps the worker initializing
+	if w.Run(config) || worker.Status != StateInitializing {
+		t.Errorf("Expected initializing, got %s", worker.Status)
+	}
+	if !w.Run(config) || worker.Status != StateReady {
+		t.Errorf("Expected unchanged output to mean ready, got %s", worker.Status)
+	}
+	fake.output["%1"] = "running tests"
+	if !w.Run(config) || worker.Status != StateBusy {
+		t.Errorf("Expected busy, got %s", worker.Status)
+	}
+	fake.output["%1"] = "Overwrite main.go? [y/n] "
+	if !w.Run(config) || worker.Status != StateNeedsAttention {
+		t.Errorf("Expected needs-attention, got %s", worker.Status)
+	}
+
+	fake.alive["%1"] = false
+	if !w.Run(config) || worker.Status != StateFailed {
+		t.Errorf("Expected failed, got %s", worker.Status)
+	}
+	fake.alive["%1"] = true
+	if !w.Run(config) || worker.Status != StateReady {
+		t.Errorf("Expected the worker to recover once its pane is back, got %s", worker.Status)
+	}
+
+	if config.Workers[1].Status != WorkerPaused {
+		t.Errorf("Expected paused workers to be left alone, got %s", config.Workers[1].Status)
+	}
+
+	// A failed init is not cleared by the watcher
+	worker.transition(StateInitializing, "")
+	worker.transition(StateFailed, "init command could not be sent")
+	if w.Run(config) || worker.Status != StateFailed {
+		t.Errorf("Expected the failed init to stay, got %s", worker.Status)
+	}
+}
+
+func TestStateWatcherReadiness(t *testing.T) {
+	fake := &stateMultiplexer{
+		captureMultiplexer: captureMultiplexer{output: map[string]string{"%1": "starting dev server"}},
+		commands:           map[string]string{"%1": "node"},
+		alive:              map[string]bool{"%1": true},
+	}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+
+	config := &Config{
+		Workers:  []Worker{{ID: "web", PaneID: "%1", Profile: "dev", Status: StateInitializing}},
+		Profiles: map[string]Profile{"dev": {Readiness: []ReadinessCheck{{Output: "listening on"}}}},
+	}
+	w := NewStateWatcher(0)
+	worker := &config.Workers[0]
+
+	w.Run(config)
+	if w.Run(config) || worker.Status != StateInitializing {
+		t.Errorf("Expected an idle worker that is not serving to stay initializing, got %s", worker.Status)
+	}
+	fake.output["%1"] = "listening on :3000"
+	w.Run(config)
+	if !w.Run(config) || worker.Status != StateReady {
+		t.Errorf("Expected ready once the check passes, got %s", worker.Status)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// StatsOptions holds the settings given to `gtw stats`.
+type StatsOptions struct {
+	Since time.Duration
+	Tags  []string
+	JSON  bool
+	Costs bool // Run the usage commands and report token and cost usage
+}
+
+// WorkerStats is what a worker produced, as reported by `gtw stats`.
+type WorkerStats struct {
+	Worker         string     `json:"worker"`
+	Branch         string     `json:"branch"`
+	Base           string     `json:"base,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Lifetime       string     `json:"lifetime"`
+	Commits        int        `json:"commits"`
+	LinesAdded     int        `json:"lines_added"`
+	LinesRemoved   int        `json:"lines_removed"`
+	TasksCompleted int        `json:"tasks_completed"`
+	TasksQueued    int        `json:"tasks_queued"`
+	LastActivity   *time.Time `json:"last_activity,omitempty"`
+	Usage          *Usage     `json:"usage,omitempty"` // With --costs, from the worker's usage_command
+	UsageError     string     `json:"usage_error,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+func init() {
+	var opts StatsOptions
+	statsCmd := &cobra.Command{
+		Use:   "stats [worker-id...]",
+		Short: "Summarize what each worker produced: commits, lines changed and tasks",
+		Long: `Summarize per worker its lifetime, the commits on its branch since its base,
+the lines those commits added and removed, the tasks it completed and when
+it was last active (its last commit or gtw event).
+
+A sent task counts as completed once a later task was sent to the worker,
+or when its pane is back at a shell prompt. With --since, only commits,
+tasks and workers active in that period count.
+
+With --costs, the usage_command of each worker's profile (or the top-level
+usage_command) is run in its worktree and adds the tokens and cost of the
+worker's agent. It must print a JSON object such as
+{"input_tokens": 120000, "output_tokens": 8000, "cost_usd": 1.25}, and gets
+GTW_WORKER_ID, GTW_WORKER_BRANCH, GTW_WORKER_WORKTREE, GTW_WORKER_PANE,
+GTW_WORKER_CREATED and (with --since) GTW_SINCE in its environment.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !showStats(args, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	statsCmd.Flags().DurationVar(&opts.Since, "since", 0, "Only count activity newer than this (e.g. 24h)")
+	statsCmd.Flags().StringSliceVarP(&opts.Tags, "tag", "t", nil, "Only workers with these tags")
+	statsCmd.Flags().BoolVar(&opts.JSON, "json", false, "Print the stats as JSON")
+	statsCmd.Flags().BoolVar(&opts.Costs, "costs", false, "Add the token and cost usage reported by usage_command")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// commitStats holds the totals of `git log --numstat`.
+type commitStats struct {
+	Commits    int
+	Added      int
+	Removed    int
+	LastCommit time.Time
+}
+
+// parseNumstatLog parses `git log --format=@%ct --numstat`: a line "@<unix
+// time>" per commit followed by "<added>\t<removed>\t<path>" per file.
+// Binary files ("-") count no lines.
+func parseNumstatLog(output string) commitStats {
+	var stats commitStats
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "@") {
+			stats.Commits++
+			if seconds, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				if t := time.Unix(seconds, 0); t.After(stats.LastCommit) {
+					stats.LastCommit = t
+				}
+			}
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(fields[0])
+		removed, _ := strconv.Atoi(fields[1])
+		stats.Added += added
+		stats.Removed += removed
+	}
+	return stats
+}
+
+// workerCommitStats sums the commits of the worker's branch since its base,
+// only those newer than since if it is set.
+func workerCommitStats(worker Worker, base string, since time.Time) (commitStats, error) {
+	args := []string{"-C", worker.WorktreePath, "log", "--no-merges", "--format=@%ct", "--numstat"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	args = append(args, base+"..HEAD")
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return commitStats{}, fmt.Errorf("git log failed: %v", err)
+	}
+	return parseNumstatLog(string(output)), nil
+}
+
+// completedTasks counts the sent tasks the worker finished: every one that
+// was followed by another, and the last one if lastDone.
+func completedTasks(tasks []Task, since time.Time, lastDone bool) int {
+	var sent []time.Time
+	for _, task := range tasks {
+		if task.Status == TaskSent && task.SentAt != nil {
+			sent = append(sent, *task.SentAt)
+		}
+	}
+	sort.Slice(sent, func(i, j int) bool { return sent[i].Before(sent[j]) })
+	completed := 0
+	for i, at := range sent {
+		if at.Before(since) {
+			continue
+		}
+		if i < len(sent)-1 || lastDone {
+			completed++
+		}
+	}
+	return completed
+}
+
+// lastEventTimes returns the time of each worker's latest history event.
+func lastEventTimes(events []Event) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, event := range events {
+		if event.Worker != "" && event.Time.After(last[event.Worker]) {
+			last[event.Worker] = event.Time
+		}
+	}
+	return last
+}
+
+// formatLifetime shows a duration in days, hours and minutes.
+func formatLifetime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// collectWorkerStats gathers the stats of one worker. lastEvent is the time
+// of its latest history event.
+func collectWorkerStats(worker Worker, since, lastEvent, now time.Time) WorkerStats {
+	stats := WorkerStats{
+		Worker:    worker.ID,
+		Branch:    getWorktreeBranch(worker.WorktreePath, worker.branchName()),
+		CreatedAt: worker.CreatedAt,
+		Lifetime:  formatLifetime(now.Sub(worker.CreatedAt)),
+	}
+	for _, task := range worker.Tasks {
+		if task.Status == TaskQueued {
+			stats.TasksQueued++
+		}
+	}
+
+	lastDone := true
+	if worker.Status != WorkerDetached && mux.PaneExists(worker.PaneID) {
+		command, err := paneCurrentCommand(worker.PaneID)
+		lastDone = err == nil && isShellCommand(command)
+	}
+	stats.TasksCompleted = completedTasks(worker.Tasks, since, lastDone)
+
+	last := lastEvent
+	stats.Base = workerBase(worker)
+	if _, err := os.Stat(worker.WorktreePath); err != nil {
+		stats.Error = "worktree is missing"
+	} else if stats.Base != "" {
+		commits, err := workerCommitStats(worker, stats.Base, since)
+		if err != nil {
+			stats.Error = err.Error()
+		}
+		stats.Commits = commits.Commits
+		stats.LinesAdded = commits.Added
+		stats.LinesRemoved = commits.Removed
+		if commits.LastCommit.After(last) {
+			last = commits.LastCommit
+		}
+	}
+	if !last.IsZero() {
+		stats.LastActivity = &last
+	}
+	return stats
+}
+
+func showStats(ids []string, opts StatsOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	workers, err := selectWorkers(config, ids, len(ids) == 0, opts.Tags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	now := time.Now()
+	var since time.Time
+	if opts.Since > 0 {
+		since = now.Add(-opts.Since)
+	}
+	events, err := readHistory(HistoryOptions{}, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not read history: %v\n", err)
+	}
+	lastEvents := lastEventTimes(events)
+
+	stats := []WorkerStats{}
+	for _, worker := range workers {
+		s := collectWorkerStats(worker, since, lastEvents[worker.ID], now)
+		if !since.IsZero() && (s.LastActivity == nil || s.LastActivity.Before(since)) && worker.CreatedAt.Before(since) {
+			continue
+		}
+		if opts.Costs {
+			if s.Usage, err = workerUsage(config, worker, since); err != nil {
+				s.UsageError = err.Error()
+			}
+		}
+		stats = append(stats, s)
+	}
+
+	if opts.JSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		fmt.Println(string(data))
+		return true
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No worker activity in this period")
+		return true
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "WORKER\tLIFETIME\tCOMMITS\tLINES\tTASKS\tLAST ACTIVITY"
+	if opts.Costs {
+		header += "\tTOKENS IN\tTOKENS OUT\tCOST"
+	}
+	fmt.Fprintln(w, header)
+	var total WorkerStats
+	var totalUsage Usage
+	var usageErrors []string
+	for _, s := range stats {
+		last := "-"
+		if s.LastActivity != nil {
+			last = formatLifetime(now.Sub(*s.LastActivity)) + " ago"
+		}
+		commits := strconv.Itoa(s.Commits)
+		if s.Error != "" {
+			commits = "? (" + s.Error + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t+%d -%d\t%d\t%s", s.Worker, s.Lifetime, commits, s.LinesAdded, s.LinesRemoved, s.TasksCompleted, last)
+		if opts.Costs {
+			fmt.Fprintf(w, "\t%s", usageColumns(s.Usage))
+			if s.UsageError != "" {
+				usageErrors = append(usageErrors, fmt.Sprintf("%s: %s", s.Worker, s.UsageError))
+			}
+		}
+		fmt.Fprintln(w)
+		total.Commits += s.Commits
+		total.LinesAdded += s.LinesAdded
+		total.LinesRemoved += s.LinesRemoved
+		total.TasksCompleted += s.TasksCompleted
+		totalUsage.Add(s.Usage)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%d\t+%d -%d\t%d\t", total.Commits, total.LinesAdded, total.LinesRemoved, total.TasksCompleted)
+	if opts.Costs {
+		fmt.Fprintf(w, "\t%s", usageColumns(&totalUsage))
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	for _, message := range usageErrors {
+		fmt.Printf("⚠️  %s\n", message)
+	}
+	return true
+}
+
+// usageColumns formats the token and cost columns of `gtw stats --costs`.
+func usageColumns(usage *Usage) string {
+	if usage == nil {
+		return "-\t-\t-"
+	}
+	return fmt.Sprintf("%s\t%s\t$%.2f", formatTokens(usage.InputTokens), formatTokens(usage.OutputTokens), usage.CostUSD)
+}
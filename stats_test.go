@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNumstatLog(t *testing.T) {
+	output := "@1736467200\n\n3\t1\tmain.go\n-\t-\tlogo.png\n@1736470800\n\n10\t0\tREADME.md\n"
+	stats := parseNumstatLog(output)
+	if stats.Commits != 2 || stats.Added != 13 || stats.Removed != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if !stats.LastCommit.Equal(time.Unix(1736470800, 0)) {
+		t.Errorf("Expected the newest commit time, got %v", stats.LastCommit)
+	}
+
+	if stats := parseNumstatLog(""); stats.Commits != 0 || !stats.LastCommit.IsZero() {
+		t.Errorf("Expected no commits, got %+v", stats)
+	}
+}
+
+func TestCompletedTasks(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	at := func(h int) *time.Time {
+		t := start.Add(time.Duration(h) * time.Hour)
+		return &t
+	}
+	tasks := []Task{
+		{ID: 1, Status: TaskSent, SentAt: at(1)},
+		{ID: 3, Status: TaskSent, SentAt: at(3)},
+		{ID: 2, Status: TaskSent, SentAt: at(2)},
+		{ID: 4, Status: TaskQueued},
+	}
+
+	if n := completedTasks(tasks, time.Time{}, false); n != 2 {
+		t.Errorf("Expected the running task not to count, got %d", n)
+	}
+	if n := completedTasks(tasks, time.Time{}, true); n != 3 {
+		t.Errorf("Expected all sent tasks to count, got %d", n)
+	}
+	if n := completedTasks(tasks, *at(2), true); n != 2 {
+		t.Errorf("Expected tasks since 2h to count, got %d", n)
+	}
+}
+
+func TestLastEventTimes(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: start, Type: EventWorkerAdded, Worker: "a"},
+		{Time: start.Add(2 * time.Hour), Type: EventTaskSent, Worker: "a"},
+		{Time: start.Add(time.Hour), Type: EventRepair, Worker: "a"},
+		{Time: start.Add(3 * time.Hour), Type: EventSessionCreated},
+	}
+	last := lastEventTimes(events)
+	if !last["a"].Equal(start.Add(2*time.Hour)) || len(last) != 1 {
+		t.Errorf("Unexpected last events: %v", last)
+	}
+}
+
+func TestFormatLifetime(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:            "0m",
+		45 * time.Minute:            "45m",
+		2*time.Hour + 5*time.Minute: "2h5m",
+		75 * time.Hour:              "3d3h",
+	}
+	for d, want := range tests {
+		if got := formatLifetime(d); got != want {
+			t.Errorf("formatLifetime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// showSessionSummary prints the overall state of the workspace for
+// `gtw status` without a worker ID.
+func showSessionSummary() {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error getting current directory: %v\n", err)
+		return
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return
+	}
+
+	fmt.Printf("Project: %s\n", getCurrentProjectName())
+
+	switch {
+	case config.ProjectPath == "":
+		fmt.Printf("Project path: not initialized (run 'gtw init')\n")
+	case config.ProjectPath == cwd:
+		fmt.Printf("Project path: %s (matches current directory)\n", config.ProjectPath)
+	default:
+		fmt.Printf("Project path: %s (⚠️  current directory is %s)\n", config.ProjectPath, cwd)
+	}
+
+	sessionExists := mux.HasSession(sessionName)
+	if sessionExists {
+		fmt.Printf("Session: %s (%s, running)\n", sessionName, mux.Name())
+	} else {
+		fmt.Printf("Session: %s (%s, not running)\n", sessionName, mux.Name())
+	}
+
+	if sessionExists && mux.Name() == "tmux" {
+		windows, panes := 0, 0
+		if output, err := tmuxCommand("list-windows", "-t", sessionName, "-F", "#{window_panes}").Output(); err == nil {
+			for _, line := range strings.Fields(string(output)) {
+				var n int
+				fmt.Sscanf(line, "%d", &n)
+				windows++
+				panes += n
+			}
+		}
+		fmt.Printf("Windows: %d, Panes: %d\n", windows, panes)
+	}
+
+	live := 0
+	states := make(map[WorkerState]int)
+	alive := livePaneCheck()
+	for _, worker := range config.Workers {
+		if alive(worker.PaneID) {
+			live++
+		}
+		states[liveState(worker, alive)]++
+	}
+	fmt.Printf("Workers: %d configured, %d live", len(config.Workers), live)
+	for _, state := range workerStates {
+		if states[state] > 0 {
+			fmt.Printf(", %d %s", states[state], state)
+		}
+	}
+	if len(config.Archived) > 0 {
+		fmt.Printf(", %d archived", len(config.Archived))
+	}
+	fmt.Println()
+
+	switch {
+	case !sessionExists:
+		fmt.Println("Consistency: unknown (session is not running)")
+	case mux.Name() != "tmux":
+		fmt.Printf("Consistency: unknown (not supported with %s)\n", mux.Name())
+	default:
+		inconsistencies, err := findInconsistencies(sessionName, config)
+		switch {
+		case err != nil:
+			fmt.Printf("Consistency: unknown (%v)\n", err)
+		case len(inconsistencies) == 0:
+			fmt.Println("Consistency: ✅ in sync")
+		default:
+			fmt.Printf("Consistency: ❌ %d inconsistency(ies), run 'gtw check' for details\n", len(inconsistencies))
+		}
+	}
+}
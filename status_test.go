@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShowSessionSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectPath string // "." for the current directory
+		running     bool
+		config      Config
+		want        []string
+	}{
+		{
+			name: "not initialized",
+			want: []string{
+				"Project: demo",
+				"Project path: not initialized (run 'gtw init')",
+				"Session: demo (fake, not running)",
+				"Workers: 0 configured, 0 live",
+				"Consistency: unknown (session is not running)",
+			},
+		},
+		{
+			name:        "running with workers",
+			projectPath: ".",
+			running:     true,
+			config: Config{
+				Workers: []Worker{
+					{ID: "a", PaneID: "%1"},
+					{ID: "b", PaneID: "%2"},
+					{ID: "c", PaneID: "%3", Status: StateBusy},
+					{ID: "d", Status: WorkerPaused},
+				},
+				Archived: []Worker{{ID: "e", Status: StateArchived}},
+			},
+			want: []string{
+				"Project path: DIR (matches current directory)",
+				"Session: demo (fake, running)",
+				"Workers: 4 configured, 2 live, 1 ready, 1 busy, 1 failed, 1 paused, 1 archived",
+				"Consistency: unknown (not supported with fake)",
+			},
+		},
+		{
+			name:        "initialized elsewhere",
+			projectPath: "/elsewhere",
+			config:      Config{Workers: []Worker{{ID: "a", PaneID: "%1"}}},
+			want: []string{
+				"Project path: /elsewhere (⚠️  current directory is DIR)",
+				"Workers: 1 configured, 1 live, 1 ready",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "demo")
+			os.Mkdir(dir, 0755)
+			t.Chdir(dir)
+			dir, _ = os.Getwd()
+
+			config := tt.config
+			config.ProjectPath = tt.projectPath
+			if tt.projectPath == "." {
+				config.ProjectPath = dir
+			}
+			if err := saveConfig(&config); err != nil {
+				t.Fatal(err)
+			}
+			fake := newSessionMultiplexer("%1", "%3")
+			fake.sessions["demo"] = tt.running
+			useMultiplexer(t, fake)
+
+			output := captureStdout(showSessionSummary)
+			for _, line := range tt.want {
+				line = strings.ReplaceAll(line, "DIR", dir)
+				if !strings.Contains(output, line+"\n") {
+					t.Errorf("Expected %q in the summary, got:\n%s", line, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Store persists what gtw knows about workers: their list, task queues,
+// counters and the event history. Settings always stay in the config file
+// so that they can be edited and validated; the JSON store (the default)
+// keeps the state there too, while the SQLite store keeps it in
+// .gtw/state.db.
+type Store interface {
+	Name() string
+
+	// Load fills the workers, archived workers, workspaces and counters of
+	// config, which holds what was read from the config file.
+	Load(config *Config) error
+	// Save writes config as it is stored (see Config.persisted).
+	Save(config *Config) error
+
+	AppendEvent(event Event) error
+	// Events returns the events matching the history filters, oldest first.
+	Events(opts HistoryOptions, now time.Time) ([]Event, error)
+}
+
+// Values of the store config field
+const (
+	StoreJSON   = "json"
+	StoreSQLite = "sqlite"
+)
+
+// openStore returns the store of the project in the current directory,
+// as set by the store config value.
+func openStore() (Store, error) {
+	return openStoreIn(".")
+}
+
+// openStoreIn returns the store of the project in dir.
+func openStoreIn(dir string) (Store, error) {
+	name, err := configuredStore(filepath.Join(dir, configFile))
+	if err != nil {
+		return nil, err
+	}
+	return newStore(name, dir)
+}
+
+func newStore(name, dir string) (Store, error) {
+	switch strings.ToLower(name) {
+	case "", StoreJSON:
+		return &JSONStore{Dir: dir}, nil
+	case StoreSQLite:
+		return openSQLiteStore(filepath.Join(dir, sqliteStoreFile))
+	}
+	return nil, fmt.Errorf("unknown store %q (expected %s or %s)", name, StoreJSON, StoreSQLite)
+}
+
+// configuredStore reads only the store value of a config file, which
+// must be known before the rest of the config can be loaded.
+func configuredStore(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var settings struct {
+		Store string `json:"store"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", err
+	}
+	return settings.Store, nil
+}
+
+// JSONStore keeps the state in the config file and the history in
+// .gtw/history.jsonl.
+type JSONStore struct {
+	Dir string
+}
+
+func (s *JSONStore) Name() string { return StoreJSON }
+
+// Load has nothing to add: the state was read with the config file.
+func (s *JSONStore) Load(config *Config) error { return nil }
+
+func (s *JSONStore) Save(config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.Dir, configFile), data)
+}
+
+// writeFileAtomic writes data through a temporary file in the same
+// directory, so that readers without the config lock never see a
+// half-written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *JSONStore) AppendEvent(event Event) error {
+	return appendEvent(filepath.Join(s.Dir, historyFile), event)
+}
+
+func (s *JSONStore) Events(opts HistoryOptions, now time.Time) ([]Event, error) {
+	events, err := readEvents(filepath.Join(s.Dir, historyFile))
+	return filterEvents(events, opts, now), err
+}
+
+// readHistory returns the history of the current project.
+func readHistory(opts HistoryOptions, now time.Time) ([]Event, error) {
+	store, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Events(opts, now)
+}
+
+func init() {
+	storeCmd := &cobra.Command{
+		Use:   "store",
+		Short: "Show where worker state and history are stored",
+		Run:   func(cmd *cobra.Command, args []string) { showStore() },
+	}
+
+	storeMigrateCmd := &cobra.Command{
+		Use:   "migrate <json|sqlite>",
+		Short: "Move worker state and history to another store",
+		Long: `Move the workers, task queues, counters and history of the project to
+another store and select it in the config. The SQLite store keeps them in
+.gtw/state.db, indexed so that the daemon, task queue and stats stay fast
+as the history grows, and saves only the workers that changed so that
+parallel gtw commands do not overwrite each other. The files of the old
+store are kept with a .migrated suffix.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{StoreJSON, StoreSQLite},
+		Run: func(cmd *cobra.Command, args []string) {
+			if !migrateStore(args[0]) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	storeCmd.AddCommand(storeMigrateCmd)
+	rootCmd.AddCommand(storeCmd)
+}
+
+func showStore() {
+	store, err := openStore()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	switch store.Name() {
+	case StoreSQLite:
+		fmt.Printf("Store: %s (settings in %s, state and history in %s)\n", store.Name(), configFile, sqliteStoreFile)
+	default:
+		fmt.Printf("Store: %s (settings and state in %s, history in %s)\n", store.Name(), configFile, historyFile)
+	}
+}
+
+// migrateStore copies the state and history into the store named to and
+// selects it.
+func migrateStore(to string) bool {
+	to = strings.ToLower(to)
+	from, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if to != StoreJSON && to != StoreSQLite {
+		fmt.Fprintf(os.Stderr, "Error: unknown store %q (expected %s or %s)\n", to, StoreJSON, StoreSQLite)
+		return false
+	}
+	if from.Name() == to {
+		fmt.Printf("Already using the %s store\n", to)
+		return true
+	}
+
+	// The persisted form, with every workspace
+	config, err := readConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	if err := from.Load(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+		return false
+	}
+	events, err := from.Events(HistoryOptions{}, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		return false
+	}
+
+	// The target must not have state of its own, which would be mixed in
+	oldFile := historyFile
+	if to == StoreJSON {
+		oldFile = sqliteStoreFile
+	}
+	target := historyFile
+	if to == StoreSQLite {
+		target = sqliteStoreFile
+	}
+	if _, err := os.Stat(target); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists; move it away first\n", target)
+		return false
+	}
+
+	config.Store = to
+	if to == StoreJSON {
+		config.Store = ""
+	}
+	store, err := newStore(to, ".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	for _, event := range events {
+		if err := store.AppendEvent(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying history: %v\n", err)
+			return false
+		}
+	}
+	if err := store.Save(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving state: %v\n", err)
+		return false
+	}
+
+	if sqlite, ok := from.(*SQLiteStore); ok {
+		sqlite.Close()
+	}
+	if _, err := os.Stat(oldFile); err == nil {
+		if err := os.Rename(oldFile, oldFile+".migrated"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not rename %s: %v\n", oldFile, err)
+		}
+	}
+	fmt.Printf("✅ Moved %d worker(s) and %d event(s) to the %s store\n", countStoredWorkers(config), len(events), to)
+	return true
+}
+
+// countStoredWorkers counts the active and archived workers of every
+// workspace.
+func countStoredWorkers(config *Config) int {
+	count := len(config.Workers) + len(config.Archived)
+	for _, ws := range config.Workspaces {
+		count += len(ws.Workers)
+	}
+	return count
+}
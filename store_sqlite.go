@@ -0,0 +1,501 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go driver, so that gtw still builds without cgo
+)
+
+const sqliteStoreFile = ".gtw/state.db"
+
+// Lists of workers in the workers table
+const (
+	storedActive   = "workers"
+	storedArchived = "archived"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS workspaces (
+	name TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS workers (
+	list      TEXT NOT NULL,
+	workspace TEXT NOT NULL,
+	id        TEXT NOT NULL,
+	position  INTEGER NOT NULL,
+	data      TEXT NOT NULL,
+	PRIMARY KEY (list, workspace, id)
+);
+CREATE TABLE IF NOT EXISTS tasks (
+	list       TEXT NOT NULL,
+	workspace  TEXT NOT NULL,
+	worker     TEXT NOT NULL,
+	id         INTEGER NOT NULL,
+	status     TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	data       TEXT NOT NULL,
+	PRIMARY KEY (list, workspace, worker, id)
+);
+CREATE INDEX IF NOT EXISTS tasks_status ON tasks (status, created_at);
+CREATE TABLE IF NOT EXISTS counters (
+	name  TEXT PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	time      TEXT NOT NULL,
+	type      TEXT NOT NULL,
+	worker    TEXT NOT NULL,
+	workspace TEXT NOT NULL,
+	data      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_time ON events (time);
+CREATE INDEX IF NOT EXISTS events_worker ON events (worker, time);
+CREATE INDEX IF NOT EXISTS events_type ON events (type, time);
+`
+
+// storedTime formats times so that they sort as text.
+const storedTime = "2006-01-02T15:04:05.000000000Z"
+
+// SQLiteStore keeps the state and history in a SQLite database. Workers
+// and tasks are rows, and Save writes only those that changed since Load,
+// so that gtw commands running at the same time (e.g. parallel `gtw add`)
+// do not overwrite each other's workers.
+type SQLiteStore struct {
+	Path string
+	db   *sql.DB
+}
+
+var (
+	sqliteStoresMu sync.Mutex
+	sqliteStores   = make(map[string]*SQLiteStore) // Open databases by path
+)
+
+// openSQLiteStore opens (creating if needed) the database at path. Each
+// database is opened once per process.
+func openSQLiteStore(path string) (*SQLiteStore, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	sqliteStoresMu.Lock()
+	defer sqliteStoresMu.Unlock()
+	if store, ok := sqliteStores[abs]; ok {
+		return store, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return nil, err
+	}
+	// Wait for other gtw processes instead of failing with SQLITE_BUSY, and
+	// take the write lock when a transaction starts so that it cannot fail
+	// half way
+	dsn := "file:" + abs + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not set up %s: %v", path, err)
+	}
+	store := &SQLiteStore{Path: abs, db: db}
+	sqliteStores[abs] = store
+	return store, nil
+}
+
+func (s *SQLiteStore) Name() string { return StoreSQLite }
+
+func (s *SQLiteStore) Close() error {
+	sqliteStoresMu.Lock()
+	defer sqliteStoresMu.Unlock()
+	delete(sqliteStores, s.Path)
+	return s.db.Close()
+}
+
+// workerKey identifies a row of the workers table.
+type workerKey struct {
+	List, Workspace, ID string
+}
+
+// workerRow is a worker as stored, without its tasks.
+type workerRow struct {
+	Position int
+	Data     string
+}
+
+// taskKey identifies a row of the tasks table.
+type taskKey struct {
+	List, Workspace, Worker string
+	ID                      int
+}
+
+// storedState is what Load read, to be compared with the config on Save.
+type storedState struct {
+	Workers    map[workerKey]workerRow
+	Tasks      map[taskKey]string
+	Workspaces map[string]bool
+	Counters   Counters
+}
+
+func (s *SQLiteStore) initialized() (bool, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'initialized'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *SQLiteStore) Load(config *Config) error {
+	initialized, err := s.initialized()
+	if err != nil {
+		return err
+	}
+	if !initialized {
+		// Switched to SQLite by hand: the state is still in the config file
+		// and is moved to the database on the next save
+		config.stored = &storedState{}
+		return nil
+	}
+
+	stored := &storedState{
+		Workers:    make(map[workerKey]workerRow),
+		Tasks:      make(map[taskKey]string),
+		Workspaces: make(map[string]bool),
+	}
+	tasks := make(map[workerKey][]Task)
+	rows, err := s.db.Query(`SELECT list, workspace, worker, id, data FROM tasks ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var key taskKey
+		var data string
+		if err := rows.Scan(&key.List, &key.Workspace, &key.Worker, &key.ID, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		var task Task
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			rows.Close()
+			return fmt.Errorf("task %d of worker '%s': %v", key.ID, key.Worker, err)
+		}
+		stored.Tasks[key] = data
+		owner := workerKey{key.List, key.Workspace, key.Worker}
+		tasks[owner] = append(tasks[owner], task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	config.Workers = []Worker{}
+	config.Archived = nil
+	config.Workspaces = nil
+	workspaces, err := s.db.Query(`SELECT name FROM workspaces`)
+	if err != nil {
+		return err
+	}
+	for workspaces.Next() {
+		var name string
+		if err := workspaces.Scan(&name); err != nil {
+			workspaces.Close()
+			return err
+		}
+		stored.Workspaces[name] = true
+		if config.Workspaces == nil {
+			config.Workspaces = make(map[string]*Workspace)
+		}
+		config.Workspaces[name] = &Workspace{Workers: []Worker{}}
+	}
+	workspaces.Close()
+
+	// Workers added at the same time by different processes share a
+	// position; rowid keeps them in the order they were added
+	rows, err = s.db.Query(`SELECT list, workspace, id, position, data FROM workers ORDER BY position, rowid`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key workerKey
+		var row workerRow
+		if err := rows.Scan(&key.List, &key.Workspace, &key.ID, &row.Position, &row.Data); err != nil {
+			return err
+		}
+		var worker Worker
+		if err := json.Unmarshal([]byte(row.Data), &worker); err != nil {
+			return fmt.Errorf("worker '%s': %v", key.ID, err)
+		}
+		worker.Tasks = tasks[key]
+		stored.Workers[key] = row
+
+		switch {
+		case key.List == storedArchived:
+			config.Archived = append(config.Archived, worker)
+		case key.Workspace == "":
+			config.Workers = append(config.Workers, worker)
+		default:
+			if config.Workspaces == nil {
+				config.Workspaces = make(map[string]*Workspace)
+			}
+			if config.Workspaces[key.Workspace] == nil {
+				config.Workspaces[key.Workspace] = &Workspace{Workers: []Worker{}}
+			}
+			ws := config.Workspaces[key.Workspace]
+			ws.Workers = append(ws.Workers, worker)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var counters Counters
+	values := map[string]*int64{
+		"workers_added":   &counters.WorkersAdded,
+		"workers_removed": &counters.WorkersRemoved,
+		"init_failures":   &counters.InitFailures,
+	}
+	for name, value := range values {
+		if err := s.db.QueryRow(`SELECT value FROM counters WHERE name = ?`, name).Scan(value); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	config.Counters = nil
+	if counters != (Counters{}) {
+		config.Counters = &counters
+	}
+	stored.Counters = counters
+	config.stored = stored
+	return nil
+}
+
+// storedRows splits the state of config into rows.
+func storedRows(config *Config) (map[workerKey]workerRow, map[taskKey]string, map[string]bool, error) {
+	workers := make(map[workerKey]workerRow)
+	tasks := make(map[taskKey]string)
+	workspaces := make(map[string]bool)
+	add := func(list, ws string, position int, worker Worker) error {
+		for _, task := range worker.Tasks {
+			data, err := json.Marshal(task)
+			if err != nil {
+				return err
+			}
+			tasks[taskKey{list, ws, worker.ID, task.ID}] = string(data)
+		}
+		worker.Tasks = nil
+		data, err := json.Marshal(worker)
+		if err != nil {
+			return err
+		}
+		workers[workerKey{list, ws, worker.ID}] = workerRow{Position: position, Data: string(data)}
+		return nil
+	}
+
+	for i, worker := range config.Workers {
+		if err := add(storedActive, "", i, worker); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for i, worker := range config.Archived {
+		if err := add(storedArchived, "", i, worker); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for name, ws := range config.Workspaces {
+		workspaces[name] = true
+		if ws == nil {
+			continue
+		}
+		for i, worker := range ws.Workers {
+			if err := add(storedActive, name, i, worker); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	return workers, tasks, workspaces, nil
+}
+
+func (s *SQLiteStore) Save(config *Config) error {
+	workers, tasks, workspaces, err := storedRows(config)
+	if err != nil {
+		return err
+	}
+	// Shared with the copy made by Config.persisted, so it is updated in place
+	stored := config.stored
+	if stored == nil {
+		stored = &storedState{}
+	}
+	counters := Counters{}
+	if config.Counters != nil {
+		counters = *config.Counters
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for key, row := range workers {
+		if old, ok := stored.Workers[key]; ok && old == row {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO workers (list, workspace, id, position, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (list, workspace, id) DO UPDATE SET position = excluded.position, data = excluded.data`,
+			key.List, key.Workspace, key.ID, row.Position, row.Data); err != nil {
+			return err
+		}
+	}
+	for key := range stored.Workers {
+		if _, ok := workers[key]; !ok {
+			if _, err := tx.Exec(`DELETE FROM workers WHERE list = ? AND workspace = ? AND id = ?`, key.List, key.Workspace, key.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, data := range tasks {
+		if old, ok := stored.Tasks[key]; ok && old == data {
+			continue
+		}
+		var task Task
+		json.Unmarshal([]byte(data), &task)
+		if _, err := tx.Exec(`INSERT INTO tasks (list, workspace, worker, id, status, created_at, data) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (list, workspace, worker, id) DO UPDATE SET status = excluded.status, data = excluded.data`,
+			key.List, key.Workspace, key.Worker, key.ID, task.Status, task.CreatedAt.UTC().Format(storedTime), data); err != nil {
+			return err
+		}
+	}
+	for key := range stored.Tasks {
+		if _, ok := tasks[key]; !ok {
+			if _, err := tx.Exec(`DELETE FROM tasks WHERE list = ? AND workspace = ? AND worker = ? AND id = ?`, key.List, key.Workspace, key.Worker, key.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name := range workspaces {
+		if !stored.Workspaces[name] {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO workspaces (name) VALUES (?)`, name); err != nil {
+				return err
+			}
+		}
+	}
+	for name := range stored.Workspaces {
+		if !workspaces[name] {
+			if _, err := tx.Exec(`DELETE FROM workspaces WHERE name = ?`, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Counters are only ever incremented: add what this process counted
+	deltas := map[string]int64{
+		"workers_added":   counters.WorkersAdded - stored.Counters.WorkersAdded,
+		"workers_removed": counters.WorkersRemoved - stored.Counters.WorkersRemoved,
+		"init_failures":   counters.InitFailures - stored.Counters.InitFailures,
+	}
+	for name, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO counters (name, value) VALUES (?, ?)
+			ON CONFLICT (name) DO UPDATE SET value = value + excluded.value`, name, delta); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO meta (key, value) VALUES ('initialized', ?)`, time.Now().UTC().Format(storedTime)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	*stored = storedState{Workers: workers, Tasks: tasks, Workspaces: workspaces, Counters: counters}
+	config.stored = stored
+
+	return saveSettingsFile(filepath.Join(filepath.Dir(filepath.Dir(s.Path)), configFile), config)
+}
+
+// saveSettingsFile writes the config file without the state kept in the
+// database, leaving it alone when nothing changed.
+func saveSettingsFile(file string, config *Config) error {
+	settings := *config
+	settings.Workers = []Worker{}
+	settings.Archived = nil
+	settings.Workspaces = nil
+	settings.Counters = nil
+	data, err := json.MarshalIndent(&settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if existing, err := os.ReadFile(file); err == nil && string(existing) == string(data) {
+		return nil
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+func (s *SQLiteStore) AppendEvent(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO events (time, type, worker, workspace, data) VALUES (?, ?, ?, ?, ?)`,
+		event.Time.UTC().Format(storedTime), event.Type, event.Worker, event.Workspace, string(data))
+	return err
+}
+
+func (s *SQLiteStore) Events(opts HistoryOptions, now time.Time) ([]Event, error) {
+	query := `SELECT data FROM events WHERE 1 = 1`
+	var args []interface{}
+	if opts.Worker != "" {
+		query += ` AND worker = ?`
+		args = append(args, opts.Worker)
+	}
+	if opts.Type != "" {
+		query += ` AND type = ?`
+		args = append(args, opts.Type)
+	}
+	if opts.Since > 0 {
+		query += ` AND time >= ?`
+		args = append(args, now.Add(-opts.Since).UTC().Format(storedTime))
+	}
+	rows, err := s.db.Query(query+` ORDER BY time, seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var event Event
+		if json.Unmarshal([]byte(data), &event) == nil {
+			events = append(events, event)
+		}
+	}
+	return events, rows.Err()
+}
+
+// storedWorkerCount counts the workers of the default workspace, for
+// listing projects without loading their config.
+func (s *SQLiteStore) storedWorkerCount() (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM workers WHERE list = ? AND workspace = ''`, storedActive).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// sqliteProject sets up a project using the SQLite store in a temporary
+// directory.
+func sqliteProject(t *testing.T) *SQLiteStore {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(configFile, []byte(`{"workers": [], "store": "sqlite"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := openSQLiteStore(sqliteStoreFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStoreRoundTrip(t *testing.T) {
+	sqliteProject(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.InitCommand = "claude"
+	config.Workers = []Worker{
+		{ID: "w1", Status: StateReady, CreatedAt: now, Tasks: []Task{{ID: 1, Command: "run tests", Status: TaskQueued, CreatedAt: now}}},
+		{ID: "w2", Status: StateBusy, CreatedAt: now},
+	}
+	config.Archived = []Worker{{ID: "old", Status: StateArchived}}
+	config.Workspaces = map[string]*Workspace{"review": {Workers: []Worker{{ID: "r1"}}}}
+	config.counters().WorkersAdded = 3
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	// The state is in the database, the settings in the config file
+	settings, err := readConfigFile()
+	if err != nil || len(settings.Workers) != 0 || settings.Counters != nil || settings.InitCommand != "claude" {
+		t.Errorf("Expected only settings in the config file, got %+v (%v)", settings, err)
+	}
+
+	loaded, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Workers) != 2 || loaded.Workers[0].ID != "w1" || loaded.Workers[1].ID != "w2" {
+		t.Fatalf("Expected w1 and w2 in order, got %+v", loaded.Workers)
+	}
+	if len(loaded.Workers[0].Tasks) != 1 || loaded.Workers[0].Tasks[0].Command != "run tests" {
+		t.Errorf("Expected the task of w1, got %+v", loaded.Workers[0].Tasks)
+	}
+	if len(loaded.Archived) != 1 || loaded.Workspaces["review"] == nil || len(loaded.Workspaces["review"].Workers) != 1 {
+		t.Errorf("Expected the archived and workspace workers, got %+v %+v", loaded.Archived, loaded.Workspaces)
+	}
+	if loaded.Counters == nil || loaded.Counters.WorkersAdded != 3 {
+		t.Errorf("Expected the counters, got %+v", loaded.Counters)
+	}
+
+	// Removing a worker and its tasks
+	loaded.Workers = loaded.Workers[1:]
+	if err := saveConfig(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := loadConfig(); len(again.Workers) != 1 || again.Workers[0].ID != "w2" {
+		t.Errorf("Expected only w2, got %+v", again.Workers)
+	}
+}
+
+func TestSQLiteStoreConcurrentSaves(t *testing.T) {
+	sqliteProject(t)
+
+	// Two commands load the same state and each add a worker
+	first, _ := loadConfig()
+	second, _ := loadConfig()
+	first.Workers = append(first.Workers, Worker{ID: "a"})
+	first.counters().WorkersAdded++
+	second.Workers = append(second.Workers, Worker{ID: "b"})
+	second.counters().WorkersAdded++
+	if err := saveConfig(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveConfig(second); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _ := loadConfig()
+	if len(config.Workers) != 2 || config.Workers[0].ID != "a" || config.Workers[1].ID != "b" {
+		t.Errorf("Expected both workers to be kept, got %+v", config.Workers)
+	}
+	if config.Counters == nil || config.Counters.WorkersAdded != 2 {
+		t.Errorf("Expected both additions to be counted, got %+v", config.Counters)
+	}
+}
+
+func TestSQLiteStoreWorkspace(t *testing.T) {
+	sqliteProject(t)
+	previous := workspace
+	t.Cleanup(func() { workspace = previous })
+
+	config, _ := loadConfig()
+	config.Workers = []Worker{{ID: "w1"}}
+	saveConfig(config)
+
+	// Workers added and removed while another workspace is selected
+	workspace = "review"
+	config, _ = loadConfig()
+	config.Workers = append(config.Workers, Worker{ID: "r1"})
+	saveConfig(config)
+	config.Workers = nil
+	saveConfig(config)
+
+	config, _ = loadConfig()
+	if len(config.Workers) != 0 || config.Workspaces["review"] == nil {
+		t.Errorf("Expected the empty review workspace, got %+v %+v", config.Workers, config.Workspaces)
+	}
+	workspace = ""
+	if config, _ := loadConfig(); len(config.Workers) != 1 || config.Workers[0].ID != "w1" {
+		t.Errorf("Expected the default workspace to be untouched, got %+v", config.Workers)
+	}
+}
+
+func TestSQLiteStoreEvents(t *testing.T) {
+	store := sqliteProject(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, event := range []Event{
+		{Time: now.Add(-3 * time.Hour), Type: EventWorkerAdded, Worker: "w1"},
+		{Time: now.Add(-time.Hour), Type: EventTaskSent, Worker: "w1", Detail: "#1 run tests"},
+		{Time: now.Add(-time.Minute), Type: EventWorkerAdded, Worker: "w2"},
+	} {
+		if err := store.AppendEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if events, err := store.Events(HistoryOptions{}, now); err != nil || len(events) != 3 || events[1].Detail != "#1 run tests" {
+		t.Errorf("Expected all events in order, got %+v (%v)", events, err)
+	}
+	if events, _ := store.Events(HistoryOptions{Worker: "w1"}, now); len(events) != 2 {
+		t.Errorf("Expected 2 events for w1, got %d", len(events))
+	}
+	if events, _ := store.Events(HistoryOptions{Type: EventWorkerAdded, Since: 2 * time.Hour}, now); len(events) != 1 || events[0].Worker != "w2" {
+		t.Errorf("Expected only w2's creation, got %+v", events)
+	}
+}
+
+func TestMigrateStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	os.WriteFile(configFile, []byte(`{"workers": [{"id": "w1", "status": "ready"}], "init_command": "claude"}`), 0644)
+	appendEvent(historyFile, Event{Time: time.Now(), Type: EventWorkerAdded, Worker: "w1"})
+
+	if !migrateStore(StoreSQLite) {
+		t.Fatal("Expected the migration to SQLite to succeed")
+	}
+	if name, _ := configuredStore(configFile); name != StoreSQLite {
+		t.Errorf("Expected the SQLite store to be selected, got %q", name)
+	}
+	if _, err := os.Stat(historyFile + ".migrated"); err != nil {
+		t.Errorf("Expected the old history to be kept: %v", err)
+	}
+	config, err := loadConfig()
+	if err != nil || len(config.Workers) != 1 || config.InitCommand != "claude" {
+		t.Fatalf("Expected w1 from the SQLite store, got %+v (%v)", config, err)
+	}
+	if events, _ := readHistory(HistoryOptions{}, time.Now()); len(events) != 1 {
+		t.Errorf("Expected the history to be moved, got %d events", len(events))
+	}
+
+	if !migrateStore(StoreJSON) {
+		t.Fatal("Expected the migration back to JSON to succeed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, sqliteStoreFile+".migrated")); err != nil {
+		t.Errorf("Expected the database to be kept: %v", err)
+	}
+	config, err = loadConfig()
+	if err != nil || len(config.Workers) != 1 || config.Store != "" {
+		t.Errorf("Expected w1 back in the config file, got %+v (%v)", config, err)
+	}
+	if events, _ := readEvents(historyFile); len(events) != 1 {
+		t.Errorf("Expected the history back in %s, got %d events", historyFile, len(events))
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, configFile)
+	os.WriteFile(path, []byte(`{"workers": [{"id": "a"}]}`), 0600)
+
+	if err := writeFileAtomic(path, []byte(`{"workers": []}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"workers": []}` {
+		t.Errorf("Unexpected content: %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %v", info.Mode().Perm())
+	}
+	// No temporary file is left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the config file, got %d entries", len(entries))
+	}
+}
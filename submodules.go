@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// usesSubmodules reports whether the worktree has submodules.
+func usesSubmodules(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".gitmodules"))
+	return err == nil
+}
+
+// usesLFS reports whether the worktree's .gitattributes tracks files with
+// Git LFS.
+func usesLFS(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	return err == nil && strings.Contains(string(data), "filter=lfs")
+}
+
+// prepareWorktree fetches what a fresh worktree lacks: submodules and LFS
+// objects, when enabled in the config and used by the repository. git's
+// progress is shown as it runs. Failures are reported as warnings since the
+// worktree itself is usable.
+func prepareWorktree(config *Config, dir string) {
+	if config.Submodules && usesSubmodules(dir) {
+		fmt.Println("Updating submodules...")
+		cmd := gitCommand("-C", dir, "submodule", "update", "--init", "--recursive", "--progress")
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		cmd.Timeout = 0 // Cloning submodules can take a while
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("Warning: Updating submodules failed: %v\n", err)
+		}
+	}
+
+	if config.LFS && usesLFS(dir) {
+		if err := gitCommand("lfs", "version").Run(); err != nil {
+			fmt.Println("Warning: The repository uses Git LFS but git-lfs is not installed; skipping 'git lfs pull'")
+			return
+		}
+		fmt.Println("Pulling Git LFS objects...")
+		cmd := gitCommand("-C", dir, "lfs", "pull")
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		cmd.Timeout = 0
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("Warning: git lfs pull failed: %v\n", err)
+		}
+	}
+}
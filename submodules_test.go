@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsesLFS(t *testing.T) {
+	dir := t.TempDir()
+	if usesLFS(dir) || usesSubmodules(dir) {
+		t.Error("Expected an empty directory to use neither")
+	}
+	os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), 0644)
+	if !usesLFS(dir) {
+		t.Error("Expected LFS to be detected")
+	}
+}
+
+func TestPrepareWorktreeSubmodules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	// Local submodules are refused by default since git 2.38
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	lib, dir := t.TempDir(), t.TempDir()
+	git := func(dir string, args ...string) {
+		if output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git(lib, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(lib, "lib.txt"), []byte("lib"), 0644)
+	git(lib, "add", ".")
+	git(lib, "commit", "-q", "-m", "lib")
+	git(dir, "init", "-q", "-b", "main")
+	git(dir, "submodule", "add", "-q", lib, "lib")
+	git(dir, "commit", "-q", "-m", "add lib")
+	git(dir, "worktree", "add", "-q", "-b", "api", "wt")
+	worktree := filepath.Join(dir, "wt")
+
+	prepareWorktree(&Config{}, worktree)
+	if _, err := os.Stat(filepath.Join(worktree, "lib", "lib.txt")); err == nil {
+		t.Fatal("Expected submodules to be left alone unless enabled")
+	}
+	prepareWorktree(&Config{Submodules: true}, worktree)
+	if _, err := os.Stat(filepath.Join(worktree, "lib", "lib.txt")); err != nil {
+		t.Errorf("Expected the submodule to be checked out: %v", err)
+	}
+}
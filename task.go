@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Task is a prompt or command queued for a worker. Tasks are sent to the
+// worker's pane in FIFO order whenever the worker becomes idle.
+type Task struct {
+	ID        int        `json:"id"`
+	Command   string     `json:"command"`
+	Status    string     `json:"status"` // queued, sent
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+const (
+	TaskQueued = "queued"
+	TaskSent   = "sent"
+)
+
+const defaultIdleQuiet = 10 * time.Second
+
+func init() {
+	taskCmd := &cobra.Command{
+		Use:   "task",
+		Short: "Manage per-worker task queues",
+	}
+
+	taskAddCmd := &cobra.Command{
+		Use:   "add <worker-id> <prompt/command>",
+		Short: "Queue a task for a worker",
+		Args:  cobra.ExactArgs(2),
+		Run:   func(cmd *cobra.Command, args []string) { addTask(args[0], args[1]) },
+	}
+
+	taskListCmd := &cobra.Command{
+		Use:   "list [worker-id]",
+		Short: "List queued and sent tasks",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) == 1 {
+				id = args[0]
+			}
+			listTasks(id)
+		},
+	}
+
+	taskClearCmd := &cobra.Command{
+		Use:   "clear <worker-id>",
+		Short: "Drop all queued tasks of a worker",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { clearTasks(args[0]) },
+	}
+
+	var dispatchQuiet time.Duration
+	taskDispatchCmd := &cobra.Command{
+		Use:   "dispatch",
+		Short: "Send the next queued task to every idle worker",
+		Run: func(cmd *cobra.Command, args []string) {
+			tracker := NewIdleTracker(dispatchQuiet)
+			// Sample twice so output changes during the quiet period count as busy
+			dispatchTasks(tracker, false)
+			time.Sleep(dispatchQuiet)
+			dispatchTasks(tracker, true)
+		},
+	}
+	taskDispatchCmd.Flags().DurationVar(&dispatchQuiet, "quiet", 3*time.Second, "How long pane output must stay unchanged to count as idle")
+
+	taskResumeCmd := &cobra.Command{
+		Use:   "resume <worker-id>",
+		Short: "Dispatch tasks to a worker again after a pause policy held its queue",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { resumeTasks(args[0]) },
+	}
+
+	taskCmd.AddCommand(taskAddCmd, taskListCmd, taskClearCmd, taskDispatchCmd, taskResumeCmd)
+	rootCmd.AddCommand(taskCmd)
+}
+
+// sendToPane types text into the pane literally and presses Enter.
+func sendToPane(paneID, text string) error {
+	return mux.SendKeys(paneID, text)
+}
+
+// queuedTasks returns the worker's tasks that have not been sent yet.
+func queuedTasks(worker Worker) []Task {
+	var queued []Task
+	for _, task := range worker.Tasks {
+		if task.Status == TaskQueued {
+			queued = append(queued, task)
+		}
+	}
+	return queued
+}
+
+func addTask(id, command string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	worker := &config.Workers[index]
+	nextID := 1
+	for _, task := range worker.Tasks {
+		if task.ID >= nextID {
+			nextID = task.ID + 1
+		}
+	}
+
+	worker.Tasks = append(worker.Tasks, Task{
+		ID:        nextID,
+		Command:   command,
+		Status:    TaskQueued,
+		CreatedAt: time.Now(),
+	})
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Queued task #%d for worker '%s' (%d in queue)\n", nextID, id, len(queuedTasks(*worker)))
+}
+
+func listTasks(id string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	if id != "" && findWorkerIndex(config, id) == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	fmt.Printf("%-20s %-6s %-8s %-17s %s\n", "WORKER", "TASK", "STATUS", "CREATED", "COMMAND")
+	fmt.Println(strings.Repeat("-", 90))
+
+	count := 0
+	for _, worker := range config.Workers {
+		if id != "" && worker.ID != id {
+			continue
+		}
+		for _, task := range worker.Tasks {
+			fmt.Printf("%-20s %-6s %-8s %-17s %s\n",
+				worker.ID,
+				fmt.Sprintf("#%d", task.ID),
+				task.Status,
+				task.CreatedAt.Format("2006-01-02 15:04"),
+				task.Command)
+			count++
+		}
+	}
+
+	if count == 0 {
+		fmt.Println("No tasks found")
+	}
+	for _, worker := range config.Workers {
+		if worker.TasksHeld && (id == "" || worker.ID == id) {
+			fmt.Printf("⏸️  The task queue of worker '%s' is held (run 'gtw task resume %s')\n", worker.ID, worker.ID)
+		}
+	}
+}
+
+func clearTasks(id string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+
+	worker := &config.Workers[index]
+	var kept []Task
+	dropped := 0
+	for _, task := range worker.Tasks {
+		if task.Status == TaskQueued {
+			dropped++
+			continue
+		}
+		kept = append(kept, task)
+	}
+	worker.Tasks = kept
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Dropped %d queued task(s) for worker '%s'\n", dropped, id)
+}
+
+// dispatchTasks sends the next queued task to every worker that the tracker
+// considers idle. When send is false the panes are only sampled, which lets
+// the caller establish a baseline before the quiet period. It returns the
+// number of tasks sent.
+func dispatchTasks(tracker *IdleTracker, send bool) int {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return 0
+	}
+	loaded := snapshotWorkerState(config.Workers)
+
+	sent := 0
+	for i := range config.Workers {
+		worker := &config.Workers[i]
+		queued := queuedTasks(*worker)
+		if len(queued) == 0 || worker.Status == WorkerPaused || worker.TasksHeld {
+			continue
+		}
+
+		idle, err := tracker.Observe(worker.PaneID)
+		if err != nil {
+			fmt.Printf("Warning: Could not inspect worker '%s': %v\n", worker.ID, err)
+			continue
+		}
+		if !idle || !send {
+			continue
+		}
+		// A pane waiting at a permission prompt looks idle; a task typed
+		// into it would answer the prompt
+		if waitingAtPrompt(worker.PaneID) {
+			continue
+		}
+
+		next := queued[0]
+		fmt.Printf("Sending task #%d to worker '%s': %s\n", next.ID, worker.ID, next.Command)
+		if err := sendToPane(worker.PaneID, next.Command); err != nil {
+			fmt.Printf("Warning: Could not send task to worker '%s': %v\n", worker.ID, err)
+			continue
+		}
+		tracker.Touch(worker.PaneID)
+		recordEvent(EventTaskSent, worker.ID, fmt.Sprintf("#%d %s", next.ID, next.Command))
+
+		now := time.Now()
+		for j := range worker.Tasks {
+			if worker.Tasks[j].ID == next.ID {
+				worker.Tasks[j].Status = TaskSent
+				worker.Tasks[j].SentAt = &now
+			}
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		if err := saveWorkerChanges(config, loaded); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+		}
+	}
+	return sent
+}
+
+// resumeTasks lets the daemon dispatch to a worker whose queue a pause
+// policy held.
+func resumeTasks(id string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	if !config.Workers[index].TasksHeld {
+		fmt.Printf("The task queue of worker '%s' is not held\n", id)
+		return
+	}
+	config.Workers[index].TasksHeld = false
+
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Resumed the task queue of worker '%s'\n", id)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// paneMultiplexer shows a fixed foreground command and content per pane. A
+// pane without a command is gone.
+type paneMultiplexer struct {
+	fakeMultiplexer
+	commands map[string]string
+	output   map[string]string
+}
+
+func (p *paneMultiplexer) CurrentCommand(paneID string) (string, error) {
+	command, ok := p.commands[paneID]
+	if !ok {
+		return "", errors.New("no such pane")
+	}
+	return command, nil
+}
+
+func (p *paneMultiplexer) Capture(paneID string, lines int) (string, error) {
+	return p.output[paneID], nil
+}
+
+func queued(commands ...string) []Task {
+	var tasks []Task
+	for i, command := range commands {
+		tasks = append(tasks, Task{ID: i + 1, Command: command, Status: TaskQueued})
+	}
+	return tasks
+}
+
+func TestQueuedTasks(t *testing.T) {
+	worker := Worker{Tasks: queued("a", "b", "c")}
+	worker.Tasks[0].Status = TaskSent
+
+	got := queuedTasks(worker)
+	if len(got) != 2 || got[0].Command != "b" || got[1].Command != "c" {
+		t.Errorf("queuedTasks() = %+v", got)
+	}
+}
+
+func TestDispatchTasks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := &Config{Workers: []Worker{
+		{ID: "idle", PaneID: "%1", Tasks: queued("make test", "make lint")},
+		{ID: "paused", PaneID: "%2", Status: WorkerPaused, Tasks: queued("make")},
+		{ID: "held", PaneID: "%3", TasksHeld: true, Tasks: queued("make")},
+		{ID: "prompt", PaneID: "%4", Tasks: queued("make")},
+		{ID: "busy", PaneID: "%5", Tasks: queued("make")},
+		{ID: "gone", PaneID: "%6", Tasks: queued("make")},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	fake := &paneMultiplexer{
+		commands: map[string]string{"%1": "zsh", "%2": "zsh", "%3": "zsh", "%4": "bash", "%5": "claude"},
+		output:   map[string]string{"%1": "$ ", "%4": "Overwrite config.json? [y/N] ", "%5": "thinking"},
+	}
+	useMultiplexer(t, fake)
+
+	tracker := NewIdleTracker(time.Hour)
+	if got := dispatchTasks(tracker, false); got != 0 || len(fake.sent) != 0 {
+		t.Fatalf("Expected nothing sent while sampling, got %d: %v", got, fake.sent)
+	}
+	if got := dispatchTasks(tracker, true); got != 1 {
+		t.Errorf("Expected 1 task sent, got %d", got)
+	}
+	if len(fake.sent) != 1 || fake.sent[0] != "%1 make test" {
+		t.Errorf("Expected only the first task of the idle worker sent, got %v", fake.sent)
+	}
+
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := saved.Workers[0].Tasks
+	if tasks[0].Status != TaskSent || tasks[0].SentAt == nil || tasks[1].Status != TaskQueued {
+		t.Errorf("Expected the first task marked sent and the second queued, got %+v", tasks)
+	}
+	for _, worker := range saved.Workers[1:] {
+		if len(queuedTasks(worker)) != 1 {
+			t.Errorf("Expected the task of worker '%s' to stay queued", worker.ID)
+		}
+	}
+
+	// The tracker counts the send as activity, so the next task waits for
+	// the quiet period when the pane is not at a shell
+	fake.commands["%1"] = "claude"
+	if got := dispatchTasks(tracker, true); got != 0 {
+		t.Errorf("Expected no task sent right after the last one, got %d", got)
+	}
+}
+
+func TestDispatchTasksKeepsConcurrentChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := saveConfig(&Config{Workers: []Worker{{ID: "a", PaneID: "%1", Tasks: queued("make")}}}); err != nil {
+		t.Fatal(err)
+	}
+	fake := &paneMultiplexer{commands: map[string]string{"%1": "zsh"}, output: map[string]string{"%1": "$ "}}
+	useMultiplexer(t, &addingMultiplexer{paneMultiplexer: fake, t: t})
+
+	if got := dispatchTasks(NewIdleTracker(time.Hour), true); got != 1 {
+		t.Fatalf("Expected 1 task sent, got %d", got)
+	}
+
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Workers) != 2 || saved.Workers[1].ID != "b" {
+		t.Errorf("Expected the worker added meanwhile to be kept, got %+v", saved.Workers)
+	}
+	if saved.Workers[0].Tasks[0].Status != TaskSent {
+		t.Errorf("Expected the task marked sent, got %+v", saved.Workers[0].Tasks)
+	}
+}
+
+// addingMultiplexer adds a worker to the config, like a concurrent 'gtw
+// add', when keys are sent.
+type addingMultiplexer struct {
+	*paneMultiplexer
+	t *testing.T
+}
+
+func (a *addingMultiplexer) SendKeys(paneID, text string) error {
+	config, err := loadConfig()
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	config.Workers = append(config.Workers, Worker{ID: "b", PaneID: "%2"})
+	if err := saveConfig(config); err != nil {
+		a.t.Fatal(err)
+	}
+	return a.paneMultiplexer.SendKeys(paneID, text)
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const testOutputTail = 8 * 1024 // Bytes of output kept per worker for reports
+
+// TestOptions holds the settings given to `gtw test`.
+type TestOptions struct {
+	All      bool
+	Tags     []string
+	Command  string // Overrides test_command
+	Parallel int
+	Timeout  time.Duration
+	JSON     string   // Write the JSON report to this file ("-" for stdout)
+	Reports  []string // JUnit or Markdown reports (see reportTarget)
+}
+
+// TestResult is the outcome of the test command in one worker.
+type TestResult struct {
+	Worker   string     `json:"worker"`
+	Branch   string     `json:"branch"`
+	Passed   bool       `json:"passed"`
+	ExitCode int        `json:"exit_code"`
+	Duration float64    `json:"duration_seconds"`
+	Error    string     `json:"error,omitempty"`  // Why the command could not run or was stopped
+	Output   string     `json:"output,omitempty"` // Last part of the combined output
+	Diff     *DiffStats `json:"diff,omitempty"`   // Changes on the worker's branch
+}
+
+// TestReport is the JSON report written by `gtw test --json`.
+type TestReport struct {
+	Command   string       `json:"command"`
+	StartedAt time.Time    `json:"started_at"`
+	Passed    int          `json:"passed"`
+	Failed    int          `json:"failed"`
+	Results   []TestResult `json:"results"`
+}
+
+func init() {
+	var opts TestOptions
+	testCmd := &cobra.Command{
+		Use:   "test [worker-id...]",
+		Short: "Run the test command in workers' worktrees and report which pass",
+		Long: `Run test_command from the config (or --command) in the worktree of each
+selected worker, several at a time, streaming the output prefixed with the
+worker ID. A pass/fail matrix is printed at the end and gtw exits with
+status 1 if any worker failed.
+
+--report writes a summary of each worker's branch, test outcome and diff
+stats for CI: a file ending in .xml is written as JUnit XML and one ending
+in .md as Markdown (e.g. for $GITHUB_STEP_SUMMARY or a PR comment); "junit"
+and "markdown" print to stdout.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !runTests(args, opts) {
+				os.Exit(1)
+			}
+		},
+	}
+	testCmd.Flags().BoolVar(&opts.All, "all", false, "Test every worker")
+	testCmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Test workers with this tag (repeatable, all must match)")
+	testCmd.Flags().StringVarP(&opts.Command, "command", "c", "", "Test command (default: test_command from the config)")
+	testCmd.Flags().IntVarP(&opts.Parallel, "parallel", "p", runtime.NumCPU(), "Number of workers tested at the same time")
+	testCmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Stop a worker's tests after this long (default: no limit)")
+	testCmd.Flags().StringVar(&opts.JSON, "json", "", "Write a JSON report to this file ('-' for stdout)")
+	testCmd.Flags().StringArrayVar(&opts.Reports, "report", nil, "Write a report: junit.xml, report.md, junit or markdown (repeatable)")
+	rootCmd.AddCommand(testCmd)
+}
+
+// testTargets returns the workers selected by IDs, --tag or --all.
+func testTargets(config *Config, ids []string, opts TestOptions) ([]Worker, error) {
+	return selectWorkers(config, ids, opts.All, opts.Tags)
+}
+
+// selectWorkers returns the workers given by ID, or else the attached
+// workers with all of tags (or every one with all).
+func selectWorkers(config *Config, ids []string, all bool, tags []string) ([]Worker, error) {
+	if len(ids) > 0 {
+		var targets []Worker
+		for _, id := range ids {
+			index := findWorkerIndex(config, id)
+			if index == -1 {
+				return nil, fmt.Errorf("worker '%s' not found", id)
+			}
+			targets = append(targets, config.Workers[index])
+		}
+		return targets, nil
+	}
+	if !all && len(tags) == 0 {
+		return nil, fmt.Errorf("give worker IDs, --tag or --all")
+	}
+	var targets []Worker
+	for _, worker := range config.Workers {
+		if worker.Status != WorkerDetached && hasAllTags(worker, tags) {
+			targets = append(targets, worker)
+		}
+	}
+	return targets, nil
+}
+
+// prefixWriter writes complete lines to out prefixed with the worker ID, and
+// keeps the last part of the output.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex // Shared by all workers so that lines do not interleave
+	line   []byte
+	tail   []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > testOutputTail {
+		w.tail = w.tail[len(w.tail)-testOutputTail:]
+	}
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i == -1 {
+			break
+		}
+		w.emit(w.line[:i])
+		w.line = w.line[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *prefixWriter) emit(line []byte) {
+	w.mu.Lock()
+	fmt.Fprintf(w.out, "%s %s\n", w.prefix, line)
+	w.mu.Unlock()
+}
+
+// Flush writes a final line that did not end with a newline.
+func (w *prefixWriter) Flush() {
+	if len(w.line) > 0 {
+		w.emit(w.line)
+		w.line = nil
+	}
+}
+
+// runWorkerTest runs command in the worker's worktree.
+func runWorkerTest(worker Worker, command string, timeout time.Duration, out io.Writer, mu *sync.Mutex, width int) TestResult {
+	result := TestResult{Worker: worker.ID, Branch: getWorktreeBranch(worker.WorktreePath, worker.branchName())}
+	writer := &prefixWriter{prefix: fmt.Sprintf("[%-*s]", width, worker.ID), out: out, mu: mu}
+
+	cmd := newCommand("sh", "-c", command)
+	cmd.Dir = worker.WorktreePath
+	cmd.Env = append(os.Environ(), workerIDEnv+"="+worker.ID)
+	cmd.Stdout, cmd.Stderr = writer, writer
+	cmd.Timeout = timeout
+
+	start := time.Now()
+	err := cmd.Run()
+	writer.Flush()
+	result.Duration = time.Since(start).Round(10 * time.Millisecond).Seconds()
+	result.Output = string(writer.tail)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Passed = true
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// testWorkers runs the command in every worker with at most parallel at a
+// time and returns the results in the order of workers.
+func testWorkers(workers []Worker, command string, opts TestOptions, out io.Writer) []TestResult {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	width := 0
+	for _, worker := range workers {
+		width = max(width, len(worker.ID))
+	}
+
+	results := make([]TestResult, len(workers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+	for i, worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if _, err := os.Stat(worker.WorktreePath); err != nil {
+				results[i] = TestResult{Worker: worker.ID, Branch: worker.branchName(), ExitCode: -1, Error: "worktree is missing"}
+				return
+			}
+			results[i] = runWorkerTest(worker, command, opts.Timeout, out, &mu, width)
+			results[i].Diff = workerDiffStats(worker)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// newTestReport summarizes the results.
+func newTestReport(command string, startedAt time.Time, results []TestResult) *TestReport {
+	report := &TestReport{Command: command, StartedAt: startedAt, Results: results}
+	for _, result := range results {
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// printTestMatrix prints one row per worker with its outcome.
+func printTestMatrix(report *TestReport) {
+	fmt.Println(colorize(fmt.Sprintf("%-20s %-30s %-10s %s", "WORKER", "BRANCH", "RESULT", "DURATION"), "header"))
+	fmt.Println(colorize(strings.Repeat("-", 72), "separator"))
+	for _, result := range report.Results {
+		outcome := "pass"
+		switch {
+		case result.Error != "":
+			outcome = "error"
+		case !result.Passed:
+			outcome = fmt.Sprintf("fail (%d)", result.ExitCode)
+		}
+		line := fmt.Sprintf("%-20s %-30s %s %.1fs", result.Worker, result.Branch, colorize(fmt.Sprintf("%-10s", outcome), statusColorKey(outcome)), result.Duration)
+		if result.Error != "" {
+			line += "  " + result.Error
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if report.Failed > 0 {
+		fmt.Printf("❌ %d passed, %d failed\n", report.Passed, report.Failed)
+	} else {
+		fmt.Printf("✅ %d passed\n", report.Passed)
+	}
+}
+
+func writeJSONReport(path string, report *TestReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(userPath(path), data, 0644)
+}
+
+// runTests runs the tests and reports whether every worker passed.
+func runTests(ids []string, opts TestOptions) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+	command := opts.Command
+	if command == "" {
+		command = config.TestCommand
+	}
+	if command == "" {
+		fmt.Fprintf(os.Stderr, "Error: No test command; set test_command in %s or use --command\n", configFile)
+		return false
+	}
+	workers, err := testTargets(config, ids, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if len(workers) == 0 {
+		fmt.Fprintln(os.Stderr, "No matching workers found")
+		return false
+	}
+
+	// Keep stdout clean for a report written there
+	reportToStdout := opts.JSON == "-"
+	for _, spec := range opts.Reports {
+		_, path, err := reportTarget(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		reportToStdout = reportToStdout || path == ""
+	}
+	out := io.Writer(os.Stdout)
+	if reportToStdout {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "Running '%s' in %d worker(s)...\n", command, len(workers))
+	startedAt := time.Now()
+	report := newTestReport(command, startedAt, testWorkers(workers, command, opts, out))
+
+	if !reportToStdout {
+		fmt.Println()
+		printTestMatrix(report)
+	}
+	if opts.JSON != "" {
+		if err := writeJSONReport(opts.JSON, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			return false
+		}
+	}
+	for _, spec := range opts.Reports {
+		if err := writeReport(spec, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			return false
+		}
+	}
+	return report.Failed == 0
+}
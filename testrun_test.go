@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{prefix: "[api]", out: &out, mu: &sync.Mutex{}}
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\nthree"))
+	w.Flush()
+
+	want := "[api] one\n[api] two\n[api] three\n"
+	if out.String() != want {
+		t.Errorf("Output = %q, want %q", out.String(), want)
+	}
+	if string(w.tail) != "one\ntwo\nthree" {
+		t.Errorf("Tail = %q", w.tail)
+	}
+}
+
+func TestTestTargets(t *testing.T) {
+	config := &Config{Workers: []Worker{
+		{ID: "a", Tags: []string{"backend"}},
+		{ID: "b"},
+		{ID: "c", Status: WorkerDetached},
+	}}
+	if targets, err := testTargets(config, nil, TestOptions{All: true}); err != nil || len(targets) != 2 {
+		t.Errorf("--all: %v, %v", targets, err)
+	}
+	if targets, _ := testTargets(config, nil, TestOptions{Tags: []string{"backend"}}); len(targets) != 1 || targets[0].ID != "a" {
+		t.Errorf("--tag: %v", targets)
+	}
+	if _, err := testTargets(config, []string{"missing"}, TestOptions{}); err == nil {
+		t.Error("Expected an error for an unknown worker")
+	}
+	if _, err := testTargets(config, nil, TestOptions{}); err == nil {
+		t.Error("Expected an error without a selection")
+	}
+}
+
+func TestTestWorkers(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	workers := []Worker{
+		{ID: "pass", WorktreePath: t.TempDir()},
+		{ID: "fail", WorktreePath: t.TempDir()},
+		{ID: "gone", WorktreePath: "/nonexistent/worktree"},
+	}
+	command := `echo "testing $GTW_WORKER_ID"; [ "$GTW_WORKER_ID" = pass ] || exit 3`
+
+	var out bytes.Buffer
+	results := testWorkers(workers, command, TestOptions{Parallel: 2}, &out)
+	if !results[0].Passed || results[1].Passed || results[1].ExitCode != 3 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if results[2].Passed || results[2].Error == "" {
+		t.Errorf("Expected an error for a missing worktree: %+v", results[2])
+	}
+	if !strings.Contains(out.String(), "[fail] testing fail") {
+		t.Errorf("Output not prefixed:\n%s", out.String())
+	}
+
+	if report := newTestReport(command, time.Now(), results); report.Passed != 1 || report.Failed != 2 {
+		t.Errorf("Report counts = %d passed, %d failed", report.Passed, report.Failed)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// UndoOptions holds the settings given to `gtw undo`.
+type UndoOptions struct {
+	Yes    bool
+	NoInit bool
+}
+
+func init() {
+	var opts UndoOptions
+	undoCmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Reverse the last 'gtw remove' or 'gtw destroy'",
+		Long: `Reverse the most recent remove or destroy of this workspace, as recorded in
+.gtw/history.jsonl. A removed worker gets its worktree recreated from its
+branch and a new pane; a destroyed session is recreated with the panes of all
+its workers. Only the last operation can be undone.
+
+Uncommitted changes of a removed worktree cannot be recovered unless they
+were saved to a backup branch.`,
+		Args: cobra.NoArgs,
+		Run:  func(cmd *cobra.Command, args []string) { undoLast(opts) },
+	}
+	undoCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Do not ask for confirmation")
+	undoCmd.Flags().BoolVar(&opts.NoInit, "no-init", false, "Do not run the initialization command in restored panes")
+	rootCmd.AddCommand(undoCmd)
+}
+
+// lastUndoable returns the most recent remove or destroy of the workspace,
+// or nil if there is none or it has already been undone.
+func lastUndoable(events []Event, workspaceName string) *Event {
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Workspace != workspaceName {
+			continue
+		}
+		switch event.Type {
+		case EventUndo:
+			return nil
+		case EventWorkerRemoved, EventSessionDestroyed:
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// branchExists reports whether the local branch exists.
+func branchExists(branch string) bool {
+	return gitCommand("rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+// restorableWorkers drops the workers whose worktree and branch are both
+// gone, as there is nothing left to restore them from.
+func restorableWorkers(workers []SnapshotWorker) []SnapshotWorker {
+	var result []SnapshotWorker
+	for _, sw := range workers {
+		if _, err := os.Stat(sw.WorktreePath); err != nil && !branchExists(sw.Branch) {
+			fmt.Printf("⚠️  Cannot restore worker '%s': branch '%s' no longer exists\n", sw.ID, sw.Branch)
+			continue
+		}
+		result = append(result, sw)
+	}
+	return result
+}
+
+func undoLast(opts UndoOptions) {
+	events, err := readHistory(HistoryOptions{}, time.Now())
+	if err != nil {
+		fmt.Printf("Error reading history: %v\n", err)
+		return
+	}
+
+	event := lastUndoable(events, workspace)
+	if event == nil {
+		fmt.Println("Nothing to undo")
+		return
+	}
+
+	var question string
+	if event.Type == EventWorkerRemoved {
+		question = fmt.Sprintf("Restore worker '%s' removed at %s?", event.Worker, event.Time.Local().Format("2006-01-02 15:04:05"))
+	} else {
+		question = fmt.Sprintf("Recreate session '%s' with %d worker(s), destroyed at %s?", event.Detail, len(event.Workers), event.Time.Local().Format("2006-01-02 15:04:05"))
+	}
+	if !opts.Yes && !confirm(question) {
+		fmt.Println("Aborted")
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	sessionName := getSessionName()
+	if sessionName == "" {
+		return
+	}
+
+	if event.Type == EventSessionDestroyed {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+		if err := ensureSession(sessionName, cwd); err != nil {
+			fmt.Printf("Error creating %s session: %v\n", mux.Name(), err)
+			return
+		}
+		config.ProjectPath = cwd
+	} else if !mux.HasSession(sessionName) {
+		fmt.Printf("Error: Session '%s' does not exist. Run 'gtw attach --recreate' first.\n", sessionName)
+		return
+	}
+
+	restored := restoreWorkers(config, sessionName, restorableWorkers(event.Workers), !opts.NoInit)
+	if err := saveConfig(config); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+
+	if event.Type == EventWorkerRemoved && restored == 0 {
+		fmt.Printf("❌ Could not restore worker '%s'\n", event.Worker)
+		return
+	}
+
+	recordEvent(EventUndo, event.Worker, event.Type)
+	if event.Type == EventWorkerRemoved {
+		fmt.Printf("✅ Restored worker '%s'\n", event.Worker)
+		if event.Detail != "" {
+			fmt.Printf("Uncommitted work was saved to a backup branch (%s)\n", event.Detail)
+		}
+		return
+	}
+	fmt.Printf("✅ Recreated session '%s' with %d worker(s)\n", sessionName, restored)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Usage is the token and cost usage of a worker's agent, as printed by its
+// usage_command.
+type Usage struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// Add sums usage into u.
+func (u *Usage) Add(usage *Usage) {
+	if usage == nil {
+		return
+	}
+	u.InputTokens += usage.InputTokens
+	u.OutputTokens += usage.OutputTokens
+	u.CostUSD += usage.CostUSD
+}
+
+// usageCommandFor returns the usage command of the worker's profile, or
+// usage_command when the worker has no (known) profile.
+func (c *Config) usageCommandFor(worker Worker) string {
+	if profile, ok := c.Profiles[worker.Profile]; ok && worker.Profile != "" && profile.UsageCommand != "" {
+		return profile.UsageCommand
+	}
+	return c.UsageCommand
+}
+
+// parseUsage reads the JSON object printed by a usage command. Missing
+// fields count as zero.
+func parseUsage(output []byte) (*Usage, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("usage command printed nothing")
+	}
+	var usage Usage
+	if err := json.Unmarshal(output, &usage); err != nil {
+		return nil, fmt.Errorf("usage command printed invalid JSON: %v", err)
+	}
+	return &usage, nil
+}
+
+// usageEnv describes the worker to its usage command.
+func usageEnv(worker Worker, since time.Time) []string {
+	env := []string{
+		workerIDEnv + "=" + worker.ID,
+		"GTW_WORKER_BRANCH=" + worker.branchName(),
+		"GTW_WORKER_WORKTREE=" + worker.WorktreePath,
+		"GTW_WORKER_PANE=" + worker.PaneID,
+		"GTW_WORKER_CREATED=" + worker.CreatedAt.Format(time.RFC3339),
+	}
+	if !since.IsZero() {
+		env = append(env, "GTW_SINCE="+since.Format(time.RFC3339))
+	}
+	return env
+}
+
+// workerUsage runs the usage command in the worker's worktree. A worker
+// without a usage command has no usage (nil, nil).
+func workerUsage(config *Config, worker Worker, since time.Time) (*Usage, error) {
+	command := config.usageCommandFor(worker)
+	if command == "" {
+		return nil, nil
+	}
+	cmd := newCommand("sh", "-c", command)
+	cmd.Dir = worker.WorktreePath
+	cmd.Env = append(os.Environ(), usageEnv(worker, since)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return nil, fmt.Errorf("usage command failed: %s", strings.TrimSpace(lastLines(detail, 1)))
+		}
+		return nil, fmt.Errorf("usage command failed: %v", err)
+	}
+	return parseUsage(output)
+}
+
+// formatTokens shows a token count in thousands or millions.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseUsage(t *testing.T) {
+	usage, err := parseUsage([]byte(`{"input_tokens": 120000, "output_tokens": 8000, "cost_usd": 1.25}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.InputTokens != 120000 || usage.OutputTokens != 8000 || usage.CostUSD != 1.25 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	if usage, err := parseUsage([]byte(`{"cost_usd": 0.5}`)); err != nil || usage.InputTokens != 0 || usage.CostUSD != 0.5 {
+		t.Errorf("Expected missing fields to be zero, got %+v, %v", usage, err)
+	}
+	for _, output := range []string{"", "  \n", "1.25 USD"} {
+		if _, err := parseUsage([]byte(output)); err == nil {
+			t.Errorf("Expected error for %q", output)
+		}
+	}
+}
+
+func TestUsageCommandFor(t *testing.T) {
+	config := &Config{
+		UsageCommand: "default-usage",
+		Profiles: map[string]Profile{
+			"claude": {InitCommand: "claude", UsageCommand: "claude-usage"},
+			"shell":  {InitCommand: "bash"},
+		},
+	}
+	tests := map[string]string{
+		"claude":  "claude-usage",
+		"shell":   "default-usage",
+		"":        "default-usage",
+		"missing": "default-usage",
+	}
+	for profile, want := range tests {
+		if got := config.usageCommandFor(Worker{ID: "w", Profile: profile}); got != want {
+			t.Errorf("usageCommandFor(%q) = %q, want %q", profile, got, want)
+		}
+	}
+}
+
+func TestWorkerUsage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "usage.json"), []byte(`{"output_tokens": 42}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worker := Worker{ID: "w1", WorktreePath: dir, CreatedAt: time.Now()}
+
+	if usage, err := workerUsage(&Config{}, worker, time.Time{}); usage != nil || err != nil {
+		t.Errorf("Expected no usage without a usage command, got %+v, %v", usage, err)
+	}
+
+	config := &Config{UsageCommand: `test "$GTW_WORKER_ID" = w1 && cat usage.json`}
+	usage, err := workerUsage(config, worker, time.Time{})
+	if err != nil || usage == nil || usage.OutputTokens != 42 {
+		t.Errorf("Unexpected usage: %+v, %v", usage, err)
+	}
+
+	config.UsageCommand = "echo 'no log found' >&2; exit 1"
+	if _, err := workerUsage(config, worker, time.Time{}); err == nil || err.Error() != "usage command failed: no log found" {
+		t.Errorf("Expected the command's error, got %v", err)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	var total Usage
+	total.Add(&Usage{InputTokens: 1000, OutputTokens: 10, CostUSD: 0.5})
+	total.Add(nil)
+	total.Add(&Usage{InputTokens: 2500, CostUSD: 0.25})
+	if total.InputTokens != 3500 || total.OutputTokens != 10 || total.CostUSD != 0.75 {
+		t.Errorf("Unexpected total: %+v", total)
+	}
+	if got := formatTokens(total.InputTokens); got != "3.5k" {
+		t.Errorf("formatTokens() = %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Set at release time with -ldflags "-X main.version=v1.2.3 -X main.commit=...
+// -X main.date=..."; otherwise filled in from the Go build info.
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// BuildInfo describes the running gtw binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a worktree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+func init() {
+	var asJSON bool
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the version of gtw and how it was built",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			info := buildInfo()
+			if !asJSON {
+				fmt.Println(info.String())
+				return
+			}
+			data, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			fmt.Println(string(data))
+		},
+	}
+	versionCmd.Flags().BoolVar(&asJSON, "json", false, "Print the build info as JSON")
+	rootCmd.AddCommand(versionCmd)
+
+	rootCmd.Version = buildInfo().Version
+	rootCmd.SetVersionTemplate("{{.Version}}\n")
+}
+
+// buildInfo combines the release ldflags with what the Go toolchain records:
+// the module version for `go install ...@v1.2.3` and the VCS state for
+// builds from a checkout.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String renders the info on one line, e.g.
+// "gtw v1.2.3 (commit 1a2b3c4, built 2026-01-02T03:04:05Z, go1.22.1 linux/amd64)".
+func (info BuildInfo) String() string {
+	var details []string
+	if info.Commit != "" {
+		rev := info.Commit
+		if len(rev) > 7 {
+			rev = rev[:7]
+		}
+		if info.Modified {
+			rev += "-dirty"
+		}
+		details = append(details, "commit "+rev)
+	}
+	if info.Date != "" {
+		details = append(details, "built "+info.Date)
+	}
+	details = append(details, info.GoVersion+" "+info.Platform)
+	return fmt.Sprintf("gtw %s (%s)", info.Version, strings.Join(details, ", "))
+}
+
+// compareVersions compares two versions like v1.2.3 or 1.2.3-rc.1, returning
+// -1, 0 or 1. A prerelease sorts before its release. Versions that do not
+// parse (e.g. "dev") sort before every release.
+func compareVersions(a, b string) int {
+	an, apre, aok := parseVersion(a)
+	bn, bpre, bok := parseVersion(b)
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return -1
+	case !bok:
+		return 1
+	}
+	for i := range an {
+		if an[i] != bn[i] {
+			if an[i] < bn[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	case apre < bpre:
+		return -1
+	}
+	return 1
+}
+
+// parseVersion splits a version into major, minor and patch numbers and a
+// prerelease suffix.
+func parseVersion(v string) ([3]int, string, bool) {
+	var numbers [3]int
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "+") // Build metadata does not affect ordering
+	core, pre, _ := strings.Cut(v, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return numbers, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return numbers, "", false
+		}
+		numbers[i] = n
+	}
+	return numbers, pre, true
+}
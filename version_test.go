@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v1.2", "v1.2.1", -1},
+		{"v2.0.0-rc.1", "v2.0.0", -1},
+		{"v2.0.0-rc.2", "v2.0.0-rc.1", 1},
+		{"dev", "v0.1.0", -1},
+		{"v0.0.0-20260101000000-abcdef123456", "v0.1.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBuildInfoString(t *testing.T) {
+	info := BuildInfo{Version: "v1.2.3", Commit: "1a2b3c4d5e6f", Date: "2026-01-02T03:04:05Z", Modified: true, GoVersion: "go1.22.1", Platform: "linux/amd64"}
+	want := "gtw v1.2.3 (commit 1a2b3c4-dirty, built 2026-01-02T03:04:05Z, go1.22.1 linux/amd64)"
+	if got := info.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of `gtw wait`, so scripts can tell the outcomes apart.
+const (
+	WaitExitOK       = 0 // Condition met
+	WaitExitError    = 1 // Bad arguments or unknown worker
+	WaitExitTimeout  = 2 // Timeout expired first
+	WaitExitPaneGone = 3 // The worker's pane disappeared
+)
+
+// WaitOptions configures what `gtw wait` blocks on.
+type WaitOptions struct {
+	Timeout     time.Duration
+	Pattern     string
+	ProcessExit bool
+	Ready       bool // Wait for the readiness checks of the worker's profile
+	Quiet       time.Duration
+	Interval    time.Duration
+}
+
+func init() {
+	var opts WaitOptions
+	waitCmd := &cobra.Command{
+		Use:   "wait <worker-id>",
+		Short: "Block until a worker prints a pattern, goes idle, or its process exits",
+		Long: `Block until a worker is done. By default the worker is done when it goes idle
+(back at a shell prompt, or output unchanged for --quiet). With --pattern it is
+done when the pane prints a new match; with --process-exit when the foreground
+process returns to the shell; with --ready when the readiness checks of its
+profile (port, file or output) pass.
+
+Exit codes: 0 condition met, 1 error, 2 timeout, 3 pane gone.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { os.Exit(waitForWorker(args[0], opts)) },
+	}
+	waitCmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Give up after this long (0 means no limit)")
+	waitCmd.Flags().StringVar(&opts.Pattern, "pattern", "", "Regexp to wait for in the pane output")
+	waitCmd.Flags().BoolVar(&opts.ProcessExit, "process-exit", false, "Wait for the foreground process to exit")
+	waitCmd.Flags().BoolVar(&opts.Ready, "ready", false, "Wait for the readiness checks of the worker's profile (idle without checks)")
+	waitCmd.Flags().DurationVar(&opts.Quiet, "quiet", defaultIdleQuiet, "How long pane output must stay unchanged to count as idle")
+	waitCmd.Flags().DurationVar(&opts.Interval, "interval", time.Second, "How often the pane is polled")
+	rootCmd.AddCommand(waitCmd)
+}
+
+// waitCaptureLines is how much of the pane's scrollback is compared between
+// polls for --pattern.
+const waitCaptureLines = 2000
+
+func waitForWorker(id string, opts WaitOptions) int {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return WaitExitError
+	}
+
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Fprintf(os.Stderr, "Worker '%s' not found\n", id)
+		return WaitExitError
+	}
+	worker := config.Workers[index]
+	paneID := worker.PaneID
+	checks := config.readinessFor(worker)
+
+	var pattern *regexp.Regexp
+	var output string
+	if opts.Pattern != "" {
+		if pattern, err = regexp.Compile(opts.Pattern); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --pattern: %v\n", err)
+			return WaitExitError
+		}
+		// Only output printed after we started waiting counts
+		if output, err = capturePane(paneID, waitCaptureLines); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return WaitExitPaneGone
+		}
+	}
+
+	tracker := NewIdleTracker(opts.Quiet)
+	started := time.Now()
+
+	for {
+		command, err := paneCurrentCommand(paneID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Worker '%s': pane %s is gone\n", id, paneID)
+			return WaitExitPaneGone
+		}
+
+		switch {
+		case pattern != nil:
+			// Comparing with the last poll rather than counting matches
+			// keeps working when output scrolls away or the pane is cleared
+			current, err := capturePane(paneID, waitCaptureLines)
+			if err != nil {
+				return WaitExitPaneGone
+			}
+			if pattern.MatchString(newOutput(output, current)) {
+				fmt.Printf("Worker '%s' printed %q\n", id, opts.Pattern)
+				return WaitExitOK
+			}
+			output = current
+		case opts.Ready && len(checks) > 0:
+			if ok, _ := checkReadiness(worker, checks); ok {
+				fmt.Printf("Worker '%s' is ready\n", id)
+				return WaitExitOK
+			}
+		case opts.ProcessExit:
+			if isShellCommand(command) {
+				fmt.Printf("Worker '%s' foreground process exited\n", id)
+				return WaitExitOK
+			}
+		default:
+			idle, err := tracker.Observe(paneID)
+			if err != nil {
+				return WaitExitPaneGone
+			}
+			if idle {
+				fmt.Printf("Worker '%s' is idle\n", id)
+				return WaitExitOK
+			}
+		}
+
+		if opts.Timeout > 0 && time.Since(started) >= opts.Timeout {
+			fmt.Fprintf(os.Stderr, "Timed out after %s waiting for worker '%s'\n", opts.Timeout, id)
+			return WaitExitTimeout
+		}
+		time.Sleep(opts.Interval)
+	}
+}
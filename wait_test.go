@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptedMultiplexer plays back a pane: each poll returns the next capture
+// and foreground command, repeating the last ones. A pane without commands
+// is gone.
+type scriptedMultiplexer struct {
+	fakeMultiplexer
+	captures []string
+	commands []string
+	polls    int
+}
+
+func (s *scriptedMultiplexer) CurrentCommand(paneID string) (string, error) {
+	if len(s.commands) == 0 {
+		return "", errors.New("no such pane")
+	}
+	s.polls++
+	return s.commands[min(s.polls, len(s.commands))-1], nil
+}
+
+func (s *scriptedMultiplexer) Capture(paneID string, lines int) (string, error) {
+	return s.captures[min(s.polls, len(s.captures)-1)], nil
+}
+
+func setupWaitTest(t *testing.T, fake *scriptedMultiplexer) {
+	t.Chdir(t.TempDir())
+	if err := saveConfig(&Config{Workers: []Worker{{ID: "api", PaneID: "%1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	previous := mux
+	mux = fake
+	t.Cleanup(func() { mux = previous })
+}
+
+func TestWaitForPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		captures []string // The first one is on screen when waiting starts
+		want     int
+		polls    int
+	}{
+		{"printed", []string{"$ make\n", "$ make\nbuilding\n", "$ make\nbuilding\nDONE\n$ "}, WaitExitOK, 2},
+		{"already on screen", []string{"DONE\n$ make\n", "DONE\n$ make\nbuilding\n"}, WaitExitTimeout, 0},
+		{"last line unchanged", []string{"DONE", "DONE\n\n", "DONE"}, WaitExitTimeout, 0},
+		{"after the pane was cleared", []string{"DONE\nDONE\nDONE\n$ make\n", "$ \n", "$ \nDONE\n"}, WaitExitOK, 2},
+		{"after old output scrolled away", []string{"DONE\na\nb\n", "a\nb\nc\n", "b\nc\nDONE\n"}, WaitExitOK, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &scriptedMultiplexer{captures: tt.captures, commands: []string{"make"}}
+			setupWaitTest(t, fake)
+
+			got := waitForWorker("api", WaitOptions{Pattern: "DONE", Timeout: 50 * time.Millisecond, Interval: time.Millisecond})
+			if got != tt.want {
+				t.Errorf("Expected exit %d, got %d", tt.want, got)
+			}
+			if tt.polls > 0 && fake.polls != tt.polls {
+				t.Errorf("Expected the match on poll %d, got %d", tt.polls, fake.polls)
+			}
+		})
+	}
+}
+
+func TestWaitForIdle(t *testing.T) {
+	// An agent in the foreground is idle once its output stops changing
+	fake := &scriptedMultiplexer{captures: []string{"thinking", "thinking.", "thinking..", "done"}, commands: []string{"claude"}}
+	setupWaitTest(t, fake)
+
+	if got := waitForWorker("api", WaitOptions{Quiet: 20 * time.Millisecond, Timeout: time.Second, Interval: time.Millisecond}); got != WaitExitOK {
+		t.Errorf("Expected the worker to go idle, got exit %d", got)
+	}
+	if fake.polls < 4 {
+		t.Errorf("Expected idle only after the output settled, got it on poll %d", fake.polls)
+	}
+
+	// Back at a shell prompt is idle right away
+	fake = &scriptedMultiplexer{captures: []string{"$ "}, commands: []string{"zsh"}}
+	setupWaitTest(t, fake)
+	if got := waitForWorker("api", WaitOptions{Quiet: time.Hour, Timeout: time.Second, Interval: time.Millisecond}); got != WaitExitOK {
+		t.Errorf("Expected a shell prompt to be idle, got exit %d", got)
+	}
+}
+
+func TestWaitForProcessExit(t *testing.T) {
+	fake := &scriptedMultiplexer{captures: []string{""}, commands: []string{"npm", "npm", "node", "bash"}}
+	setupWaitTest(t, fake)
+
+	if got := waitForWorker("api", WaitOptions{ProcessExit: true, Timeout: time.Second, Interval: time.Millisecond}); got != WaitExitOK {
+		t.Errorf("Expected the process to exit, got exit %d", got)
+	}
+	if fake.polls != 4 {
+		t.Errorf("Expected the exit on poll 4, got %d", fake.polls)
+	}
+}
+
+func TestWaitForWorkerErrors(t *testing.T) {
+	setupWaitTest(t, &scriptedMultiplexer{captures: []string{""}})
+
+	if got := waitForWorker("api", WaitOptions{ProcessExit: true, Interval: time.Millisecond}); got != WaitExitPaneGone {
+		t.Errorf("Expected exit %d for a gone pane, got %d", WaitExitPaneGone, got)
+	}
+	if got := waitForWorker("missing", WaitOptions{}); got != WaitExitError {
+		t.Errorf("Expected exit %d for an unknown worker, got %d", WaitExitError, got)
+	}
+	if got := waitForWorker("api", WaitOptions{Pattern: "("}); got != WaitExitError {
+		t.Errorf("Expected exit %d for an invalid pattern, got %d", WaitExitError, got)
+	}
+}
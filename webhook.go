@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Kinds of webhooks
+const (
+	WebhookSlack   = "slack"
+	WebhookDiscord = "discord"
+)
+
+const (
+	webhookTimeout = 10 * time.Second
+	// Discord rejects messages longer than this
+	discordMessageLimit = 2000
+)
+
+// defaultWebhookTemplates quote the pane output in each service's markup.
+var defaultWebhookTemplates = map[string]string{
+	WebhookSlack:   "*{{.Title}}* ({{.Project}}{{if .Branch}}, branch `{{.Branch}}`{{end}})\n{{.Message}}{{if .Output}}\n```\n{{.Output}}\n```{{end}}",
+	WebhookDiscord: "**{{.Title}}** ({{.Project}}{{if .Branch}}, branch `{{.Branch}}`{{end}})\n{{.Message}}{{if .Output}}\n```\n{{.Output}}\n```{{end}}",
+}
+
+// Webhook posts notifications to a Slack or Discord incoming webhook.
+type Webhook struct {
+	URL      string `json:"url"`                // Webhook URL; $VARS are expanded so the secret can stay in the environment
+	Kind     string `json:"kind,omitempty"`     // slack or discord (default: from the URL)
+	Template string `json:"template,omitempty"` // Go template of the message (fields of Notification)
+}
+
+// kind returns the webhook's service, from its kind or its URL.
+func (w Webhook) kind() string {
+	if w.Kind != "" {
+		return w.Kind
+	}
+	if strings.Contains(w.URL, "discord.com/") || strings.Contains(w.URL, "discordapp.com/") {
+		return WebhookDiscord
+	}
+	return WebhookSlack
+}
+
+func (w Webhook) Name() string { return w.kind() }
+
+// message renders the webhook's template for n.
+func (w Webhook) message(n Notification) (string, error) {
+	text := w.Template
+	if text == "" {
+		text = defaultWebhookTemplates[w.kind()]
+	}
+	tmpl, err := template.New("webhook").Funcs(formatFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, n); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// payload returns the JSON body the service expects.
+func (w Webhook) payload(message string) ([]byte, error) {
+	switch w.kind() {
+	case WebhookSlack:
+		return json.Marshal(map[string]string{"text": message})
+	case WebhookDiscord:
+		if runes := []rune(message); len(runes) > discordMessageLimit {
+			message = string(runes[:discordMessageLimit-1]) + "…"
+		}
+		return json.Marshal(map[string]string{"content": message})
+	}
+	return nil, fmt.Errorf("unknown webhook kind '%s' (expected slack or discord)", w.Kind)
+}
+
+func (w Webhook) Notify(n Notification) error {
+	message, err := w.message(n)
+	if err != nil {
+		return fmt.Errorf("invalid template: %v", err)
+	}
+	body, err := w.payload(message)
+	if err != nil {
+		return err
+	}
+	url := os.ExpandEnv(w.URL)
+	if url == "" {
+		return fmt.Errorf("webhook URL %q is empty", w.URL)
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error includes the URL, which is a secret
+		return fmt.Errorf("posting to the %s webhook failed", w.kind())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s webhook: %s %s", w.kind(), resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
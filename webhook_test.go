@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookKind(t *testing.T) {
+	tests := map[Webhook]string{
+		{URL: "https://hooks.slack.com/services/T/B/X"}:                 WebhookSlack,
+		{URL: "https://discord.com/api/webhooks/1/abc"}:                 WebhookDiscord,
+		{URL: "https://example.com/hook", Kind: WebhookDiscord}:         WebhookDiscord,
+		{URL: "https://discordapp.com/api/webhooks/1/abc"}:              WebhookDiscord,
+		{URL: "https://chat.example.com/hooks/abc", Kind: WebhookSlack}: WebhookSlack,
+	}
+	for webhook, want := range tests {
+		if got := webhook.kind(); got != want {
+			t.Errorf("kind(%s) = %q, want %q", webhook.URL, got, want)
+		}
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := Notification{Event: NotifyCompleted, Worker: "w1", Branch: "feature/w1", Project: "app", Title: "gtw: w1 is done", Message: "Worker 'w1' went idle", Output: "ok  \tpkg\t0.3s"}
+
+	t.Setenv("GTW_TEST_WEBHOOK", server.URL)
+	slack := Webhook{URL: "$GTW_TEST_WEBHOOK", Kind: WebhookSlack}
+	if err := slack.Notify(n); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	want := "*gtw: w1 is done* (app, branch `feature/w1`)\nWorker 'w1' went idle\n```\nok  \tpkg\t0.3s\n```"
+	if received["text"] != want {
+		t.Errorf("Slack text = %q, want %q", received["text"], want)
+	}
+
+	discord := Webhook{URL: server.URL, Kind: WebhookDiscord, Template: "{{.Worker}}: {{.Message}}"}
+	if err := discord.Notify(n); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if received["content"] != "w1: Worker 'w1' went idle" {
+		t.Errorf("Discord content = %q", received["content"])
+	}
+
+	n.Output = strings.Repeat("x", 3000)
+	if err := (Webhook{URL: server.URL, Kind: WebhookDiscord}).Notify(n); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if got := len([]rune(received["content"])); got != discordMessageLimit {
+		t.Errorf("Expected the Discord message to be cut to %d characters, got %d", discordMessageLimit, got)
+	}
+}
+
+func TestWebhookErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n := Notification{Worker: "w1"}
+	if err := (Webhook{URL: server.URL}).Notify(n); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("Expected the service's error, got %v", err)
+	}
+	if err := (Webhook{URL: server.URL, Kind: "teams"}).Notify(n); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+	if err := (Webhook{URL: server.URL, Template: "{{.Missing}}"}).Notify(n); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if err := (Webhook{URL: "$GTW_UNSET_WEBHOOK_URL"}).Notify(n); err == nil {
+		t.Error("Expected an error for an empty URL")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// workerIDEnv is set in the environment of every worker pane (tmux and
+// screen) so that programs running there know their worker.
+const workerIDEnv = "GTW_WORKER_ID"
+
+func init() {
+	var format string
+	whoamiCmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the worker of the pane or worktree gtw is run from",
+		Long: `Identify the current worker from $TMUX_PANE, $GTW_WORKER_ID or the worktree
+the command is run in, and print its ID, branch and worktree. Exits with
+status 1 outside of a worker.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !whoami(format) {
+				os.Exit(1)
+			}
+		},
+	}
+	whoamiCmd.Flags().StringVar(&format, "format", "", "Print the worker with a Go template (e.g. '{{.ID}}')")
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+// findCurrentWorker returns the index of the worker that owns paneID, is
+// named by envID, or whose worktree contains dir, in that order of trust.
+func findCurrentWorker(config *Config, paneID, envID, dir string) int {
+	if paneID != "" {
+		for i, worker := range config.Workers {
+			if worker.PaneID == paneID {
+				return i
+			}
+		}
+	}
+	if envID != "" {
+		if index := findWorkerIndex(config, envID); index != -1 {
+			return index
+		}
+	}
+	if dir != "" {
+		for i, worker := range config.Workers {
+			if isInsideWorktreeDir(dir, config.ProjectPath, worker.WorktreePath) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func whoami(format string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return false
+	}
+
+	paneID := ""
+	if mux.Name() == "tmux" {
+		paneID = os.Getenv("TMUX_PANE")
+	}
+	envID := os.Getenv(workerIDEnv)
+	dir := invocationDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	index := findCurrentWorker(config, paneID, envID, dir)
+	if index == -1 {
+		if name, exists := config.otherWorkspaceOf(envID); envID != "" && exists {
+			fmt.Fprintf(os.Stderr, "Worker '%s' belongs to workspace '%s'; run 'gtw whoami --workspace %s'\n", envID, name, name)
+			return false
+		}
+		fmt.Fprintln(os.Stderr, "Not inside a worker")
+		return false
+	}
+	worker := config.Workers[index]
+	worker.Branch = getWorktreeBranch(worker.WorktreePath, worker.branchName())
+
+	if format != "" {
+		printFormatted(format, []WorkerView{newWorkerView(worker, mux.PaneExists)})
+		return true
+	}
+
+	worktree := worker.WorktreePath
+	if !filepath.IsAbs(worktree) && config.ProjectPath != "" {
+		worktree = filepath.Join(config.ProjectPath, worktree)
+	}
+	fmt.Printf("Worker: %s\n", worker.ID)
+	fmt.Printf("Branch: %s\n", worker.Branch)
+	fmt.Printf("Worktree: %s\n", worktree)
+	if workspace != "" {
+		fmt.Printf("Workspace: %s\n", workspace)
+	}
+	return true
+}
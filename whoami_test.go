@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindCurrentWorker(t *testing.T) {
+	root := filepath.FromSlash("/src/app")
+	config := &Config{
+		ProjectPath: root,
+		Workers: []Worker{
+			{ID: "a", PaneID: "%1", WorktreePath: filepath.FromSlash("worktree/a")},
+			{ID: "b", PaneID: "%2", WorktreePath: filepath.FromSlash("worktree/b")},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		paneID string
+		envID  string
+		dir    string
+		want   int
+	}{
+		{"pane", "%2", "", "", 1},
+		{"pane wins over env", "%1", "b", "", 0},
+		{"env", "", "b", "", 1},
+		{"unknown pane falls back to env", "%9", "a", "", 0},
+		{"worktree subdirectory", "", "", filepath.Join(root, "worktree", "b", "src"), 1},
+		{"worktree root", "", "", filepath.Join(root, "worktree", "a"), 0},
+		{"similar prefix", "", "", filepath.Join(root, "worktree", "ab"), -1},
+		{"project root", "", "", root, -1},
+		{"nothing", "", "", "", -1},
+	}
+	for _, tt := range tests {
+		if got := findCurrentWorker(config, tt.paneID, tt.envID, tt.dir); got != tt.want {
+			t.Errorf("%s: findCurrentWorker() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// wizardPrompter asks questions on out and reads the answers from in.
+type wizardPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints the question with its default and returns the trimmed answer,
+// or the default for an empty one. validate, when given, re-asks until the
+// answer is accepted.
+func (p *wizardPrompter) ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", errors.New("aborted")
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "  ❌ %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// suggestWorkerID returns the first free "worker-N" ID.
+func suggestWorkerID(config *Config) string {
+	for n := len(config.Workers) + 1; ; n++ {
+		id := "worker-" + strconv.Itoa(n)
+		if findWorkerIndex(config, id) == -1 && findArchivedIndex(config, id) == -1 {
+			if _, exists := config.otherWorkspaceOf(id); !exists {
+				return id
+			}
+		}
+	}
+}
+
+// checkNewWorkerID reports why id cannot be used for a new worker.
+func checkNewWorkerID(config *Config, id string) error {
+	if id == "" {
+		return errors.New("a worker ID is required")
+	}
+	if err := validateWorkerID(id); err != nil {
+		return err
+	}
+	if findWorkerIndex(config, id) != -1 {
+		return fmt.Errorf("worker '%s' already exists", id)
+	}
+	if findArchivedIndex(config, id) != -1 {
+		return fmt.Errorf("worker '%s' is archived", id)
+	}
+	if name, exists := config.otherWorkspaceOf(id); exists {
+		return fmt.Errorf("worker '%s' already exists in workspace '%s'", id, name)
+	}
+	return nil
+}
+
+// checkBaseRef reports whether git can resolve base to a commit.
+func checkBaseRef(base string) error {
+	if base == "" {
+		return nil
+	}
+	if err := gitCommand("rev-parse", "--verify", "--quiet", base+"^{commit}").Run(); err != nil {
+		return fmt.Errorf("'%s' is not a branch or commit", base)
+	}
+	return nil
+}
+
+// runAddWizard asks for the settings of a new worker, using id and opts
+// (from the command line) as defaults.
+func runAddWizard(p *wizardPrompter, config *Config, id string, opts AddOptions, checkBase func(string) error) (string, AddOptions, error) {
+	if id == "" {
+		id = suggestWorkerID(config)
+	}
+	id, err := p.ask("Worker ID", id, func(answer string) error { return checkNewWorkerID(config, answer) })
+	if err != nil {
+		return "", opts, err
+	}
+
+	baseDefault := opts.Base
+	if baseDefault == "" {
+		baseDefault = "HEAD"
+	}
+	base, err := p.ask("Base branch or commit", baseDefault, checkBase)
+	if err != nil {
+		return "", opts, err
+	}
+	opts.Base = base
+	if base == "HEAD" {
+		opts.Base = ""
+	}
+
+	if names := config.profileNames(); len(names) > 0 {
+		fmt.Fprintln(p.out, "Profiles:")
+		fmt.Fprintf(p.out, "  - (none): %s\n", config.InitCommand)
+		for _, name := range names {
+			fmt.Fprintf(p.out, "  - %s: %s\n", name, config.Profiles[name].InitCommand)
+		}
+		profile, err := p.ask("Profile (empty for none)", opts.Profile, func(answer string) error {
+			if _, ok := config.Profiles[answer]; answer != "" && !ok {
+				return fmt.Errorf("unknown profile '%s'", answer)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", opts, err
+		}
+		opts.Profile = profile
+	}
+
+	prompt, err := p.ask("Initial prompt or command to type after the init command (optional)", opts.Prompt, nil)
+	if err != nil {
+		return "", opts, err
+	}
+	opts.Prompt = prompt
+
+	if opts.Note == "" {
+		if opts.Note, err = p.ask("Note (optional)", "", nil); err != nil {
+			return "", opts, err
+		}
+	}
+	return id, opts, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunAddWizard(t *testing.T) {
+	config := &Config{
+		Workers:     []Worker{{ID: "worker-2"}},
+		InitCommand: "claude",
+		Profiles:    map[string]Profile{"review": {InitCommand: "claude --plan"}},
+	}
+	checkBase := func(base string) error {
+		if base != "HEAD" && base != "main" {
+			return errors.New("unknown base")
+		}
+		return nil
+	}
+
+	// Invalid answers are asked again: a taken ID, a bad base and an unknown profile
+	input := "worker-2\napi\nnope\nmain\nother\nreview\nfix the login bug\n\n"
+	var out bytes.Buffer
+	prompter := &wizardPrompter{in: bufio.NewReader(strings.NewReader(input)), out: &out}
+	id, opts, err := runAddWizard(prompter, config, "", AddOptions{}, checkBase)
+	if err != nil {
+		t.Fatalf("runAddWizard() error: %v\n%s", err, out.String())
+	}
+	if id != "api" || opts.Base != "main" || opts.Profile != "review" || opts.Prompt != "fix the login bug" {
+		t.Errorf("Unexpected result %q %+v", id, opts)
+	}
+	for _, want := range []string{"Worker ID [worker-3]", "already exists", "unknown base", "unknown profile 'other'"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Missing %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunAddWizardDefaults(t *testing.T) {
+	config := &Config{}
+	prompter := &wizardPrompter{in: bufio.NewReader(strings.NewReader("\n\n\n\n")), out: &bytes.Buffer{}}
+	id, opts, err := runAddWizard(prompter, config, "given", AddOptions{Note: "from flag"}, func(string) error { return nil })
+	if err != nil || id != "given" || opts.Base != "" || opts.Prompt != "" || opts.Note != "from flag" {
+		t.Errorf("runAddWizard() = %q, %+v, %v", id, opts, err)
+	}
+
+	prompter = &wizardPrompter{in: bufio.NewReader(strings.NewReader("")), out: &bytes.Buffer{}}
+	if _, _, err := runAddWizard(prompter, config, "", AddOptions{}, nil); err == nil {
+		t.Error("Expected end of input to abort")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Workspace is a named set of workers with its own session
+// (<project>-<workspace>) that shares the project's settings and worktree
+// directory with the default workspace.
+type Workspace struct {
+	Workers []Worker `json:"workers"`
+}
+
+// workspace is the workspace selected with --workspace ("" is the default).
+var workspace string
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func validateWorkspaceName(name string) error {
+	if name != "" && !workspaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid workspace name '%s' (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// selectWorkspace swaps the selected workspace's workers into c.Workers so
+// that every command operates on them. saveConfig swaps them back.
+func (c *Config) selectWorkspace(name string) {
+	if name == "" {
+		return
+	}
+	c.defaultWorkers = c.Workers
+	c.Workers = []Worker{}
+	if ws := c.Workspaces[name]; ws != nil && ws.Workers != nil {
+		c.Workers = ws.Workers
+	}
+}
+
+// persisted returns the config as it is stored on disk, with the selected
+// workspace's workers moved back under "workspaces".
+func (c *Config) persisted(name string) *Config {
+	if name == "" {
+		return c
+	}
+	saved := *c
+	saved.Workspaces = make(map[string]*Workspace, len(c.Workspaces)+1)
+	for key, ws := range c.Workspaces {
+		saved.Workspaces[key] = ws
+	}
+	saved.Workspaces[name] = &Workspace{Workers: c.Workers}
+	saved.Workers = c.defaultWorkers
+	if saved.Workers == nil {
+		saved.Workers = []Worker{}
+	}
+	return &saved
+}
+
+// otherWorkspaceWorkers returns the workers of every workspace other than
+// the selected one.
+func (c *Config) otherWorkspaceWorkers() []Worker {
+	var workers []Worker
+	if workspace != "" {
+		workers = append(workers, c.defaultWorkers...)
+	}
+	for name, ws := range c.Workspaces {
+		if name != workspace {
+			workers = append(workers, ws.Workers...)
+		}
+	}
+	return workers
+}
+
+// otherWorkspaceOf returns the workspace other than the selected one that
+// has a worker with id, as worktrees and branches are shared by all
+// workspaces. The default workspace is reported as "default".
+func (c *Config) otherWorkspaceOf(id string) (string, bool) {
+	if workspace != "" {
+		for _, worker := range c.defaultWorkers {
+			if worker.ID == id {
+				return "default", true
+			}
+		}
+	}
+	for name, ws := range c.Workspaces {
+		if name == workspace {
+			continue
+		}
+		for _, worker := range ws.Workers {
+			if worker.ID == id {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestWorkspaceSelection(t *testing.T) {
+	config := &Config{
+		Workers:    []Worker{{ID: "agent-1"}},
+		Workspaces: map[string]*Workspace{"ops": {Workers: []Worker{{ID: "deploy"}}}},
+	}
+
+	config.selectWorkspace("review")
+	if len(config.Workers) != 0 {
+		t.Fatalf("Expected a new workspace to start empty, got %v", config.Workers)
+	}
+	config.Workers = append(config.Workers, Worker{ID: "manual"})
+
+	saved := config.persisted("review")
+	if len(saved.Workers) != 1 || saved.Workers[0].ID != "agent-1" {
+		t.Errorf("Expected default workers to be kept, got %v", saved.Workers)
+	}
+	if ws := saved.Workspaces["review"]; ws == nil || len(ws.Workers) != 1 || ws.Workers[0].ID != "manual" {
+		t.Errorf("Expected 'manual' in workspace 'review', got %v", saved.Workspaces)
+	}
+	if len(saved.Workspaces["ops"].Workers) != 1 {
+		t.Errorf("Expected workspace 'ops' to be untouched")
+	}
+}
+
+func TestOtherWorkspaceOf(t *testing.T) {
+	old := workspace
+	workspace = "review"
+	defer func() { workspace = old }()
+
+	config := &Config{
+		Workers:    []Worker{{ID: "agent-1"}},
+		Workspaces: map[string]*Workspace{"ops": {Workers: []Worker{{ID: "deploy"}}}},
+	}
+	config.selectWorkspace(workspace)
+
+	if name, ok := config.otherWorkspaceOf("agent-1"); !ok || name != "default" {
+		t.Errorf("Expected 'agent-1' in the default workspace, got %q", name)
+	}
+	if name, ok := config.otherWorkspaceOf("deploy"); !ok || name != "ops" {
+		t.Errorf("Expected 'deploy' in workspace 'ops', got %q", name)
+	}
+	if _, ok := config.otherWorkspaceOf("new"); ok {
+		t.Error("Expected 'new' to be free")
+	}
+}
+
+func TestValidateWorkspaceName(t *testing.T) {
+	for _, name := range []string{"", "review", "agents_2", "a-b"} {
+		if err := validateWorkspaceName(name); err != nil {
+			t.Errorf("Expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"-x", "a b", "a:b", "a.b"} {
+		if err := validateWorkspaceName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var reason string
+	lockCmd := &cobra.Command{
+		Use:   "lock <worker-id>",
+		Short: "Lock a worker's worktree so that git and gtw do not remove or prune it",
+		Long: `Lock the worker's worktree with 'git worktree lock', e.g. when it lives on a
+network mount or removable drive that is not always available. gtw does
+not remove, prune or recreate a locked worktree: remove, archive and
+repair refuse to touch it until 'gtw unlock'.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { lockWorktree(args[0], true, reason) },
+	}
+	lockCmd.Flags().StringVar(&reason, "reason", "", "Why the worktree is locked (shown by git and gtw)")
+	rootCmd.AddCommand(lockCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "unlock <worker-id>",
+		Short: "Unlock a worker's worktree locked with 'gtw lock' or 'git worktree lock'",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { lockWorktree(args[0], false, "") },
+	})
+}
+
+// parseWorktreeLocks reads `git worktree list --porcelain` into the lock
+// reason of each locked worktree, by path. A worktree locked without a
+// reason maps to "".
+func parseWorktreeLocks(output string) map[string]string {
+	locks := make(map[string]string)
+	path := ""
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case line == "locked":
+			locks[path] = ""
+		case strings.HasPrefix(line, "locked "):
+			locks[path] = strings.TrimPrefix(line, "locked ")
+		}
+	}
+	return locks
+}
+
+// worktreeLocks lists the locked worktrees of the repository.
+func worktreeLocks() (map[string]string, error) {
+	output, err := gitCommand("worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list failed: %v", err)
+	}
+	return parseWorktreeLocks(string(output)), nil
+}
+
+// lockReason looks up a worktree path in locks. git lists absolute paths
+// with symlinks resolved, so path is compared in both forms. The worktree
+// itself may be missing (an unmounted share), so then only its parent is
+// resolved.
+func lockReason(locks map[string]string, path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	if reason, ok := locks[abs]; ok {
+		return reason, true
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		parent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+		if err != nil {
+			return "", false
+		}
+		resolved = filepath.Join(parent, filepath.Base(abs))
+	}
+	reason, ok := locks[resolved]
+	return reason, ok
+}
+
+// worktreeLocked reports whether the worktree at path is locked, and why.
+// When git cannot tell, the worktree counts as unlocked.
+func worktreeLocked(path string) (string, bool) {
+	locks, err := worktreeLocks()
+	if err != nil {
+		return "", false
+	}
+	return lockReason(locks, path)
+}
+
+// describeLock formats a lock for messages.
+func describeLock(reason string) string {
+	if reason == "" {
+		return "locked"
+	}
+	return "locked: " + reason
+}
+
+// refuseLocked prints why a locked worktree is left alone and reports
+// whether it is locked.
+func refuseLocked(id, path string) bool {
+	reason, locked := worktreeLocked(path)
+	if !locked {
+		return false
+	}
+	fmt.Printf("❌ Worktree '%s' of worker '%s' is %s\n", path, id, describeLock(reason))
+	fmt.Printf("Run 'gtw unlock %s' first\n", id)
+	return true
+}
+
+func lockWorktree(id string, lock bool, reason string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	index := findWorkerIndex(config, id)
+	if index == -1 {
+		fmt.Printf("Worker '%s' not found\n", id)
+		return
+	}
+	worker := config.Workers[index]
+
+	current, locked := worktreeLocked(worker.WorktreePath)
+	if lock && locked {
+		fmt.Printf("Worktree of worker '%s' is already %s\n", id, describeLock(current))
+		return
+	}
+	if !lock && !locked {
+		fmt.Printf("Worktree of worker '%s' is not locked\n", id)
+		return
+	}
+
+	args := []string{"worktree", "unlock", worker.WorktreePath}
+	if lock {
+		args = []string{"worktree", "lock", worker.WorktreePath}
+		if reason != "" {
+			args = []string{"worktree", "lock", "--reason", reason, worker.WorktreePath}
+		}
+	}
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		fmt.Printf("Git output: %s\n", strings.TrimSpace(string(output)))
+		return
+	}
+
+	if lock {
+		recordEvent(EventWorktreeLocked, id, reason)
+		fmt.Printf("🔒 Locked worktree of worker '%s'\n", id)
+	} else {
+		recordEvent(EventWorktreeUnlocked, id, "")
+		fmt.Printf("🔓 Unlocked worktree of worker '%s'\n", id)
+	}
+}
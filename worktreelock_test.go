@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorktreeLocks(t *testing.T) {
+	output := `worktree /repo
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /repo/worktree/a
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/a
+locked on the NAS
+
+worktree /repo/worktree/b
+HEAD 3333333333333333333333333333333333333333
+branch refs/heads/b
+locked
+
+worktree /repo/worktree/c
+HEAD 4444444444444444444444444444444444444444
+branch refs/heads/c
+prunable gitdir file points to non-existent location
+`
+	locks := parseWorktreeLocks(output)
+	if len(locks) != 2 {
+		t.Fatalf("Expected 2 locked worktrees, got %v", locks)
+	}
+	if reason, ok := locks["/repo/worktree/a"]; !ok || reason != "on the NAS" {
+		t.Errorf("Unexpected lock of a: %q, %v", reason, ok)
+	}
+	if reason, ok := locks["/repo/worktree/b"]; !ok || reason != "" {
+		t.Errorf("Expected b to be locked without a reason, got %q, %v", reason, ok)
+	}
+}
+
+func TestLockReasonResolvesPaths(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real")
+	link := filepath.Join(dir, "link")
+	if err := os.MkdirAll(filepath.Join(real, "w1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	resolved, _ := filepath.EvalSymlinks(real)
+	locks := map[string]string{
+		filepath.Join(resolved, "w1"):      "mount",
+		filepath.Join(resolved, "missing"): "",
+	}
+
+	if reason, ok := lockReason(locks, filepath.Join(link, "w1")); !ok || reason != "mount" {
+		t.Errorf("Expected the symlinked path to match, got %q, %v", reason, ok)
+	}
+	// An unmounted worktree no longer exists; its parent is resolved instead
+	if _, ok := lockReason(locks, filepath.Join(link, "missing")); !ok {
+		t.Error("Expected the missing worktree to match")
+	}
+	if _, ok := lockReason(locks, filepath.Join(link, "other")); ok {
+		t.Error("Expected an unlocked worktree not to match")
+	}
+}
+
+func TestDescribeLock(t *testing.T) {
+	if got := describeLock(""); got != "locked" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if got := describeLock("on the NAS"); got != "locked: on the NAS" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
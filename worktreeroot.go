@@ -0,0 +1,353 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// WorktreePrefixData is the data given to a worktree_prefix template.
+type WorktreePrefixData struct {
+	Project   string // Base name of the project directory
+	Workspace string // Selected workspace, or ""
+}
+
+// expandWorktreePrefix renders the worktree_prefix template and expands a
+// leading "~". The result is absolute, or relative to the project root.
+func expandWorktreePrefix(prefix string, data WorktreePrefixData) (string, error) {
+	if prefix == "" {
+		prefix = getDefaultWorktreePrefix()
+	}
+	if strings.Contains(prefix, "{{") {
+		tmpl, err := template.New("worktree_prefix").Option("missingkey=error").Parse(prefix)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", err
+		}
+		prefix = b.String()
+	}
+	if prefix == "~" || strings.HasPrefix(prefix, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand '~': %v", err)
+		}
+		prefix = filepath.Join(home, prefix[1:])
+	}
+	if strings.TrimSpace(prefix) == "" {
+		return "", fmt.Errorf("worktree_prefix is empty")
+	}
+	return filepath.Clean(prefix), nil
+}
+
+// resolvedWorktreePrefix is the configured worktree prefix with its template
+// and "~" expanded. An invalid template falls back to the default.
+func resolvedWorktreePrefix(config *Config) string {
+	project := getCurrentProjectName()
+	if config.ProjectPath != "" {
+		project = filepath.Base(config.ProjectPath)
+	}
+	prefix, err := expandWorktreePrefix(config.WorktreePrefix, WorktreePrefixData{Project: project, Workspace: workspace})
+	if err != nil {
+		fmt.Printf("Warning: Invalid worktree_prefix %q: %v; using %s\n", config.WorktreePrefix, err, getDefaultWorktreePrefix())
+		return getDefaultWorktreePrefix()
+	}
+	return prefix
+}
+
+// workerWorktreePath returns where the worktree of a new worker goes:
+// "./<prefix>/<id>" inside the project, or an absolute path outside it.
+func workerWorktreePath(config *Config, id string) string {
+	prefix := resolvedWorktreePrefix(config)
+	if filepath.IsAbs(prefix) {
+		return filepath.Join(prefix, id)
+	}
+	return filepath.Join("./"+prefix, id)
+}
+
+// worktreeRootInRepo returns the worktree root relative to the project
+// when it lies inside it.
+func worktreeRootInRepo(config *Config) (string, bool) {
+	projectPath := config.ProjectPath
+	if projectPath == "" {
+		projectPath, _ = os.Getwd()
+	}
+	rel, err := filepath.Rel(projectPath, worktreeRoot(config))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// worktreesIgnored reports whether git ignores the worktree root given
+// relative to the project. An error means git cannot tell, e.g. outside a
+// work tree (bare clones).
+func worktreesIgnored(rel string) (bool, error) {
+	err := gitCommand("check-ignore", "-q", rel+"/").Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	}
+	return false, fmt.Errorf("git check-ignore failed: %v", err)
+}
+
+// appendIgnoreEntry adds a pattern to a gitignore-style file, creating it
+// when needed.
+func appendIgnoreEntry(file, entry string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	text := string(existing)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += "# Worker worktrees (added by gtw)\n" + entry + "\n"
+	return os.WriteFile(file, []byte(text), 0644)
+}
+
+// ensureWorktreesExcluded adds the worktree root to .git/info/exclude when
+// it lies inside the repository and git does not ignore it yet, so that
+// `git status` and tools walking the source tree skip the worktrees.
+func ensureWorktreesExcluded(config *Config) error {
+	rel, inside := worktreeRootInRepo(config)
+	if !inside {
+		return nil
+	}
+	if ignored, err := worktreesIgnored(rel); ignored || err != nil {
+		return nil
+	}
+	output, err := gitCommand("rev-parse", "--git-path", "info/exclude").Output()
+	if err != nil {
+		return fmt.Errorf("could not find .git/info/exclude: %v", err)
+	}
+	exclude := strings.TrimSpace(string(output))
+	entry := "/" + rel + "/"
+	if err := appendIgnoreEntry(exclude, entry); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s to %s\n", entry, exclude)
+	return nil
+}
+
+// ignoreWorktrees keeps the worktree root out of git when a session is
+// initialized. With gitignore (or when the user agrees) it goes to
+// .gitignore, shared with everyone who clones the project; otherwise to
+// .git/info/exclude.
+func ignoreWorktrees(config *Config, gitignore bool) {
+	rel, inside := worktreeRootInRepo(config)
+	if !inside {
+		return
+	}
+	if ignored, err := worktreesIgnored(rel); ignored || err != nil {
+		return
+	}
+	entry := "/" + rel + "/"
+	if gitignore || confirm(fmt.Sprintf("Add %s to .gitignore? (otherwise it is only excluded in this clone)", entry)) {
+		if err := appendIgnoreEntry(filepath.Join(config.ProjectPath, ".gitignore"), entry); err != nil {
+			fmt.Printf("Warning: Could not update .gitignore: %v\n", err)
+		} else {
+			fmt.Printf("Added %s to .gitignore; commit it to share it\n", entry)
+			return
+		}
+	}
+	if err := ensureWorktreesExcluded(config); err != nil {
+		fmt.Printf("Warning: Could not exclude the worktree directory from git: %v\n", err)
+	}
+}
+
+// trackedWorktreeFiles lists the files under the worktree root that are
+// committed or staged, e.g. after a `git add .` before the directory was
+// ignored.
+func trackedWorktreeFiles(config *Config) (string, []string) {
+	rel, inside := worktreeRootInRepo(config)
+	if !inside {
+		return "", nil
+	}
+	output, err := gitCommand("ls-files", "-z", "--", rel).Output()
+	if err != nil || len(output) == 0 {
+		return rel, nil
+	}
+	return rel, strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+}
+
+// untrackWorktrees removes the worktree root from the index, keeping the
+// files, and excludes it so it is not added again.
+func untrackWorktrees(config *Config, rel string) error {
+	if output, err := gitCommand("rm", "-r", "-q", "--cached", "--", rel).CombinedOutput(); err != nil {
+		return fmt.Errorf("git rm --cached failed: %s", strings.TrimSpace(string(output)))
+	}
+	return ensureWorktreesExcluded(config)
+}
+
+// WorktreeEntry is a worktree listed by `git worktree list --porcelain`.
+type WorktreeEntry struct {
+	Path        string
+	Head        string
+	Branch      string // Without refs/heads/; "" when detached
+	Bare        bool
+	Locked      bool
+	LockReason  string
+	Prunable    bool
+	PruneReason string
+}
+
+// parseWorktreeList parses `git worktree list --porcelain`. The first
+// entry is the main worktree.
+func parseWorktreeList(output string) []WorktreeEntry {
+	var entries []WorktreeEntry
+	var entry *WorktreeEntry
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		if key == "worktree" {
+			entries = append(entries, WorktreeEntry{Path: value})
+			entry = &entries[len(entries)-1]
+			continue
+		}
+		if entry == nil {
+			continue
+		}
+		switch key {
+		case "HEAD":
+			entry.Head = value
+		case "branch":
+			entry.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			entry.Bare = true
+		case "locked":
+			entry.Locked, entry.LockReason = true, value
+		case "prunable":
+			entry.Prunable, entry.PruneReason = true, value
+		}
+	}
+	return entries
+}
+
+// listWorktrees returns the worktrees of the repository.
+func listWorktrees() ([]WorktreeEntry, error) {
+	output, err := gitCommand("worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list failed: %v", err)
+	}
+	return parseWorktreeList(string(output)), nil
+}
+
+// gitWorktreePath returns path in the form git lists worktrees in: absolute
+// with symlinks resolved. A missing worktree (an unmounted share) only has
+// its parent resolved.
+func gitWorktreePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(parent, filepath.Base(abs))
+	}
+	return abs
+}
+
+// findWorktreeEntry returns the entry git lists for the worktree at path,
+// or nil when git does not know it.
+func findWorktreeEntry(entries []WorktreeEntry, path string) *WorktreeEntry {
+	abs, _ := filepath.Abs(path)
+	resolved := gitWorktreePath(path)
+	for i := range entries {
+		if entries[i].Path == abs || entries[i].Path == resolved {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// worktreeInconsistencies reconciles the workers with the worktrees git
+// lists, which is what `git worktree add/remove` keep up to date wherever
+// worktree_prefix points. A worker's worktree is missing when its
+// directory is gone or git does not list it; a linked worktree under the
+// worktree root that no worker of any workspace uses is orphaned; entries
+// git would prune are reported on their own.
+func worktreeInconsistencies(config *Config, entries []WorktreeEntry) []Inconsistency {
+	var inconsistencies []Inconsistency
+	used := make(map[string]bool)
+	for _, worker := range config.Workers {
+		entry := findWorktreeEntry(entries, worker.WorktreePath)
+		if entry != nil {
+			used[entry.Path] = true
+		}
+		_, statErr := os.Stat(worker.WorktreePath)
+		switch {
+		case os.IsNotExist(statErr):
+			description := fmt.Sprintf("Worker '%s' has pane but missing worktree", worker.ID)
+			locked := entry != nil && entry.Locked
+			if locked {
+				description += fmt.Sprintf(" (worktree is %s; is its mount available?)", describeLock(entry.LockReason))
+			} else if entry != nil && entry.Prunable {
+				description += fmt.Sprintf(" (git lists it as prunable: %s)", entry.PruneReason)
+			}
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:        MissingWorktree,
+				WorkerID:    worker.ID,
+				Locked:      locked,
+				Description: description,
+			})
+		case statErr == nil && (entry == nil || entry.Prunable):
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:        MissingWorktree,
+				WorkerID:    worker.ID,
+				Path:        worker.WorktreePath,
+				Description: fmt.Sprintf("Worker '%s' has a directory at %s but git does not list it as a worktree", worker.ID, worker.WorktreePath),
+			})
+		}
+	}
+	// Worktrees of other workspaces share the directory
+	for _, worker := range config.otherWorkspaceWorkers() {
+		if entry := findWorktreeEntry(entries, worker.WorktreePath); entry != nil {
+			used[entry.Path] = true
+		}
+	}
+
+	root := gitWorktreePath(worktreeRoot(config))
+	for i, entry := range entries {
+		if i == 0 || entry.Bare || used[entry.Path] || nameIgnored(filepath.Base(entry.Path), config.CheckIgnore) {
+			continue
+		}
+		name := filepath.Base(entry.Path)
+		switch {
+		case entry.Prunable:
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:        PrunableWorktree,
+				WorkerID:    name,
+				Path:        entry.Path,
+				Locked:      entry.Locked,
+				Description: fmt.Sprintf("Worktree %s is registered in git but %s", entry.Path, entry.PruneReason),
+			})
+		case pathInside(entry.Path, root) && entry.Path != root:
+			description := fmt.Sprintf("Worktree '%s' exists but no worker in config", name)
+			if entry.Locked {
+				description += fmt.Sprintf(" (%s)", describeLock(entry.LockReason))
+			}
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Type:        OrphanedWorktree,
+				WorkerID:    name,
+				Path:        importedWorktreePath(gitWorktreePath(config.ProjectPath), entry.Path),
+				Locked:      entry.Locked,
+				Description: description,
+			})
+		}
+	}
+	return inconsistencies
+}
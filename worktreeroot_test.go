@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandWorktreePrefix(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	data := WorktreePrefixData{Project: "app", Workspace: "review"}
+	tests := map[string]string{
+		"":                                  "worktree",
+		"work":                              "work",
+		"/srv/worktrees":                    "/srv/worktrees",
+		"~":                                 home,
+		"~/worktrees/{{.Project}}":          filepath.Join(home, "worktrees", "app"),
+		"/tmp/{{.Project}}-{{.Workspace}}/": "/tmp/app-review",
+	}
+	for prefix, want := range tests {
+		got, err := expandWorktreePrefix(prefix, data)
+		if err != nil || got != want {
+			t.Errorf("expandWorktreePrefix(%q) = %q, %v, want %q", prefix, got, err, want)
+		}
+	}
+	for _, prefix := range []string{"{{.Project", "{{.Nope}}", "{{.Workspace}}"} {
+		if _, err := expandWorktreePrefix(prefix, WorktreePrefixData{Project: "app"}); err == nil {
+			t.Errorf("Expected %q to be rejected", prefix)
+		}
+	}
+}
+
+func TestWorkerWorktreePath(t *testing.T) {
+	if got := workerWorktreePath(&Config{WorktreePrefix: "work"}, "w1"); got != filepath.Join("work", "w1") {
+		t.Errorf("Expected a path inside the project, got %q", got)
+	}
+	if got := workerWorktreePath(&Config{WorktreePrefix: "/srv/{{.Project}}", ProjectPath: "/src/app"}, "w1"); got != "/srv/app/w1" {
+		t.Errorf("Expected an absolute path, got %q", got)
+	}
+}
+
+func TestEnsureWorktreesExcluded(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	t.Chdir(dir)
+	exclude := filepath.Join(dir, ".git", "info", "exclude")
+
+	config := &Config{ProjectPath: dir, WorktreePrefix: "worktree"}
+	for i := 0; i < 2; i++ {
+		if err := ensureWorktreesExcluded(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(exclude)
+	if strings.Count(string(data), "/worktree/") != 1 {
+		t.Errorf("Expected the worktree directory to be excluded once, got:\n%s", data)
+	}
+
+	// Roots outside the repository are left alone
+	before := string(data)
+	config.WorktreePrefix = t.TempDir()
+	if err := ensureWorktreesExcluded(config); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exclude); string(data) != before {
+		t.Errorf("Expected no change for an outside root, got:\n%s", data)
+	}
+}
+
+func TestTrackedWorktreeFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	t.Chdir(dir)
+	os.MkdirAll(filepath.Join(dir, "worktree", "w1"), 0755)
+	os.WriteFile(filepath.Join(dir, "worktree", "w1", "my notes.txt"), []byte("x"), 0644)
+	git("add", ".")
+	git("commit", "-q", "-m", "oops")
+
+	config := &Config{ProjectPath: dir}
+	rel, files := trackedWorktreeFiles(config)
+	if rel != "worktree" || len(files) != 1 || files[0] != "worktree/w1/my notes.txt" {
+		t.Fatalf("Expected the committed file, got %q %q", rel, files)
+	}
+	if err := untrackWorktrees(config, rel); err != nil {
+		t.Fatal(err)
+	}
+	if _, files := trackedWorktreeFiles(config); len(files) != 0 {
+		t.Errorf("Expected no tracked files after untracking, got %q", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "worktree", "w1", "my notes.txt")); err != nil {
+		t.Errorf("Expected the file to be kept: %v", err)
+	}
+	if ignored, err := worktreesIgnored(rel); !ignored || err != nil {
+		t.Errorf("Expected the directory to be excluded, got %v, %v", ignored, err)
+	}
+}
+
+func TestIgnoreWorktreesGitignore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	t.Chdir(dir)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules"), 0644)
+
+	config := &Config{ProjectPath: dir, WorktreePrefix: "work"}
+	ignoreWorktrees(config, true)
+	ignoreWorktrees(config, true)
+	data, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if !strings.HasPrefix(string(data), "node_modules\n") || strings.Count(string(data), "/work/") != 1 {
+		t.Errorf("Unexpected .gitignore:\n%s", data)
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /src/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/app/worktree/w1
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/w1
+locked on a usb drive
+
+worktree /src/app/worktree/w2
+HEAD 3333333333333333333333333333333333333333
+detached
+prunable gitdir file points to non-existent location
+`
+	entries := parseWorktreeList(output)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	if entries[0].Branch != "main" || entries[0].Locked {
+		t.Errorf("Unexpected main worktree: %+v", entries[0])
+	}
+	if entries[1].Branch != "feature/w1" || !entries[1].Locked || entries[1].LockReason != "on a usb drive" {
+		t.Errorf("Unexpected locked worktree: %+v", entries[1])
+	}
+	if entries[2].Branch != "" || !entries[2].Prunable || entries[2].PruneReason != "gitdir file points to non-existent location" {
+		t.Errorf("Unexpected prunable worktree: %+v", entries[2])
+	}
+}
+
+func TestWorktreeInconsistencies(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	t.Chdir(dir)
+	for _, name := range []string{"ok", "unregistered", "orphan", "review"} {
+		os.MkdirAll(filepath.Join(dir, "worktree", name), 0755)
+	}
+	os.MkdirAll(filepath.Join(dir, "elsewhere"), 0755)
+
+	config := &Config{
+		ProjectPath: dir,
+		Workers: []Worker{
+			{ID: "ok", WorktreePath: "./worktree/ok"},
+			{ID: "gone", WorktreePath: "./worktree/gone"},
+			{ID: "unmounted", WorktreePath: "./worktree/unmounted"},
+			{ID: "unregistered", WorktreePath: "./worktree/unregistered"},
+		},
+		Workspaces: map[string]*Workspace{"review": {Workers: []Worker{{ID: "review", WorktreePath: "./worktree/review"}}}},
+	}
+	entries := []WorktreeEntry{
+		{Path: dir, Branch: "main"},
+		{Path: filepath.Join(dir, "worktree", "ok"), Branch: "ok"},
+		{Path: filepath.Join(dir, "worktree", "gone"), Branch: "gone", Prunable: true, PruneReason: "gitdir file points to non-existent location"},
+		{Path: filepath.Join(dir, "worktree", "unmounted"), Branch: "unmounted", Locked: true, LockReason: "usb"},
+		{Path: filepath.Join(dir, "worktree", "orphan"), Branch: "orphan", Locked: true},
+		{Path: filepath.Join(dir, "worktree", "review"), Branch: "review"},
+		{Path: filepath.Join(dir, "elsewhere"), Branch: "mine"},
+		{Path: "/old/clone/stale", Branch: "stale", Prunable: true, PruneReason: "gitdir file points to non-existent location"},
+	}
+
+	found := make(map[string]Inconsistency)
+	for _, inc := range worktreeInconsistencies(config, entries) {
+		found[inc.Type.String()+":"+inc.WorkerID] = inc
+	}
+	if len(found) != 5 {
+		t.Errorf("Expected 5 inconsistencies, got %+v", found)
+	}
+	if inc, ok := found["missing_worktree:gone"]; !ok || !strings.Contains(inc.Description, "prunable") {
+		t.Errorf("Expected the prunable worktree of gone to be missing, got %+v", inc)
+	}
+	if inc, ok := found["missing_worktree:unmounted"]; !ok || !inc.Locked || !strings.Contains(inc.Description, "locked: usb") {
+		t.Errorf("Expected the locked worktree of unmounted to be missing, got %+v", inc)
+	}
+	if inc, ok := found["missing_worktree:unregistered"]; !ok || inc.Path != "./worktree/unregistered" {
+		t.Errorf("Expected the unregistered directory to be reported, got %+v", inc)
+	}
+	if inc, ok := found["orphaned_worktree:orphan"]; !ok || !inc.Locked || inc.Path != "worktree/orphan" {
+		t.Errorf("Expected the locked orphaned worktree, got %+v", inc)
+	}
+	if inc, ok := found["prunable_worktree:stale"]; !ok || inc.Path != "/old/clone/stale" {
+		t.Errorf("Expected the stale entry to be prunable, got %+v", inc)
+	}
+}